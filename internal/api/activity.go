@@ -0,0 +1,71 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) handleGetActivity(w http.ResponseWriter, r *http.Request) {
+	activity, err := h.introspector.GetActivity(r.Context(), h.config.AppName)
+	if err != nil {
+		h.respondError(w, ErrActivityError, "Failed to load activity", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, activity)
+}
+
+func (h *Handler) handleGetLockWaits(w http.ResponseWriter, r *http.Request) {
+	report, err := h.introspector.GetLockWaits(r.Context())
+	if err != nil {
+		h.respondError(w, ErrActivityError, "Failed to load lock waits", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, report)
+}
+
+type signalBackendRequest struct {
+	Terminate  bool  `json:"terminate"`  // Close the connection instead of cancelling the query
+	ConfirmPID int32 `json:"confirmPid"` // Must repeat the PID from the path
+}
+
+type signalBackendData struct {
+	PID        int32 `json:"pid"`
+	Terminated bool  `json:"terminated"`
+}
+
+func (h *Handler) handleSignalBackend(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	pid, err := strconv.ParseInt(r.PathValue("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		h.respondError(w, ErrInvalidRequest, "Invalid backend PID", http.StatusBadRequest, nil)
+		return
+	}
+
+	var req signalBackendRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.ConfirmPID != int32(pid) {
+		h.respondError(w, ErrConfirmationRequired, "Confirm by repeating the backend PID", http.StatusBadRequest, nil)
+		return
+	}
+
+	ok, err := h.introspector.SignalBackend(r.Context(), int32(pid), req.Terminate)
+	if err != nil {
+		h.respondError(w, ErrActivityError, "Failed to signal backend", http.StatusInternalServerError, err)
+		return
+	}
+	if !ok {
+		h.respondError(w, ErrBackendNotFound, "Backend not found in the current database", http.StatusNotFound, nil)
+		return
+	}
+
+	log.Printf("[ADMIN] Signalled backend %d (terminate=%v)", pid, req.Terminate)
+	respondJSON(w, signalBackendData{PID: int32(pid), Terminated: req.Terminate})
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+func (h *Handler) handleCountOrphans(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	check := schema.OrphanCheck{
+		Table:            q.Get("table"),
+		Column:           q.Get("column"),
+		ReferencesTable:  q.Get("referencesTable"),
+		ReferencesColumn: q.Get("referencesColumn"),
+	}
+
+	if !h.validateIdentifier(w, check.Table, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, check.Column, "column name", ErrInvalidColName) ||
+		!h.validateIdentifier(w, check.ReferencesTable, "referenced table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, check.ReferencesColumn, "referenced column name", ErrInvalidColName) {
+		return
+	}
+
+	report, err := h.introspector.CountOrphans(r.Context(), check)
+	if err != nil {
+		h.respondError(w, ErrAnalysisError, "Failed to check for orphaned rows", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, report)
+}
+
+type inferredRelationshipsData struct {
+	Relationships []schema.InferredRelationship `json:"relationships"`
+}
+
+// handleInferRelationships returns probable undeclared foreign keys.
+// Each entry can be promoted by posting it to /api/tables/{table}/foreign-keys.
+func (h *Handler) handleInferRelationships(w http.ResponseWriter, r *http.Request) {
+	s, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, inferredRelationshipsData{Relationships: schema.InferRelationships(s)})
+}
+
+// handleGetDependencies returns the object dependency graph with a safe
+// creation order; drop objects in reverse order.
+func (h *Handler) handleGetDependencies(w http.ResponseWriter, r *http.Request) {
+	graph, err := h.introspector.GetDependencyGraph(r.Context())
+	if err != nil {
+		h.respondError(w, ErrAnalysisError, "Failed to build dependency graph", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, graph)
+}
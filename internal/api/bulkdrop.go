@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// maxBulkDropTables bounds how many tables one bulk drop may remove.
+const maxBulkDropTables = 100
+
+type bulkDropRequest struct {
+	Tables []string `json:"tables"`
+}
+
+type bulkDropData struct {
+	DryRun bool `json:"dryRun,omitempty"`
+	*schema.BulkDropPlan
+}
+
+// handleBulkDropTables drops several tables in one transaction, each before
+// the tables it references. If foreign keys on other tables or views depend
+// on the set, the request is refused with the plan unless ?cascade=true.
+// ?dryRun=true returns the plan without dropping anything.
+func (h *Handler) handleBulkDropTables(w http.ResponseWriter, r *http.Request) {
+	var req bulkDropRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if len(req.Tables) == 0 {
+		h.respondError(w, ErrMissingField, "At least one table is required", http.StatusBadRequest, nil)
+		return
+	}
+	if len(req.Tables) > maxBulkDropTables {
+		h.respondError(w, ErrInvalidRequest, "Too many tables in one request", http.StatusBadRequest, nil)
+		return
+	}
+	for idx, table := range req.Tables {
+		if !h.validateIdentifier(w, table, "table name", ErrInvalidTableName) {
+			return
+		}
+		if slices.Contains(req.Tables[:idx], table) {
+			h.respondError(w, ErrInvalidRequest, "Table "+table+" is listed twice", http.StatusBadRequest, nil)
+			return
+		}
+	}
+	cascade := r.URL.Query().Get("cascade") == "true"
+
+	plan, err := h.introspector.PlanBulkDrop(r.Context(), req.Tables, cascade)
+	if err != nil {
+		if errors.Is(err, schema.ErrTableNotFound) {
+			h.respondError(w, ErrTableNotFound, "One or more tables do not exist", http.StatusNotFound, err)
+			return
+		}
+		h.respondError(w, ErrDropTable, "Failed to plan drop", http.StatusInternalServerError, err)
+		return
+	}
+	if r.URL.Query().Get("dryRun") == "true" {
+		respondJSON(w, bulkDropData{DryRun: true, BulkDropPlan: plan})
+		return
+	}
+	if len(plan.External) > 0 && !cascade {
+		h.respondErrorDetails(w, ErrHasDependents, "Objects outside these tables depend on them; retry with cascade=true to drop them too",
+			http.StatusConflict, plan)
+		return
+	}
+
+	if err := h.introspector.DropTables(r.Context(), plan); err != nil {
+		h.respondError(w, ErrDropTable, "Failed to drop tables", http.StatusInternalServerError, err)
+		return
+	}
+
+	log.Printf("[SCHEMA] Dropped %d tables (cascade=%v, %d external dependents)", len(plan.Tables), cascade, len(plan.External))
+	respondJSON(w, bulkDropData{BulkDropPlan: plan})
+}
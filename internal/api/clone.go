@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type cloneTableRequest struct {
+	Name string `json:"name"`
+	schema.CloneOptions
+}
+
+type cloneTableData struct {
+	Table  string `json:"table"`
+	Source string `json:"source"`
+}
+
+// handleCloneTable creates an empty table with the same structure as an
+// existing one, for trying out schema changes on a copy.
+func (h *Handler) handleCloneTable(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	var req cloneTableRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if !h.validateIdentifier(w, req.Name, "new table name", ErrInvalidTableName) {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.CloneTable(ctx, tableName, req.Name, req.CloneOptions); err != nil {
+		h.respondError(w, ErrCreateTable, "Failed to clone table", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, cloneTableData{Table: req.Name, Source: tableName})
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// maxCommentLength bounds table, column, constraint, and index comments.
+const maxCommentLength = 4000
+
+type commentRequest struct {
+	Comment string `json:"comment"`
+}
+
+type commentData struct {
+	Table      string `json:"table"`
+	Column     string `json:"column,omitempty"`
+	Constraint string `json:"constraint,omitempty"`
+	Index      string `json:"index,omitempty"`
+	Comment    string `json:"comment"`
+}
+
+// decodeComment reads and validates a comment body. An empty comment is
+// allowed and clears the existing one.
+func (h *Handler) decodeComment(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req commentRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return "", false
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	if len(comment) > maxCommentLength {
+		h.respondError(w, ErrInvalidRequest, "Comment is too long", http.StatusBadRequest, nil)
+		return "", false
+	}
+	return comment, true
+}
+
+func (h *Handler) handleSetTableComment(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	comment, ok := h.decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.SetTableComment(ctx, tableName, comment); err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to set table comment", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, commentData{Table: tableName, Comment: comment})
+}
+
+func (h *Handler) handleSetColumnComment(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	columnName := r.PathValue("columnName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, columnName, "column name", ErrInvalidColName) {
+		return
+	}
+
+	comment, ok := h.decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.SetColumnComment(ctx, tableName, columnName, comment); err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to set column comment", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, commentData{Table: tableName, Column: columnName, Comment: comment})
+}
+
+func (h *Handler) handleSetConstraintComment(w http.ResponseWriter, r *http.Request) {
+	tableName, constraintName, ok := h.constraintPathValues(w, r)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.SetConstraintComment(ctx, tableName, constraintName, comment); err != nil {
+		h.respondError(w, ErrConstraintError, "Failed to set constraint comment", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, commentData{Table: tableName, Constraint: constraintName, Comment: comment})
+}
+
+func (h *Handler) handleSetIndexComment(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	indexName := r.PathValue("indexName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, indexName, "index name", ErrInvalidRequest) {
+		return
+	}
+
+	comment, ok := h.decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.SetIndexComment(ctx, tableName, indexName, comment); err != nil {
+		if errors.Is(err, schema.ErrIndexNotFound) {
+			h.respondError(w, ErrIndexNotFound, "Index not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrDatabaseError, "Failed to set index comment", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, commentData{Table: tableName, Index: indexName, Comment: comment})
+}
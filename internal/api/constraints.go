@@ -0,0 +1,230 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type tableConstraintsData struct {
+	Table       string                   `json:"table"`
+	Constraints []schema.TableConstraint `json:"constraints"`
+}
+
+// handleGetTableConstraints lists a table's constraints with their full
+// definitions, enough to generate DROP and re-ADD CONSTRAINT statements.
+func (h *Handler) handleGetTableConstraints(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	constraints, err := h.introspector.GetTableConstraints(r.Context(), tableName)
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load table constraints", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, tableConstraintsData{Table: tableName, Constraints: constraints})
+}
+
+// constraintPathValues validates the table and constraint names in the path
+// and checks that the constraint exists. Returns false if a check failed
+// (error response already sent).
+func (h *Handler) constraintPathValues(w http.ResponseWriter, r *http.Request) (tableName, constraintName string, ok bool) {
+	tableName = r.PathValue("tableName")
+	constraintName = r.PathValue("constraintName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, constraintName, "constraint name", ErrInvalidRequest) {
+		return "", "", false
+	}
+
+	constraints, err := h.introspector.GetTableConstraints(r.Context(), tableName)
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load table constraints", http.StatusInternalServerError, err)
+		return "", "", false
+	}
+	if !slices.ContainsFunc(constraints, func(c schema.TableConstraint) bool { return c.Name == constraintName }) {
+		h.respondError(w, ErrConstraintNotFound, "Constraint not found", http.StatusNotFound, nil)
+		return "", "", false
+	}
+	return tableName, constraintName, true
+}
+
+type dropConstraintData struct {
+	Table      string `json:"table"`
+	Constraint string `json:"constraint"`
+	Cascade    bool   `json:"cascade"`
+}
+
+// handleDropConstraint drops a constraint by name. ?cascade=true also drops
+// foreign keys that depend on a primary key or unique constraint.
+func (h *Handler) handleDropConstraint(w http.ResponseWriter, r *http.Request) {
+	tableName, constraintName, ok := h.constraintPathValues(w, r)
+	if !ok {
+		return
+	}
+	cascade := r.URL.Query().Get("cascade") == "true"
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.DropConstraint(ctx, tableName, constraintName, cascade); err != nil {
+		h.respondError(w, ErrConstraintError, "Failed to drop constraint", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, dropConstraintData{Table: tableName, Constraint: constraintName, Cascade: cascade})
+}
+
+type renameConstraintData struct {
+	Table      string `json:"table"`
+	Constraint string `json:"constraint"`
+	NewName    string `json:"newName"`
+}
+
+func (h *Handler) handleRenameConstraint(w http.ResponseWriter, r *http.Request) {
+	tableName, constraintName, ok := h.constraintPathValues(w, r)
+	if !ok {
+		return
+	}
+
+	newName, ok := h.decodeRename(w, r, constraintName, "constraint name", ErrInvalidRequest)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.RenameConstraint(ctx, tableName, constraintName, newName); err != nil {
+		h.respondError(w, ErrConstraintError, "Failed to rename constraint", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, renameConstraintData{Table: tableName, Constraint: constraintName, NewName: newName})
+}
+
+type foreignKeyActionsData struct {
+	Table      string            `json:"table"`
+	ForeignKey schema.ForeignKey `json:"foreignKey"`
+}
+
+// handleSetForeignKeyActions changes a foreign key's ON DELETE and ON UPDATE
+// actions. PostgreSQL cannot alter them in place, so the key is dropped and
+// re-added NOT VALID, then validated, in one transaction.
+func (h *Handler) handleSetForeignKeyActions(w http.ResponseWriter, r *http.Request) {
+	tableName, constraintName, ok := h.constraintPathValues(w, r)
+	if !ok {
+		return
+	}
+
+	var req schema.ForeignKeyActionsRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.OnDelete == "" && req.OnUpdate == "" {
+		h.respondError(w, ErrMissingField, "onDelete or onUpdate is required", http.StatusBadRequest, nil)
+		return
+	}
+	for _, action := range []string{req.OnDelete, req.OnUpdate} {
+		if action != "" && !schema.IsReferentialAction(action) {
+			h.respondError(w, ErrInvalidRequest, "Invalid action "+action+": must be NO ACTION, RESTRICT, CASCADE, SET NULL, or SET DEFAULT", http.StatusBadRequest, nil)
+			return
+		}
+	}
+
+	ctx, preview := previewContext(r)
+	fk, err := h.introspector.SetForeignKeyActions(ctx, tableName, constraintName, req)
+	if err != nil {
+		if errors.Is(err, schema.ErrNotForeignKey) {
+			h.respondError(w, ErrInvalidRequest, "Constraint is not a foreign key", http.StatusBadRequest, nil)
+			return
+		}
+		h.respondError(w, ErrConstraintError, "Failed to change foreign key actions", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, foreignKeyActionsData{Table: tableName, ForeignKey: *fk})
+}
+
+type addCheckData struct {
+	Table string `json:"table"`
+	Check string `json:"check,omitempty"` // Empty when PostgreSQL chose the name
+}
+
+// handleAddCheck adds a CHECK constraint from one of the fixed patterns in
+// schema.CheckDef. The kind-specific fields are validated when the DDL is built.
+func (h *Handler) handleAddCheck(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	var req schema.CheckDef
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if !h.validateIdentifier(w, req.Column, "column name", ErrInvalidColName) {
+		return
+	}
+	if req.Name != "" && !schema.ValidIdentifier(req.Name) {
+		h.respondError(w, ErrInvalidRequest, "Invalid constraint name format", http.StatusBadRequest, nil)
+		return
+	}
+	if _, err := schema.BuildAddCheckDDL(tableName, req); err != nil {
+		h.respondError(w, ErrInvalidRequest, "Invalid check: "+err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.AddCheckConstraint(ctx, tableName, req); err != nil {
+		h.respondError(w, ErrConstraintError, "Failed to add check constraint", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, addCheckData{Table: tableName, Check: req.Name})
+}
+
+type dropUniqueData struct {
+	Table   string                  `json:"table"`
+	Column  string                  `json:"column"`
+	Dropped []schema.UniqueEnforcer `json:"dropped"`
+}
+
+// handleDropUnique removes uniqueness from a column, whether a unique
+// constraint or a standalone unique index enforces it.
+func (h *Handler) handleDropUnique(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	columnName := r.PathValue("columnName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, columnName, "column name", ErrInvalidColName) {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	dropped, err := h.introspector.DropUnique(ctx, tableName, columnName)
+	if err != nil {
+		if errors.Is(err, schema.ErrNotUnique) {
+			h.respondError(w, ErrConstraintNotFound, "No unique constraint or unique index covers only this column", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrConstraintError, "Failed to remove uniqueness", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, dropUniqueData{Table: tableName, Column: columnName, Dropped: dropped})
+}
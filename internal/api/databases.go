@@ -0,0 +1,92 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type databaseChangeData struct {
+	Database string `json:"database"`
+	NewName  string `json:"newName,omitempty"`
+}
+
+// handleCreateDatabase creates a database on the current server, e.g. the
+// target of a migration. It does not switch to it. It requires the admin
+// token, since a template copies another database's data; the template must
+// be template0, template1, or a listed database.
+func (h *Handler) handleCreateDatabase(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	var req schema.CreateDatabaseRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if _, err := schema.BuildCreateDatabaseDDL(req); err != nil {
+		h.respondError(w, ErrInvalidRequest, "Invalid database: "+err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	if req.Template != "" && req.Template != "template0" && req.Template != "template1" && !h.validateDatabase(w, r, req.Template) {
+		return
+	}
+
+	databases, err := h.introspector.ListDatabases(r.Context())
+	if err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to list databases", http.StatusInternalServerError, err)
+		return
+	}
+	if slices.Contains(databases, req.Name) {
+		h.respondError(w, ErrDatabaseExists, "A database with that name already exists", http.StatusConflict, nil)
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.CreateDatabase(ctx, req); err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to create database", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	log.Printf("[ADMIN] Created database %s", req.Name)
+	respondJSON(w, databaseChangeData{Database: req.Name})
+}
+
+// handleRenameDatabase renames another database. It requires the admin token
+// since other applications may connect to the database by name.
+func (h *Handler) handleRenameDatabase(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	name := r.PathValue("name")
+	if !h.validateDatabase(w, r, name) {
+		return
+	}
+	if name == h.introspector.CurrentDatabase() {
+		h.respondError(w, ErrInvalidRequest, "Switch to another database before renaming this one", http.StatusConflict, nil)
+		return
+	}
+
+	newName, ok := h.decodeRename(w, r, name, "database name", ErrInvalidRequest)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.RenameDatabase(ctx, name, newName); err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to rename database; it may have active connections", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	log.Printf("[ADMIN] Renamed database %s to %s", name, newName)
+	respondJSON(w, databaseChangeData{Database: name, NewName: newName})
+}
@@ -0,0 +1,537 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/datacopy"
+	"github.com/JonMunkholm/AltDbMigration/internal/jobs"
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Job kinds of data copies and their verifications in the job list.
+const (
+	dataCopyJobKind       = "datacopy"
+	dataCopyVerifyJobKind = "datacopy-verify"
+)
+
+// dataCopyRun is the latest run of a data copy since the server came up,
+// kept for its live per-table progress.
+type dataCopyRun struct {
+	jobID  string
+	copier *datacopy.Copier
+}
+
+// dataCopyVerifyRun is the latest verification of a data copy since the
+// server came up, kept for its live per-table results.
+type dataCopyVerifyRun struct {
+	jobID    string
+	verifier *datacopy.Verifier
+}
+
+// dataCopies holds the runs and verifications started since the server came
+// up, by data copy ID.
+type dataCopies struct {
+	mu            sync.Mutex
+	runs          map[string]dataCopyRun
+	verifications map[string]dataCopyVerifyRun
+}
+
+func newDataCopies() *dataCopies {
+	return &dataCopies{runs: make(map[string]dataCopyRun), verifications: make(map[string]dataCopyVerifyRun)}
+}
+
+func (d *dataCopies) get(id string) (dataCopyRun, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	run, ok := d.runs[id]
+	return run, ok
+}
+
+func (d *dataCopies) add(id string, run dataCopyRun) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.runs[id] = run
+}
+
+func (d *dataCopies) getVerification(id string) (dataCopyVerifyRun, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	run, ok := d.verifications[id]
+	return run, ok
+}
+
+func (d *dataCopies) addVerification(id string, run dataCopyVerifyRun) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.verifications[id] = run
+}
+
+type startDataCopyRequest struct {
+	Source string   `json:"source,omitempty"` // Defaults to the current database
+	Target string   `json:"target"`
+	Tables []string `json:"tables,omitempty"` // Empty copies every table both databases have
+	// Mappings reshape rows on the way, by table; see datacopy.Mapping
+	Mappings map[string]datacopy.Mapping `json:"mappings,omitempty"`
+	datacopy.Options
+}
+
+type dataCopyData struct {
+	ID        string                   `json:"id"`
+	Job       *jobs.Job                `json:"job,omitempty"` // The latest run; absent after a server restart
+	Source    string                   `json:"source"`
+	Target    string                   `json:"target"`
+	Options   datacopy.Options         `json:"options"`
+	Tables    []datacopy.TableProgress `json:"tables"` // In copy order
+	Resumable bool                     `json:"resumable"`
+	Warnings  []string                 `json:"warnings,omitempty"`
+}
+
+type dataCopiesData struct {
+	Copies []dataCopyData `json:"copies"`
+}
+
+type dataCopyVerifyData struct {
+	ID         string                 `json:"id"`            // The data copy
+	Job        *jobs.Job              `json:"job,omitempty"` // Absent after a server restart
+	Options    datacopy.VerifyOptions `json:"options"`
+	Tables     []datacopy.TableCheck  `json:"tables"`     // In copy order
+	Mismatched []string               `json:"mismatched"` // Tables that differ
+	StartedAt  time.Time              `json:"startedAt"`
+}
+
+type dataCopyTemplatesData struct {
+	Templates []datacopy.Template `json:"templates"`
+}
+
+// handleStartDataCopy copies table rows from one database on the server to
+// another as a background job. Tables are copied parents first so foreign
+// keys hold. Columns may be renamed, left out, cast, filled with a constant,
+// or filled through one of datacopy.Templates. Progress is saved as the copy
+// goes, so a stopped copy can be resumed. Writing to another database, and
+// optionally truncating it, requires the admin token.
+func (h *Handler) handleStartDataCopy(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	var req startDataCopyRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.Source == "" {
+		req.Source = h.introspector.CurrentDatabase()
+	}
+	if req.Target == "" {
+		h.respondError(w, ErrMissingField, "Target database is required", http.StatusBadRequest, nil)
+		return
+	}
+	if req.Source == req.Target {
+		h.respondError(w, ErrInvalidRequest, "Source and target must be different databases", http.StatusBadRequest, nil)
+		return
+	}
+	for _, table := range req.Tables {
+		if !h.validateIdentifier(w, table, "table name", ErrInvalidTableName) {
+			return
+		}
+	}
+	for table := range req.Mappings {
+		if !h.validateIdentifier(w, table, "table name", ErrInvalidTableName) {
+			return
+		}
+	}
+	if req.BatchSize < 0 {
+		h.respondError(w, ErrInvalidRequest, "Batch size must not be negative", http.StatusBadRequest, nil)
+		return
+	}
+
+	c := store.DataCopy{
+		Source:   req.Source,
+		Target:   req.Target,
+		Tables:   req.Tables,
+		Mappings: req.Mappings,
+		Options:  req.Options,
+	}
+	plan, warnings, ok := h.planDataCopy(w, r, c)
+	if !ok {
+		return
+	}
+	// Checked here too, so a copy that cannot start is not saved
+	if h.jobs.Running(dataCopyJobKind) {
+		h.respondError(w, ErrJobConflict, "A data copy is already running", http.StatusConflict, nil)
+		return
+	}
+	c, err := h.dataCopyStore.Add(c)
+	if err != nil {
+		h.respondError(w, ErrStoreError, "Failed to save data copy", http.StatusInternalServerError, err)
+		return
+	}
+	h.runDataCopy(w, c, plan, warnings, false)
+}
+
+// handleResumeDataCopy continues a data copy that was cancelled, failed, or
+// cut off by a restart, with the tables and mappings it was started with.
+// Finished tables are skipped and tables with a primary key continue after
+// their checkpoint. ?upsert=true turns on upsert for the rest of the copy,
+// for when rows were written after the last checkpoint.
+func (h *Handler) handleResumeDataCopy(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	c, err := h.dataCopyStore.Get(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrJobNotFound, "Data copy not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrStoreError, "Failed to load data copy", http.StatusInternalServerError, err)
+		return
+	}
+	if h.dataCopyRunning(c.ID) {
+		h.respondError(w, ErrJobConflict, "Data copy is already running", http.StatusConflict, nil)
+		return
+	}
+	if !slices.ContainsFunc(c.Progress, func(p datacopy.TableProgress) bool { return p.Status != datacopy.StatusDone }) {
+		h.respondError(w, ErrJobConflict, "Data copy has already finished", http.StatusConflict, nil)
+		return
+	}
+	if r.URL.Query().Get("upsert") == "true" {
+		c.Options.Upsert = true
+	}
+
+	plan, warnings, ok := h.planDataCopy(w, r, c)
+	if !ok {
+		return
+	}
+	h.runDataCopy(w, c, plan, warnings, true)
+}
+
+// planDataCopy checks both databases of c and plans the copy between them.
+func (h *Handler) planDataCopy(w http.ResponseWriter, r *http.Request, c store.DataCopy) ([]datacopy.TableCopy, []string, bool) {
+	if !h.validateDatabase(w, r, c.Source) || !h.validateDatabase(w, r, c.Target) {
+		return nil, nil, false
+	}
+	source, err := h.introspectDatabase(r.Context(), c.Source)
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load source schema", http.StatusInternalServerError, err)
+		return nil, nil, false
+	}
+	target, err := h.introspectDatabase(r.Context(), c.Target)
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load target schema", http.StatusInternalServerError, err)
+		return nil, nil, false
+	}
+	plan, warnings, err := datacopy.Plan(source, target, c.Tables, c.Mappings)
+	if err == nil && c.Options.Upsert {
+		err = datacopy.CheckUpsert(plan)
+	}
+	if err != nil {
+		h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+		return nil, nil, false
+	}
+	if len(plan) == 0 {
+		h.respondError(w, ErrInvalidRequest, "No tables to copy", http.StatusBadRequest, nil)
+		return nil, nil, false
+	}
+	return plan, warnings, true
+}
+
+// runDataCopy starts a run of c as a background job, saving its progress to
+// the store as it goes.
+func (h *Handler) runDataCopy(w http.ResponseWriter, c store.DataCopy, plan []datacopy.TableCopy, warnings []string, resume bool) {
+	// One copy at a time; two copies into one target would trample each other
+	if h.jobs.Running(dataCopyJobKind) {
+		h.respondError(w, ErrJobConflict, "A data copy is already running", http.StatusConflict, nil)
+		return
+	}
+
+	sourcePool, targetPool, ok := h.openDataCopyPools(w, c)
+	if !ok {
+		return
+	}
+
+	copier := datacopy.NewCopier(sourcePool, targetPool, plan, c.Options)
+	if resume {
+		copier.Resume(c.Progress)
+	}
+	save := func(progress []datacopy.TableProgress) {
+		if err := h.dataCopyStore.Update(c.ID, func(stored *store.DataCopy) { stored.Progress = progress }); err != nil {
+			log.Printf("failed to save progress of data copy %s: %v", c.ID, err)
+		}
+	}
+	job, err := h.jobs.Start(dataCopyJobKind, copier.Steps(), func(ctx context.Context, p *jobs.Progress) error {
+		defer sourcePool.Close()
+		defer targetPool.Close()
+		return copier.Run(ctx, p.Step, save)
+	})
+	if err != nil {
+		sourcePool.Close()
+		targetPool.Close()
+		h.respondError(w, ErrJobError, "Failed to start data copy", http.StatusInternalServerError, err)
+		return
+	}
+	h.dataCopies.add(c.ID, dataCopyRun{jobID: job.ID, copier: copier})
+	if err := h.dataCopyStore.Update(c.ID, func(stored *store.DataCopy) {
+		stored.JobID, stored.Options = job.ID, c.Options
+	}); err != nil {
+		log.Printf("failed to save data copy %s: %v", c.ID, err)
+	}
+	c.JobID = job.ID
+
+	verb := "Started"
+	if resume {
+		verb = "Resumed"
+	}
+	log.Printf("[ADMIN] %s data copy %s from %s to %s (%d tables, truncate=%t, upsert=%t)",
+		verb, c.ID, c.Source, c.Target, len(plan), c.Options.Truncate && !resume, c.Options.Upsert)
+	data := h.describeDataCopy(c)
+	data.Warnings = warnings
+	respondJSON(w, data)
+}
+
+// openDataCopyPools connects to both databases of c. The pools outlive the
+// request; the job using them closes them.
+func (h *Handler) openDataCopyPools(w http.ResponseWriter, c store.DataCopy) (*pgxpool.Pool, *pgxpool.Pool, bool) {
+	sourcePool, err := pgxpool.New(context.Background(), h.config.BuildDatabaseURL(c.Source))
+	if err != nil {
+		h.respondError(w, ErrConnectionError, "Failed to connect to source database", http.StatusInternalServerError, err)
+		return nil, nil, false
+	}
+	targetPool, err := pgxpool.New(context.Background(), h.config.BuildDatabaseURL(c.Target))
+	if err != nil {
+		sourcePool.Close()
+		h.respondError(w, ErrConnectionError, "Failed to connect to target database", http.StatusInternalServerError, err)
+		return nil, nil, false
+	}
+	return sourcePool, targetPool, true
+}
+
+// handleListDataCopies lists data copies, newest first, including those
+// from before a restart that can be resumed.
+func (h *Handler) handleListDataCopies(w http.ResponseWriter, r *http.Request) {
+	stored := h.dataCopyStore.List()
+	copies := make([]dataCopyData, len(stored))
+	for idx, c := range stored {
+		copies[idx] = h.describeDataCopy(c)
+	}
+	respondJSON(w, dataCopiesData{Copies: copies})
+}
+
+// handleGetDataCopyTemplates lists the expressions a data copy mapping may
+// fill a column with.
+func (h *Handler) handleGetDataCopyTemplates(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, dataCopyTemplatesData{Templates: datacopy.Templates})
+}
+
+// handleGetDataCopy reports a data copy, its latest run, and the progress of
+// each table.
+func (h *Handler) handleGetDataCopy(w http.ResponseWriter, r *http.Request) {
+	h.respondDataCopy(w, r.PathValue("id"))
+}
+
+// handleCancelDataCopy stops a running data copy. Committed batches stay, so
+// the copy can be resumed. Like starting one, it requires the admin token.
+func (h *Handler) handleCancelDataCopy(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id := r.PathValue("id")
+	if _, err := h.dataCopyStore.Get(id); err != nil {
+		h.respondError(w, ErrJobNotFound, "Data copy not found", http.StatusNotFound, nil)
+		return
+	}
+	run, ok := h.dataCopies.get(id)
+	if !ok {
+		h.respondError(w, ErrJobConflict, "Data copy is not running", http.StatusConflict, nil)
+		return
+	}
+	if err := h.jobs.Cancel(run.jobID); err != nil {
+		if errors.Is(err, jobs.ErrNotRunning) {
+			h.respondError(w, ErrJobConflict, "Data copy is not running", http.StatusConflict, nil)
+			return
+		}
+		h.respondError(w, ErrJobError, "Failed to cancel data copy", http.StatusInternalServerError, err)
+		return
+	}
+	h.respondDataCopy(w, id)
+}
+
+func (h *Handler) respondDataCopy(w http.ResponseWriter, id string) {
+	c, err := h.dataCopyStore.Get(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrJobNotFound, "Data copy not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrStoreError, "Failed to load data copy", http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, h.describeDataCopy(c))
+}
+
+// describeDataCopy reports c, with live progress while it runs.
+func (h *Handler) describeDataCopy(c store.DataCopy) dataCopyData {
+	data := dataCopyData{ID: c.ID, Source: c.Source, Target: c.Target, Options: c.Options, Tables: c.Progress}
+	running := false
+	if run, ok := h.dataCopies.get(c.ID); ok {
+		if job, err := h.jobs.Get(run.jobID); err == nil {
+			data.Job = &job
+			data.Tables = run.copier.Progress()
+			running = job.Status == jobs.StatusRunning
+		}
+	}
+	data.Resumable = !running && slices.ContainsFunc(data.Tables, func(p datacopy.TableProgress) bool { return p.Status != datacopy.StatusDone })
+	return data
+}
+
+// dataCopyRunning reports whether a run of the data copy with id is in progress.
+func (h *Handler) dataCopyRunning(id string) bool {
+	run, ok := h.dataCopies.get(id)
+	if !ok {
+		return false
+	}
+	job, err := h.jobs.Get(run.jobID)
+	return err == nil && job.Status == jobs.StatusRunning
+}
+
+// handleStartDataCopyVerify compares the tables of a data copy in source and
+// target as a background job: exact row counts, plus with ?checksum=keys a
+// hash of every primary key, or with ?checksum=rows the hashes of a random
+// sample of ?sample= rows per table, mappings applied. It only reads, but
+// scans every table on both sides.
+func (h *Handler) handleStartDataCopyVerify(w http.ResponseWriter, r *http.Request) {
+	c, err := h.dataCopyStore.Get(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrJobNotFound, "Data copy not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrStoreError, "Failed to load data copy", http.StatusInternalServerError, err)
+		return
+	}
+	if h.dataCopyRunning(c.ID) {
+		h.respondError(w, ErrJobConflict, "Data copy is still running", http.StatusConflict, nil)
+		return
+	}
+
+	q := r.URL.Query()
+	opts := datacopy.VerifyOptions{Checksum: q.Get("checksum")}
+	if !slices.Contains([]string{datacopy.ChecksumNone, datacopy.ChecksumKeys, datacopy.ChecksumRows}, opts.Checksum) {
+		h.respondError(w, ErrInvalidRequest, "Checksum must be keys or rows", http.StatusBadRequest, nil)
+		return
+	}
+	if sample := q.Get("sample"); sample != "" {
+		n, err := strconv.Atoi(sample)
+		if err != nil || n < 1 || n > datacopy.MaxSampleSize {
+			h.respondError(w, ErrInvalidRequest, fmt.Sprintf("Sample must be between 1 and %d", datacopy.MaxSampleSize), http.StatusBadRequest, nil)
+			return
+		}
+		opts.SampleSize = n
+	}
+
+	c.Options = datacopy.Options{} // Only the plan is needed; how it was written doesn't matter
+	plan, _, ok := h.planDataCopy(w, r, c)
+	if !ok {
+		return
+	}
+	if h.jobs.Running(dataCopyVerifyJobKind) {
+		h.respondError(w, ErrJobConflict, "A data copy verification is already running", http.StatusConflict, nil)
+		return
+	}
+	sourcePool, targetPool, ok := h.openDataCopyPools(w, c)
+	if !ok {
+		return
+	}
+
+	verifier := datacopy.NewVerifier(sourcePool, targetPool, plan, opts)
+	// The new record is stored before the job starts, so progress the job
+	// saves always lands in it; the job's ID is filled in after
+	verification := &store.DataCopyVerification{Options: opts, Tables: verifier.Results(), StartedAt: time.Now().UTC()}
+	previous := c.Verification
+	if err := h.dataCopyStore.Update(c.ID, func(stored *store.DataCopy) { stored.Verification = verification }); err != nil {
+		log.Printf("failed to save verification of data copy %s: %v", c.ID, err)
+	}
+	updateVerification := func(fn func(v *store.DataCopyVerification)) {
+		err := h.dataCopyStore.Update(c.ID, func(stored *store.DataCopy) {
+			if stored.Verification != nil {
+				v := *stored.Verification // Copied, since earlier versions of the copy share it
+				fn(&v)
+				stored.Verification = &v
+			}
+		})
+		if err != nil {
+			log.Printf("failed to save verification of data copy %s: %v", c.ID, err)
+		}
+	}
+	save := func(checks []datacopy.TableCheck) {
+		updateVerification(func(v *store.DataCopyVerification) { v.Tables = checks })
+	}
+	job, err := h.jobs.Start(dataCopyVerifyJobKind, len(plan), func(ctx context.Context, p *jobs.Progress) error {
+		defer sourcePool.Close()
+		defer targetPool.Close()
+		return verifier.Run(ctx, p.Step, save)
+	})
+	if err != nil {
+		sourcePool.Close()
+		targetPool.Close()
+		if err := h.dataCopyStore.Update(c.ID, func(stored *store.DataCopy) { stored.Verification = previous }); err != nil {
+			log.Printf("failed to save verification of data copy %s: %v", c.ID, err)
+		}
+		h.respondError(w, ErrJobError, "Failed to start verification", http.StatusInternalServerError, err)
+		return
+	}
+	h.dataCopies.addVerification(c.ID, dataCopyVerifyRun{jobID: job.ID, verifier: verifier})
+	updateVerification(func(v *store.DataCopyVerification) { v.JobID = job.ID })
+	started := *verification
+	started.JobID = job.ID
+	c.Verification = &started
+
+	respondJSON(w, h.describeDataCopyVerify(c))
+}
+
+// handleGetDataCopyVerify reports the latest verification of a data copy,
+// with the tables whose source and target differ.
+func (h *Handler) handleGetDataCopyVerify(w http.ResponseWriter, r *http.Request) {
+	c, err := h.dataCopyStore.Get(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrJobNotFound, "Data copy not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrStoreError, "Failed to load data copy", http.StatusInternalServerError, err)
+		return
+	}
+	if c.Verification == nil {
+		h.respondError(w, ErrJobNotFound, "Data copy has not been verified", http.StatusNotFound, nil)
+		return
+	}
+	respondJSON(w, h.describeDataCopyVerify(c))
+}
+
+// describeDataCopyVerify reports the verification of c, with live results
+// while it runs.
+func (h *Handler) describeDataCopyVerify(c store.DataCopy) dataCopyVerifyData {
+	v := c.Verification
+	data := dataCopyVerifyData{ID: c.ID, Options: v.Options, Tables: v.Tables, Mismatched: []string{}, StartedAt: v.StartedAt}
+	if run, ok := h.dataCopies.getVerification(c.ID); ok && run.jobID == v.JobID {
+		if job, err := h.jobs.Get(run.jobID); err == nil {
+			data.Job = &job
+			data.Tables = run.verifier.Results()
+		}
+	}
+	for _, check := range data.Tables {
+		if check.Status == datacopy.CheckMismatch {
+			data.Mismatched = append(data.Mismatched, check.Table)
+		}
+	}
+	return data
+}
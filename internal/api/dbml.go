@@ -0,0 +1,217 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/dbml"
+	"github.com/JonMunkholm/AltDbMigration/internal/diff"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+)
+
+// DBML import modes.
+const (
+	dbmlModeDiff   = "diff"
+	dbmlModeCreate = "create"
+)
+
+type importDBMLRequest struct {
+	Name string `json:"name,omitempty"` // Snapshot name; defaults to schema.dbml
+	DBML string `json:"dbml"`
+	Mode string `json:"mode,omitempty"` // diff (default) or create
+}
+
+type importDBMLData struct {
+	Snapshot store.SnapshotInfo `json:"snapshot"`
+	// Diff compares the current database (before) with the design (after)
+	Diff     diff.SchemaDiff `json:"diff"`
+	Warnings []string        `json:"warnings"`
+}
+
+type createDBMLData struct {
+	Tables     []string `json:"tables"`  // Created
+	Enums      []string `json:"enums"`   // Created
+	Skipped    []string `json:"skipped"` // Already present, e.g. "table users"
+	Statements []string `json:"statements"`
+	Warnings   []string `json:"warnings"`
+}
+
+// handleImportDBML reads a DBML document, as exported from dbdiagram.io. In
+// diff mode the design is saved as a snapshot, usable as ?snapshot= anywhere
+// a target schema is taken, and diffed against the database. In create mode
+// its enums and tables that don't exist yet are created in one undoable
+// change; existing ones are left alone. The body is JSON, or the document
+// itself when sent as text/plain with ?name= and ?mode=.
+func (h *Handler) handleImportDBML(w http.ResponseWriter, r *http.Request) {
+	var req importDBMLRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/plain" {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.respondError(w, ErrInvalidRequest, "Invalid request body", http.StatusBadRequest, err)
+			return
+		}
+		q := r.URL.Query()
+		req = importDBMLRequest{Name: q.Get("name"), DBML: string(body), Mode: q.Get("mode")}
+	} else if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.DBML) == "" {
+		h.respondError(w, ErrMissingField, "DBML is required", http.StatusBadRequest, nil)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = dbmlModeDiff
+	}
+	if req.Mode != dbmlModeDiff && req.Mode != dbmlModeCreate {
+		h.respondError(w, ErrInvalidRequest, "Mode must be diff or create", http.StatusBadRequest, nil)
+		return
+	}
+
+	doc, err := dbml.Parse(req.DBML)
+	if err != nil {
+		h.respondError(w, ErrInvalidRequest, "Invalid DBML: "+err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	if req.Mode == dbmlModeCreate {
+		h.createFromDBML(w, r, doc)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		req.Name = "schema.dbml"
+	}
+	if len(req.Name) > 100 {
+		h.respondError(w, ErrInvalidRequest, "Snapshot name is too long", http.StatusBadRequest, nil)
+		return
+	}
+
+	current, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+	info, err := h.snapshots.Save(req.Name, h.introspector.CurrentDatabase(), store.SnapshotImport, doc.Schema)
+	if err != nil {
+		h.respondError(w, ErrStoreError, "Failed to save imported schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, importDBMLData{Snapshot: info, Diff: diff.Compare(current, doc.Schema), Warnings: doc.Warnings})
+}
+
+// createFromDBML creates the enums and tables of doc missing from the
+// database. Defaults, checks, and index expressions in DBML are raw SQL, so
+// this requires the admin token.
+func (h *Handler) createFromDBML(w http.ResponseWriter, r *http.Request, doc *dbml.Document) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	current, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+	enums, err := h.introspector.GetEnumTypes(r.Context())
+	if err != nil {
+		h.respondError(w, ErrTypeError, "Failed to load enum types", http.StatusInternalServerError, err)
+		return
+	}
+
+	data := createDBMLData{Tables: []string{}, Enums: []string{}, Skipped: []string{}, Warnings: doc.Warnings}
+	var createEnums, dropEnums []string
+	for _, e := range doc.Enums {
+		if slices.ContainsFunc(enums, func(existing schema.EnumType) bool { return existing.Name == e.Name }) {
+			data.Skipped = append(data.Skipped, "enum "+e.Name)
+			continue
+		}
+		create, err := schema.BuildCreateEnumDDL(e.Name, e.Labels)
+		if err != nil {
+			h.respondError(w, ErrInvalidRequest, fmt.Sprintf("Invalid enum %s: %v", e.Name, err), http.StatusBadRequest, nil)
+			return
+		}
+		drop, _ := schema.BuildDropTypeDDL(e.Name)
+		createEnums = append(createEnums, create)
+		dropEnums = append([]string{drop}, dropEnums...)
+		data.Enums = append(data.Enums, e.Name)
+	}
+
+	target := &schema.Schema{}
+	var comments []string
+	for _, t := range doc.Schema.Tables {
+		if slices.ContainsFunc(current.Tables, func(existing schema.Table) bool { return existing.Name == t.Name }) {
+			data.Skipped = append(data.Skipped, "table "+t.Name)
+			continue
+		}
+		if !h.validateIdentifier(w, t.Name, "table name", ErrInvalidRequest) {
+			return
+		}
+		for _, col := range t.Columns {
+			if !h.validateIdentifier(w, col.Name, "column name", ErrInvalidRequest) {
+				return
+			}
+		}
+		target.Tables = append(target.Tables, t)
+		data.Tables = append(data.Tables, t.Name)
+		comments = append(comments, dbmlComments(t, &data.Warnings)...)
+	}
+	if len(data.Tables) == 0 && len(data.Enums) == 0 {
+		h.respondError(w, ErrNothingToMigrate, "Every table and enum in the document already exists", http.StatusBadRequest, nil)
+		return
+	}
+
+	m := diff.GenerateMigration(&schema.Schema{}, target)
+	data.Warnings = append(data.Warnings, m.Up.Warnings...)
+	data.Statements = slices.Concat(createEnums, m.Up.Statements, comments)
+	inverse := slices.Concat(m.Down.Statements, dropEnums)
+
+	ctx, preview := previewContext(r)
+	description := fmt.Sprintf("Create %d tables and %d enums from DBML", len(data.Tables), len(data.Enums))
+	if err := h.introspector.CreateObjects(ctx, description, data.Statements, inverse); err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to create objects from DBML", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	log.Printf("[ADMIN] %s", description)
+	respondJSON(w, data)
+}
+
+// dbmlComments returns the statements setting the notes of a created table,
+// its columns, and its indexes. Indexes with names that need quoting keep no
+// note, with a warning.
+func dbmlComments(t schema.Table, warnings *[]string) []string {
+	var statements []string
+	if t.Comment != "" {
+		stmt, _ := schema.BuildTableCommentDDL(t.Name, t.Comment)
+		statements = append(statements, stmt)
+	}
+	for _, col := range t.Columns {
+		if col.Comment != "" {
+			stmt, _ := schema.BuildColumnCommentDDL(t.Name, col.Name, col.Comment)
+			statements = append(statements, stmt)
+		}
+	}
+	for _, idx := range t.Indexes {
+		if idx.Comment == "" {
+			continue
+		}
+		stmt, err := schema.BuildIndexCommentDDL(idx.Name, idx.Comment)
+		if err != nil {
+			*warnings = append(*warnings, fmt.Sprintf("note on index %s is not set: %v", idx.Name, err))
+			continue
+		}
+		statements = append(statements, stmt)
+	}
+	return statements
+}
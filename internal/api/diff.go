@@ -0,0 +1,275 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/diff"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+)
+
+// handleVisualDiff compares the current database (before) with ?target= (after)
+// and returns the change overlay for the graph view.
+func (h *Handler) handleVisualDiff(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		h.respondError(w, ErrMissingField, "Target database is required", http.StatusBadRequest, nil)
+		return
+	}
+	if !h.validateDatabase(w, r, target) {
+		return
+	}
+
+	before, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	after, err := h.introspectDatabase(r.Context(), target)
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load target schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, diff.Visual(before, after))
+}
+
+type schemaDiffData struct {
+	Source string `json:"source"` // The current database
+	Target string `json:"target"` // A database name, or snapshot:<id>
+	diff.SchemaDiff
+}
+
+// handleSchemaDiff compares the current database (before) with either another
+// database (?target=) or a saved snapshot (?snapshot=), and returns the
+// tables, columns, constraints, and indexes that differ.
+func (h *Handler) handleSchemaDiff(w http.ResponseWriter, r *http.Request) {
+	before, after, target, ok := h.loadDiffSchemas(w, r, r.URL.Query().Get("target"), r.URL.Query().Get("snapshot"))
+	if !ok {
+		return
+	}
+
+	respondJSON(w, schemaDiffData{
+		Source:     h.introspector.CurrentDatabase(),
+		Target:     target,
+		SchemaDiff: diff.Compare(before, after),
+	})
+}
+
+// loadDiffSchemas loads the current schema and the one to compare it with:
+// another database's, or a snapshot's when snapshotID is set. It returns the
+// target as reported in responses: the database name, or snapshot:<id>.
+// Returns false if loading fails (error response already sent).
+func (h *Handler) loadDiffSchemas(w http.ResponseWriter, r *http.Request, target, snapshotID string) (*schema.Schema, *schema.Schema, string, bool) {
+	if (target == "") == (snapshotID == "") {
+		h.respondError(w, ErrMissingField, "Exactly one of target or snapshot is required", http.StatusBadRequest, nil)
+		return nil, nil, "", false
+	}
+	if target != "" && !h.validateDatabase(w, r, target) {
+		return nil, nil, "", false
+	}
+
+	before, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return nil, nil, "", false
+	}
+
+	if target != "" {
+		after, err := h.introspectDatabase(r.Context(), target)
+		if err != nil {
+			h.respondError(w, ErrSchemaError, "Failed to load target schema", http.StatusInternalServerError, err)
+			return nil, nil, "", false
+		}
+		return before, after, target, true
+	}
+
+	snap, err := h.snapshots.Get(snapshotID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrSnapshotNotFound, "Snapshot not found", http.StatusNotFound, nil)
+			return nil, nil, "", false
+		}
+		h.respondError(w, ErrStoreError, "Failed to load snapshot", http.StatusInternalServerError, err)
+		return nil, nil, "", false
+	}
+	return before, snap.Schema, "snapshot:" + snapshotID, true
+}
+
+type generateMigrationRequest struct {
+	Target   string `json:"target,omitempty"`   // Database to migrate to
+	Snapshot string `json:"snapshot,omitempty"` // Or snapshot ID to migrate to
+	// Description and Version name exported files, NNN_description.up.sql
+	Description string `json:"description,omitempty"`
+	Version     int    `json:"version,omitempty"` // Export only; written files take the next free number
+}
+
+type generateMigrationData struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Up and Down are the scripts as SQL text, warnings first as comments
+	Up           string   `json:"up"`
+	Down         string   `json:"down"`
+	UpWarnings   []string `json:"upWarnings"`
+	DownWarnings []string `json:"downWarnings"`
+}
+
+// handleGenerateMigration generates, but does not run, the SQL that migrates
+// the current database to the schema of another database or snapshot, and the
+// SQL that reverses it. ?format=goose returns a goose migration file and
+// ?format=atlas an Atlas HCL schema of the target, both as plain text.
+func (h *Handler) handleGenerateMigration(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != diff.FormatGoose && format != diff.FormatAtlas {
+		h.respondError(w, ErrInvalidRequest, "Format must be goose or atlas", http.StatusBadRequest, nil)
+		return
+	}
+
+	var req generateMigrationRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	before, after, target, ok := h.loadDiffSchemas(w, r, req.Target, req.Snapshot)
+	if !ok {
+		return
+	}
+
+	migration := diff.GenerateMigration(before, after)
+	switch format {
+	case diff.FormatGoose:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(migration.Goose()))
+		return
+	case diff.FormatAtlas:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(diff.AtlasHCL(after)))
+		return
+	}
+	respondJSON(w, generateMigrationData{
+		Source:       h.introspector.CurrentDatabase(),
+		Target:       target,
+		Up:           migration.Up.SQL(),
+		Down:         migration.Down.SQL(),
+		UpWarnings:   migration.Up.Warnings,
+		DownWarnings: migration.Down.Warnings,
+	})
+}
+
+type migrationPlanData struct {
+	Source   string          `json:"source"`
+	Target   string          `json:"target"`
+	Steps    []diff.PlanStep `json:"steps"`
+	Risk     string          `json:"risk"` // The highest risk of any step
+	Warnings []string        `json:"warnings"`
+}
+
+// handlePlanMigration generates the up migration like handleGenerateMigration
+// and annotates each statement with the lock it takes, whether it rewrites or
+// scans its table, and the table's current size, so risky steps can be
+// scheduled. Nothing is run.
+func (h *Handler) handlePlanMigration(w http.ResponseWriter, r *http.Request) {
+	var req generateMigrationRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	before, after, target, ok := h.loadDiffSchemas(w, r, req.Target, req.Snapshot)
+	if !ok {
+		return
+	}
+
+	migration := diff.GenerateMigration(before, after)
+	plan := diff.PlanMigration(before, migration.Up.Statements)
+	respondJSON(w, migrationPlanData{
+		Source:   h.introspector.CurrentDatabase(),
+		Target:   target,
+		Steps:    plan.Steps,
+		Risk:     plan.Risk,
+		Warnings: migration.Up.Warnings,
+	})
+}
+
+// generateFromRequest decodes a generateMigrationRequest and generates its
+// migration, refusing one with nothing to do.
+// Returns false if generating fails (error response already sent).
+func (h *Handler) generateFromRequest(w http.ResponseWriter, r *http.Request) (generateMigrationRequest, diff.Migration, bool) {
+	var req generateMigrationRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return req, diff.Migration{}, false
+	}
+	if req.Version < 0 {
+		h.respondError(w, ErrInvalidRequest, "Version must be positive", http.StatusBadRequest, nil)
+		return req, diff.Migration{}, false
+	}
+
+	before, after, _, ok := h.loadDiffSchemas(w, r, req.Target, req.Snapshot)
+	if !ok {
+		return req, diff.Migration{}, false
+	}
+
+	migration := diff.GenerateMigration(before, after)
+	if len(migration.Up.Statements) == 0 {
+		h.respondError(w, ErrNothingToMigrate, "The schemas do not differ", http.StatusBadRequest, nil)
+		return req, diff.Migration{}, false
+	}
+	return req, migration, true
+}
+
+// handleExportMigration downloads a generated migration as a zip of
+// golang-migrate files. Without a version it takes the next free number in
+// the migrations directory, or 1.
+func (h *Handler) handleExportMigration(w http.ResponseWriter, r *http.Request) {
+	req, migration, ok := h.generateFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	version := req.Version
+	if version == 0 {
+		version = 1
+		if h.config.MigrationsDir != "" {
+			next, err := diff.NextMigrationVersion(h.config.MigrationsDir)
+			if err != nil {
+				h.respondError(w, ErrMigrationError, "Failed to read migrations directory", http.StatusInternalServerError, err)
+				return
+			}
+			version = next
+		}
+	}
+
+	files := diff.NewMigrationFiles(version, req.Description)
+	filename := strings.TrimSuffix(files.Up, ".up.sql") + ".zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if err := diff.WriteMigrationZip(w, files, migration); err != nil {
+		log.Printf("failed to write migration zip: %v", err)
+	}
+}
+
+// handleWriteMigration writes a generated migration to the configured
+// migrations directory as the next numbered pair of golang-migrate files.
+func (h *Handler) handleWriteMigration(w http.ResponseWriter, r *http.Request) {
+	if h.config.MigrationsDir == "" {
+		h.respondError(w, ErrMigrationsDisabled, "No migrations directory is configured", http.StatusForbidden, nil)
+		return
+	}
+
+	req, migration, ok := h.generateFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	files, err := diff.WriteMigrationFiles(h.config.MigrationsDir, req.Description, migration)
+	if err != nil {
+		h.respondError(w, ErrMigrationError, "Failed to write migration files", http.StatusInternalServerError, err)
+		return
+	}
+
+	log.Printf("[MIGRATIONS] Wrote %s and %s", files.Up, files.Down)
+	respondJSON(w, files)
+}
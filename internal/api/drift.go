@@ -0,0 +1,176 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/diff"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+)
+
+// Baseline storage locations.
+const (
+	baselineFile     = "file"     // A snapshot in the data directory
+	baselineDatabase = "database" // A row in the database's own baselines table
+)
+
+// defaultBaseline names the baseline when a request names none.
+const defaultBaseline = "default"
+
+// baselineParams reads and checks a baseline's name and storage, applying
+// defaults. Returns false if invalid (error response already sent).
+func (h *Handler) baselineParams(w http.ResponseWriter, name, storage string) (string, string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultBaseline
+	}
+	if len(name) > 100 {
+		h.respondError(w, ErrInvalidRequest, "Baseline name is too long", http.StatusBadRequest, nil)
+		return "", "", false
+	}
+	if storage == "" {
+		storage = baselineFile
+	}
+	if storage != baselineFile && storage != baselineDatabase {
+		h.respondError(w, ErrInvalidRequest, "Storage must be file or database", http.StatusBadRequest, nil)
+		return "", "", false
+	}
+	return name, storage, true
+}
+
+type saveBaselineRequest struct {
+	Name    string `json:"name,omitempty"`    // Defaults to "default"
+	Storage string `json:"storage,omitempty"` // file (default) or database
+}
+
+type baselineData struct {
+	Name       string    `json:"name"`
+	Storage    string    `json:"storage"`
+	Database   string    `json:"database"`
+	SnapshotID string    `json:"snapshotId,omitempty"` // Set for file storage
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// handleSaveBaseline saves the current schema as a named drift baseline,
+// replacing the previous one of that name.
+func (h *Handler) handleSaveBaseline(w http.ResponseWriter, r *http.Request) {
+	var req saveBaselineRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	name, storage, ok := h.baselineParams(w, req.Name, req.Storage)
+	if !ok {
+		return
+	}
+
+	s, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	data := baselineData{Name: name, Storage: storage, Database: h.introspector.CurrentDatabase()}
+	if storage == baselineDatabase {
+		if data.CreatedAt, err = h.introspector.SaveBaseline(r.Context(), name, s); err != nil {
+			h.respondError(w, ErrDatabaseError, "Failed to save baseline", http.StatusInternalServerError, err)
+			return
+		}
+	} else {
+		previous, hadPrevious := h.snapshots.FindBaseline(data.Database, name)
+		info, err := h.snapshots.Save(name, data.Database, store.SnapshotBaseline, s)
+		if err != nil {
+			h.respondError(w, ErrStoreError, "Failed to save baseline", http.StatusInternalServerError, err)
+			return
+		}
+		if hadPrevious {
+			if err := h.snapshots.Delete(previous.ID); err != nil {
+				log.Printf("failed to delete replaced baseline %s: %v", previous.ID, err)
+			}
+		}
+		data.SnapshotID, data.CreatedAt = info.ID, info.CreatedAt
+	}
+
+	respondJSON(w, data)
+}
+
+type driftData struct {
+	Baseline baselineData     `json:"baseline"`
+	Drifted  bool             `json:"drifted"`
+	Counts   map[string]int   `json:"counts"` // Items per severity
+	Items    []diff.DriftItem `json:"items"`  // Most severe first
+}
+
+// handleDrift compares the current schema with a saved baseline (?baseline=,
+// ?storage=) and lists how it has drifted. With ?failOn=error or warning,
+// drift at or above that severity is answered with 409 and the same report,
+// so a deploy script can gate on the status code.
+func (h *Handler) handleDrift(w http.ResponseWriter, r *http.Request) {
+	name, storage, ok := h.baselineParams(w, r.URL.Query().Get("baseline"), r.URL.Query().Get("storage"))
+	if !ok {
+		return
+	}
+	failOn := r.URL.Query().Get("failOn")
+	if failOn != "" && diff.SeverityRank(failOn) == 0 {
+		h.respondError(w, ErrInvalidRequest, "failOn must be error, warning, or info", http.StatusBadRequest, nil)
+		return
+	}
+
+	data := driftData{
+		Baseline: baselineData{Name: name, Storage: storage, Database: h.introspector.CurrentDatabase()},
+		Counts:   map[string]int{diff.SeverityError: 0, diff.SeverityWarning: 0, diff.SeverityInfo: 0},
+	}
+	var baseline *schema.Schema
+	if storage == baselineDatabase {
+		var err error
+		baseline, data.Baseline.CreatedAt, err = h.introspector.GetBaseline(r.Context(), name)
+		if err != nil {
+			if errors.Is(err, schema.ErrBaselineNotFound) {
+				h.respondError(w, ErrBaselineNotFound, "Baseline not found", http.StatusNotFound, nil)
+				return
+			}
+			h.respondError(w, ErrDatabaseError, "Failed to load baseline", http.StatusInternalServerError, err)
+			return
+		}
+	} else {
+		info, found := h.snapshots.FindBaseline(data.Baseline.Database, name)
+		if !found {
+			h.respondError(w, ErrBaselineNotFound, "Baseline not found", http.StatusNotFound, nil)
+			return
+		}
+		snap, err := h.snapshots.Get(info.ID)
+		if err != nil {
+			h.respondError(w, ErrStoreError, "Failed to load baseline", http.StatusInternalServerError, err)
+			return
+		}
+		baseline = snap.Schema
+		data.Baseline.SnapshotID, data.Baseline.CreatedAt = info.ID, info.CreatedAt
+	}
+
+	live, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	data.Items = diff.Drift(baseline, live)
+	data.Drifted = len(data.Items) > 0
+	failing := 0
+	for _, item := range data.Items {
+		data.Counts[item.Severity]++
+		if failOn != "" && diff.SeverityRank(item.Severity) >= diff.SeverityRank(failOn) {
+			failing++
+		}
+	}
+	if failing > 0 {
+		h.respondErrorDetails(w, ErrDriftDetected, fmt.Sprintf("%d drift items at or above %s severity", failing, failOn),
+			http.StatusConflict, data)
+		return
+	}
+
+	respondJSON(w, data)
+}
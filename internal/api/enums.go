@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type createEnumRequest struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type enumData struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// enumExists reports whether an enum of that name exists in the current database.
+func (h *Handler) enumExists(ctx context.Context, name string) (bool, error) {
+	enums, err := h.introspector.GetEnumTypes(ctx)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(enums, func(e schema.EnumType) bool { return e.Name == name }), nil
+}
+
+// handleCreateEnum creates an enum type. Once created it is accepted as a
+// column type like any other user-defined type (see customTypes).
+func (h *Handler) handleCreateEnum(w http.ResponseWriter, r *http.Request) {
+	var req createEnumRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if !h.validateIdentifier(w, req.Name, "type name", ErrInvalidRequest) {
+		return
+	}
+	if _, err := schema.BuildCreateEnumDDL(req.Name, req.Values); err != nil {
+		h.respondError(w, ErrInvalidRequest, "Invalid enum: "+err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.CreateEnum(ctx, req.Name, req.Values); err != nil {
+		h.respondError(w, ErrTypeError, "Failed to create enum", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, enumData{Name: req.Name, Values: req.Values})
+}
+
+type addEnumValueRequest struct {
+	Value  string `json:"value"`
+	Before string `json:"before,omitempty"` // Existing label to insert before
+	After  string `json:"after,omitempty"`  // Existing label to insert after
+}
+
+func (h *Handler) handleAddEnumValue(w http.ResponseWriter, r *http.Request) {
+	typeName := r.PathValue("typeName")
+	if !h.validateIdentifier(w, typeName, "type name", ErrInvalidRequest) {
+		return
+	}
+
+	var req addEnumValueRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if _, err := schema.BuildAddEnumValueDDL(typeName, req.Value, req.Before, req.After); err != nil {
+		h.respondError(w, ErrInvalidRequest, "Invalid enum value: "+err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	exists, err := h.enumExists(r.Context(), typeName)
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load enum types", http.StatusInternalServerError, err)
+		return
+	}
+	if !exists {
+		h.respondError(w, ErrTypeNotFound, "Enum not found", http.StatusNotFound, nil)
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.AddEnumValue(ctx, typeName, req.Value, req.Before, req.After); err != nil {
+		h.respondError(w, ErrTypeError, "Failed to add enum value", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	enums, err := h.introspector.GetEnumTypes(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load enum types", http.StatusInternalServerError, err)
+		return
+	}
+	data := enumData{Name: typeName}
+	for _, e := range enums {
+		if e.Name == typeName {
+			data.Values = e.Labels
+		}
+	}
+	respondJSON(w, data)
+}
+
+type dropEnumData struct {
+	Name string `json:"name"`
+}
+
+type typeUsageDetails struct {
+	Columns []string `json:"columns"` // As table.column
+}
+
+// handleDropEnum drops an enum that no column uses. While columns still use
+// it the request is refused with those columns listed.
+func (h *Handler) handleDropEnum(w http.ResponseWriter, r *http.Request) {
+	typeName := r.PathValue("typeName")
+	if !h.validateIdentifier(w, typeName, "type name", ErrInvalidRequest) {
+		return
+	}
+
+	exists, err := h.enumExists(r.Context(), typeName)
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load enum types", http.StatusInternalServerError, err)
+		return
+	}
+	if !exists {
+		h.respondError(w, ErrTypeNotFound, "Enum not found", http.StatusNotFound, nil)
+		return
+	}
+
+	columns, err := h.introspector.GetTypeUsage(r.Context(), typeName)
+	if err != nil {
+		h.respondError(w, ErrTypeError, "Failed to check enum usage", http.StatusInternalServerError, err)
+		return
+	}
+	if len(columns) > 0 {
+		h.respondErrorDetails(w, ErrHasDependents, "Enum is still used by columns", http.StatusConflict, typeUsageDetails{Columns: columns})
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.DropType(ctx, typeName); err != nil {
+		h.respondError(w, ErrTypeError, "Failed to drop enum", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, dropEnumData{Name: typeName})
+}
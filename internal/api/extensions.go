@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type extensionsData struct {
+	Extensions []schema.Extension `json:"extensions"`
+}
+
+func (h *Handler) handleListExtensions(w http.ResponseWriter, r *http.Request) {
+	extensions, err := h.introspector.ListExtensions(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to list extensions", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, extensionsData{Extensions: extensions})
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Pagination bounds for GET /api/functions.
+const (
+	defaultFunctionLimit = 100
+	maxFunctionLimit     = 1000
+)
+
+// handleListFunctions lists functions and procedures in a schema (default
+// public), paginated with ?limit= and ?offset=.
+func (h *Handler) handleListFunctions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	schemaName := q.Get("schema")
+	if schemaName == "" {
+		schemaName = "public"
+	}
+	if !h.validateIdentifier(w, schemaName, "schema name", ErrInvalidRequest) {
+		return
+	}
+
+	limit := defaultFunctionLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxFunctionLimit {
+			h.respondError(w, ErrInvalidRequest, "limit must be between 1 and 1000", http.StatusBadRequest, nil)
+			return
+		}
+		limit = n
+	}
+
+	offset := 0
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			h.respondError(w, ErrInvalidRequest, "offset must be a non-negative integer", http.StatusBadRequest, nil)
+			return
+		}
+		offset = n
+	}
+
+	page, err := h.introspector.ListFunctions(r.Context(), schemaName, limit, offset)
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to list functions", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, page)
+}
@@ -2,28 +2,48 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/JonMunkholm/AltDbMigration/internal/config"
+	"github.com/JonMunkholm/AltDbMigration/internal/jobs"
+	"github.com/JonMunkholm/AltDbMigration/internal/scheduler"
 	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+	"github.com/JonMunkholm/AltDbMigration/internal/watch"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Handler holds dependencies for HTTP handlers.
 type Handler struct {
-	introspector *schema.Introspector
-	webFS        fs.FS
-	config       *config.Config
-	csrf         *CSRFMiddleware
-	rateLimiter  *RateLimiter
-	poolCloseMu  sync.Mutex // Serializes pool close operations to prevent resource exhaustion
+	introspector  *schema.Introspector
+	webFS         fs.FS
+	config        *config.Config
+	csrf          *CSRFMiddleware
+	rateLimiter   *RateLimiter
+	notes         *store.Notes
+	preferences   *store.PreferenceStore
+	snapshots     *store.SnapshotStore
+	dataCopyStore *store.DataCopyStore
+	templates     *store.TemplateStore
+	jobs          *jobs.Manager
+	truncations   *truncateTokens
+	dataCopies    *dataCopies
+	shadows       *shadowValidations
+	snapshotCron  *scheduler.Scheduler // nil when automatic snapshots are disabled
+	schemaWatch   *schemaWatch         // nil when no schema file is watched
+	schemaWatcher *watch.Watcher
+	poolCloseMu   sync.Mutex // Serializes pool close operations to prevent resource exhaustion
 }
 
 // NewHandler creates a new API handler.
@@ -39,13 +59,73 @@ func NewHandler(introspector *schema.Introspector, webFS embed.FS, cfg *config.C
 		return nil, fmt.Errorf("failed to create CSRF middleware: %w", err)
 	}
 
-	return &Handler{
-		introspector: introspector,
-		webFS:        subFS,
-		config:       cfg,
-		csrf:         csrf,
-		rateLimiter:  NewRateLimiter(100, time.Minute), // 100 requests per minute
-	}, nil
+	st, err := store.New(cfg.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data store: %w", err)
+	}
+
+	notes, err := store.NewNotes(st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+
+	preferences, err := store.NewPreferenceStore(st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load preferences: %w", err)
+	}
+
+	snapshots, err := store.NewSnapshotStore(st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshots: %w", err)
+	}
+
+	dataCopyStore, err := store.NewDataCopyStore(st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data copies: %w", err)
+	}
+
+	templateStore, err := store.NewTemplateStore(st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migration templates: %w", err)
+	}
+
+	h := &Handler{
+		introspector:  introspector,
+		webFS:         subFS,
+		config:        cfg,
+		csrf:          csrf,
+		rateLimiter:   NewRateLimiter(100, time.Minute), // 100 requests per minute
+		notes:         notes,
+		preferences:   preferences,
+		snapshots:     snapshots,
+		dataCopyStore: dataCopyStore,
+		templates:     templateStore,
+		jobs:          jobs.NewManager(),
+		truncations:   newTruncateTokens(),
+		dataCopies:    newDataCopies(),
+		shadows:       newShadowValidations(),
+	}
+
+	if cfg.SnapshotSchedule != "" {
+		schedule, err := scheduler.ParseCron(cfg.SnapshotSchedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SNAPSHOT_SCHEDULE: %w", err)
+		}
+		h.snapshotCron = scheduler.Start(schedule, h.captureScheduledSnapshot)
+	}
+
+	if cfg.SchemaFile != "" {
+		if _, err := schemaFileFormat(cfg.SchemaFile); err != nil {
+			return nil, fmt.Errorf("invalid SCHEMA_FILE: %w", err)
+		}
+		if cfg.SchemaWatchInterval <= 0 {
+			return nil, fmt.Errorf("invalid SCHEMA_WATCH_INTERVAL: must be at least 1 second")
+		}
+		h.schemaWatch = newSchemaWatch()
+		h.schemaWatcher = watch.Start(cfg.SchemaFile, cfg.SchemaWatchInterval, h.checkSchemaFile)
+	}
+
+	return h, nil
 }
 
 // RegisterRoutes sets up the HTTP routes.
@@ -56,17 +136,130 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	// API routes - wrapped with rate limiting and CSRF protection
 	apiMux := http.NewServeMux()
 	apiMux.HandleFunc("GET /api/schema", h.handleGetSchema)
+	apiMux.HandleFunc("GET /api/schema/snapshot", h.handleGetSchemaSnapshot)
 	apiMux.HandleFunc("GET /api/databases", h.handleListDatabases)
+	apiMux.HandleFunc("POST /api/databases", h.handleCreateDatabase)
+	apiMux.HandleFunc("PATCH /api/databases/{name}", h.handleRenameDatabase)
+	apiMux.HandleFunc("GET /api/database/info", h.handleGetDatabaseInfo)
+	apiMux.HandleFunc("GET /api/schemas", h.handleListSchemas)
+	apiMux.HandleFunc("GET /api/tablespaces", h.handleListTablespaces)
 	apiMux.HandleFunc("GET /api/types", h.handleGetTypes)
+	apiMux.HandleFunc("POST /api/types/enums", h.handleCreateEnum)
+	apiMux.HandleFunc("POST /api/types/enums/{typeName}/values", h.handleAddEnumValue)
+	apiMux.HandleFunc("DELETE /api/types/enums/{typeName}", h.handleDropEnum)
 	apiMux.HandleFunc("POST /api/database", h.handleSwitchDatabase)
 	apiMux.HandleFunc("POST /api/tables", h.handleCreateTable)
+	apiMux.HandleFunc("DELETE /api/tables/{tableName}", h.handleDropTable)
+	apiMux.HandleFunc("POST /api/tables/bulk-delete", h.handleBulkDropTables)
+	apiMux.HandleFunc("PATCH /api/tables/{tableName}", h.handleRenameTable)
+	apiMux.HandleFunc("POST /api/tables/{tableName}/truncate", h.handleTruncateTable)
+	apiMux.HandleFunc("POST /api/tables/{tableName}/clone", h.handleCloneTable)
+	apiMux.HandleFunc("POST /api/tables/{tableName}/reorder-script", h.handleReorderScript)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/storage", h.handleSetStorageParameters)
+	apiMux.HandleFunc("POST /api/tables/{tableName}/partitions", h.handleCreatePartition)
+	apiMux.HandleFunc("POST /api/tables/{tableName}/partitions/attach", h.handleAttachPartition)
+	apiMux.HandleFunc("DELETE /api/tables/{tableName}/partitions/{partition}", h.handleDetachPartition)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/columns/{columnName}/storage", h.handleSetColumnStorage)
+	apiMux.HandleFunc("PATCH /api/tables/{tableName}/columns/{columnName}", h.handleRenameColumn)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/columns/{columnName}/type", h.handleAlterColumnType)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/columns/{columnName}/nullable", h.handleSetNullable)
+	apiMux.HandleFunc("POST /api/tables/{tableName}/columns/{columnName}/safe-not-null", h.handleSafeNotNull)
 	apiMux.HandleFunc("POST /api/tables/{tableName}/columns", h.handleAddColumn)
+	apiMux.HandleFunc("POST /api/tables/{tableName}/columns/batch", h.handleAddColumns)
+	apiMux.HandleFunc("POST /api/tables/{tableName}/foreign-keys", h.handleAddForeignKey)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/comment", h.handleSetTableComment)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/columns/{columnName}/comment", h.handleSetColumnComment)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/constraints/{constraintName}/comment", h.handleSetConstraintComment)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/indexes/{indexName}/comment", h.handleSetIndexComment)
+	apiMux.HandleFunc("GET /api/tags", h.handleListTags)
+	apiMux.HandleFunc("POST /api/tags/enable", h.handleEnableTags)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/tags", h.handleSetTableTags)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/columns/{columnName}/tags", h.handleSetColumnTags)
+	apiMux.HandleFunc("GET /api/relationships/inferred", h.handleInferRelationships)
+	apiMux.HandleFunc("POST /api/relationships/many-to-many", h.handleCreateManyToMany)
+	apiMux.HandleFunc("POST /api/views", h.handleCreateView)
+	apiMux.HandleFunc("GET /api/history", h.handleGetHistory)
+	apiMux.HandleFunc("POST /api/undo", h.handleUndo)
+	apiMux.HandleFunc("GET /api/dependencies", h.handleGetDependencies)
+	apiMux.HandleFunc("GET /api/functions", h.handleListFunctions)
+	apiMux.HandleFunc("GET /api/extensions", h.handleListExtensions)
+	apiMux.HandleFunc("GET /api/replication", h.handleGetReplication)
+	apiMux.HandleFunc("GET /api/tables/{tableName}/stats", h.handleGetColumnStats)
+	apiMux.HandleFunc("GET /api/tables/{tableName}/constraints", h.handleGetTableConstraints)
+	apiMux.HandleFunc("POST /api/tables/{tableName}/checks", h.handleAddCheck)
+	apiMux.HandleFunc("DELETE /api/tables/{tableName}/constraints/{constraintName}", h.handleDropConstraint)
+	apiMux.HandleFunc("PATCH /api/tables/{tableName}/constraints/{constraintName}", h.handleRenameConstraint)
+	apiMux.HandleFunc("PUT /api/tables/{tableName}/constraints/{constraintName}/actions", h.handleSetForeignKeyActions)
+	apiMux.HandleFunc("DELETE /api/tables/{tableName}/columns/{columnName}/unique", h.handleDropUnique)
+	apiMux.HandleFunc("GET /api/activity", h.handleGetActivity)
+	apiMux.HandleFunc("GET /api/activity/locks", h.handleGetLockWaits)
+	apiMux.HandleFunc("POST /api/activity/{pid}/signal", h.handleSignalBackend)
+	apiMux.HandleFunc("GET /api/stats/tables", h.handleGetTableActivity)
+	apiMux.HandleFunc("GET /api/stats/bloat", h.handleGetBloat)
+	apiMux.HandleFunc("GET /api/analysis/orphans", h.handleCountOrphans)
+	apiMux.HandleFunc("POST /api/maintenance", h.handleStartMaintenance)
+	apiMux.HandleFunc("GET /api/jobs", h.handleListJobs)
+	apiMux.HandleFunc("GET /api/jobs/{id}", h.handleGetJob)
+	apiMux.HandleFunc("POST /api/jobs/{id}/cancel", h.handleCancelJob)
+	apiMux.HandleFunc("GET /api/datacopy", h.handleListDataCopies)
+	apiMux.HandleFunc("POST /api/datacopy", h.handleStartDataCopy)
+	apiMux.HandleFunc("GET /api/datacopy/templates", h.handleGetDataCopyTemplates)
+	apiMux.HandleFunc("GET /api/datacopy/{id}", h.handleGetDataCopy)
+	apiMux.HandleFunc("POST /api/datacopy/{id}/cancel", h.handleCancelDataCopy)
+	apiMux.HandleFunc("POST /api/datacopy/{id}/resume", h.handleResumeDataCopy)
+	apiMux.HandleFunc("POST /api/datacopy/{id}/verify", h.handleStartDataCopyVerify)
+	apiMux.HandleFunc("GET /api/datacopy/{id}/verify", h.handleGetDataCopyVerify)
+	apiMux.HandleFunc("GET /api/snapshots", h.handleListSnapshots)
+	apiMux.HandleFunc("POST /api/snapshots", h.handleCreateSnapshot)
+	apiMux.HandleFunc("GET /api/snapshots/{id}", h.handleGetSnapshot)
+	apiMux.HandleFunc("DELETE /api/snapshots/{id}", h.handleDeleteSnapshot)
+	apiMux.HandleFunc("GET /api/workspace/export", h.handleExportWorkspace)
+	apiMux.HandleFunc("GET /api/notes", h.handleListNotes)
+	apiMux.HandleFunc("POST /api/notes", h.handleAddNote)
+	apiMux.HandleFunc("DELETE /api/notes/{id}", h.handleDeleteNote)
+	apiMux.HandleFunc("GET /api/lint", h.handleLint)
+	apiMux.HandleFunc("POST /api/lint/migration", h.handleLintMigration)
+	apiMux.HandleFunc("GET /api/diff", h.handleSchemaDiff)
+	apiMux.HandleFunc("GET /api/diff/visual", h.handleVisualDiff)
+	apiMux.HandleFunc("GET /api/drift", h.handleDrift)
+	apiMux.HandleFunc("GET /api/watch", h.handleGetSchemaWatch)
+	apiMux.HandleFunc("GET /api/watch/events", h.handleSchemaWatchEvents)
+	apiMux.HandleFunc("POST /api/schema/import", h.handleImportSchema)
+	apiMux.HandleFunc("POST /api/schema/import/dbml", h.handleImportDBML)
+	apiMux.HandleFunc("POST /api/drift/baseline", h.handleSaveBaseline)
+	apiMux.HandleFunc("GET /api/migrations", h.handleListMigrations)
+	apiMux.HandleFunc("POST /api/migrations/apply", h.handleApplyMigrations)
+	apiMux.HandleFunc("POST /api/migrations/rollback", h.handleRollbackMigrations)
+	apiMux.HandleFunc("POST /api/migrations/squash", h.handleSquashMigrations)
+	apiMux.HandleFunc("POST /api/migrations/generate", h.handleGenerateMigration)
+	apiMux.HandleFunc("POST /api/migrations/plan", h.handlePlanMigration)
+	apiMux.HandleFunc("POST /api/migrations/validate", h.handleValidateMigration)
+	apiMux.HandleFunc("GET /api/migrations/validate/{id}", h.handleGetMigrationValidation)
+	// {view} rather than log, which would conflict with validate/{id}
+	apiMux.HandleFunc("GET /api/migrations/{id}/{view}", h.handleGetMigrationLog)
+	apiMux.HandleFunc("POST /api/migrations/export", h.handleExportMigration)
+	apiMux.HandleFunc("POST /api/migrations/write", h.handleWriteMigration)
+	apiMux.HandleFunc("POST /api/seed", h.handleSeed)
+	apiMux.HandleFunc("GET /api/templates", h.handleListTemplates)
+	apiMux.HandleFunc("POST /api/templates", h.handleCreateTemplate)
+	apiMux.HandleFunc("GET /api/templates/{id}", h.handleGetTemplate)
+	apiMux.HandleFunc("PUT /api/templates/{id}", h.handleUpdateTemplate)
+	apiMux.HandleFunc("DELETE /api/templates/{id}", h.handleDeleteTemplate)
+	apiMux.HandleFunc("POST /api/templates/{id}/apply", h.handleApplyTemplate)
+	apiMux.HandleFunc("GET /api/preferences", h.handleGetPreferences)
+	apiMux.HandleFunc("PUT /api/preferences/pins/{tableName}", h.handlePinTable)
+	apiMux.HandleFunc("DELETE /api/preferences/pins/{tableName}", h.handleUnpinTable)
+	apiMux.HandleFunc("POST /api/preferences/recent", h.handleRecordVisit)
 
 	// Apply middleware chain: body limit -> rate limiting -> CSRF
 	// 1MB limit for API request bodies
 	protected := LimitBodySize(h.rateLimiter.Wrap(h.csrf.Wrap(apiMux)), 1<<20)
 	mux.Handle("/api/", protected)
 
+	// Workspace import carries snapshots, so it gets a larger body limit
+	importHandler := LimitBodySize(h.rateLimiter.Wrap(h.csrf.Wrap(http.HandlerFunc(h.handleImportWorkspace))), maxImportSize)
+	mux.Handle("POST /api/workspace/import", importHandler)
+
 	// Static files (no CSRF needed for GET)
 	mux.Handle("/", http.FileServer(http.FS(h.webFS)))
 }
@@ -75,6 +268,13 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 func (h *Handler) Stop() {
 	h.csrf.Stop()
 	h.rateLimiter.Stop()
+	h.jobs.Stop()
+	if h.snapshotCron != nil {
+		h.snapshotCron.Stop()
+	}
+	if h.schemaWatcher != nil {
+		h.schemaWatcher.Stop()
+	}
 }
 
 type csrfTokenData struct {
@@ -95,20 +295,66 @@ type apiResponse[T any] struct {
 type apiError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	Details any    `json:"details,omitempty"` // Structured context, e.g. blocking objects
 }
 
 // Error codes for API responses
 const (
-	ErrInvalidRequest   = "INVALID_REQUEST"
-	ErrMissingField     = "MISSING_FIELD"
-	ErrInvalidTableName = "INVALID_TABLE_NAME"
-	ErrInvalidColName   = "INVALID_COLUMN_NAME"
-	ErrSchemaError      = "SCHEMA_ERROR"
-	ErrDatabaseError    = "DATABASE_ERROR"
-	ErrConnectionError  = "CONNECTION_ERROR"
-	ErrUnknownDatabase  = "UNKNOWN_DATABASE"
-	ErrCreateTable      = "CREATE_TABLE_ERROR"
-	ErrAddColumn        = "ADD_COLUMN_ERROR"
+	ErrInvalidRequest       = "INVALID_REQUEST"
+	ErrMissingField         = "MISSING_FIELD"
+	ErrInvalidTableName     = "INVALID_TABLE_NAME"
+	ErrInvalidColName       = "INVALID_COLUMN_NAME"
+	ErrSchemaError          = "SCHEMA_ERROR"
+	ErrDatabaseError        = "DATABASE_ERROR"
+	ErrConnectionError      = "CONNECTION_ERROR"
+	ErrUnknownDatabase      = "UNKNOWN_DATABASE"
+	ErrDatabaseExists       = "DATABASE_EXISTS"
+	ErrCreateTable          = "CREATE_TABLE_ERROR"
+	ErrCreateView           = "CREATE_VIEW_ERROR"
+	ErrViewExists           = "VIEW_EXISTS"
+	ErrAddColumn            = "ADD_COLUMN_ERROR"
+	ErrAddForeignKey        = "ADD_FOREIGN_KEY_ERROR"
+	ErrDropTable            = "DROP_TABLE_ERROR"
+	ErrRename               = "RENAME_ERROR"
+	ErrAlterColumn          = "ALTER_COLUMN_ERROR"
+	ErrAlterTable           = "ALTER_TABLE_ERROR"
+	ErrPartitionError       = "PARTITION_ERROR"
+	ErrTableNotFound        = "TABLE_NOT_FOUND"
+	ErrHasDependents        = "HAS_DEPENDENTS"
+	ErrHasNulls             = "HAS_NULLS"
+	ErrConstraintNotFound   = "CONSTRAINT_NOT_FOUND"
+	ErrIndexNotFound        = "INDEX_NOT_FOUND"
+	ErrConstraintError      = "CONSTRAINT_ERROR"
+	ErrTypeNotFound         = "TYPE_NOT_FOUND"
+	ErrTypeError            = "TYPE_ERROR"
+	ErrNoteNotFound         = "NOTE_NOT_FOUND"
+	ErrTagsDisabled         = "TAGS_DISABLED"
+	ErrStoreError           = "STORE_ERROR"
+	ErrStatsError           = "STATS_ERROR"
+	ErrAnalysisError        = "ANALYSIS_ERROR"
+	ErrActivityError        = "ACTIVITY_ERROR"
+	ErrJobError             = "JOB_ERROR"
+	ErrJobNotFound          = "JOB_NOT_FOUND"
+	ErrJobConflict          = "JOB_CONFLICT"
+	ErrForbidden            = "FORBIDDEN"
+	ErrBackendNotFound      = "BACKEND_NOT_FOUND"
+	ErrConfirmationRequired = "CONFIRMATION_REQUIRED"
+	ErrSnapshotNotFound     = "SNAPSHOT_NOT_FOUND"
+	ErrImportError          = "IMPORT_ERROR"
+	ErrNothingToUndo        = "NOTHING_TO_UNDO"
+	ErrCannotUndo           = "CANNOT_UNDO"
+	ErrUndoError            = "UNDO_ERROR"
+	ErrTruncate             = "TRUNCATE_ERROR"
+	ErrRowCountChanged      = "ROW_COUNT_CHANGED"
+	ErrMigrationsDisabled   = "MIGRATIONS_DISABLED"
+	ErrNothingToMigrate     = "NOTHING_TO_MIGRATE"
+	ErrMigrationError       = "MIGRATION_ERROR"
+	ErrMigrationNotFound    = "MIGRATION_NOT_FOUND"
+	ErrBaselineNotFound     = "BASELINE_NOT_FOUND"
+	ErrDriftDetected        = "DRIFT_DETECTED"
+	ErrWatchDisabled        = "WATCH_DISABLED"
+	ErrWatchPending         = "WATCH_PENDING"
+	ErrTemplateNotFound     = "TEMPLATE_NOT_FOUND"
 )
 
 // respondJSON sends a successful JSON response with type-safe data
@@ -135,11 +381,22 @@ func (h *Handler) respondError(w http.ResponseWriter, code string, clientMessage
 		log.Printf("[%s] %s", code, clientMessage)
 	}
 
+	writeError(w, status, apiError{Code: code, Message: clientMessage})
+}
+
+// respondErrorDetails sends an error JSON response carrying structured details
+// the client needs to act on, such as the objects blocking an operation.
+func (h *Handler) respondErrorDetails(w http.ResponseWriter, code string, clientMessage string, status int, details any) {
+	log.Printf("[%s] %s", code, clientMessage)
+	writeError(w, status, apiError{Code: code, Message: clientMessage, Details: details})
+}
+
+func writeError(w http.ResponseWriter, status int, e apiError) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(status)
 	resp := errorResponse{
 		Success: false,
-		Error:   &apiError{Code: code, Message: clientMessage},
+		Error:   &e,
 	}
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		log.Printf("failed to encode error response: %v", err)
@@ -156,6 +413,40 @@ func (h *Handler) decodeJSONBody(w http.ResponseWriter, r *http.Request, v any)
 	return true
 }
 
+// validateDeferral checks a foreign key's deferral flags.
+// Returns false if invalid (error response already sent).
+func (h *Handler) validateDeferral(w http.ResponseWriter, deferrable, initiallyDeferred bool) bool {
+	if initiallyDeferred && !deferrable {
+		h.respondError(w, ErrInvalidRequest, "initiallyDeferred requires deferrable", http.StatusBadRequest, nil)
+		return false
+	}
+	return true
+}
+
+type ddlPreviewData struct {
+	DryRun     bool     `json:"dryRun"`
+	Statements []string `json:"statements"`
+}
+
+// previewContext returns the request context or, for ?dryRun=true, one under
+// which mutations record their SQL in the returned preview instead of running it.
+func previewContext(r *http.Request) (context.Context, *schema.DDLPreview) {
+	if r.URL.Query().Get("dryRun") != "true" {
+		return r.Context(), nil
+	}
+	return schema.WithDDLPreview(r.Context())
+}
+
+// respondPreview sends the recorded statements if preview is set.
+// Returns true if it responded.
+func respondPreview(w http.ResponseWriter, preview *schema.DDLPreview) bool {
+	if preview == nil {
+		return false
+	}
+	respondJSON(w, ddlPreviewData{DryRun: true, Statements: preview.Statements})
+	return true
+}
+
 // validateIdentifier checks if a name is a valid SQL identifier.
 // Returns true if valid, false if validation failed (error response already sent).
 func (h *Handler) validateIdentifier(w http.ResponseWriter, name, fieldName, errCode string) bool {
@@ -170,12 +461,57 @@ func (h *Handler) validateIdentifier(w http.ResponseWriter, name, fieldName, err
 	return true
 }
 
+// requireAdmin checks the X-Admin-Token header against the configured admin token.
+// Admin endpoints are disabled entirely when no token is configured.
+// Returns false if the check failed (error response already sent).
+func (h *Handler) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if h.config.AdminToken == "" {
+		h.respondError(w, ErrForbidden, "Admin operations are disabled", http.StatusForbidden, nil)
+		return false
+	}
+	token := r.Header.Get("X-Admin-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.config.AdminToken)) != 1 {
+		h.respondError(w, ErrForbidden, "Admin token required", http.StatusForbidden, nil)
+		return false
+	}
+	return true
+}
+
+// handleGetSchema returns the schema. With ?includeGrants=true each table
+// also lists the roles holding SELECT/INSERT/UPDATE/DELETE on it.
+// ?includeSystem=true|false overrides INCLUDE_SYSTEM_CATALOGS, which appends
+// the pg_catalog and information_schema tables.
 func (h *Handler) handleGetSchema(w http.ResponseWriter, r *http.Request) {
 	schema, err := h.introspector.GetSchema(r.Context())
 	if err != nil {
 		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
 		return
 	}
+
+	if r.URL.Query().Get("includeGrants") == "true" {
+		grants, err := h.introspector.GetTableGrants(r.Context())
+		if err != nil {
+			h.respondError(w, ErrSchemaError, "Failed to load table grants", http.StatusInternalServerError, err)
+			return
+		}
+		for idx := range schema.Tables {
+			schema.Tables[idx].Grants = grants[schema.Tables[idx].Name]
+		}
+	}
+
+	includeSystem := h.config.IncludeSystemCatalogs
+	if v, err := strconv.ParseBool(r.URL.Query().Get("includeSystem")); err == nil {
+		includeSystem = v
+	}
+	if includeSystem {
+		systemTables, err := h.introspector.GetSystemTables(r.Context())
+		if err != nil {
+			h.respondError(w, ErrSchemaError, "Failed to load system catalogs", http.StatusInternalServerError, err)
+			return
+		}
+		schema.Tables = append(schema.Tables, systemTables...)
+	}
+
 	respondJSON(w, schema)
 }
 
@@ -197,12 +533,103 @@ func (h *Handler) handleListDatabases(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleGetDatabaseInfo returns encoding, locale, and version details for the
+// current database, to confirm source and target match before copying data.
+func (h *Handler) handleGetDatabaseInfo(w http.ResponseWriter, r *http.Request) {
+	info, err := h.introspector.GetDatabaseInfo(r.Context())
+	if err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to load database info", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, info)
+}
+
+type schemasData struct {
+	Schemas []schema.SchemaInfo `json:"schemas"`
+	Current string              `json:"current"`
+}
+
+func (h *Handler) handleListSchemas(w http.ResponseWriter, r *http.Request) {
+	schemas, err := h.introspector.ListSchemas(r.Context())
+	if err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to list schemas", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, schemasData{
+		Schemas: schemas,
+		Current: schema.IntrospectedSchema,
+	})
+}
+
+type tablespacesData struct {
+	Tablespaces []schema.Tablespace `json:"tablespaces"`
+}
+
+func (h *Handler) handleListTablespaces(w http.ResponseWriter, r *http.Request) {
+	tablespaces, err := h.introspector.ListTablespaces(r.Context())
+	if err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to list tablespaces", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, tablespacesData{Tablespaces: tablespaces})
+}
+
 type typesData struct {
-	Types []schema.TypeInfo `json:"types"`
+	Types       []schema.TypeInfo           `json:"types"`
+	Conversions []schema.ConversionTemplate `json:"conversions"` // USING choices for type changes
+	// StorageParameters are the table storage parameters that may be set
+	StorageParameters []string `json:"storageParameters"`
 }
 
 func (h *Handler) handleGetTypes(w http.ResponseWriter, r *http.Request) {
-	respondJSON(w, typesData{Types: schema.AllowedTypes})
+	custom, err := h.customTypes(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load user-defined types", http.StatusInternalServerError, err)
+		return
+	}
+
+	types := make([]schema.TypeInfo, 0, len(schema.AllowedTypes)+len(custom))
+	types = append(types, schema.AllowedTypes...)
+	types = append(types, custom...)
+	respondJSON(w, typesData{
+		Types:             types,
+		Conversions:       schema.ConversionTemplates,
+		StorageParameters: schema.StorageParameterNames(),
+	})
+}
+
+// customTypes returns the user-defined types columns may use in the current
+// database: enums, domains, and composite types, plus extension types when
+// ALLOW_EXTENSION_TYPES is set.
+func (h *Handler) customTypes(ctx context.Context) ([]schema.TypeInfo, error) {
+	enums, err := h.introspector.GetEnumTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	domains, err := h.introspector.GetDomainTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	composites, err := h.introspector.GetCompositeTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	types := schema.EnumTypeInfo(enums)
+	types = append(types, schema.DomainTypeInfo(domains)...)
+	types = append(types, schema.CompositeTypeInfo(composites)...)
+
+	if h.config.AllowExtensionTypes {
+		extTypes, err := h.introspector.GetExtensionTypes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, schema.ExtensionTypeInfo(extTypes)...)
+	}
+	return types, nil
 }
 
 type switchDatabaseRequest struct {
@@ -225,21 +652,7 @@ func (h *Handler) handleSwitchDatabase(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate database name against allowed list
-	databases, err := h.introspector.ListDatabases(r.Context())
-	if err != nil {
-		h.respondError(w, ErrDatabaseError, "Failed to validate database", http.StatusInternalServerError, err)
-		return
-	}
-
-	allowed := false
-	for _, db := range databases {
-		if db == req.Name {
-			allowed = true
-			break
-		}
-	}
-	if !allowed {
-		h.respondError(w, ErrUnknownDatabase, "Database not found", http.StatusBadRequest, nil)
+	if !h.validateDatabase(w, r, req.Name) {
 		return
 	}
 
@@ -294,8 +707,37 @@ func (h *Handler) handleSwitchDatabase(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, switchDatabaseData{Database: req.Name})
 }
 
-type createTableRequest struct {
-	Name string `json:"name"`
+// validateDatabase checks that name is one of the listed user databases.
+// Returns false if validation failed (error response already sent).
+func (h *Handler) validateDatabase(w http.ResponseWriter, r *http.Request, name string) bool {
+	databases, err := h.introspector.ListDatabases(r.Context())
+	if err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to validate database", http.StatusInternalServerError, err)
+		return false
+	}
+
+	for _, db := range databases {
+		if db == name {
+			return true
+		}
+	}
+	h.respondError(w, ErrUnknownDatabase, "Database not found", http.StatusBadRequest, nil)
+	return false
+}
+
+// introspectDatabase loads the schema of another database on the same server
+// through a short-lived pool, leaving the active connection untouched.
+func (h *Handler) introspectDatabase(ctx context.Context, name string) (*schema.Schema, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.config.QueryTimeout)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, h.config.BuildDatabaseURL(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", name, err)
+	}
+	defer pool.Close()
+
+	return schema.NewIntrospector(pool, name, h.config.QueryTimeout).GetSchema(ctx)
 }
 
 type createTableData struct {
@@ -303,7 +745,7 @@ type createTableData struct {
 }
 
 func (h *Handler) handleCreateTable(w http.ResponseWriter, r *http.Request) {
-	var req createTableRequest
+	var req schema.CreateTableRequest
 	if !h.decodeJSONBody(w, r, &req) {
 		return
 	}
@@ -312,14 +754,219 @@ func (h *Handler) handleCreateTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.introspector.CreateTable(r.Context(), req.Name); err != nil {
+	var customNames []string
+	if len(req.Columns) > 0 {
+		custom, err := h.customTypes(r.Context())
+		if err != nil {
+			h.respondError(w, ErrSchemaError, "Failed to load user-defined types", http.StatusInternalServerError, err)
+			return
+		}
+		customNames = schema.TypeNames(custom)
+	}
+	for _, col := range req.Columns {
+		if !h.validateIdentifier(w, col.Name, "column name", ErrInvalidColName) {
+			return
+		}
+		if !schema.IsValidType(col.Type, customNames) {
+			h.respondError(w, ErrInvalidRequest, "Invalid column type", http.StatusBadRequest, nil)
+			return
+		}
+		if col.ForeignKey != nil && !h.validateDeferral(w, col.ForeignKey.Deferrable, col.ForeignKey.InitiallyDeferred) {
+			return
+		}
+		if !h.validateColumnOptions(w, col) {
+			return
+		}
+	}
+	for _, name := range req.PrimaryKey {
+		if !slices.ContainsFunc(req.Columns, func(c schema.AddColumnRequest) bool { return c.Name == name }) {
+			h.respondError(w, ErrInvalidRequest, "Primary key column "+name+" is not among the table's columns", http.StatusBadRequest, nil)
+			return
+		}
+	}
+	if req.IDIdentity != nil {
+		if len(req.PrimaryKey) > 0 {
+			h.respondError(w, ErrInvalidRequest, "An identity id column cannot be combined with a table primary key", http.StatusBadRequest, nil)
+			return
+		}
+		if _, err := schema.BuildIdentityClause("bigint", *req.IDIdentity); err != nil {
+			h.respondError(w, ErrInvalidRequest, "Invalid identity: "+err.Error(), http.StatusBadRequest, nil)
+			return
+		}
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.CreateTable(ctx, req, customNames); err != nil {
+		if errors.Is(err, schema.ErrInvalidGenerated) {
+			h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+			return
+		}
 		h.respondError(w, ErrCreateTable, "Failed to create table", http.StatusInternalServerError, err)
 		return
 	}
+	if respondPreview(w, preview) {
+		return
+	}
 
 	respondJSON(w, createTableData{Table: req.Name})
 }
 
+type dropTableData struct {
+	Table      string                   `json:"table"`
+	Cascade    bool                     `json:"cascade"`
+	Dependents []schema.DependentObject `json:"dependents"` // Dropped along with the table
+}
+
+type dependentsDetails struct {
+	Dependents []schema.DependentObject `json:"dependents"`
+}
+
+// handleDropTable drops a table. If other tables reference it or views use it,
+// the request is refused with the dependents listed unless ?cascade=true.
+func (h *Handler) handleDropTable(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+	cascade := r.URL.Query().Get("cascade") == "true"
+
+	dependents, err := h.introspector.GetTableDependents(r.Context(), tableName)
+	if err != nil {
+		if errors.Is(err, schema.ErrTableNotFound) {
+			h.respondError(w, ErrTableNotFound, "Table not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrDropTable, "Failed to check table dependents", http.StatusInternalServerError, err)
+		return
+	}
+	if len(dependents) > 0 && !cascade {
+		h.respondErrorDetails(w, ErrHasDependents, "Other objects depend on this table; retry with cascade=true to drop them too",
+			http.StatusConflict, dependentsDetails{Dependents: dependents})
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.DropTable(ctx, tableName, cascade); err != nil {
+		h.respondError(w, ErrDropTable, "Failed to drop table", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	log.Printf("[SCHEMA] Dropped table %s (cascade=%v, %d dependents)", tableName, cascade, len(dependents))
+	respondJSON(w, dropTableData{Table: tableName, Cascade: cascade, Dependents: dependents})
+}
+
+type alterColumnTypeData struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Type   string `json:"type"`
+}
+
+func (h *Handler) handleAlterColumnType(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	columnName := r.PathValue("columnName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, columnName, "column name", ErrInvalidColName) {
+		return
+	}
+
+	var req schema.AlterColumnTypeRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.Type == "" {
+		h.respondError(w, ErrMissingField, "Column type is required", http.StatusBadRequest, nil)
+		return
+	}
+	custom, err := h.customTypes(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load user-defined types", http.StatusInternalServerError, err)
+		return
+	}
+	customNames := schema.TypeNames(custom)
+	if !schema.IsValidType(req.Type, customNames) || req.Type == "serial" || req.Type == "bigserial" {
+		h.respondError(w, ErrInvalidRequest, "Invalid column type", http.StatusBadRequest, nil)
+		return
+	}
+	if req.Using != "" && !slices.ContainsFunc(schema.ConversionTemplates, func(t schema.ConversionTemplate) bool { return t.Name == req.Using }) {
+		h.respondError(w, ErrInvalidRequest, "Unknown conversion", http.StatusBadRequest, nil)
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.AlterColumnType(ctx, tableName, columnName, req, customNames); err != nil {
+		h.respondError(w, ErrAlterColumn, "Failed to change column type", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, alterColumnTypeData{Table: tableName, Column: columnName, Type: req.Type})
+}
+
+type setNullableRequest struct {
+	Nullable *bool `json:"nullable"`
+}
+
+type setNullableData struct {
+	Table    string `json:"table"`
+	Column   string `json:"column"`
+	Nullable bool   `json:"nullable"`
+}
+
+type nullCountDetails struct {
+	NullCount int64 `json:"nullCount"`
+}
+
+// handleSetNullable sets or drops NOT NULL on a column. Before setting it the
+// existing NULLs are counted, so the client gets a count rather than a raw
+// constraint violation.
+func (h *Handler) handleSetNullable(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	columnName := r.PathValue("columnName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, columnName, "column name", ErrInvalidColName) {
+		return
+	}
+
+	var req setNullableRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.Nullable == nil {
+		h.respondError(w, ErrMissingField, "nullable is required", http.StatusBadRequest, nil)
+		return
+	}
+
+	if !*req.Nullable {
+		count, err := h.introspector.CountNulls(r.Context(), tableName, columnName)
+		if err != nil {
+			h.respondError(w, ErrAlterColumn, "Failed to check column for NULLs", http.StatusInternalServerError, err)
+			return
+		}
+		if count > 0 {
+			h.respondErrorDetails(w, ErrHasNulls, fmt.Sprintf("Column has %d NULL values; fill them before setting NOT NULL", count),
+				http.StatusConflict, nullCountDetails{NullCount: count})
+			return
+		}
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.SetNullable(ctx, tableName, columnName, *req.Nullable); err != nil {
+		h.respondError(w, ErrAlterColumn, "Failed to change column nullability", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, setNullableData{Table: tableName, Column: columnName, Nullable: *req.Nullable})
+}
+
 type addColumnData struct {
 	Column string `json:"column"`
 }
@@ -335,28 +982,175 @@ func (h *Handler) handleAddColumn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" {
-		h.respondError(w, ErrMissingField, "Column name is required", http.StatusBadRequest, nil)
+	custom, err := h.customTypes(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load user-defined types", http.StatusInternalServerError, err)
 		return
 	}
-	if !schema.ValidIdentifier(req.Name) {
-		h.respondError(w, ErrInvalidColName, "Invalid column name format", http.StatusBadRequest, nil)
+	customNames := schema.TypeNames(custom)
+	if !h.validateNewColumn(w, req, customNames) {
 		return
 	}
 
-	if req.Type == "" {
-		h.respondError(w, ErrMissingField, "Column type is required", http.StatusBadRequest, nil)
+	ctx, preview := previewContext(r)
+	if err := h.introspector.AddColumn(ctx, tableName, req, customNames); err != nil {
+		if errors.Is(err, schema.ErrInvalidGenerated) {
+			h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+			return
+		}
+		h.respondError(w, ErrAddColumn, "Failed to add column", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
 		return
 	}
-	if !schema.IsValidType(req.Type) {
+
+	respondJSON(w, addColumnData{Column: req.Name})
+}
+
+// validateNewColumn checks the name, type, and foreign key deferral of a
+// column to be added. Returns false if a check failed (error response already sent).
+func (h *Handler) validateNewColumn(w http.ResponseWriter, col schema.AddColumnRequest, customNames []string) bool {
+	if col.Name == "" {
+		h.respondError(w, ErrMissingField, "Column name is required", http.StatusBadRequest, nil)
+		return false
+	}
+	if !schema.ValidIdentifier(col.Name) {
+		h.respondError(w, ErrInvalidColName, "Invalid column name format", http.StatusBadRequest, nil)
+		return false
+	}
+
+	if col.Type == "" {
+		h.respondError(w, ErrMissingField, "Column type is required", http.StatusBadRequest, nil)
+		return false
+	}
+	if !schema.IsValidType(col.Type, customNames) {
 		h.respondError(w, ErrInvalidRequest, "Invalid column type", http.StatusBadRequest, nil)
+		return false
+	}
+	if col.ForeignKey != nil && !h.validateDeferral(w, col.ForeignKey.Deferrable, col.ForeignKey.InitiallyDeferred) {
+		return false
+	}
+	return h.validateColumnOptions(w, col)
+}
+
+// validateColumnOptions checks a column's generated expression against the
+// expression grammar and its identity options, if any. Returns false if one
+// is invalid (error response already sent).
+func (h *Handler) validateColumnOptions(w http.ResponseWriter, col schema.AddColumnRequest) bool {
+	if col.Generated != nil && col.Identity != nil {
+		h.respondError(w, ErrInvalidRequest, "Column "+col.Name+" cannot be both generated and an identity", http.StatusBadRequest, nil)
+		return false
+	}
+	if col.Generated != nil {
+		if _, err := schema.BuildGeneratedExpr(*col.Generated); err != nil {
+			h.respondError(w, ErrInvalidRequest, "Invalid generated expression for "+col.Name+": "+err.Error(), http.StatusBadRequest, nil)
+			return false
+		}
+	}
+	if col.Identity != nil {
+		if _, err := schema.BuildIdentityClause(col.Type, *col.Identity); err != nil {
+			h.respondError(w, ErrInvalidRequest, "Invalid identity for "+col.Name+": "+err.Error(), http.StatusBadRequest, nil)
+			return false
+		}
+	}
+	return true
+}
+
+// maxBatchColumns bounds how many columns one batch request may add.
+const maxBatchColumns = 100
+
+type addColumnsData struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+}
+
+// handleAddColumns adds an array of columns in a single ALTER TABLE, so a
+// large table is locked and rewritten once rather than once per column.
+func (h *Handler) handleAddColumns(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
 		return
 	}
 
-	if err := h.introspector.AddColumn(r.Context(), tableName, req); err != nil {
-		h.respondError(w, ErrAddColumn, "Failed to add column", http.StatusInternalServerError, err)
+	var reqs []schema.AddColumnRequest
+	if !h.decodeJSONBody(w, r, &reqs) {
+		return
+	}
+	if len(reqs) == 0 {
+		h.respondError(w, ErrMissingField, "At least one column is required", http.StatusBadRequest, nil)
+		return
+	}
+	if len(reqs) > maxBatchColumns {
+		h.respondError(w, ErrInvalidRequest, fmt.Sprintf("At most %d columns can be added at once", maxBatchColumns), http.StatusBadRequest, nil)
 		return
 	}
 
-	respondJSON(w, addColumnData{Column: req.Name})
+	custom, err := h.customTypes(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load user-defined types", http.StatusInternalServerError, err)
+		return
+	}
+	customNames := schema.TypeNames(custom)
+	names := make([]string, len(reqs))
+	for idx, col := range reqs {
+		if !h.validateNewColumn(w, col, customNames) {
+			return
+		}
+		if slices.Contains(names[:idx], col.Name) {
+			h.respondError(w, ErrInvalidRequest, "Duplicate column "+col.Name, http.StatusBadRequest, nil)
+			return
+		}
+		names[idx] = col.Name
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.AddColumns(ctx, tableName, reqs, customNames); err != nil {
+		if errors.Is(err, schema.ErrInvalidGenerated) {
+			h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+			return
+		}
+		h.respondError(w, ErrAddColumn, "Failed to add columns", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, addColumnsData{Table: tableName, Columns: names})
+}
+
+type addForeignKeyData struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+}
+
+func (h *Handler) handleAddForeignKey(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	var req schema.AddForeignKeyRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if !h.validateIdentifier(w, req.Column, "column name", ErrInvalidColName) ||
+		!h.validateIdentifier(w, req.ReferencesTable, "referenced table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, req.ReferencesColumn, "referenced column name", ErrInvalidColName) ||
+		!h.validateDeferral(w, req.Deferrable, req.InitiallyDeferred) {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.AddForeignKey(ctx, tableName, req); err != nil {
+		h.respondError(w, ErrAddForeignKey, "Failed to add foreign key", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, addForeignKeyData{Table: tableName, Column: req.Column})
 }
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type historyData struct {
+	Changes []schema.Change `json:"changes"`
+}
+
+type undoData struct {
+	Undone schema.Change `json:"undone"`
+}
+
+// handleGetHistory lists the schema changes applied since the server started,
+// newest first.
+func (h *Handler) handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, historyData{Changes: h.introspector.History()})
+}
+
+// handleUndo reverses the most recent schema change. A change without an
+// inverse, such as a dropped table, gets a 409 explaining why.
+func (h *Handler) handleUndo(w http.ResponseWriter, r *http.Request) {
+	ctx, preview := previewContext(r)
+	change, err := h.introspector.Undo(ctx)
+	if err != nil {
+		var cannotUndo *schema.CannotUndoError
+		switch {
+		case errors.Is(err, schema.ErrNothingToUndo):
+			h.respondError(w, ErrNothingToUndo, "There are no changes to undo", http.StatusNotFound, err)
+		case errors.As(err, &cannotUndo):
+			h.respondErrorDetails(w, ErrCannotUndo, "Cannot undo: "+cannotUndo.Reason, http.StatusConflict, change)
+		default:
+			h.respondError(w, ErrUndoError, "Failed to undo change", http.StatusInternalServerError, err)
+		}
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, undoData{Undone: change})
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/diff"
+	"github.com/JonMunkholm/AltDbMigration/internal/lint"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type lintData struct {
+	Findings []lint.Finding  `json:"findings"`
+	Rules    []lint.RuleInfo `json:"rules"`
+}
+
+func (h *Handler) handleLint(w http.ResponseWriter, r *http.Request) {
+	s, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	disabled := h.disabledLintRules()
+	respondJSON(w, lintData{
+		Findings: lint.Run(s, disabled),
+		Rules:    lint.Describe(disabled),
+	})
+}
+
+// disabledLintRules returns the rule IDs LINT_DISABLED_RULES turns off, for
+// schema and migration rules alike.
+func (h *Handler) disabledLintRules() map[string]bool {
+	disabled := make(map[string]bool, len(h.config.LintDisabledRules))
+	for _, id := range h.config.LintDisabledRules {
+		disabled[id] = true
+	}
+	return disabled
+}
+
+type lintMigrationRequest struct {
+	SQL string `json:"sql,omitempty"` // A script to check
+	// Or the migration to generate and check, as for /api/migrations/generate
+	Target   string `json:"target,omitempty"`
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+type lintMigrationData struct {
+	Statements []string                `json:"statements"`
+	Findings   []lint.MigrationFinding `json:"findings"`
+	Rules      []lint.RuleInfo         `json:"rules"`
+}
+
+// handleLintMigration checks DDL for dangerous patterns against the current
+// schema: either a pasted script or the up migration generated toward another
+// database or a snapshot. Findings refer to statements by index.
+func (h *Handler) handleLintMigration(w http.ResponseWriter, r *http.Request) {
+	var req lintMigrationRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.SQL != "" && (req.Target != "" || req.Snapshot != "") {
+		h.respondError(w, ErrInvalidRequest, "Give either sql or a target or snapshot to generate from, not both", http.StatusBadRequest, nil)
+		return
+	}
+
+	var before *schema.Schema
+	var statements []string
+	if req.SQL != "" {
+		statements = schema.SplitStatements(req.SQL)
+		if len(statements) == 0 {
+			h.respondError(w, ErrInvalidRequest, "The script has no statements", http.StatusBadRequest, nil)
+			return
+		}
+		var err error
+		if before, err = h.introspector.GetSchema(r.Context()); err != nil {
+			h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+			return
+		}
+	} else {
+		var after *schema.Schema
+		var ok bool
+		if before, after, _, ok = h.loadDiffSchemas(w, r, req.Target, req.Snapshot); !ok {
+			return
+		}
+		statements = diff.GenerateMigration(before, after).Up.Statements
+	}
+
+	views, err := h.introspector.GetViewColumnUsage(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load view dependencies", http.StatusInternalServerError, err)
+		return
+	}
+
+	disabled := h.disabledLintRules()
+	respondJSON(w, lintMigrationData{
+		Statements: statements,
+		Findings:   lint.RunMigration(statements, lint.MigrationContext{Schema: before, ViewColumns: views}, disabled),
+		Rules:      lint.DescribeMigration(disabled),
+	})
+}
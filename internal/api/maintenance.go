@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/jobs"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// maintenanceJobKind identifies maintenance jobs in the job list.
+const maintenanceJobKind = "maintenance"
+
+// maxMaintenanceTables bounds how many tables one maintenance job may touch.
+const maxMaintenanceTables = 100
+
+type maintenanceRequest struct {
+	Operation string   `json:"operation"`
+	Tables    []string `json:"tables"`
+	schema.MaintenanceOptions
+}
+
+func (h *Handler) handleStartMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req maintenanceRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if len(req.Tables) == 0 {
+		h.respondError(w, ErrMissingField, "At least one table is required", http.StatusBadRequest, nil)
+		return
+	}
+	if len(req.Tables) > maxMaintenanceTables {
+		h.respondError(w, ErrInvalidRequest, "Too many tables in one request", http.StatusBadRequest, nil)
+		return
+	}
+	for _, table := range req.Tables {
+		if !h.validateIdentifier(w, table, "table name", ErrInvalidTableName) {
+			return
+		}
+	}
+	if _, err := schema.BuildMaintenanceSQL(req.Operation, req.Tables[0], req.MaintenanceOptions); err != nil {
+		h.respondError(w, ErrInvalidRequest, "Operation must be analyze, vacuum, or reindex", http.StatusBadRequest, err)
+		return
+	}
+
+	if r.URL.Query().Get("dryRun") == "true" {
+		preview := ddlPreviewData{DryRun: true, Statements: make([]string, 0, len(req.Tables))}
+		for _, table := range req.Tables {
+			stmt, _ := schema.BuildMaintenanceSQL(req.Operation, table, req.MaintenanceOptions)
+			preview.Statements = append(preview.Statements, stmt)
+		}
+		respondJSON(w, preview)
+		return
+	}
+
+	// One maintenance job at a time keeps heavy I/O from piling up on the server
+	if h.jobs.Running(maintenanceJobKind) {
+		h.respondError(w, ErrJobConflict, "A maintenance job is already running", http.StatusConflict, nil)
+		return
+	}
+
+	tables := req.Tables
+	job, err := h.jobs.Start(maintenanceJobKind, len(tables), func(ctx context.Context, p *jobs.Progress) error {
+		for _, table := range tables {
+			p.Step(fmt.Sprintf("%s %s", req.Operation, table))
+			if err := h.introspector.RunMaintenance(ctx, req.Operation, table, req.MaintenanceOptions); err != nil {
+				return fmt.Errorf("%s %s: %w", req.Operation, table, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.respondError(w, ErrJobError, "Failed to start maintenance job", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, job)
+}
+
+type jobsData struct {
+	Jobs []jobs.Job `json:"jobs"`
+}
+
+func (h *Handler) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, jobsData{Jobs: h.jobs.List()})
+}
+
+func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobs.Get(r.PathValue("id"))
+	if err != nil {
+		h.respondError(w, ErrJobNotFound, "Job not found", http.StatusNotFound, nil)
+		return
+	}
+	respondJSON(w, job)
+}
+
+func (h *Handler) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.jobs.Cancel(id); err != nil {
+		if errors.Is(err, jobs.ErrNotFound) {
+			h.respondError(w, ErrJobNotFound, "Job not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrJobConflict, "Job is not running", http.StatusConflict, nil)
+		return
+	}
+
+	job, _ := h.jobs.Get(id)
+	respondJSON(w, job)
+}
@@ -0,0 +1,379 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/diff"
+	"github.com/JonMunkholm/AltDbMigration/internal/jobs"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// migrationsJobKind identifies migration runs in the job list.
+const migrationsJobKind = "migrations"
+
+// Migration statuses in the migrations list.
+const (
+	migrationApplied  = "applied"
+	migrationPending  = "pending"
+	migrationModified = "modified" // Applied, but the file changed since
+	migrationMissing  = "missing"  // Applied, but the file is gone
+)
+
+type migrationStatus struct {
+	Version     int        `json:"version"`
+	Name        string     `json:"name"`
+	File        string     `json:"file,omitempty"`
+	Status      string     `json:"status"`
+	Checksum    string     `json:"checksum"`
+	AppliedAt   *time.Time `json:"appliedAt,omitempty"`
+	ExecutionMs *int64     `json:"executionMs,omitempty"`
+}
+
+type migrationsData struct {
+	Directory  string            `json:"directory"` // Empty when none is configured
+	Migrations []migrationStatus `json:"migrations"`
+}
+
+// migrationStatuses pairs the files in the migrations directory with the
+// recorded migrations, in version order.
+func (h *Handler) migrationStatuses(ctx context.Context) ([]diff.MigrationSource, []migrationStatus, error) {
+	sources := []diff.MigrationSource{}
+	if h.config.MigrationsDir != "" {
+		var err error
+		if sources, err = diff.LoadMigrations(h.config.MigrationsDir); err != nil {
+			return nil, nil, err
+		}
+	}
+	applied, err := h.introspector.GetAppliedMigrations(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byVersion := make(map[int]schema.AppliedMigration, len(applied))
+	for _, m := range applied {
+		byVersion[m.Version] = m
+	}
+	statuses := make([]migrationStatus, 0, len(sources)+len(applied))
+	for _, src := range sources {
+		s := migrationStatus{Version: src.Version, Name: src.Name, File: src.File, Status: migrationPending, Checksum: src.Checksum}
+		if m, ok := byVersion[src.Version]; ok {
+			s.Status = migrationApplied
+			if m.Checksum != src.Checksum {
+				s.Status = migrationModified
+			}
+			s.AppliedAt, s.ExecutionMs = &m.AppliedAt, &m.ExecutionMs
+			delete(byVersion, src.Version)
+		}
+		statuses = append(statuses, s)
+	}
+	for _, m := range applied {
+		if _, ok := byVersion[m.Version]; ok {
+			statuses = append(statuses, migrationStatus{Version: m.Version, Name: m.Name, Status: migrationMissing,
+				Checksum: m.Checksum, AppliedAt: &m.AppliedAt, ExecutionMs: &m.ExecutionMs})
+		}
+	}
+	slices.SortStableFunc(statuses, func(a, b migrationStatus) int { return a.Version - b.Version })
+	return sources, statuses, nil
+}
+
+// handleListMigrations lists applied and pending migrations.
+func (h *Handler) handleListMigrations(w http.ResponseWriter, r *http.Request) {
+	_, statuses, err := h.migrationStatuses(r.Context())
+	if err != nil {
+		h.respondError(w, ErrMigrationError, "Failed to list migrations", http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, migrationsData{Directory: h.config.MigrationsDir, Migrations: statuses})
+}
+
+// handleApplyMigrations runs the pending migrations in the migrations
+// directory in version order, each in its own transaction, as a background
+// job. The run stops at the first failure. It is refused while an applied
+// migration's file has been modified.
+func (h *Handler) handleApplyMigrations(w http.ResponseWriter, r *http.Request) {
+	if h.config.MigrationsDir == "" {
+		h.respondError(w, ErrMigrationsDisabled, "No migrations directory is configured", http.StatusForbidden, nil)
+		return
+	}
+
+	sources, statuses, err := h.migrationStatuses(r.Context())
+	if err != nil {
+		h.respondError(w, ErrMigrationError, "Failed to list migrations", http.StatusInternalServerError, err)
+		return
+	}
+
+	var modified []migrationStatus
+	pending := make([]diff.MigrationSource, 0, len(sources))
+	for _, s := range statuses {
+		switch s.Status {
+		case migrationModified:
+			modified = append(modified, s)
+		case migrationPending:
+			idx := slices.IndexFunc(sources, func(src diff.MigrationSource) bool { return src.Version == s.Version })
+			pending = append(pending, sources[idx])
+		}
+	}
+	if len(modified) > 0 {
+		h.respondErrorDetails(w, ErrMigrationError, "Applied migrations were modified; restore their files before applying more",
+			http.StatusConflict, modified)
+		return
+	}
+	if len(pending) == 0 {
+		h.respondError(w, ErrNothingToMigrate, "No migrations are pending", http.StatusBadRequest, nil)
+		return
+	}
+
+	if h.jobs.Running(migrationsJobKind) {
+		h.respondError(w, ErrJobConflict, "A migration job is already running", http.StatusConflict, nil)
+		return
+	}
+
+	job, err := h.jobs.Start(migrationsJobKind, len(pending), func(ctx context.Context, p *jobs.Progress) error {
+		for _, src := range pending {
+			p.Step("Apply " + src.File)
+			m := schema.PendingMigration{Version: src.Version, Name: src.Name, Up: src.Up, Down: src.Down}
+			if _, err := h.introspector.ApplyMigration(ctx, m); err != nil {
+				return fmt.Errorf("%s: %w", src.File, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.respondError(w, ErrJobError, "Failed to start migrations job", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, job)
+}
+
+// handleGetMigrationLog returns the statement log of an applied migration,
+// for post-mortems: each statement's duration, rows affected, and the
+// notices the server sent while it ran. The id is the migration's version.
+func (h *Handler) handleGetMigrationLog(w http.ResponseWriter, r *http.Request) {
+	// The route matches any view; log is the only one
+	if r.PathValue("view") != "log" {
+		h.respondError(w, ErrMigrationNotFound, "Unknown migration resource "+r.PathValue("view"), http.StatusNotFound, nil)
+		return
+	}
+	version, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || version < 0 {
+		h.respondError(w, ErrInvalidRequest, "Invalid migration version", http.StatusBadRequest, nil)
+		return
+	}
+
+	m, err := h.introspector.GetMigrationLog(r.Context(), version)
+	if err != nil {
+		if errors.Is(err, schema.ErrMigrationNotApplied) {
+			h.respondError(w, ErrMigrationNotFound, "Migration is not applied", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrMigrationError, "Failed to get migration log", http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, m)
+}
+
+type rollbackRequest struct {
+	// To rolls back every migration above this version; without it only the
+	// last applied migration is rolled back
+	To *int `json:"to,omitempty"`
+}
+
+// rollbackProblem is why a migration cannot be rolled back.
+type rollbackProblem struct {
+	Version int    `json:"version"`
+	Problem string `json:"problem"`
+}
+
+// handleRollbackMigrations runs the stored down scripts of the last applied
+// migration, or of every migration above a target version newest first, as a
+// background job. Each down script must match the checksum recorded with it
+// and, where the migration's files are still present, so must they.
+func (h *Handler) handleRollbackMigrations(w http.ResponseWriter, r *http.Request) {
+	var req rollbackRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.To != nil && *req.To < 0 {
+		h.respondError(w, ErrInvalidRequest, "Target version must not be negative", http.StatusBadRequest, nil)
+		return
+	}
+
+	applied, err := h.introspector.GetAppliedMigrations(r.Context())
+	if err != nil {
+		h.respondError(w, ErrMigrationError, "Failed to list migrations", http.StatusInternalServerError, err)
+		return
+	}
+	sources := []diff.MigrationSource{}
+	if h.config.MigrationsDir != "" {
+		if sources, err = diff.LoadMigrations(h.config.MigrationsDir); err != nil {
+			h.respondError(w, ErrMigrationError, "Failed to list migrations", http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	// Newest first
+	var targets []schema.AppliedMigration
+	for _, m := range slices.Backward(applied) {
+		if req.To == nil && len(targets) == 1 {
+			break
+		}
+		if req.To == nil || m.Version > *req.To {
+			targets = append(targets, m)
+		}
+	}
+	if len(targets) == 0 {
+		h.respondError(w, ErrNothingToMigrate, "No applied migrations to roll back", http.StatusBadRequest, nil)
+		return
+	}
+
+	var problems []rollbackProblem
+	for _, m := range targets {
+		if m.DownChecksum == "" {
+			problems = append(problems, rollbackProblem{m.Version, "no down script was recorded"})
+			continue
+		}
+		idx := slices.IndexFunc(sources, func(src diff.MigrationSource) bool { return src.Version == m.Version })
+		if idx < 0 {
+			continue // Files removed; the stored script is checked when it runs
+		}
+		if src := sources[idx]; src.Checksum != m.Checksum {
+			problems = append(problems, rollbackProblem{m.Version, src.File + " was modified after it was applied"})
+		} else if src.DownFile != "" && src.DownChecksum != m.DownChecksum {
+			problems = append(problems, rollbackProblem{m.Version, src.DownFile + " differs from the down script recorded when it was applied"})
+		}
+	}
+	if len(problems) > 0 {
+		h.respondErrorDetails(w, ErrMigrationError, "Some migrations cannot be rolled back safely", http.StatusConflict, problems)
+		return
+	}
+
+	if h.jobs.Running(migrationsJobKind) {
+		h.respondError(w, ErrJobConflict, "A migration job is already running", http.StatusConflict, nil)
+		return
+	}
+
+	job, err := h.jobs.Start(migrationsJobKind, len(targets), func(ctx context.Context, p *jobs.Progress) error {
+		for _, m := range targets {
+			p.Step(fmt.Sprintf("Roll back %03d_%s", m.Version, m.Name))
+			if err := h.introspector.RollbackMigration(ctx, m.Version); err != nil {
+				return fmt.Errorf("%03d_%s: %w", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.respondError(w, ErrJobError, "Failed to start rollback job", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, job)
+}
+
+type squashMigrationsData struct {
+	DryRun   bool                     `json:"dryRun"`
+	Baseline diff.MigrationFiles      `json:"baseline"`
+	Squashed []migrationStatus        `json:"squashed"`          // The migrations the baseline replaces
+	Archive  string                   `json:"archive,omitempty"` // Where their files went, relative to the migrations directory
+	Up       string                   `json:"up"`                // The baseline has no down script: reversing it would drop every table
+	Warnings []string                 `json:"warnings"`
+	Record   *schema.AppliedMigration `json:"record,omitempty"` // The baseline's history entry
+}
+
+// handleSquashMigrations collapses every applied migration into one baseline
+// generated from the current schema. Their files move to an archive
+// directory inside the migrations directory, and their history entries to
+// the migrations backup table; the baseline takes the highest version, so
+// later migrations keep their numbers. The baseline gets no down script, so
+// it cannot be rolled back: reversing it would drop every table. It is
+// refused while any migration is pending or modified. ?dryRun=true shows the baseline without changing
+// anything.
+func (h *Handler) handleSquashMigrations(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	if h.config.MigrationsDir == "" {
+		h.respondError(w, ErrMigrationsDisabled, "No migrations directory is configured", http.StatusForbidden, nil)
+		return
+	}
+
+	sources, statuses, err := h.migrationStatuses(r.Context())
+	if err != nil {
+		h.respondError(w, ErrMigrationError, "Failed to list migrations", http.StatusInternalServerError, err)
+		return
+	}
+	var blocking []migrationStatus
+	versions := make([]int, 0, len(statuses))
+	for _, s := range statuses {
+		switch s.Status {
+		case migrationPending, migrationModified:
+			blocking = append(blocking, s)
+		default:
+			versions = append(versions, s.Version)
+		}
+	}
+	if len(blocking) > 0 {
+		h.respondErrorDetails(w, ErrMigrationError, "Apply pending migrations and restore modified files before squashing",
+			http.StatusConflict, blocking)
+		return
+	}
+	if len(versions) < 2 {
+		h.respondError(w, ErrNothingToMigrate, "Fewer than two migrations are applied", http.StatusBadRequest, nil)
+		return
+	}
+	if h.jobs.Running(migrationsJobKind) {
+		h.respondError(w, ErrJobConflict, "A migration job is already running", http.StatusConflict, nil)
+		return
+	}
+
+	current, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+	baseline := diff.BaselineMigration(current)
+	version := versions[len(versions)-1]
+	files := diff.NewMigrationFiles(version, "baseline")
+	files.Down = ""
+	data := squashMigrationsData{
+		DryRun:   r.URL.Query().Get("dryRun") == "true",
+		Baseline: files,
+		Squashed: statuses,
+		Up:       baseline.Up.SQL(),
+		Warnings: baseline.Up.Warnings,
+	}
+	if data.DryRun {
+		respondJSON(w, data)
+		return
+	}
+
+	dir := h.config.MigrationsDir
+	data.Baseline, data.Archive, err = diff.SquashMigrationFiles(dir, sources, version, baseline.Up)
+	if err != nil {
+		h.respondError(w, ErrMigrationError, "Failed to move migration files", http.StatusInternalServerError, err)
+		return
+	}
+	m := schema.PendingMigration{Version: version, Name: "baseline", Up: data.Up}
+	data.Record, err = h.introspector.SquashMigrations(r.Context(), versions, m)
+	if err != nil {
+		if undoErr := diff.UndoSquashMigrationFiles(dir, data.Baseline, data.Archive); undoErr != nil {
+			log.Printf("failed to restore squashed migration files from %s: %v", data.Archive, undoErr)
+		}
+		if errors.Is(err, schema.ErrHistoryChanged) {
+			h.respondError(w, ErrMigrationError, "Migrations were applied or rolled back meanwhile; try again", http.StatusConflict, nil)
+			return
+		}
+		h.respondError(w, ErrMigrationError, "Failed to reset migration history", http.StatusInternalServerError, err)
+		return
+	}
+
+	log.Printf("[ADMIN] Squashed %d migrations into %s; old files in %s", len(versions), data.Baseline.Up, data.Archive)
+	respondJSON(w, data)
+}
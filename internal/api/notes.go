@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+)
+
+// maxNoteLength caps note bodies so the notes document stays small.
+const maxNoteLength = 4000
+
+type notesData struct {
+	Notes []store.Note `json:"notes"`
+}
+
+func (h *Handler) handleListNotes(w http.ResponseWriter, r *http.Request) {
+	table := r.URL.Query().Get("table")
+	if table != "" && !h.validateIdentifier(w, table, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	respondJSON(w, notesData{Notes: h.notes.List(h.introspector.CurrentDatabase(), table)})
+}
+
+type addNoteRequest struct {
+	Kind   string `json:"kind"`
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Body   string `json:"body"`
+	Author string `json:"author"`
+}
+
+func (h *Handler) handleAddNote(w http.ResponseWriter, r *http.Request) {
+	var req addNoteRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if !store.ValidNoteKind(req.Kind) {
+		h.respondError(w, ErrInvalidRequest, "Note kind must be table, column, or relationship", http.StatusBadRequest, nil)
+		return
+	}
+	if !h.validateIdentifier(w, req.Table, "table name", ErrInvalidTableName) {
+		return
+	}
+	if req.Kind == store.NoteTable {
+		req.Column = ""
+	} else if !h.validateIdentifier(w, req.Column, "column name", ErrInvalidColName) {
+		return
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		h.respondError(w, ErrMissingField, "Note body is required", http.StatusBadRequest, nil)
+		return
+	}
+	if len(req.Body) > maxNoteLength {
+		h.respondError(w, ErrInvalidRequest, "Note body is too long", http.StatusBadRequest, nil)
+		return
+	}
+
+	author := strings.TrimSpace(req.Author)
+	if author == "" {
+		author = "anonymous"
+	}
+
+	note, err := h.notes.Add(store.Note{
+		Database: h.introspector.CurrentDatabase(),
+		Kind:     req.Kind,
+		Table:    req.Table,
+		Column:   req.Column,
+		Body:     req.Body,
+		Author:   author,
+	})
+	if err != nil {
+		h.respondError(w, ErrStoreError, "Failed to save note", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, note)
+}
+
+type deleteNoteData struct {
+	ID string `json:"id"`
+}
+
+func (h *Handler) handleDeleteNote(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := h.notes.Delete(id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrNoteNotFound, "Note not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrStoreError, "Failed to delete note", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, deleteNoteData{ID: id})
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/jobs"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// notNullJobKind identifies zero-downtime NOT NULL jobs in the job list.
+const notNullJobKind = "not-null"
+
+// handleSafeNotNull sets NOT NULL on a column without blocking reads and
+// writes while the table is scanned. The steps run as a background job whose
+// progress is reported through /api/jobs.
+func (h *Handler) handleSafeNotNull(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	columnName := r.PathValue("columnName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, columnName, "column name", ErrInvalidColName) {
+		return
+	}
+
+	steps, err := schema.BuildSafeNotNullSteps(tableName, columnName)
+	if err != nil {
+		h.respondError(w, ErrInvalidRequest, "Invalid column: "+err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	count, err := h.introspector.CountNulls(r.Context(), tableName, columnName)
+	if err != nil {
+		h.respondError(w, ErrAlterColumn, "Failed to check column for NULLs", http.StatusInternalServerError, err)
+		return
+	}
+	if count > 0 {
+		h.respondErrorDetails(w, ErrHasNulls, fmt.Sprintf("Column has %d NULL values; fill them before setting NOT NULL", count),
+			http.StatusConflict, nullCountDetails{NullCount: count})
+		return
+	}
+
+	if r.URL.Query().Get("dryRun") == "true" {
+		ctx, preview := previewContext(r)
+		if err := h.introspector.SetNotNullSafely(ctx, tableName, columnName, nil); err != nil {
+			h.respondError(w, ErrAlterColumn, "Failed to build NOT NULL steps", http.StatusInternalServerError, err)
+			return
+		}
+		respondPreview(w, preview)
+		return
+	}
+
+	// The temporary check's name is per column, so runs must not overlap
+	if h.jobs.Running(notNullJobKind) {
+		h.respondError(w, ErrJobConflict, "A NOT NULL job is already running", http.StatusConflict, nil)
+		return
+	}
+
+	job, err := h.jobs.Start(notNullJobKind, len(steps), func(ctx context.Context, p *jobs.Progress) error {
+		return h.introspector.SetNotNullSafely(ctx, tableName, columnName, func(step string) {
+			p.Step(fmt.Sprintf("%s on %s.%s", step, tableName, columnName))
+		})
+	})
+	if err != nil {
+		h.respondError(w, ErrJobError, "Failed to start NOT NULL job", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, job)
+}
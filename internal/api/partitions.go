@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type partitionData struct {
+	Table     string `json:"table"`
+	Partition string `json:"partition"`
+}
+
+// decodePartition reads and validates a create or attach partition request.
+// Returns false if decoding or validation failed (error response already sent).
+func (h *Handler) decodePartition(w http.ResponseWriter, r *http.Request) (string, schema.PartitionRequest, bool) {
+	var req schema.PartitionRequest
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return "", req, false
+	}
+	if !h.decodeJSONBody(w, r, &req) {
+		return "", req, false
+	}
+	if !h.validateIdentifier(w, req.Name, "partition name", ErrInvalidTableName) {
+		return "", req, false
+	}
+	return tableName, req, true
+}
+
+// respondPartitionError maps a partition operation error to a response.
+func (h *Handler) respondPartitionError(w http.ResponseWriter, message string, err error) {
+	if errors.Is(err, schema.ErrInvalidPartition) {
+		h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	h.respondError(w, ErrPartitionError, message, http.StatusInternalServerError, err)
+}
+
+// handleCreatePartition creates a new partition of a partitioned table.
+func (h *Handler) handleCreatePartition(w http.ResponseWriter, r *http.Request) {
+	tableName, req, ok := h.decodePartition(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.CreatePartition(ctx, tableName, req); err != nil {
+		h.respondPartitionError(w, "Failed to create partition", err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, partitionData{Table: tableName, Partition: req.Name})
+}
+
+// handleAttachPartition attaches an existing table as a partition.
+func (h *Handler) handleAttachPartition(w http.ResponseWriter, r *http.Request) {
+	tableName, req, ok := h.decodePartition(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.AttachPartition(ctx, tableName, req); err != nil {
+		h.respondPartitionError(w, "Failed to attach partition", err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, partitionData{Table: tableName, Partition: req.Name})
+}
+
+// handleDetachPartition detaches a partition, keeping it as a standalone
+// table. ?concurrently=true detaches without blocking queries on the parent.
+func (h *Handler) handleDetachPartition(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	partition := r.PathValue("partition")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, partition, "partition name", ErrInvalidTableName) {
+		return
+	}
+	concurrently := r.URL.Query().Get("concurrently") == "true"
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.DetachPartition(ctx, tableName, partition, concurrently); err != nil {
+		h.respondPartitionError(w, "Failed to detach partition", err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, partitionData{Table: tableName, Partition: partition})
+}
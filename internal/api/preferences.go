@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+)
+
+// defaultUser is used when the client does not identify itself.
+const defaultUser = "default"
+
+// requestUser returns the user named in the X-User header.
+// This is a local tool without authentication, so the header only
+// separates preferences between people sharing one server.
+func requestUser(r *http.Request) string {
+	user := strings.TrimSpace(r.Header.Get("X-User"))
+	if user == "" || len(user) > 64 {
+		return defaultUser
+	}
+	return user
+}
+
+func (h *Handler) handleGetPreferences(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, h.preferences.Get(requestUser(r), h.introspector.CurrentDatabase()))
+}
+
+func (h *Handler) handlePinTable(w http.ResponseWriter, r *http.Request) {
+	h.updatePreferences(w, r, r.PathValue("tableName"), h.preferences.Pin)
+}
+
+func (h *Handler) handleUnpinTable(w http.ResponseWriter, r *http.Request) {
+	h.updatePreferences(w, r, r.PathValue("tableName"), h.preferences.Unpin)
+}
+
+type recordVisitRequest struct {
+	Table string `json:"table"`
+}
+
+func (h *Handler) handleRecordVisit(w http.ResponseWriter, r *http.Request) {
+	var req recordVisitRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	h.updatePreferences(w, r, req.Table, h.preferences.Visit)
+}
+
+// updatePreferences validates the table name and applies a preference change for the requesting user.
+func (h *Handler) updatePreferences(w http.ResponseWriter, r *http.Request, table string, apply func(user, database, table string) (store.Preferences, error)) {
+	if !h.validateIdentifier(w, table, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	prefs, err := apply(requestUser(r), h.introspector.CurrentDatabase(), table)
+	if err != nil {
+		h.respondError(w, ErrStoreError, "Failed to save preferences", http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, prefs)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type manyToManyData struct {
+	Table string `json:"table"`
+}
+
+// handleCreateManyToMany creates a join table between two existing tables:
+// one foreign key column per side and a composite primary key over both.
+func (h *Handler) handleCreateManyToMany(w http.ResponseWriter, r *http.Request) {
+	var req schema.ManyToManyRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if !h.validateIdentifier(w, req.LeftTable, "left table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, req.RightTable, "right table name", ErrInvalidTableName) {
+		return
+	}
+	if req.JoinTable != "" && !h.validateIdentifier(w, req.JoinTable, "join table name", ErrInvalidTableName) {
+		return
+	}
+	if req.LeftColumn != "" && !h.validateIdentifier(w, req.LeftColumn, "left column name", ErrInvalidColName) {
+		return
+	}
+	if req.RightColumn != "" && !h.validateIdentifier(w, req.RightColumn, "right column name", ErrInvalidColName) {
+		return
+	}
+
+	var customNames []string
+	if len(req.Columns) > 0 {
+		custom, err := h.customTypes(r.Context())
+		if err != nil {
+			h.respondError(w, ErrSchemaError, "Failed to load user-defined types", http.StatusInternalServerError, err)
+			return
+		}
+		customNames = schema.TypeNames(custom)
+	}
+	for _, col := range req.Columns {
+		if !h.validateIdentifier(w, col.Name, "column name", ErrInvalidColName) {
+			return
+		}
+		if !schema.IsValidType(col.Type, customNames) {
+			h.respondError(w, ErrInvalidRequest, "Invalid column type", http.StatusBadRequest, nil)
+			return
+		}
+	}
+
+	ctx, preview := previewContext(r)
+	table, err := h.introspector.CreateManyToMany(ctx, req, customNames)
+	if err != nil {
+		if errors.Is(err, schema.ErrNoSingleColumnKey) {
+			h.respondError(w, ErrInvalidRequest, "Both tables need a single-column primary key", http.StatusBadRequest, err)
+			return
+		}
+		h.respondError(w, ErrCreateTable, "Failed to create join table", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, manyToManyData{Table: table})
+}
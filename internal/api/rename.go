@@ -0,0 +1,78 @@
+package api
+
+import "net/http"
+
+type renameRequest struct {
+	NewName string `json:"newName"`
+}
+
+type renameData struct {
+	Table   string `json:"table"`
+	Column  string `json:"column,omitempty"`
+	NewName string `json:"newName"`
+}
+
+// decodeRename reads and validates a rename request body.
+// Returns false if decoding or validation failed (error response already sent).
+func (h *Handler) decodeRename(w http.ResponseWriter, r *http.Request, current, field, code string) (string, bool) {
+	var req renameRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return "", false
+	}
+	if !h.validateIdentifier(w, req.NewName, "new "+field, code) {
+		return "", false
+	}
+	if req.NewName == current {
+		h.respondError(w, ErrInvalidRequest, "New name is the same as the current name", http.StatusBadRequest, nil)
+		return "", false
+	}
+	return req.NewName, true
+}
+
+func (h *Handler) handleRenameTable(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	newName, ok := h.decodeRename(w, r, tableName, "table name", ErrInvalidTableName)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.RenameTable(ctx, tableName, newName); err != nil {
+		h.respondError(w, ErrRename, "Failed to rename table", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, renameData{Table: tableName, NewName: newName})
+}
+
+func (h *Handler) handleRenameColumn(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	columnName := r.PathValue("columnName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, columnName, "column name", ErrInvalidColName) {
+		return
+	}
+
+	newName, ok := h.decodeRename(w, r, columnName, "column name", ErrInvalidColName)
+	if !ok {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.RenameColumn(ctx, tableName, columnName, newName); err != nil {
+		h.respondError(w, ErrRename, "Failed to rename column", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, renameData{Table: tableName, Column: columnName, NewName: newName})
+}
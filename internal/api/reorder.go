@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type reorderRequest struct {
+	Columns []string `json:"columns"` // Every column of the table, in the desired order
+}
+
+// handleReorderScript generates, but does not run, the migration that
+// recreates a table with its columns in a new order. With ?format=sql the
+// script is returned as plain text, warnings first as comments.
+func (h *Handler) handleReorderScript(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	var req reorderRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	script, err := h.introspector.BuildReorderScript(r.Context(), tableName, req.Columns)
+	if err != nil {
+		switch {
+		case errors.Is(err, schema.ErrTableNotFound):
+			h.respondError(w, ErrTableNotFound, "Table not found", http.StatusNotFound, nil)
+		case errors.Is(err, schema.ErrCannotReorder):
+			h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+		default:
+			h.respondError(w, ErrSchemaError, "Failed to generate reorder script", http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if r.URL.Query().Get("format") == "sql" {
+		var b strings.Builder
+		for _, warning := range script.Warnings {
+			b.WriteString("-- WARNING: " + warning + "\n")
+		}
+		for _, stmt := range script.Statements {
+			b.WriteString(stmt + ";\n")
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(b.String()))
+		return
+	}
+
+	respondJSON(w, script)
+}
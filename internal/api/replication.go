@@ -0,0 +1,15 @@
+package api
+
+import "net/http"
+
+// handleGetReplication lists publications and subscriptions, so a migration
+// using logical replication can see which tables are already published.
+func (h *Handler) handleGetReplication(w http.ResponseWriter, r *http.Request) {
+	replication, err := h.introspector.GetReplication(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load replication status", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, replication)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/jobs"
+	"github.com/JonMunkholm/AltDbMigration/internal/seed"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// seedJobKind identifies seed data generation in the job list.
+const seedJobKind = "seed"
+
+// defaultSeedRows is how many rows each table gets when no count is given.
+const defaultSeedRows = 100
+
+type seedRequest struct {
+	Rows int `json:"rows,omitempty"` // Per table, when tables is not given; default 100
+	// Tables gives row counts by table; only these tables are seeded
+	Tables map[string]int `json:"tables,omitempty"`
+	// Seed makes the values repeatable; without one a random seed is used
+	Seed uint64 `json:"seed,omitempty"`
+}
+
+type seedData struct {
+	DryRun   bool             `json:"dryRun,omitempty"`
+	Job      *jobs.Job        `json:"job,omitempty"`
+	Seed     uint64           `json:"seed"` // Pass it again to generate the same values
+	Tables   []seed.TablePlan `json:"tables"`
+	Warnings []string         `json:"warnings"`
+}
+
+// handleSeed fills tables of the current database with generated rows, so a
+// new schema can be demoed right away. Values follow column types and names
+// (emails, names, prices, dates), foreign keys point at existing rows with
+// parents seeded first, and unique keys are not repeated. It runs as a
+// background job in one transaction. ?dryRun=true returns the plan of
+// tables and column generators without inserting anything. Writing rows
+// requires the admin token.
+func (h *Handler) handleSeed(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	var req seedRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.Rows == 0 {
+		req.Rows = defaultSeedRows
+	}
+	if req.Rows < 1 || req.Rows > seed.MaxRows {
+		h.respondError(w, ErrInvalidRequest, fmt.Sprintf("Rows must be between 1 and %d", seed.MaxRows), http.StatusBadRequest, nil)
+		return
+	}
+	for table := range req.Tables {
+		if !h.validateIdentifier(w, table, "table name", ErrInvalidTableName) {
+			return
+		}
+	}
+	if req.Seed == 0 {
+		req.Seed = rand.Uint64N(1 << 53) // Exact as a JavaScript number
+	}
+
+	current, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+	enums, err := h.introspector.GetEnumTypes(r.Context())
+	if err != nil {
+		h.respondError(w, ErrTypeError, "Failed to load enum types", http.StatusInternalServerError, err)
+		return
+	}
+	plan, warnings, err := seed.Plan(current, enums, req.Rows, req.Tables)
+	if err != nil {
+		h.respondError(w, ErrInvalidRequest, "Cannot seed: "+err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+	if len(plan) == 0 {
+		h.respondError(w, ErrInvalidRequest, "There are no tables to seed", http.StatusBadRequest, nil)
+		return
+	}
+
+	data := seedData{Seed: req.Seed, Tables: plan, Warnings: warnings}
+	if r.URL.Query().Get("dryRun") == "true" {
+		data.DryRun = true
+		respondJSON(w, data)
+		return
+	}
+
+	if h.jobs.Running(seedJobKind) {
+		h.respondError(w, ErrJobConflict, "A seed is already running", http.StatusConflict, nil)
+		return
+	}
+	database := h.introspector.CurrentDatabase()
+	pool, err := pgxpool.New(context.Background(), h.config.BuildDatabaseURL(database))
+	if err != nil {
+		h.respondError(w, ErrConnectionError, "Failed to connect to database", http.StatusInternalServerError, err)
+		return
+	}
+
+	seeder := seed.NewSeeder(pool, plan, req.Seed)
+	job, err := h.jobs.Start(seedJobKind, len(plan), func(ctx context.Context, p *jobs.Progress) error {
+		defer pool.Close()
+		if err := seeder.Run(ctx, p.Step); err != nil {
+			return err
+		}
+		inserted, skipped := 0, 0
+		for _, t := range seeder.Results() {
+			inserted, skipped = inserted+t.Inserted, skipped+t.Skipped
+		}
+		log.Printf("[ADMIN] Seeded %d rows into %d tables of %s (%d left out for want of unique values)",
+			inserted, len(plan), database, skipped)
+		return nil
+	})
+	if err != nil {
+		pool.Close()
+		h.respondError(w, ErrJobError, "Failed to start seed job", http.StatusInternalServerError, err)
+		return
+	}
+
+	log.Printf("[ADMIN] Seeding %d tables of %s (seed %d)", len(plan), database, req.Seed)
+	data.Job = &job
+	respondJSON(w, data)
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/diff"
+	"github.com/JonMunkholm/AltDbMigration/internal/jobs"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// shadowValidateJobKind identifies shadow database validations in the job list.
+const shadowValidateJobKind = "shadow-validate"
+
+// How a shadow database gets the current schema before the migration runs.
+const (
+	shadowBaselineSchema   = "schema"   // Generated from the current schema; tables only
+	shadowBaselineFiles    = "files"    // The applied files of the migrations directory, replayed
+	shadowBaselineTemplate = "template" // A copy of a template database, as it is
+)
+
+type validateMigrationRequest struct {
+	Target   string `json:"target,omitempty"`   // Database with the desired schema
+	Snapshot string `json:"snapshot,omitempty"` // Or snapshot ID of it
+	// SQL is the migration to validate; without it the generated one is
+	SQL      string `json:"sql,omitempty"`
+	Baseline string `json:"baseline,omitempty"` // schema (default) or files
+	// Template is a database to copy as the shadow instead of building a
+	// baseline; nobody may be connected to it
+	Template string `json:"template,omitempty"`
+}
+
+// shadowValidation is the outcome of a validation, filled in as its job runs.
+type shadowValidation struct {
+	JobID      string   `json:"jobId"`
+	Target     string   `json:"target"`   // Database name, or snapshot:<id>
+	Baseline   string   `json:"baseline"` // schema, files, or template
+	Template   string   `json:"template,omitempty"`
+	Statements []string `json:"statements"` // The migration validated
+	// BaselineDiff is what the shadow lacked of the current schema before the
+	// migration ran; differences mean the baseline does not reproduce it
+	BaselineDiff *diff.SchemaDiff `json:"baselineDiff,omitempty"`
+	// Diff is what the shadow lacks of the desired schema after the migration
+	Diff     *diff.SchemaDiff `json:"diff,omitempty"`
+	Matches  bool             `json:"matches"` // The migration produced the desired schema
+	Warnings []string         `json:"warnings"`
+}
+
+// shadowValidations holds the validations started since the server came up,
+// by job ID.
+type shadowValidations struct {
+	mu      sync.Mutex
+	results map[string]*shadowValidation
+}
+
+func newShadowValidations() *shadowValidations {
+	return &shadowValidations{results: make(map[string]*shadowValidation)}
+}
+
+func (s *shadowValidations) get(id string) (shadowValidation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.results[id]
+	if !ok {
+		return shadowValidation{}, false
+	}
+	return *v, true
+}
+
+// add records v as the validation run by job id and returns a copy of it.
+func (s *shadowValidations) add(id string, v *shadowValidation) shadowValidation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v.JobID = id
+	s.results[id] = v
+	return *v
+}
+
+func (s *shadowValidations) update(v *shadowValidation, fn func(v *shadowValidation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(v)
+}
+
+type shadowValidationData struct {
+	Job        jobs.Job         `json:"job"`
+	Validation shadowValidation `json:"validation"`
+}
+
+// handleValidateMigration checks a migration in a shadow database before it
+// touches a real one: a scratch database gets the current schema, from a
+// baseline or a template copy, then the migration, and is introspected to
+// confirm the result matches the desired schema. The migration is the one
+// generated toward the target or snapshot, or a script given as sql. It runs
+// as a background job; the shadow database is dropped when it ends. Creating
+// databases and running scripts requires the admin token.
+func (h *Handler) handleValidateMigration(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	var req validateMigrationRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	switch {
+	case req.Template != "" && req.Baseline != "":
+		h.respondError(w, ErrInvalidRequest, "A template is the baseline; give one or the other", http.StatusBadRequest, nil)
+		return
+	case req.Template != "":
+		if !h.validateDatabase(w, r, req.Template) {
+			return
+		}
+		if req.Template == h.introspector.CurrentDatabase() {
+			h.respondError(w, ErrInvalidRequest, "The current database cannot be a template while the server is connected to it", http.StatusBadRequest, nil)
+			return
+		}
+		req.Baseline = shadowBaselineTemplate
+	case req.Baseline == "":
+		req.Baseline = shadowBaselineSchema
+	case req.Baseline != shadowBaselineSchema && req.Baseline != shadowBaselineFiles:
+		h.respondError(w, ErrInvalidRequest, "Baseline must be schema or files", http.StatusBadRequest, nil)
+		return
+	}
+
+	current, desired, target, ok := h.loadDiffSchemas(w, r, req.Target, req.Snapshot)
+	if !ok {
+		return
+	}
+
+	v := &shadowValidation{Target: target, Baseline: req.Baseline, Template: req.Template, Warnings: []string{}}
+	script := req.SQL
+	if script != "" {
+		v.Statements = schema.SplitStatements(script)
+		if len(v.Statements) == 0 {
+			h.respondError(w, ErrInvalidRequest, "The script has no statements", http.StatusBadRequest, nil)
+			return
+		}
+	} else {
+		up := diff.GenerateMigration(current, desired).Up
+		if len(up.Statements) == 0 {
+			h.respondError(w, ErrNothingToMigrate, "The schemas do not differ", http.StatusBadRequest, nil)
+			return
+		}
+		script, v.Statements = up.SQL(), up.Statements
+		v.Warnings = append(v.Warnings, up.Warnings...)
+	}
+
+	// Each baseline is a list of migrations for the shadow's runner to apply
+	var baseline []schema.PendingMigration
+	switch req.Baseline {
+	case shadowBaselineSchema:
+		m := diff.BaselineMigration(current)
+		baseline = []schema.PendingMigration{{Version: 0, Name: "baseline", Up: m.Up.SQL()}}
+		v.Warnings = append(v.Warnings, m.Up.Warnings[len(m.Up.Warnings)-1])
+	case shadowBaselineFiles:
+		if baseline, ok = h.appliedMigrationFiles(w, r); !ok {
+			return
+		}
+	}
+
+	if h.jobs.Running(shadowValidateJobKind) {
+		h.respondError(w, ErrJobConflict, "A shadow validation is already running", http.StatusConflict, nil)
+		return
+	}
+
+	steps := len(baseline) + 4 // Create, compare the baseline, migrate, compare the result
+	job, err := h.jobs.Start(shadowValidateJobKind, steps, func(ctx context.Context, p *jobs.Progress) error {
+		return h.runShadowValidation(ctx, p, v, baseline, script, current, desired)
+	})
+	if err != nil {
+		h.respondError(w, ErrJobError, "Failed to start shadow validation job", http.StatusInternalServerError, err)
+		return
+	}
+	validation := h.shadows.add(job.ID, v)
+
+	log.Printf("[ADMIN] Validating a migration to %s in a shadow database (baseline %s)", target, req.Baseline)
+	respondJSON(w, shadowValidationData{Job: job, Validation: validation})
+}
+
+// appliedMigrationFiles returns the applied migrations of the migrations
+// directory, in order, to replay as a baseline. Every applied migration needs
+// its file, unmodified.
+// Returns false if they cannot be replayed (error response already sent).
+func (h *Handler) appliedMigrationFiles(w http.ResponseWriter, r *http.Request) ([]schema.PendingMigration, bool) {
+	if h.config.MigrationsDir == "" {
+		h.respondError(w, ErrMigrationsDisabled, "No migrations directory is configured", http.StatusForbidden, nil)
+		return nil, false
+	}
+	sources, statuses, err := h.migrationStatuses(r.Context())
+	if err != nil {
+		h.respondError(w, ErrMigrationError, "Failed to list migrations", http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	var unusable []migrationStatus
+	for _, s := range statuses {
+		if s.Status == migrationMissing || s.Status == migrationModified {
+			unusable = append(unusable, s)
+		}
+	}
+	if len(unusable) > 0 {
+		h.respondErrorDetails(w, ErrMigrationError, "Applied migrations are missing or modified, so the files cannot rebuild the schema",
+			http.StatusConflict, unusable)
+		return nil, false
+	}
+
+	var applied []schema.PendingMigration
+	for _, src := range sources {
+		for _, s := range statuses {
+			if s.Version == src.Version && s.Status == migrationApplied {
+				applied = append(applied, schema.PendingMigration{Version: src.Version, Name: src.Name, Up: src.Up})
+			}
+		}
+	}
+	if len(applied) == 0 {
+		h.respondError(w, ErrNothingToMigrate, "No migrations are applied to replay", http.StatusBadRequest, nil)
+		return nil, false
+	}
+	return applied, true
+}
+
+// runShadowValidation builds the shadow database, migrates it, and records
+// how it compares with current before and desired after.
+func (h *Handler) runShadowValidation(ctx context.Context, p *jobs.Progress, v *shadowValidation,
+	baseline []schema.PendingMigration, script string, current, desired *schema.Schema) error {
+	p.Step("Create shadow database")
+	name, err := h.introspector.CreateShadowDatabase(ctx, v.Template)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// ctx may be cancelled by now; the drop must still happen
+		if err := h.introspector.DropScratchDatabase(context.Background(), name); err != nil {
+			log.Printf("failed to drop shadow database %s: %v", name, err)
+		}
+	}()
+
+	pool, err := pgxpool.New(ctx, h.config.BuildDatabaseURL(name))
+	if err != nil {
+		return fmt.Errorf("failed to connect to shadow database: %w", err)
+	}
+	defer pool.Close() // Before the drop, which runs last
+	shadow := schema.NewIntrospector(pool, name, h.config.QueryTimeout)
+
+	for _, m := range baseline {
+		p.Step(fmt.Sprintf("Apply baseline %03d_%s", m.Version, m.Name))
+		if _, err := shadow.ApplyMigration(ctx, m); err != nil {
+			return fmt.Errorf("baseline %03d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+
+	p.Step("Compare baseline with " + h.introspector.CurrentDatabase())
+	built, err := shadow.GetSchema(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load shadow schema: %w", err)
+	}
+	baselineDiff := diff.Compare(built, current)
+	h.shadows.update(v, func(v *shadowValidation) { v.BaselineDiff = &baselineDiff })
+
+	p.Step("Apply migration")
+	// Numbered after whatever the baseline or template recorded
+	applied, err := shadow.GetAppliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	version := 1
+	if len(applied) > 0 {
+		version = applied[len(applied)-1].Version + 1
+	}
+	if _, err := shadow.ApplyMigration(ctx, schema.PendingMigration{Version: version, Name: "migration", Up: script}); err != nil {
+		return err
+	}
+
+	p.Step("Compare with " + v.Target)
+	migrated, err := shadow.GetSchema(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load shadow schema: %w", err)
+	}
+	result := diff.Compare(migrated, desired)
+	h.shadows.update(v, func(v *shadowValidation) { v.Diff, v.Matches = &result, result.Identical })
+	return nil
+}
+
+// handleGetMigrationValidation returns a shadow validation with its job,
+// while it runs and after.
+func (h *Handler) handleGetMigrationValidation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	v, ok := h.shadows.get(id)
+	if !ok {
+		h.respondError(w, ErrJobNotFound, "Validation not found", http.StatusNotFound, nil)
+		return
+	}
+	job, err := h.jobs.Get(id)
+	if err != nil {
+		h.respondError(w, ErrJobNotFound, "Validation not found", http.StatusNotFound, nil)
+		return
+	}
+	respondJSON(w, shadowValidationData{Job: job, Validation: v})
+}
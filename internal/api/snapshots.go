@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+)
+
+type snapshotsData struct {
+	Snapshots []store.SnapshotInfo `json:"snapshots"`
+}
+
+func (h *Handler) handleListSnapshots(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, snapshotsData{Snapshots: h.snapshots.List(r.URL.Query().Get("database"))})
+}
+
+type createSnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+func (h *Handler) handleCreateSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req createSnapshotRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if len(req.Name) > 100 {
+		h.respondError(w, ErrInvalidRequest, "Snapshot name is too long", http.StatusBadRequest, nil)
+		return
+	}
+
+	s, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	info, err := h.snapshots.Save(req.Name, h.introspector.CurrentDatabase(), store.SnapshotManual, s)
+	if err != nil {
+		h.respondError(w, ErrStoreError, "Failed to save snapshot", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, info)
+}
+
+func (h *Handler) handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	snap, err := h.snapshots.Get(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrSnapshotNotFound, "Snapshot not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrStoreError, "Failed to load snapshot", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, snap)
+}
+
+type deleteSnapshotData struct {
+	ID string `json:"id"`
+}
+
+func (h *Handler) handleDeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.snapshots.Delete(id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrSnapshotNotFound, "Snapshot not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrStoreError, "Failed to delete snapshot", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, deleteSnapshotData{ID: id})
+}
+
+// captureScheduledSnapshot is run by the snapshot scheduler. It snapshots the
+// current database and prunes scheduled snapshots beyond the retention policy.
+func (h *Handler) captureScheduledSnapshot(ctx context.Context) {
+	database := h.introspector.CurrentDatabase()
+
+	s, err := h.introspector.GetSchema(ctx)
+	if err != nil {
+		log.Printf("[SNAPSHOT] Failed to load schema for %s: %v", database, err)
+		return
+	}
+
+	info, err := h.snapshots.Save("", database, store.SnapshotScheduled, s)
+	if err != nil {
+		log.Printf("[SNAPSHOT] Failed to save snapshot for %s: %v", database, err)
+		return
+	}
+	log.Printf("[SNAPSHOT] Captured %s (%d tables)", info.ID, info.TableCount)
+
+	removed, err := h.snapshots.Prune(database, store.SnapshotScheduled, h.config.SnapshotKeep, h.config.SnapshotMaxAge)
+	if err != nil {
+		log.Printf("[SNAPSHOT] Failed to prune snapshots for %s: %v", database, err)
+		return
+	}
+	if removed > 0 {
+		log.Printf("[SNAPSHOT] Pruned %d old snapshots", removed)
+	}
+}
+
+type schemaSnapshotData struct {
+	Database string         `json:"database"`
+	Hash     string         `json:"hash"`
+	Schema   *schema.Schema `json:"schema"`
+}
+
+// handleGetSchemaSnapshot returns the current schema with a content hash.
+// Comparing hashes across environments detects drift without diffing.
+func (h *Handler) handleGetSchemaSnapshot(w http.ResponseWriter, r *http.Request) {
+	s, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	hash, err := schema.Hash(s)
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to hash schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, schemaSnapshotData{
+		Database: h.introspector.CurrentDatabase(),
+		Hash:     hash,
+		Schema:   s,
+	})
+}
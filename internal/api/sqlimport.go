@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/diff"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type importSchemaRequest struct {
+	Name string `json:"name,omitempty"` // Snapshot name; defaults to schema.sql
+	SQL  string `json:"sql"`
+}
+
+type importSchemaData struct {
+	Snapshot store.SnapshotInfo `json:"snapshot"`
+	// Diff compares the current database (before) with the imported schema (after)
+	Diff diff.SchemaDiff `json:"diff"`
+}
+
+// handleImportSchema loads a schema.sql script, such as pg_dump --schema-only
+// output, and saves the schema it describes as a snapshot. Its ID works as
+// ?snapshot= anywhere a target schema is taken, so the script can be the
+// desired state of a diff or generated migration. The body is JSON, or the
+// script itself when sent as text/plain or application/sql with ?name=.
+//
+// PostgreSQL does the parsing: the script runs in a scratch database that is
+// dropped afterwards. Running arbitrary SQL on the server requires the admin
+// token, and psql meta-commands such as \connect are not supported.
+func (h *Handler) handleImportSchema(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	var req importSchemaRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/plain" || mediaType == "application/sql" {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.respondError(w, ErrInvalidRequest, "Invalid request body", http.StatusBadRequest, err)
+			return
+		}
+		req = importSchemaRequest{Name: r.URL.Query().Get("name"), SQL: string(body)}
+	} else if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.SQL) == "" {
+		h.respondError(w, ErrMissingField, "SQL is required", http.StatusBadRequest, nil)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		req.Name = "schema.sql"
+	}
+	if len(req.Name) > 100 {
+		h.respondError(w, ErrInvalidRequest, "Snapshot name is too long", http.StatusBadRequest, nil)
+		return
+	}
+
+	imported, err := h.schemaFromSQL(r.Context(), req.SQL)
+	if err != nil {
+		if errors.Is(err, schema.ErrInvalidSQL) {
+			h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+			return
+		}
+		h.respondError(w, ErrSchemaError, "Failed to import schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	current, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	info, err := h.snapshots.Save(req.Name, h.introspector.CurrentDatabase(), store.SnapshotImport, imported)
+	if err != nil {
+		h.respondError(w, ErrStoreError, "Failed to save imported schema", http.StatusInternalServerError, err)
+		return
+	}
+
+	log.Printf("[ADMIN] Imported schema %q as snapshot %s", req.Name, info.ID)
+	respondJSON(w, importSchemaData{Snapshot: info, Diff: diff.Compare(current, imported)})
+}
+
+// schemaFromSQL runs script in a scratch database and introspects the result.
+func (h *Handler) schemaFromSQL(ctx context.Context, script string) (*schema.Schema, error) {
+	name, err := h.introspector.CreateScratchDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// ctx may be done by now; the drop must still happen
+		if err := h.introspector.DropScratchDatabase(context.Background(), name); err != nil {
+			log.Printf("failed to drop scratch database %s: %v", name, err)
+		}
+	}()
+
+	pool, err := pgxpool.New(ctx, h.config.BuildDatabaseURL(name))
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Close() // Before the drop, which runs last
+
+	scratch := schema.NewIntrospector(pool, name, h.config.QueryTimeout)
+	if err := scratch.ExecScript(ctx, script); err != nil {
+		return nil, err
+	}
+	return scratch.GetSchema(ctx)
+}
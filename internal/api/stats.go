@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type columnStatsData struct {
+	Table   string               `json:"table"`
+	Columns []schema.ColumnStats `json:"columns"`
+}
+
+func (h *Handler) handleGetColumnStats(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	stats, err := h.introspector.GetColumnStats(r.Context(), tableName)
+	if err != nil {
+		h.respondError(w, ErrStatsError, "Failed to load column statistics", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, columnStatsData{Table: tableName, Columns: stats})
+}
+
+type tableActivityData struct {
+	Tables []schema.TableActivity `json:"tables"`
+}
+
+func (h *Handler) handleGetTableActivity(w http.ResponseWriter, r *http.Request) {
+	activity, err := h.introspector.GetTableActivity(r.Context())
+	if err != nil {
+		h.respondError(w, ErrStatsError, "Failed to load table activity", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, tableActivityData{Tables: activity})
+}
+
+func (h *Handler) handleGetBloat(w http.ResponseWriter, r *http.Request) {
+	report, err := h.introspector.GetBloatReport(r.Context())
+	if err != nil {
+		h.respondError(w, ErrStatsError, "Failed to estimate bloat", http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, report)
+}
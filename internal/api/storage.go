@@ -0,0 +1,85 @@
+package api
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type storageParametersData struct {
+	Table string   `json:"table"`
+	Set   []string `json:"set"`
+	Reset []string `json:"reset"`
+}
+
+type columnStorageData struct {
+	Table      string `json:"table"`
+	Column     string `json:"column"`
+	Storage    string `json:"storage,omitempty"`
+	Statistics *int   `json:"statistics,omitempty"`
+}
+
+// handleSetStorageParameters sets and resets whitelisted table storage
+// parameters such as fillfactor and the autovacuum_* options.
+func (h *Handler) handleSetStorageParameters(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	var req schema.StorageParametersRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if _, err := schema.BuildStorageParametersDDL(tableName, req); err != nil {
+		h.respondError(w, ErrInvalidRequest, "Invalid storage parameters: "+err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.SetStorageParameters(ctx, tableName, req); err != nil {
+		h.respondError(w, ErrAlterTable, "Failed to set storage parameters", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	set := make([]string, 0, len(req.Set))
+	for _, name := range slices.Sorted(maps.Keys(req.Set)) {
+		set = append(set, name+"="+req.Set[name])
+	}
+	respondJSON(w, storageParametersData{Table: tableName, Set: set, Reset: append([]string{}, req.Reset...)})
+}
+
+// handleSetColumnStorage changes a column's SET STORAGE mode and/or SET STATISTICS target.
+func (h *Handler) handleSetColumnStorage(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	columnName := r.PathValue("columnName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, columnName, "column name", ErrInvalidColName) {
+		return
+	}
+
+	var req schema.ColumnStorageRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if _, err := schema.BuildColumnStorageDDL(tableName, columnName, req); err != nil {
+		h.respondError(w, ErrInvalidRequest, "Invalid column storage: "+err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.SetColumnStorage(ctx, tableName, columnName, req); err != nil {
+		h.respondError(w, ErrAlterColumn, "Failed to set column storage", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, columnStorageData{Table: tableName, Column: columnName, Storage: req.Storage, Statistics: req.Statistics})
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type tagsData struct {
+	Enabled bool                `json:"enabled"`
+	Tags    []schema.ObjectTags `json:"tags"`
+}
+
+// handleListTags returns every tagged table and column, so the frontend can
+// color-code the graph by tag.
+func (h *Handler) handleListTags(w http.ResponseWriter, r *http.Request) {
+	tags, enabled, err := h.introspector.GetTags(r.Context())
+	if err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to load tags", http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, tagsData{Enabled: enabled, Tags: tags})
+}
+
+// handleEnableTags creates the metadata table tags are kept in. Tags are
+// opt-in because this is the only table the tool adds to the database.
+func (h *Handler) handleEnableTags(w http.ResponseWriter, r *http.Request) {
+	if err := h.introspector.EnableTags(r.Context()); err != nil {
+		h.respondError(w, ErrDatabaseError, "Failed to enable tags", http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, tagsData{Enabled: true, Tags: []schema.ObjectTags{}})
+}
+
+type setTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// setTags validates and saves the tags in the request body for a table or,
+// when columnName is set, one of its columns.
+func (h *Handler) setTags(w http.ResponseWriter, r *http.Request, tableName, columnName string) {
+	var req setTagsRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if len(req.Tags) > schema.MaxTags {
+		h.respondError(w, ErrInvalidRequest, fmt.Sprintf("At most %d tags are allowed", schema.MaxTags), http.StatusBadRequest, nil)
+		return
+	}
+	for _, tag := range req.Tags {
+		if !schema.ValidTag(tag) {
+			h.respondError(w, ErrInvalidRequest, "Invalid tag "+tag+": use up to 32 lowercase letters, digits, hyphens, and underscores", http.StatusBadRequest, nil)
+			return
+		}
+	}
+
+	if err := h.introspector.SetTags(r.Context(), tableName, columnName, req.Tags); err != nil {
+		if errors.Is(err, schema.ErrTagsDisabled) {
+			h.respondError(w, ErrTagsDisabled, "Tags are not enabled for this database", http.StatusConflict, nil)
+			return
+		}
+		h.respondError(w, ErrDatabaseError, "Failed to save tags", http.StatusInternalServerError, err)
+		return
+	}
+
+	tags := req.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	respondJSON(w, schema.ObjectTags{Table: tableName, Column: columnName, Tags: tags})
+}
+
+func (h *Handler) handleSetTableTags(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+	h.setTags(w, r, tableName, "")
+}
+
+func (h *Handler) handleSetColumnTags(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	columnName := r.PathValue("columnName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, columnName, "column name", ErrInvalidColName) {
+		return
+	}
+	h.setTags(w, r, tableName, columnName)
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+	"github.com/JonMunkholm/AltDbMigration/internal/templates"
+)
+
+type templatesData struct {
+	Templates []templates.Template `json:"templates"` // Built-in first, then user-defined
+}
+
+func (h *Handler) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+	list := make([]templates.Template, 0, len(templates.Builtins))
+	for _, t := range templates.Builtins {
+		t.Builtin = true
+		list = append(list, t)
+	}
+	list = append(list, h.templates.List()...)
+	respondJSON(w, templatesData{Templates: list})
+}
+
+// getTemplate looks up a built-in or user-defined template.
+// Returns false if there is none (error response already sent).
+func (h *Handler) getTemplate(w http.ResponseWriter, id string) (templates.Template, bool) {
+	if t, ok := templates.Builtin(id); ok {
+		return t, true
+	}
+	t, err := h.templates.Get(id)
+	if err != nil {
+		h.respondError(w, ErrTemplateNotFound, "Template not found", http.StatusNotFound, nil)
+		return templates.Template{}, false
+	}
+	return t, true
+}
+
+func (h *Handler) handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.getTemplate(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	respondJSON(w, t)
+}
+
+type saveTemplateRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Params      []templates.Param `json:"params"`
+	Up          []string          `json:"up"`
+	Down        []string          `json:"down"`
+}
+
+// decodeTemplate reads and validates a template from the request body.
+// Returns false if it is invalid (error response already sent).
+func (h *Handler) decodeTemplate(w http.ResponseWriter, r *http.Request) (templates.Template, bool) {
+	var req saveTemplateRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return templates.Template{}, false
+	}
+	t := templates.Template{
+		Name:        req.Name,
+		Description: req.Description,
+		Params:      req.Params,
+		Up:          req.Up,
+		Down:        req.Down,
+	}
+	if err := templates.Validate(&t); err != nil {
+		h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+		return templates.Template{}, false
+	}
+	return t, true
+}
+
+// handleCreateTemplate saves a user-defined template. Templates hold SQL run
+// as given, so defining one requires the admin token.
+func (h *Handler) handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	t, ok := h.decodeTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	saved, err := h.templates.Add(t)
+	if err != nil {
+		h.respondError(w, ErrStoreError, "Failed to save template", http.StatusInternalServerError, err)
+		return
+	}
+
+	log.Printf("[ADMIN] Created migration template %q (%s)", saved.Name, saved.ID)
+	respondJSON(w, saved)
+}
+
+// handleUpdateTemplate replaces a user-defined template; built-in ones cannot
+// be changed. Requires the admin token.
+func (h *Handler) handleUpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id := r.PathValue("id")
+	if _, ok := templates.Builtin(id); ok {
+		h.respondError(w, ErrForbidden, "Built-in templates cannot be changed", http.StatusForbidden, nil)
+		return
+	}
+	t, ok := h.decodeTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	t.ID = id
+	saved, err := h.templates.Update(t)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrTemplateNotFound, "Template not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrStoreError, "Failed to save template", http.StatusInternalServerError, err)
+		return
+	}
+
+	log.Printf("[ADMIN] Updated migration template %q (%s)", saved.Name, saved.ID)
+	respondJSON(w, saved)
+}
+
+type deleteTemplateData struct {
+	ID string `json:"id"`
+}
+
+// handleDeleteTemplate removes a user-defined template. Requires the admin token.
+func (h *Handler) handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id := r.PathValue("id")
+	if _, ok := templates.Builtin(id); ok {
+		h.respondError(w, ErrForbidden, "Built-in templates cannot be deleted", http.StatusForbidden, nil)
+		return
+	}
+
+	if err := h.templates.Delete(id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondError(w, ErrTemplateNotFound, "Template not found", http.StatusNotFound, nil)
+			return
+		}
+		h.respondError(w, ErrStoreError, "Failed to delete template", http.StatusInternalServerError, err)
+		return
+	}
+
+	log.Printf("[ADMIN] Deleted migration template %s", id)
+	respondJSON(w, deleteTemplateData{ID: id})
+}
+
+type applyTemplateRequest struct {
+	Tables []string `json:"tables"`
+	// AllTables applies the template to every table except foreign tables,
+	// partitions, and tables a parameter names, such as the tenant table
+	AllTables bool              `json:"allTables"`
+	Params    map[string]string `json:"params"`
+}
+
+type applyTemplateData struct {
+	Template   string   `json:"template"`
+	Tables     []string `json:"tables"`
+	Statements []string `json:"statements"`
+	Inverse    []string `json:"inverse"` // Run by undo; empty when the change cannot be undone
+}
+
+// handleApplyTemplate expands a template for each of the given tables and
+// runs the statements as one undoable change, all or nothing. User-defined
+// templates run SQL as given, so applying one requires the admin token;
+// previewing it with ?dryRun=true does not.
+func (h *Handler) handleApplyTemplate(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.getTemplate(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	var req applyTemplateRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.AllTables && len(req.Tables) > 0 {
+		h.respondError(w, ErrInvalidRequest, "Give tables or allTables, not both", http.StatusBadRequest, nil)
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if !t.Builtin && preview == nil && !h.requireAdmin(w, r) {
+		return
+	}
+
+	values, err := templates.Arguments(t, req.Params)
+	if err != nil {
+		h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	current, err := h.introspector.GetSchema(r.Context())
+	if err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to load schema", http.StatusInternalServerError, err)
+		return
+	}
+	tables := req.Tables
+	if req.AllTables {
+		tables = templateTables(current, t, values)
+	}
+	if len(tables) == 0 {
+		h.respondError(w, ErrMissingField, "At least one table is required", http.StatusBadRequest, nil)
+		return
+	}
+	for idx, name := range tables {
+		if !h.validateIdentifier(w, name, "table name", ErrInvalidTableName) {
+			return
+		}
+		if slices.Contains(tables[:idx], name) {
+			h.respondError(w, ErrInvalidRequest, "Table "+name+" is listed twice", http.StatusBadRequest, nil)
+			return
+		}
+		if !slices.ContainsFunc(current.Tables, func(t schema.Table) bool { return t.Name == name }) {
+			h.respondError(w, ErrTableNotFound, "Table "+name+" does not exist", http.StatusNotFound, nil)
+			return
+		}
+	}
+
+	c, err := templates.Expand(t, tables, values)
+	if err != nil {
+		h.respondError(w, ErrInvalidRequest, err.Error(), http.StatusBadRequest, nil)
+		return
+	}
+
+	description := fmt.Sprintf("Apply template %q to %d tables", t.Name, len(tables))
+	if err := h.introspector.ApplyTemplate(ctx, description, c.Up, c.Down); err != nil {
+		h.respondError(w, ErrSchemaError, "Failed to apply template", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	log.Printf("[SCHEMA] %s", description)
+	respondJSON(w, applyTemplateData{Template: t.ID, Tables: tables, Statements: c.Up, Inverse: c.Down})
+}
+
+// templateTables returns the tables a template applies to with allTables:
+// every table but foreign tables, partitions, which get a partitioned
+// table's columns from it, and tables named by an identifier parameter.
+func templateTables(s *schema.Schema, t templates.Template, values map[string]string) []string {
+	skip := make(map[string]bool)
+	for _, table := range s.Tables {
+		if table.Partitioning != nil {
+			for _, p := range table.Partitioning.Partitions {
+				skip[p.Name] = true
+			}
+		}
+	}
+	for _, p := range t.Params {
+		if p.Kind == templates.ParamIdentifier {
+			skip[values[p.Name]] = true
+		}
+	}
+
+	var tables []string
+	for _, table := range s.Tables {
+		if table.IsForeign || table.IsSystem || skip[table.Name] {
+			continue
+		}
+		tables = append(tables, table.Name)
+	}
+	return tables
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// truncateTokenTTL is how long a client has to confirm a truncation.
+const truncateTokenTTL = 2 * time.Minute
+
+// pendingTruncate is what a confirmation token was issued for.
+type pendingTruncate struct {
+	database string
+	table    string
+	rowCount int64
+	expires  time.Time
+}
+
+// truncateTokens holds one-time confirmation tokens for TRUNCATE.
+type truncateTokens struct {
+	mu      sync.Mutex
+	pending map[string]pendingTruncate
+}
+
+func newTruncateTokens() *truncateTokens {
+	return &truncateTokens{pending: make(map[string]pendingTruncate)}
+}
+
+// issue returns a new token for truncating table while it holds rowCount rows.
+func (t *truncateTokens) issue(database, table string, rowCount int64) (string, time.Time, error) {
+	token, err := generateSecureToken(24)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	now := time.Now()
+	expires := now.Add(truncateTokenTTL)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, p := range t.pending {
+		if now.After(p.expires) {
+			delete(t.pending, key)
+		}
+	}
+	t.pending[token] = pendingTruncate{database: database, table: table, rowCount: rowCount, expires: expires}
+	return token, expires, nil
+}
+
+// redeem consumes token and returns what it was issued for. A token works at
+// most once, even if it names the wrong table.
+func (t *truncateTokens) redeem(token string) (pendingTruncate, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.pending[token]
+	delete(t.pending, token)
+	if !ok || time.Now().After(p.expires) {
+		return pendingTruncate{}, false
+	}
+	return p, true
+}
+
+type truncateRequest struct {
+	Token           string `json:"token,omitempty"` // Empty to request a confirmation token
+	RestartIdentity bool   `json:"restartIdentity"`
+	Cascade         bool   `json:"cascade"` // Also truncate tables with foreign keys to this one
+}
+
+type truncateData struct {
+	Table     string     `json:"table"`
+	RowCount  int64      `json:"rowCount"`
+	Truncated bool       `json:"truncated"`
+	Token     string     `json:"token,omitempty"`     // Echo back to confirm; set when Truncated is false
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // When Token stops working
+}
+
+// handleTruncateTable empties a table in two steps. A request without a token
+// returns the table's row count and a one-time token bound to the table and
+// that count; repeating the request with the token truncates, unless rows
+// were added or removed in between.
+func (h *Handler) handleTruncateTable(w http.ResponseWriter, r *http.Request) {
+	tableName := r.PathValue("tableName")
+	if !h.validateIdentifier(w, tableName, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	var req truncateRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if r.URL.Query().Get("dryRun") == "true" {
+		ctx, preview := previewContext(r)
+		if err := h.introspector.TruncateTable(ctx, tableName, 0, req.RestartIdentity, req.Cascade); err != nil {
+			h.respondError(w, ErrTruncate, "Failed to build TRUNCATE", http.StatusInternalServerError, err)
+			return
+		}
+		respondPreview(w, preview)
+		return
+	}
+
+	database := h.introspector.CurrentDatabase()
+	if req.Token == "" {
+		count, err := h.introspector.CountRows(r.Context(), tableName)
+		if err != nil {
+			h.respondError(w, ErrTruncate, "Failed to count rows", http.StatusInternalServerError, err)
+			return
+		}
+		token, expires, err := h.truncations.issue(database, tableName, count)
+		if err != nil {
+			h.respondError(w, ErrTruncate, "Failed to issue confirmation token", http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, truncateData{Table: tableName, RowCount: count, Token: token, ExpiresAt: &expires})
+		return
+	}
+
+	pending, ok := h.truncations.redeem(req.Token)
+	if !ok || pending.table != tableName || pending.database != database {
+		h.respondError(w, ErrConfirmationRequired, "Confirmation token is invalid or expired; request a new one", http.StatusBadRequest, nil)
+		return
+	}
+
+	if err := h.introspector.TruncateTable(r.Context(), tableName, pending.rowCount, req.RestartIdentity, req.Cascade); err != nil {
+		if errors.Is(err, schema.ErrRowCountChanged) {
+			h.respondError(w, ErrRowCountChanged, "The table's row count changed since confirmation; request a new token", http.StatusConflict, err)
+			return
+		}
+		h.respondError(w, ErrTruncate, "Failed to truncate table", http.StatusInternalServerError, err)
+		return
+	}
+
+	log.Printf("[SCHEMA] Truncated table %s (%d rows, cascade=%v)", tableName, pending.rowCount, req.Cascade)
+	respondJSON(w, truncateData{Table: tableName, RowCount: pending.rowCount, Truncated: true})
+}
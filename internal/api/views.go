@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+type createViewData struct {
+	View string `json:"view"`
+}
+
+// handleCreateView creates a view from a structured definition: a base table,
+// joins along foreign keys, selected columns, and simple filters.
+func (h *Handler) handleCreateView(w http.ResponseWriter, r *http.Request) {
+	var req schema.ViewDefinition
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if !h.validateIdentifier(w, req.Name, "view name", ErrInvalidTableName) ||
+		!h.validateIdentifier(w, req.From, "table name", ErrInvalidTableName) {
+		return
+	}
+
+	ctx, preview := previewContext(r)
+	if err := h.introspector.CreateView(ctx, req); err != nil {
+		var invalid *schema.InvalidViewError
+		if errors.As(err, &invalid) {
+			h.respondError(w, ErrInvalidRequest, "Invalid view: "+invalid.Error(), http.StatusBadRequest, nil)
+			return
+		}
+		if errors.Is(err, schema.ErrViewExists) {
+			h.respondError(w, ErrViewExists, "A view or other relation named "+req.Name+" already exists", http.StatusConflict, nil)
+			return
+		}
+		h.respondError(w, ErrCreateView, "Failed to create view", http.StatusInternalServerError, err)
+		return
+	}
+	if respondPreview(w, preview) {
+		return
+	}
+
+	respondJSON(w, createViewData{View: req.Name})
+}
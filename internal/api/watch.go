@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/dbml"
+	"github.com/JonMunkholm/AltDbMigration/internal/diff"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Formats a watched schema file can be in, by extension.
+const (
+	schemaFileSQL  = "sql"
+	schemaFileDBML = "dbml"
+)
+
+// watchKeepAlive is how often an idle event stream gets a comment, so proxies
+// and browsers don't take it for a dead connection.
+const watchKeepAlive = 30 * time.Second
+
+// schemaFileFormat returns the format of a schema file from its extension.
+func schemaFileFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".sql":
+		return schemaFileSQL, nil
+	case ".dbml":
+		return schemaFileDBML, nil
+	}
+	return "", fmt.Errorf("schema file must be .sql or .dbml: %s", path)
+}
+
+// schemaWatchResult is the diff from the live database to the watched file,
+// as of the file's last change.
+type schemaWatchResult struct {
+	File      string    `json:"file"`
+	Format    string    `json:"format"` // sql or dbml
+	Database  string    `json:"database"`
+	CheckedAt time.Time `json:"checkedAt"`
+	// Diff is what the database lacks of the file; absent when Error is set
+	Diff     *diff.SchemaDiff `json:"diff,omitempty"`
+	Warnings []string         `json:"warnings"`
+	Error    string           `json:"error,omitempty"` // The file could not be read or parsed
+}
+
+// schemaWatch holds the latest watch result and the event streams it is
+// pushed to.
+type schemaWatch struct {
+	mu          sync.Mutex
+	latest      *schemaWatchResult // nil until the first check finishes
+	subscribers map[chan schemaWatchResult]struct{}
+	closed      bool
+}
+
+func newSchemaWatch() *schemaWatch {
+	return &schemaWatch{subscribers: make(map[chan schemaWatchResult]struct{})}
+}
+
+// publish records r as the latest result and sends it to every subscriber.
+// A subscriber that has not taken the previous result gets only this one.
+func (s *schemaWatch) publish(r schemaWatchResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = &r
+	for ch := range s.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- r
+	}
+}
+
+// subscribe returns a channel that receives each new result, starting with
+// the latest if there is one. Returns false once the watch is closed.
+func (s *schemaWatch) subscribe() (chan schemaWatchResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, false
+	}
+	ch := make(chan schemaWatchResult, 1)
+	if s.latest != nil {
+		ch <- *s.latest
+	}
+	s.subscribers[ch] = struct{}{}
+	return ch, true
+}
+
+func (s *schemaWatch) unsubscribe(ch chan schemaWatchResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, ch)
+}
+
+func (s *schemaWatch) get() (schemaWatchResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latest == nil {
+		return schemaWatchResult{}, false
+	}
+	return *s.latest, true
+}
+
+// close ends every subscription; their channels are closed.
+func (s *schemaWatch) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for ch := range s.subscribers {
+		close(ch)
+		delete(s.subscribers, ch)
+	}
+}
+
+// checkSchemaFile is run by the file watcher with the watched file's content
+// each time it changes, and publishes its diff against the live database.
+func (h *Handler) checkSchemaFile(ctx context.Context, content []byte, readErr error) {
+	format, _ := schemaFileFormat(h.config.SchemaFile) // Checked at startup
+	result := schemaWatchResult{
+		File:     h.config.SchemaFile,
+		Format:   format,
+		Database: h.introspector.CurrentDatabase(),
+		Warnings: []string{},
+	}
+
+	d, warnings, err := h.diffSchemaFile(ctx, format, content, readErr)
+	if ctx.Err() != nil {
+		return // Shutting down
+	}
+	if err != nil {
+		log.Printf("[WATCH] Failed to check %s: %v", h.config.SchemaFile, err)
+		result.Error = err.Error()
+	} else {
+		result.Diff = &d
+		result.Warnings = append(result.Warnings, warnings...)
+		log.Printf("[WATCH] Checked %s: %d tables differ", h.config.SchemaFile, len(d.Tables))
+	}
+	result.CheckedAt = time.Now().UTC()
+	h.schemaWatch.publish(result)
+}
+
+// diffSchemaFile parses a schema file's content and compares the live
+// database with it.
+func (h *Handler) diffSchemaFile(ctx context.Context, format string, content []byte, readErr error) (diff.SchemaDiff, []string, error) {
+	if readErr != nil {
+		return diff.SchemaDiff{}, nil, readErr
+	}
+
+	var desired *schema.Schema
+	var warnings []string
+	switch format {
+	case schemaFileSQL:
+		s, err := h.schemaFromSQL(ctx, string(content))
+		if err != nil {
+			return diff.SchemaDiff{}, nil, err
+		}
+		desired = s
+	case schemaFileDBML:
+		doc, err := dbml.Parse(string(content))
+		if err != nil {
+			return diff.SchemaDiff{}, nil, fmt.Errorf("invalid DBML: %w", err)
+		}
+		desired, warnings = doc.Schema, doc.Warnings
+	}
+
+	current, err := h.introspector.GetSchema(ctx)
+	if err != nil {
+		return diff.SchemaDiff{}, nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+	return diff.Compare(current, desired), warnings, nil
+}
+
+// CloseStreams ends open schema watch event streams so graceful shutdown
+// need not wait for their clients to hang up.
+func (h *Handler) CloseStreams() {
+	if h.schemaWatch != nil {
+		h.schemaWatch.close()
+	}
+}
+
+// requireSchemaWatch checks that a schema file is being watched.
+// Returns false if it is not (error response already sent).
+func (h *Handler) requireSchemaWatch(w http.ResponseWriter) bool {
+	if h.schemaWatch == nil {
+		h.respondError(w, ErrWatchDisabled, "No schema file is configured", http.StatusForbidden, nil)
+		return false
+	}
+	return true
+}
+
+// handleGetSchemaWatch returns the latest diff from the live database to the
+// watched schema file.
+func (h *Handler) handleGetSchemaWatch(w http.ResponseWriter, r *http.Request) {
+	if !h.requireSchemaWatch(w) {
+		return
+	}
+	result, ok := h.schemaWatch.get()
+	if !ok {
+		h.respondError(w, ErrWatchPending, "The schema file has not been checked yet", http.StatusServiceUnavailable, nil)
+		return
+	}
+	respondJSON(w, result)
+}
+
+// handleSchemaWatchEvents streams the diff to the watched schema file as
+// server-sent events: a diff event with the latest result on connect, then
+// one on every change, until the client hangs up.
+func (h *Handler) handleSchemaWatchEvents(w http.ResponseWriter, r *http.Request) {
+	if !h.requireSchemaWatch(w) {
+		return
+	}
+	ch, ok := h.schemaWatch.subscribe()
+	if !ok {
+		h.respondError(w, ErrWatchDisabled, "The schema watch has stopped", http.StatusServiceUnavailable, nil)
+		return
+	}
+	defer h.schemaWatch.unsubscribe(ch)
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.respondError(w, ErrWatchDisabled, "Streaming is not supported", http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(watchKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case result, open := <-ch:
+			if !open {
+				return // Shutting down
+			}
+			data, err := json.Marshal(result)
+			if err != nil {
+				log.Printf("failed to encode watch event: %v", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: diff\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
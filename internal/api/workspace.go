@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/store"
+)
+
+// maxImportSize bounds workspace archives, which carry full schema snapshots
+// and so outgrow the regular API body limit.
+const maxImportSize = 64 << 20
+
+func (h *Handler) workspace() store.Workspace {
+	return store.Workspace{
+		Notes:       h.notes,
+		Preferences: h.preferences,
+		Snapshots:   h.snapshots,
+	}
+}
+
+// handleExportWorkspace downloads the whole workspace as a single JSON archive.
+func (h *Handler) handleExportWorkspace(w http.ResponseWriter, r *http.Request) {
+	archive, err := h.workspace().Export()
+	if err != nil {
+		h.respondError(w, ErrStoreError, "Failed to export workspace", http.StatusInternalServerError, err)
+		return
+	}
+
+	filename := fmt.Sprintf("altdb-workspace-%s.json", archive.ExportedAt.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if err := json.NewEncoder(w).Encode(archive); err != nil {
+		log.Printf("failed to encode workspace archive: %v", err)
+	}
+}
+
+// handleImportWorkspace merges an exported archive into this workspace.
+func (h *Handler) handleImportWorkspace(w http.ResponseWriter, r *http.Request) {
+	var archive store.Archive
+	if !h.decodeJSONBody(w, r, &archive) {
+		return
+	}
+
+	result, err := h.workspace().Import(&archive)
+	if err != nil {
+		h.respondError(w, ErrImportError, "Failed to import workspace", http.StatusBadRequest, err)
+		return
+	}
+
+	log.Printf("[WORKSPACE] Imported archive from %s: %+v", archive.ExportedAt.Format(time.RFC3339), result)
+	respondJSON(w, result)
+}
@@ -5,15 +5,22 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// DefaultApplicationName is reported to PostgreSQL on every connection so the
+// tool's own sessions can be recognized in pg_stat_activity.
+const DefaultApplicationName = "altdbmigration"
+
 // Config holds the application configuration.
 type Config struct {
 	DatabaseURL string
 	Port        string
+	DataDir     string   // Directory for tool-owned state (notes, preferences)
+	AppName     string   // application_name used for all connections
 	dbURL       *url.URL // Parsed database URL for building new connections
 
 	// Timeouts
@@ -21,6 +28,31 @@ type Config struct {
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
 	QueryTimeout    time.Duration
+
+	// Lint rule IDs to skip
+	LintDisabledRules []string
+
+	// Token required for admin operations; empty disables them
+	AdminToken string
+
+	// Accept types installed by extensions (citext, hstore, geometry) as column types
+	AllowExtensionTypes bool
+
+	// Include pg_catalog and information_schema tables in the schema by default
+	IncludeSystemCatalogs bool
+
+	// Automatic snapshots: cron expression (empty disables) and retention
+	SnapshotSchedule string
+	SnapshotKeep     int
+	SnapshotMaxAge   time.Duration
+
+	// Directory generated golang-migrate files are written to; empty disables writing
+	MigrationsDir string
+
+	// Declarative schema file (.sql or .dbml) diffed against the live database
+	// whenever it changes; empty disables watching
+	SchemaFile          string
+	SchemaWatchInterval time.Duration
 }
 
 // Load reads configuration from .env file and environment variables.
@@ -38,19 +70,51 @@ func Load() (*Config, error) {
 		port = "8080"
 	}
 
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = ".altdb"
+	}
+
 	parsedURL, err := url.Parse(dbURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid DATABASE_URL: %w", err)
 	}
 
+	// Tag connections unless the user chose their own application name
+	query := parsedURL.Query()
+	appName := query.Get("application_name")
+	if appName == "" {
+		appName = DefaultApplicationName
+		query.Set("application_name", appName)
+		parsedURL.RawQuery = query.Encode()
+		dbURL = parsedURL.String()
+	}
+
 	return &Config{
 		DatabaseURL:     dbURL,
 		Port:            port,
+		DataDir:         dataDir,
+		AppName:         appName,
 		dbURL:           parsedURL,
 		ReadTimeout:     getDurationEnv("READ_TIMEOUT", 10*time.Second),
 		WriteTimeout:    getDurationEnv("WRITE_TIMEOUT", 10*time.Second),
 		ShutdownTimeout: getDurationEnv("SHUTDOWN_TIMEOUT", 5*time.Second),
 		QueryTimeout:    getDurationEnv("QUERY_TIMEOUT", 30*time.Second),
+
+		LintDisabledRules: getListEnv("LINT_DISABLED_RULES"),
+		AdminToken:        os.Getenv("ADMIN_TOKEN"),
+
+		AllowExtensionTypes:   getBoolEnv("ALLOW_EXTENSION_TYPES", false),
+		IncludeSystemCatalogs: getBoolEnv("INCLUDE_SYSTEM_CATALOGS", false),
+
+		SnapshotSchedule: os.Getenv("SNAPSHOT_SCHEDULE"),
+		SnapshotKeep:     getIntEnv("SNAPSHOT_KEEP", 30),
+		SnapshotMaxAge:   time.Duration(getIntEnv("SNAPSHOT_MAX_AGE_DAYS", 0)) * 24 * time.Hour,
+
+		MigrationsDir: os.Getenv("MIGRATIONS_DIR"),
+
+		SchemaFile:          os.Getenv("SCHEMA_FILE"),
+		SchemaWatchInterval: getDurationEnv("SCHEMA_WATCH_INTERVAL", 2*time.Second),
 	}, nil
 }
 
@@ -68,6 +132,38 @@ func getDurationEnv(key string, defaultVal time.Duration) time.Duration {
 	return time.Duration(seconds) * time.Second
 }
 
+// getIntEnv reads an integer from environment variable.
+// Returns default if not set or invalid.
+func getIntEnv(key string, defaultVal int) int {
+	val, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+// getBoolEnv reads a boolean from environment variable.
+// Returns default if not set or invalid.
+func getBoolEnv(key string, defaultVal bool) bool {
+	val, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+// getListEnv reads a comma-separated list from environment variable.
+// Empty entries are dropped; returns nil if not set.
+func getListEnv(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // BuildDatabaseURL returns a connection URL for the specified database name,
 // using the same host, user, password, and options as the original connection.
 func (c *Config) BuildDatabaseURL(dbName string) string {
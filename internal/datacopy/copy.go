@@ -0,0 +1,405 @@
+// Package datacopy copies table rows from one database to another with the
+// COPY protocol.
+package datacopy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultBatchSize is how many rows go into each COPY into the target when
+// Options.BatchSize is not set.
+const DefaultBatchSize = 10000
+
+// Table statuses in a copy's progress.
+const (
+	StatusPending   = "pending"
+	StatusCopying   = "copying"
+	StatusDone      = "done"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled" // Was being copied when the copy was cancelled
+)
+
+// Options control a copy.
+type Options struct {
+	// Truncate empties every target table in the plan before copying
+	Truncate bool `json:"truncate"`
+	// BatchSize is the number of rows per COPY into the target; 0 means DefaultBatchSize
+	BatchSize int `json:"batchSize,omitempty"`
+	// Upsert writes rows with INSERT ... ON CONFLICT on the target's primary
+	// key, so rows already there are updated rather than rejected, as when
+	// resuming into a partly copied table
+	Upsert bool `json:"upsert,omitempty"`
+}
+
+// TableProgress is where the copy of one table stands.
+type TableProgress struct {
+	Table         string `json:"table"`
+	Status        string `json:"status"`
+	RowsCopied    int64  `json:"rowsCopied"`
+	EstimatedRows int64  `json:"estimatedRows"` // From source statistics; 0 until analyzed
+	Error         string `json:"error,omitempty"`
+	// Checkpoint is the source key of the last row committed, as COPY text;
+	// set for tables with a primary key while they are partly copied
+	Checkpoint []string `json:"checkpoint,omitempty"`
+}
+
+// Copier copies the tables of a plan and tracks their progress.
+type Copier struct {
+	source, target *pgxpool.Pool
+	plan           []TableCopy
+	opts           Options
+	resumed        bool
+
+	mu       sync.Mutex
+	progress []TableProgress
+}
+
+// NewCopier prepares a copy of plan, as made by Plan, from source to target.
+func NewCopier(source, target *pgxpool.Pool, plan []TableCopy, opts Options) *Copier {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	progress := make([]TableProgress, len(plan))
+	for idx, t := range plan {
+		progress[idx] = TableProgress{Table: t.Table, Status: StatusPending, EstimatedRows: t.EstimatedRows}
+	}
+	return &Copier{source: source, target: target, plan: plan, opts: opts, progress: progress}
+}
+
+// Resume carries over the progress of an earlier run of the same copy:
+// finished tables are skipped, and tables with a checkpoint continue after
+// it. Other tables start over; without a primary key a table is written in
+// one transaction, so nothing of it was kept. The targets are not truncated
+// again.
+func (c *Copier) Resume(previous []TableProgress) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resumed = true
+	for idx, t := range c.plan {
+		n := slices.IndexFunc(previous, func(p TableProgress) bool { return p.Table == t.Table })
+		if n < 0 {
+			continue
+		}
+		switch p := previous[n]; {
+		case p.Status == StatusDone:
+			c.progress[idx].Status, c.progress[idx].RowsCopied = StatusDone, p.RowsCopied
+		case p.Checkpoint != nil && len(p.Checkpoint) == len(t.Key):
+			c.progress[idx].RowsCopied, c.progress[idx].Checkpoint = p.RowsCopied, p.Checkpoint
+		}
+	}
+}
+
+// Steps returns the number of steps Run reports.
+func (c *Copier) Steps() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	steps := 0
+	if c.opts.Truncate && !c.resumed {
+		steps++
+	}
+	for _, p := range c.progress {
+		if p.Status != StatusDone {
+			steps++
+		}
+	}
+	return steps
+}
+
+// Progress returns the state of each table, in copy order.
+func (c *Copier) Progress() []TableProgress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]TableProgress(nil), c.progress...)
+}
+
+func (c *Copier) update(idx int, fn func(p *TableProgress)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(&c.progress[idx])
+}
+
+// Run copies the tables in order, calling step before each one and save
+// whenever progress worth keeping changes. The source is read in one
+// repeatable-read transaction, so every table comes from the same snapshot.
+// Tables with a primary key are read in key order and committed batch by
+// batch, each batch checkpointed; other tables are written in one
+// transaction, so a failed or cancelled table is left as it was. Sequences
+// of serial and identity columns are moved past the copied values. Run
+// stops at the first failure.
+func (c *Copier) Run(ctx context.Context, step func(description string), save func(progress []TableProgress)) error {
+	src, err := c.source.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to begin source transaction: %w", err)
+	}
+	defer src.Rollback(context.Background()) // Read-only; nothing to keep
+
+	if c.opts.Truncate && !c.resumed {
+		step("Truncate target tables")
+		names := make([]string, len(c.plan))
+		for idx, t := range c.plan {
+			names[idx] = quoteIdent(t.Table)
+		}
+		// One statement, so foreign keys between the tables don't block it
+		if _, err := c.target.Exec(ctx, "TRUNCATE "+strings.Join(names, ", ")); err != nil {
+			return fmt.Errorf("failed to truncate target tables: %w", err)
+		}
+	}
+
+	for idx, t := range c.plan {
+		if c.Progress()[idx].Status == StatusDone {
+			continue // Finished by an earlier run
+		}
+		step("Copy " + t.Table)
+		c.update(idx, func(p *TableProgress) { p.Status, p.Error = StatusCopying, "" })
+		save(c.Progress())
+		if err := c.copyTable(ctx, src, idx, t, save); err != nil {
+			cancelled := ctx.Err() != nil
+			c.update(idx, func(p *TableProgress) {
+				p.Status = StatusFailed
+				if cancelled {
+					p.Status = StatusCancelled
+				} else {
+					p.Error = err.Error()
+				}
+				if len(t.Key) == 0 {
+					p.RowsCopied = 0 // Rolled back
+				}
+			})
+			save(c.Progress())
+			if cancelled {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%s: %w", t.Table, err)
+		}
+		c.update(idx, func(p *TableProgress) { p.Status, p.Checkpoint = StatusDone, nil })
+		save(c.Progress())
+	}
+	return nil
+}
+
+// copyTable streams one table out of the source as COPY text and into the
+// target in batches of BatchSize rows. Text format rows are one line each,
+// since COPY escapes newlines inside values, and need no type information.
+// For tables with a primary key the source key is selected after the copied
+// columns and cut off each row, to checkpoint by.
+func (c *Copier) copyTable(ctx context.Context, src pgx.Tx, idx int, t TableCopy, save func([]TableProgress)) error {
+	keyed := len(t.Key) > 0
+	keys := make([]string, len(t.Key))
+	for n, col := range t.Key {
+		keys[n] = quoteIdent(col)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(slices.Concat(t.Select, keys), ", "), quoteIdent(t.Table))
+	if checkpoint := c.Progress()[idx].Checkpoint; checkpoint != nil {
+		values := make([]string, len(checkpoint))
+		for n, value := range checkpoint {
+			values[n] = schema.QuoteLiteral(unescapeCopyText(value))
+		}
+		query += fmt.Sprintf(" WHERE (%s) > (%s)", strings.Join(keys, ", "), strings.Join(values, ", "))
+	}
+	if keyed {
+		query += " ORDER BY " + strings.Join(keys, ", ")
+	}
+
+	// The target transaction: one per batch for keyed tables, else one for the table
+	var dst pgx.Tx
+	defer func() {
+		if dst != nil {
+			dst.Rollback(context.Background())
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	reader, writer := io.Pipe()
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := src.Conn().PgConn().CopyTo(ctx, writer, "COPY ("+query+") TO STDOUT")
+		writer.CloseWithError(err)
+		readErr <- err
+	}()
+	fail := func(format string, err error) error {
+		cancel() // Stop the reader, which may be blocked on the pipe
+		reader.Close()
+		<-readErr
+		return fmt.Errorf(format, err)
+	}
+
+	lines := bufio.NewReader(reader)
+	var batch bytes.Buffer
+	for done := false; !done; {
+		batch.Reset()
+		rows := 0
+		var lastKey []string
+		for rows < c.opts.BatchSize {
+			line, err := lines.ReadBytes('\n')
+			if errors.Is(err, io.EOF) {
+				done = true // COPY ends every row with a newline, so nothing is left over
+				break
+			}
+			if err != nil {
+				return fail("failed to read source rows: %w", err)
+			}
+			if keyed {
+				line, lastKey = splitKey(line, len(t.Key))
+			}
+			batch.Write(line)
+			rows++
+		}
+		if rows == 0 {
+			break
+		}
+
+		if dst == nil {
+			var err error
+			if dst, err = c.target.Begin(ctx); err != nil {
+				return fail("failed to begin target transaction: %w", err)
+			}
+		}
+		if err := c.writeBatch(ctx, dst, t, &batch); err != nil {
+			return fail("failed to write rows: %w", err)
+		}
+		if keyed {
+			err := dst.Commit(ctx)
+			dst = nil
+			if err != nil {
+				return fail("failed to commit: %w", err)
+			}
+		}
+		c.update(idx, func(p *TableProgress) {
+			p.RowsCopied += int64(rows)
+			if keyed {
+				p.Checkpoint = lastKey
+			}
+		})
+		if keyed {
+			save(c.Progress())
+		}
+	}
+	if err := <-readErr; err != nil {
+		return fmt.Errorf("failed to read source rows: %w", err)
+	}
+	if dst != nil {
+		err := dst.Commit(ctx)
+		dst = nil
+		if err != nil {
+			return fmt.Errorf("failed to commit: %w", err)
+		}
+	}
+
+	for _, col := range t.SequenceCols {
+		// setval only when the table has rows; an empty table keeps its sequence as is
+		reset := fmt.Sprintf("SELECT setval(pg_get_serial_sequence($1, $2), m) FROM (SELECT max(%s) AS m FROM %s) s WHERE m IS NOT NULL",
+			quoteIdent(col), quoteIdent(t.Table))
+		if _, err := c.target.Exec(ctx, reset, quoteIdent(t.Table), col); err != nil {
+			return fmt.Errorf("failed to reset sequence of %s: %w", col, err)
+		}
+	}
+	return nil
+}
+
+// writeBatch writes one batch of COPY text rows into t. With Upsert the rows
+// go through a temporary table, so rows whose key is already in the target
+// update it instead of failing the batch.
+func (c *Copier) writeBatch(ctx context.Context, tx pgx.Tx, t TableCopy, rows io.Reader) error {
+	columns := make([]string, len(t.Columns))
+	for n, col := range t.Columns {
+		columns[n] = quoteIdent(col)
+	}
+	columnList := strings.Join(columns, ", ")
+	if !c.opts.Upsert {
+		_, err := tx.Conn().PgConn().CopyFrom(ctx, rows, fmt.Sprintf("COPY %s (%s) FROM STDIN", quoteIdent(t.Table), columnList))
+		return err
+	}
+
+	const staging = "pg_temp.datacopy_staging"
+	if _, err := tx.Exec(ctx, "DROP TABLE IF EXISTS "+staging); err != nil {
+		return err
+	}
+	create := fmt.Sprintf("CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA", staging, columnList, quoteIdent(t.Table))
+	if _, err := tx.Exec(ctx, create); err != nil {
+		return err
+	}
+	if _, err := tx.Conn().PgConn().CopyFrom(ctx, rows, "COPY "+staging+" FROM STDIN"); err != nil {
+		return err
+	}
+
+	conflict := make([]string, len(t.ConflictCols))
+	for n, col := range t.ConflictCols {
+		conflict[n] = quoteIdent(col)
+	}
+	var updates []string
+	for n, col := range t.Columns {
+		if !slices.Contains(t.ConflictCols, col) {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", columns[n], columns[n]))
+		}
+	}
+	action := "DO NOTHING" // Every column is part of the key
+	if updates != nil {
+		action = "DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+	_, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT (%s) %s",
+		quoteIdent(t.Table), columnList, columnList, staging, strings.Join(conflict, ", "), action))
+	return err
+}
+
+// splitKey cuts the n key columns selected after the copied ones off a COPY
+// text row, returning the row, still ending in a newline, and the key
+// values. Tabs inside values are escaped, so every tab separates columns.
+func splitKey(line []byte, n int) ([]byte, []string) {
+	end := len(line) - 1 // Before the newline
+	key := make([]string, n)
+	for i := n - 1; i >= 0; i-- {
+		sep := bytes.LastIndexByte(line[:end], '\t')
+		key[i] = string(line[sep+1 : end])
+		end = sep
+	}
+	return append(line[:end], '\n'), key
+}
+
+// unescapeCopyText turns a COPY text value back into the value itself.
+func unescapeCopyText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		default:
+			b.WriteByte(s[i]) // A backslash, or any other character escaped for no reason
+		}
+	}
+	return b.String()
+}
+
+func quoteIdent(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
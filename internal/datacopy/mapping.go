@@ -0,0 +1,148 @@
+package datacopy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Mapping says how to fill the columns of one target table, by target column
+// name. Columns without a rule are copied from the source column of the same
+// name, when there is one.
+type Mapping map[string]ColumnRule
+
+// ColumnRule fills one target column. Without From, Constant, or Template the
+// value comes from the source column of the same name.
+type ColumnRule struct {
+	From     string   `json:"from,omitempty"`     // Source column, when it was renamed
+	Columns  []string `json:"columns,omitempty"`  // More source columns, for templates that take several
+	Drop     bool     `json:"drop,omitempty"`     // Leave the column out, so it gets its default
+	Constant *string  `json:"constant,omitempty"` // Same value in every row, instead of a source column
+	Template string   `json:"template,omitempty"` // One of Templates, applied to the source column
+	Arg      string   `json:"arg,omitempty"`      // The template's argument, as a string literal
+	Cast     string   `json:"cast,omitempty"`     // Type to cast the value to, e.g. integer
+}
+
+// Template is an expression a column may be filled with. Expression is a
+// format string: %[1]s is the source columns, quoted and comma separated, and
+// %[2]s the argument as a string literal.
+type Template struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Multiple    bool   `json:"multiple"` // Takes more than one column
+	Argument    bool   `json:"argument"` // Takes an argument
+	Expression  string `json:"-"`
+}
+
+// Templates are the only expressions a mapping accepts, so no user-supplied
+// SQL reaches the source database.
+var Templates = []Template{
+	{Name: "lower", Description: "Lowercase: lower(col)", Expression: "lower(%[1]s::text)"},
+	{Name: "upper", Description: "Uppercase: upper(col)", Expression: "upper(%[1]s::text)"},
+	{Name: "trim", Description: "Trim whitespace: btrim(col)", Expression: "btrim(%[1]s::text)"},
+	{Name: "empty-to-null", Description: "Empty or blank strings become NULL", Expression: "NULLIF(btrim(%[1]s::text), '')"},
+	{Name: "coalesce", Description: "NULL becomes the argument: COALESCE(col, arg)", Argument: true, Expression: "COALESCE(%[1]s, %[2]s)"},
+	{Name: "null-if", Description: "The argument becomes NULL: NULLIF(col, arg)", Argument: true, Expression: "NULLIF(%[1]s, %[2]s)"},
+	{Name: "concat", Description: "Join columns, separated by the argument: concat_ws(arg, col, ...)", Multiple: true, Argument: true, Expression: "concat_ws(%[2]s, %[1]s)"},
+	{Name: "epoch-seconds", Description: "Unix seconds to timestamp: to_timestamp(col)", Expression: "to_timestamp(%[1]s)"},
+	{Name: "epoch-millis", Description: "Unix milliseconds to timestamp: to_timestamp(col / 1000.0)", Expression: "to_timestamp(%[1]s / 1000.0)"},
+	{Name: "to-json", Description: "Wrap the value as JSON: to_jsonb(col)", Expression: "to_jsonb(%[1]s)"},
+	{Name: "md5", Description: "Hash the value, e.g. to mask personal data: md5(col)", Expression: "md5(%[1]s::text)"},
+}
+
+func template(name string) (Template, bool) {
+	for _, t := range Templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// check rejects rules for columns the target table doesn't have or that
+// cannot be written.
+func (m Mapping) check(target schema.Table) error {
+	for name := range m {
+		idx := slices.IndexFunc(target.Columns, func(c schema.Column) bool { return c.Name == name })
+		switch {
+		case idx < 0:
+			return fmt.Errorf("mapping for %s.%s: no such column in the target", target.Name, name)
+		case target.Columns[idx].IsGenerated:
+			return fmt.Errorf("mapping for %s.%s: generated columns cannot be written", target.Name, name)
+		}
+	}
+	return nil
+}
+
+// expression returns what to select from source to fill column, or false
+// when the column is left out.
+func (r ColumnRule) expression(source schema.Table, column string) (string, bool, error) {
+	fail := func(format string, args ...any) (string, bool, error) {
+		return "", false, fmt.Errorf("mapping for %s.%s: %s", source.Name, column, fmt.Sprintf(format, args...))
+	}
+	if r.Drop {
+		if r.From != "" || r.Columns != nil || r.Constant != nil || r.Template != "" || r.Cast != "" {
+			return fail("drop cannot be combined with other settings")
+		}
+		return "", false, nil
+	}
+
+	var expr string
+	if r.Constant != nil {
+		if r.From != "" || r.Columns != nil || r.Template != "" {
+			return fail("a constant cannot be combined with source columns or a template")
+		}
+		expr = schema.QuoteLiteral(*r.Constant)
+	} else {
+		from := r.From
+		if from == "" {
+			from = column
+		}
+		hasColumn := func(name string) bool {
+			return slices.ContainsFunc(source.Columns, func(c schema.Column) bool { return c.Name == name })
+		}
+		if !hasColumn(from) {
+			if r.From == "" && r.Template == "" && r.Cast == "" {
+				return "", false, nil // Nothing to copy; the column gets its default
+			}
+			return fail("source has no column %s", from)
+		}
+		expr = quoteIdent(from)
+
+		if r.Template == "" {
+			if r.Columns != nil || r.Arg != "" {
+				return fail("columns and arg need a template")
+			}
+		} else {
+			tmpl, ok := template(r.Template)
+			switch {
+			case !ok:
+				return fail("unknown template %q", r.Template)
+			case r.Columns != nil && !tmpl.Multiple:
+				return fail("template %s takes one column", tmpl.Name)
+			case r.Arg != "" && !tmpl.Argument:
+				return fail("template %s takes no argument", tmpl.Name)
+			}
+			columns := []string{expr}
+			for _, name := range r.Columns {
+				if !hasColumn(name) {
+					return fail("source has no column %s", name)
+				}
+				columns = append(columns, quoteIdent(name))
+			}
+			expr = fmt.Sprintf(tmpl.Expression, strings.Join(columns, ", "), schema.QuoteLiteral(r.Arg))
+		}
+	}
+
+	if r.Cast != "" {
+		// Only built-in types: the cast runs in the source, which may not
+		// have the target's own types
+		if !schema.IsValidType(r.Cast, nil) || r.Cast == "serial" || r.Cast == "bigserial" {
+			return fail("cannot cast to %q", r.Cast)
+		}
+		expr = fmt.Sprintf("(%s)::%s", expr, r.Cast)
+	}
+	return expr, true, nil
+}
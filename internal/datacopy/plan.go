@@ -0,0 +1,144 @@
+package datacopy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// TableCopy is one table of a copy: the target columns filled, in target
+// order, what is selected from the source for each, and the target columns
+// whose sequences are reset afterwards.
+type TableCopy struct {
+	Table         string   `json:"table"`
+	Columns       []string `json:"columns"`
+	Select        []string `json:"-"` // Source expressions, one per column
+	SequenceCols  []string `json:"-"`
+	Key           []string `json:"-"`             // Source primary key; rows are read in its order and checkpointed by it
+	ConflictCols  []string `json:"-"`             // Target primary key, when copied; what Upsert matches rows on
+	EstimatedRows int64    `json:"estimatedRows"` // In the source
+}
+
+// Plan lists the tables to copy from source to target, parents before the
+// tables whose foreign keys reference them, so rows arrive after the rows
+// they point at. With no tables given, every table in both databases is
+// copied. Columns are matched by name unless mappings, by table, say
+// otherwise. Partitions are filled through their parent, and foreign tables
+// are skipped. Tables in a foreign key cycle cannot be ordered; they come
+// last, with a warning.
+func Plan(source, target *schema.Schema, tables []string, mappings map[string]Mapping) ([]TableCopy, []string, error) {
+	warnings := []string{}
+	partitions := make(map[string]bool)
+	for _, t := range target.Tables {
+		if t.Partitioning != nil {
+			for _, part := range t.Partitioning.Partitions {
+				partitions[part.Name] = true
+			}
+		}
+	}
+
+	sourceTables := make(map[string]schema.Table, len(source.Tables))
+	for _, t := range source.Tables {
+		sourceTables[t.Name] = t
+	}
+	targetTables := make(map[string]schema.Table, len(target.Tables))
+	for _, t := range target.Tables {
+		targetTables[t.Name] = t
+	}
+
+	explicit := len(tables) > 0
+	if !explicit {
+		for _, t := range source.Tables {
+			if _, ok := targetTables[t.Name]; ok {
+				tables = append(tables, t.Name)
+			}
+		}
+	}
+
+	copies := make(map[string]TableCopy, len(tables))
+	for _, name := range tables {
+		src, inSource := sourceTables[name]
+		dst, inTarget := targetTables[name]
+		switch {
+		case !inSource || !inTarget:
+			return nil, nil, fmt.Errorf("table %s is not in both databases", name)
+		case src.IsForeign || dst.IsForeign:
+			if explicit {
+				return nil, nil, fmt.Errorf("table %s is a foreign table", name)
+			}
+			continue
+		case partitions[name]:
+			if explicit {
+				return nil, nil, fmt.Errorf("table %s is a partition; copy its parent", name)
+			}
+			continue
+		}
+
+		mapping := mappings[name]
+		if err := mapping.check(dst); err != nil {
+			return nil, nil, err
+		}
+		c := TableCopy{Table: name, EstimatedRows: src.EstimatedRows}
+		for _, col := range dst.Columns {
+			if col.IsGenerated {
+				continue
+			}
+			expr, ok, err := mapping[col.Name].expression(src, col.Name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !ok {
+				continue
+			}
+			c.Columns = append(c.Columns, col.Name)
+			c.Select = append(c.Select, expr)
+			if col.Sequence != "" {
+				c.SequenceCols = append(c.SequenceCols, col.Name)
+			}
+		}
+		if src.PrimaryKey != nil {
+			c.Key = src.PrimaryKey.Columns
+		}
+		if dst.PrimaryKey != nil && !slices.ContainsFunc(dst.PrimaryKey.Columns, func(col string) bool { return !slices.Contains(c.Columns, col) }) {
+			c.ConflictCols = dst.PrimaryKey.Columns
+		}
+		if len(c.Columns) == 0 {
+			warnings = append(warnings, fmt.Sprintf("table %s has no columns in common and is skipped", name))
+			continue
+		}
+		if missing := len(dst.Columns) - len(c.Columns); missing > 0 {
+			warnings = append(warnings, fmt.Sprintf("%d columns of %s are not copied; they get their defaults", missing, name))
+		}
+		copies[name] = c
+	}
+	for name := range mappings {
+		if _, ok := copies[name]; !ok {
+			return nil, nil, fmt.Errorf("mapping for table %s, which is not copied", name)
+		}
+	}
+
+	copyOrder, cyclic := schema.OrderByForeignKeys(targetTables, slices.Collect(maps.Keys(copies)))
+	ordered := make([]TableCopy, len(copyOrder))
+	for idx, name := range copyOrder {
+		ordered[idx] = copies[name]
+	}
+	if len(cyclic) > 0 {
+		warnings = append(warnings, fmt.Sprintf("tables %s are in or depend on a foreign key cycle and are copied last; their foreign keys may reject rows",
+			strings.Join(cyclic, ", ")))
+	}
+	return ordered, warnings, nil
+}
+
+// CheckUpsert reports the first table of plan that cannot be upserted for
+// want of a primary key among its copied target columns.
+func CheckUpsert(plan []TableCopy) error {
+	for _, t := range plan {
+		if t.ConflictCols == nil {
+			return fmt.Errorf("table %s has no primary key among the copied columns, which upsert needs", t.Table)
+		}
+	}
+	return nil
+}
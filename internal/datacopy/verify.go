@@ -0,0 +1,257 @@
+package datacopy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Checksums a verification may compare on top of row counts.
+const (
+	ChecksumNone = ""
+	ChecksumKeys = "keys" // One hash over every primary key
+	ChecksumRows = "rows" // Hashes of a random sample of rows, looked up by key
+)
+
+// DefaultSampleSize is how many rows of each table ChecksumRows compares
+// when VerifyOptions.SampleSize is not set.
+const DefaultSampleSize = 100
+
+// MaxSampleSize bounds VerifyOptions.SampleSize; the sampled keys are sent
+// back to the target in one statement.
+const MaxSampleSize = 10000
+
+// Table statuses in a verification.
+const (
+	CheckPending  = "pending"
+	CheckRunning  = "checking"
+	CheckMatch    = "match"
+	CheckMismatch = "mismatch"
+	CheckFailed   = "failed"
+)
+
+// VerifyOptions control a verification.
+type VerifyOptions struct {
+	Checksum   string `json:"checksum,omitempty"`   // ChecksumKeys, ChecksumRows, or none
+	SampleSize int    `json:"sampleSize,omitempty"` // Rows per table for ChecksumRows; 0 means DefaultSampleSize
+}
+
+// TableCheck is the verification of one table.
+type TableCheck struct {
+	Table      string   `json:"table"`
+	Status     string   `json:"status"`
+	SourceRows int64    `json:"sourceRows"`
+	TargetRows int64    `json:"targetRows"`
+	Checksum   string   `json:"checksum,omitempty"`   // What was compared besides counts
+	Mismatches []string `json:"mismatches,omitempty"` // What differs
+	Note       string   `json:"note,omitempty"`       // Why no checksum was compared
+	Error      string   `json:"error,omitempty"`
+}
+
+// Verifier compares the tables of a plan in source and target after a copy.
+type Verifier struct {
+	source, target *pgxpool.Pool
+	plan           []TableCopy
+	opts           VerifyOptions
+
+	mu     sync.Mutex
+	checks []TableCheck
+}
+
+// NewVerifier prepares a verification of plan, as made by Plan.
+func NewVerifier(source, target *pgxpool.Pool, plan []TableCopy, opts VerifyOptions) *Verifier {
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = DefaultSampleSize
+	}
+	checks := make([]TableCheck, len(plan))
+	for idx, t := range plan {
+		checks[idx] = TableCheck{Table: t.Table, Status: CheckPending}
+	}
+	return &Verifier{source: source, target: target, plan: plan, opts: opts, checks: checks}
+}
+
+// Results returns the check of each table, in plan order.
+func (v *Verifier) Results() []TableCheck {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]TableCheck(nil), v.checks...)
+}
+
+func (v *Verifier) update(idx int, fn func(c *TableCheck)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	fn(&v.checks[idx])
+}
+
+// Run checks the tables in order, calling step before each one and save
+// after. Counts are exact, so each table is scanned once on both sides;
+// rows written to either database since the copy show up as mismatches. A
+// table that cannot be checked is marked failed and the rest still run.
+func (v *Verifier) Run(ctx context.Context, step func(description string), save func(checks []TableCheck)) error {
+	for idx, t := range v.plan {
+		step("Verify " + t.Table)
+		v.update(idx, func(c *TableCheck) { c.Status = CheckRunning })
+		check, err := v.checkTable(ctx, t)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			check.Status, check.Error = CheckFailed, err.Error()
+		}
+		v.update(idx, func(c *TableCheck) { *c = check })
+		save(v.Results())
+	}
+	return nil
+}
+
+func (v *Verifier) checkTable(ctx context.Context, t TableCopy) (TableCheck, error) {
+	check := TableCheck{Table: t.Table, Status: CheckMatch}
+	keys := make([]string, len(t.Key))
+	for n, col := range t.Key {
+		keys[n] = quoteIdent(col)
+	}
+
+	checksum := v.opts.Checksum
+	if checksum != ChecksumNone {
+		switch {
+		case len(t.Key) == 0:
+			check.Note, checksum = "no primary key to compare rows by; only counts were compared", ChecksumNone
+		case !keyCopiedAsIs(t):
+			check.Note, checksum = "the primary key is mapped; only counts were compared", ChecksumNone
+		}
+	}
+	check.Checksum = checksum
+
+	// Keys are checksummed as a sum of their hashes, which needs no sort
+	count := "SELECT count(*), '' FROM " + quoteIdent(t.Table)
+	if checksum == ChecksumKeys {
+		count = fmt.Sprintf("SELECT count(*), coalesce(sum(('x' || left(md5(ROW(%s)::text), 16))::bit(64)::bigint), 0)::text FROM %s",
+			strings.Join(keys, ", "), quoteIdent(t.Table))
+	}
+	var sourceHash, targetHash string
+	if err := v.source.QueryRow(ctx, count).Scan(&check.SourceRows, &sourceHash); err != nil {
+		return check, fmt.Errorf("failed to count source rows: %w", err)
+	}
+	if err := v.target.QueryRow(ctx, count).Scan(&check.TargetRows, &targetHash); err != nil {
+		return check, fmt.Errorf("failed to count target rows: %w", err)
+	}
+	if check.SourceRows != check.TargetRows {
+		check.Mismatches = append(check.Mismatches,
+			fmt.Sprintf("row counts differ: %d in source, %d in target", check.SourceRows, check.TargetRows))
+	}
+	if sourceHash != targetHash {
+		check.Mismatches = append(check.Mismatches, "primary key checksums differ")
+	}
+
+	if checksum == ChecksumRows {
+		differ, err := v.compareSample(ctx, t, keys)
+		if err != nil {
+			return check, err
+		}
+		if len(differ) > 0 {
+			shown := differ[:min(len(differ), 5)]
+			check.Mismatches = append(check.Mismatches, fmt.Sprintf("%d of the sampled rows differ or are missing, e.g. keys %s",
+				len(differ), strings.Join(shown, ", ")))
+		}
+	}
+	if check.Mismatches != nil {
+		check.Status = CheckMismatch
+	}
+	return check, nil
+}
+
+// compareSample hashes a random sample of source rows as they would be
+// copied, mappings applied, and the target rows with the same keys, and
+// returns the keys whose hashes differ or that the target lacks. Values
+// are compared as text, so a column whose type changed may differ in
+// formatting alone.
+func (v *Verifier) compareSample(ctx context.Context, t TableCopy, keys []string) ([]string, error) {
+	columns := make([]string, len(t.Columns))
+	for n, col := range t.Columns {
+		columns[n] = quoteIdent(col)
+	}
+	keyText := make([]string, len(keys))
+	for n, key := range keys {
+		keyText[n] = key + "::text"
+	}
+
+	sample := fmt.Sprintf("SELECT md5(ROW(%s)::text), %s FROM %s ORDER BY random() LIMIT %d",
+		strings.Join(t.Select, ", "), strings.Join(keyText, ", "), quoteIdent(t.Table), v.opts.SampleSize)
+	rows, err := v.source.Query(ctx, sample)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample source rows: %w", err)
+	}
+	want := make(map[string]string) // Row hash by key
+	var tuples []string
+	err = scanHashes(rows, len(keys), func(hash, tuple string) {
+		want[tuple] = hash
+		tuples = append(tuples, tuple)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample source rows: %w", err)
+	}
+	if len(tuples) == 0 {
+		return nil, nil
+	}
+
+	// The keys go back as literals, so the lookup can use the target's key index
+	lookup := fmt.Sprintf("SELECT md5(ROW(%s)::text), %s FROM %s WHERE (%s) IN (%s)",
+		strings.Join(columns, ", "), strings.Join(keyText, ", "), quoteIdent(t.Table), strings.Join(keys, ", "), strings.Join(tuples, ", "))
+	rows, err = v.target.Query(ctx, lookup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up target rows: %w", err)
+	}
+	matched := make(map[string]bool, len(tuples))
+	err = scanHashes(rows, len(keys), func(hash, tuple string) { matched[tuple] = want[tuple] == hash })
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up target rows: %w", err)
+	}
+
+	var differ []string
+	for _, tuple := range tuples {
+		if !matched[tuple] {
+			differ = append(differ, tuple)
+		}
+	}
+	return differ, nil
+}
+
+// scanHashes reads rows of a row hash followed by the key columns as text,
+// passing fn each hash and its key as a tuple of literals.
+func scanHashes(rows pgx.Rows, keys int, fn func(hash, tuple string)) error {
+	defer rows.Close()
+	for rows.Next() {
+		var hash string
+		values := make([]string, keys)
+		dest := []any{&hash}
+		for n := range values {
+			dest = append(dest, &values[n])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		for n, value := range values {
+			values[n] = schema.QuoteLiteral(value)
+		}
+		fn(hash, "("+strings.Join(values, ", ")+")")
+	}
+	return rows.Err()
+}
+
+// keyCopiedAsIs reports whether every source key column is copied unchanged
+// into a target column of the same name, so keys can be compared across.
+func keyCopiedAsIs(t TableCopy) bool {
+	for _, key := range t.Key {
+		idx := slices.Index(t.Columns, key)
+		if idx < 0 || t.Select[idx] != quoteIdent(key) {
+			return false
+		}
+	}
+	return true
+}
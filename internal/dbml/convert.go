@@ -0,0 +1,335 @@
+package dbml
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// typeAliases maps DBML type names, which follow whichever database the
+// designer had in mind, to the names PostgreSQL reports.
+var typeAliases = map[string]string{
+	"int":                         "integer",
+	"int4":                        "integer",
+	"int2":                        "smallint",
+	"int8":                        "bigint",
+	"varchar":                     "character varying",
+	"char":                        "character",
+	"bpchar":                      "character",
+	"bool":                        "boolean",
+	"float":                       "double precision",
+	"float8":                      "double precision",
+	"double":                      "double precision",
+	"float4":                      "real",
+	"decimal":                     "numeric",
+	"timestamp":                   "timestamp without time zone",
+	"datetime":                    "timestamp without time zone",
+	"timestamptz":                 "timestamp with time zone",
+	"time":                        "time without time zone",
+	"timetz":                      "time with time zone",
+	"timestamp with time zone":    "timestamp with time zone",
+	"timestamp without time zone": "timestamp without time zone",
+	"time with time zone":         "time with time zone",
+	"time without time zone":      "time without time zone",
+}
+
+// serialTypes maps the serial pseudo-types to the integer type they create.
+var serialTypes = map[string]string{
+	"serial":      "integer",
+	"serial4":     "integer",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+}
+
+// document resolves what was parsed, which may refer to tables and enums
+// declared further down, into the schema model. Objects are named the way
+// PostgreSQL would name them unnamed, so a diff against a database created
+// from the same design comes out empty.
+func (p *parser) document() (*Document, error) {
+	tables := make([]schema.Table, 0, len(p.order))
+	for _, name := range p.order {
+		t, err := p.table(p.tables[name])
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	if err := p.resolveRefs(tables); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(tables, func(a, b schema.Table) int { return strings.Compare(a.Name, b.Name) })
+
+	s := &schema.Schema{Tables: tables, Sequences: []schema.Sequence{}, ForeignServers: []schema.ForeignServer{}}
+	s.Warnings = schema.AnalyzeSchema(s)
+	s.ForeignKeyCycles = schema.FindForeignKeyCycles(s.Tables)
+	enums := p.enums
+	if enums == nil {
+		enums = []schema.EnumType{}
+	}
+	warnings := p.warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	return &Document{Schema: s, Enums: enums, Warnings: warnings}, nil
+}
+
+func (p *parser) table(def *tableDef) (schema.Table, error) {
+	t := schema.Table{
+		Name:              def.name,
+		Comment:           def.comment,
+		Columns:           make([]schema.Column, 0, len(def.columns)),
+		ForeignKeys:       []schema.ForeignKey{},
+		Indexes:           []schema.Index{},
+		UniqueConstraints: []schema.UniqueConstraint{},
+		CheckConstraints:  def.checks,
+		Exclusions:        []schema.ExclusionConstraint{},
+		Triggers:          []schema.Trigger{},
+	}
+	if t.CheckConstraints == nil {
+		t.CheckConstraints = []schema.CheckConstraint{}
+	}
+
+	var pkColumns []string
+	for idx, c := range def.columns {
+		if slices.ContainsFunc(t.Columns, func(col schema.Column) bool { return col.Name == c.col.Name }) {
+			return t, &SyntaxError{c.line, fmt.Sprintf("column %s.%s is declared twice", def.name, c.col.Name)}
+		}
+		col := c.col
+		col.Position = idx + 1
+		if err := p.resolveType(def.name, c, &col); err != nil {
+			return t, err
+		}
+		if c.pk {
+			pkColumns = append(pkColumns, col.Name)
+		}
+		if c.unique {
+			col.IsUnique = true
+			t.UniqueConstraints = append(t.UniqueConstraints, schema.UniqueConstraint{Name: def.name + "_" + col.Name + "_key", Columns: []string{col.Name}})
+		}
+		if col.Default != nil {
+			d := schema.ParseDefault(*col.Default)
+			col.DefaultValue = &d
+		}
+		t.Columns = append(t.Columns, col)
+	}
+
+	hasColumn := func(name string) bool {
+		return slices.ContainsFunc(t.Columns, func(col schema.Column) bool { return col.Name == name })
+	}
+	for _, idx := range def.indexes {
+		columns := make([]string, len(idx.parts))
+		for n, part := range idx.parts {
+			switch {
+			case part.isExpr:
+				columns[n] = part.text
+			case !hasColumn(part.text):
+				return t, &SyntaxError{idx.line, fmt.Sprintf("index on %s refers to unknown column %s", def.name, part.text)}
+			case schema.ValidIdentifier(part.text):
+				columns[n] = part.text
+			default:
+				columns[n] = `"` + strings.ReplaceAll(part.text, `"`, `""`) + `"`
+			}
+		}
+
+		if idx.pk {
+			if pkColumns != nil {
+				return t, &SyntaxError{idx.line, fmt.Sprintf("table %s has more than one primary key", def.name)}
+			}
+			for _, part := range idx.parts {
+				if part.isExpr {
+					return t, &SyntaxError{idx.line, "a primary key cannot include an expression"}
+				}
+				pkColumns = append(pkColumns, part.text)
+			}
+			continue
+		}
+
+		index := schema.Index{Name: idx.name, Columns: columns, IsUnique: idx.unique, Method: idx.method, Comment: idx.comment}
+		if index.Method == "" {
+			index.Method = "btree"
+		}
+		if index.Name == "" {
+			// PostgreSQL's naming for an unnamed index: table, key columns, idx
+			names := []string{def.name}
+			for _, part := range idx.parts {
+				if part.isExpr {
+					names = append(names, "expr")
+				} else {
+					names = append(names, part.text)
+				}
+			}
+			index.Name = strings.Join(append(names, "idx"), "_")
+		}
+		t.Indexes = append(t.Indexes, index)
+	}
+
+	if pkColumns != nil {
+		t.PrimaryKey = &schema.PrimaryKey{Name: def.name + "_pkey", Columns: pkColumns}
+		for n := range t.Columns {
+			if slices.Contains(pkColumns, t.Columns[n].Name) {
+				t.Columns[n].IsPrimary = true
+				t.Columns[n].IsNullable = false
+			}
+		}
+	}
+	return t, nil
+}
+
+// resolveType sets col's type from the type as written. Enums declared in the
+// document are recognized by name; other names are taken to be types the
+// database knows. serial types and the increment setting give the column the
+// sequence default a serial column has.
+func (p *parser) resolveType(table string, c *columnDef, col *schema.Column) error {
+	typeName, args, dims := c.typeName, c.typeArgs, c.arrayDims
+	for strings.HasSuffix(typeName, "[]") { // Quoted, e.g. "int[]"
+		typeName = strings.TrimSuffix(typeName, "[]")
+		dims++
+	}
+	if open := strings.IndexByte(typeName, '('); open > 0 && args == nil && strings.HasSuffix(typeName, ")") {
+		for _, arg := range strings.Split(typeName[open+1:len(typeName)-1], ",") {
+			args = append(args, strings.TrimSpace(arg))
+		}
+		typeName = strings.TrimSpace(typeName[:open])
+	}
+
+	base, category := typeName, schema.TypeCategoryEnum
+	increment := c.increment
+	if !slices.ContainsFunc(p.enums, func(e schema.EnumType) bool { return e.Name == typeName }) {
+		category = schema.TypeCategoryBase
+		base = strings.Join(strings.Fields(strings.ToLower(typeName)), " ")
+		if alias, ok := typeAliases[base]; ok {
+			base = alias
+		} else if integer, ok := serialTypes[base]; ok {
+			base, increment = integer, true
+		}
+	}
+
+	col.DataType, col.TypeCategory = base, category
+	if dims > 0 {
+		// Array columns report no modifiers
+		col.DataType, col.TypeCategory, col.ElementType = base+strings.Repeat("[]", dims), schema.TypeCategoryArray, base
+	} else {
+		modifiers := make([]int32, len(args))
+		for idx, arg := range args {
+			n, err := strconv.ParseInt(arg, 10, 32)
+			if err != nil {
+				return &SyntaxError{c.line, fmt.Sprintf("invalid type modifier %q for %s.%s", arg, table, col.Name)}
+			}
+			modifiers[idx] = int32(n)
+		}
+		switch {
+		case (base == "character varying" || base == "character") && len(modifiers) == 1:
+			col.CharacterMaximumLength = &modifiers[0]
+		case base == "character" && len(modifiers) == 0:
+			length := int32(1) // char means char(1)
+			col.CharacterMaximumLength = &length
+		case base == "numeric" && (len(modifiers) == 1 || len(modifiers) == 2):
+			scale := int32(0)
+			if len(modifiers) == 2 {
+				scale = modifiers[1]
+			}
+			col.NumericPrecision, col.NumericScale = &modifiers[0], &scale
+		}
+	}
+
+	if increment {
+		if col.TypeCategory != schema.TypeCategoryBase || (base != "integer" && base != "bigint" && base != "smallint") {
+			p.warn("increment on %s.%s is ignored: it is not an integer column", table, col.Name)
+			return nil
+		}
+		col.Sequence = table + "_" + col.Name + "_seq"
+		def := "nextval('" + strings.ReplaceAll(col.Sequence, "'", "''") + "'::regclass)"
+		col.Default, col.IsNullable = &def, false
+	}
+	return nil
+}
+
+// resolveRefs adds a foreign key for each relationship to the table on its
+// many side; one-to-one relationships put it on the left. Many-to-many
+// relationships need a join table and are left out with a warning.
+func (p *parser) resolveRefs(tables []schema.Table) error {
+	byName := make(map[string]*schema.Table, len(tables))
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for _, r := range p.refs {
+		owner, err := p.resolveEndpoint(byName, r.from)
+		if err != nil {
+			return err
+		}
+		other, err := p.resolveEndpoint(byName, r.to)
+		if err != nil {
+			return err
+		}
+		if len(r.from.columns) != len(r.to.columns) {
+			return &SyntaxError{r.line, "both sides of a relationship need the same number of columns"}
+		}
+
+		child, parent := r.from, r.to
+		switch r.op {
+		case "<":
+			child, parent, owner = r.to, r.from, other
+		case "<>":
+			p.warn("many-to-many relationship between %s and %s is left out; add a join table", r.from.table, r.to.table)
+			continue
+		}
+
+		fk := schema.ForeignKey{
+			ConstraintName:    r.name,
+			Columns:           child.columns,
+			ReferencesTable:   p.tableName(parent.table),
+			ReferencesColumns: parent.columns,
+			OnDelete:          "NO ACTION",
+			OnUpdate:          "NO ACTION",
+		}
+		if fk.ConstraintName == "" {
+			fk.ConstraintName = owner.Name + "_" + strings.Join(child.columns, "_") + "_fkey"
+		}
+		for _, action := range []struct {
+			value string
+			field *string
+		}{{r.onDelete, &fk.OnDelete}, {r.onUpdate, &fk.OnUpdate}} {
+			if action.value == "" {
+				continue
+			}
+			upper := strings.ToUpper(action.value)
+			if !slices.Contains([]string{"NO ACTION", "RESTRICT", "CASCADE", "SET NULL", "SET DEFAULT"}, upper) {
+				return &SyntaxError{r.line, fmt.Sprintf("invalid referential action %q", action.value)}
+			}
+			*action.field = upper
+		}
+		if slices.ContainsFunc(owner.ForeignKeys, func(existing schema.ForeignKey) bool { return existing.ConstraintName == fk.ConstraintName }) {
+			continue // Declared both inline and as a Ref
+		}
+		owner.ForeignKeys = append(owner.ForeignKeys, fk)
+	}
+	return nil
+}
+
+func (p *parser) tableName(name string) string {
+	if target, ok := p.aliases[name]; ok {
+		return target
+	}
+	return name
+}
+
+// resolveEndpoint returns the table e refers to, by name or alias, after
+// checking its columns exist.
+func (p *parser) resolveEndpoint(byName map[string]*schema.Table, e endpoint) (*schema.Table, error) {
+	t, ok := byName[p.tableName(e.table)]
+	if !ok {
+		return nil, &SyntaxError{e.line, fmt.Sprintf("relationship refers to unknown table %s", e.table)}
+	}
+	for _, column := range e.columns {
+		if !slices.ContainsFunc(t.Columns, func(c schema.Column) bool { return c.Name == column }) {
+			return nil, &SyntaxError{e.line, fmt.Sprintf("relationship refers to unknown column %s.%s", t.Name, column)}
+		}
+	}
+	return t, nil
+}
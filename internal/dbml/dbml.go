@@ -0,0 +1,814 @@
+// Package dbml reads DBML, the schema language of dbdiagram.io, into the
+// schema model so a design can be diffed against or created in a database.
+package dbml
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Document is a DBML document read into the schema model.
+type Document struct {
+	Schema *schema.Schema
+	Enums  []schema.EnumType // In declaration order
+	// Warnings lists parts of the document that have no equivalent and were left out
+	Warnings []string
+}
+
+// Parse reads a DBML document. Tables, columns and their settings, indexes,
+// checks, enums, and relationships are read; projects, table groups, notes,
+// and records are skipped. Names keep their case. Objects in schemas other
+// than public are read into public, with a warning.
+func Parse(src string) (*Document, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, tables: make(map[string]*tableDef), aliases: make(map[string]string)}
+	if err := p.parseDocument(); err != nil {
+		return nil, err
+	}
+	return p.document()
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+
+	order    []string // Table names in declaration order
+	tables   map[string]*tableDef
+	aliases  map[string]string // Alias -> table name
+	enums    []schema.EnumType
+	refs     []refDef
+	warnings []string
+}
+
+type tableDef struct {
+	name    string
+	comment string
+	columns []*columnDef
+	indexes []indexDef
+	checks  []schema.CheckConstraint
+	line    int
+}
+
+type columnDef struct {
+	col       schema.Column
+	typeName  string   // As written, e.g. varchar or "timestamp with time zone"
+	typeArgs  []string // Modifiers, e.g. 10 and 2 for decimal(10,2)
+	arrayDims int
+	pk        bool
+	unique    bool
+	increment bool
+	line      int
+}
+
+type indexDef struct {
+	parts   []indexPart
+	name    string
+	method  string
+	unique  bool
+	pk      bool
+	comment string
+	line    int
+}
+
+type indexPart struct {
+	text   string
+	isExpr bool
+}
+
+type endpoint struct {
+	table   string
+	columns []string
+	line    int
+}
+
+type refDef struct {
+	name               string
+	from, to           endpoint
+	op                 string // >, <, -, or <>
+	onDelete, onUpdate string
+	line               int
+}
+
+type setting struct {
+	key   string // Lowercase words, e.g. "not null"
+	value []token
+	line  int
+}
+
+func (p *parser) peek() token { return p.peekAt(0) }
+
+func (p *parser) peekAt(n int) token {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) skipNewlines() {
+	for p.peek().kind == tokNewline {
+		p.pos++
+	}
+}
+
+func (p *parser) isPunct(text string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.isPunct(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf("expected %q, found %s", text, describe(p.peek()))
+	}
+	return nil
+}
+
+// isWord reports whether the next token is the keyword word.
+func (p *parser) isWord(word string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && strings.EqualFold(t.text, word)
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{p.peek().line, fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) warn(format string, args ...any) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of document"
+	case tokNewline:
+		return "end of line"
+	case tokString:
+		return "a string"
+	case tokExpr:
+		return "an expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if t.kind != tokIdent && t.kind != tokQuoted {
+		return "", p.errorf("expected a name, found %s", describe(t))
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// qualifiedName reads a name optionally prefixed by a schema and returns the
+// name, warning when the schema is not public.
+func (p *parser) qualifiedName(what string) (string, error) {
+	name, err := p.name()
+	if err != nil {
+		return "", err
+	}
+	if p.accept(".") {
+		schemaName := name
+		if name, err = p.name(); err != nil {
+			return "", err
+		}
+		if schemaName != "public" {
+			p.warn("%s %s is in schema %s; it is read into public", what, name, schemaName)
+		}
+	}
+	return name, nil
+}
+
+func (p *parser) text() (string, error) {
+	t := p.peek()
+	if t.kind != tokString && t.kind != tokQuoted {
+		return "", p.errorf("expected a string, found %s", describe(t))
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// endLine consumes the line break ending an entry; a closing brace may end it too.
+func (p *parser) endLine() error {
+	switch t := p.peek(); {
+	case t.kind == tokNewline:
+		p.pos++
+	case t.kind == tokEOF, t.kind == tokPunct && t.text == "}":
+	default:
+		return p.errorf("unexpected %s", describe(t))
+	}
+	return nil
+}
+
+func (p *parser) parseDocument() error {
+	for {
+		p.skipNewlines()
+		t := p.peek()
+		if t.kind == tokEOF {
+			return nil
+		}
+		if t.kind != tokIdent {
+			return p.errorf("expected Table, Ref, or Enum, found %s", describe(t))
+		}
+		var err error
+		switch strings.ToLower(t.text) {
+		case "table":
+			err = p.parseTable()
+		case "ref":
+			err = p.parseRef()
+		case "enum":
+			err = p.parseEnum()
+		case "project", "tablegroup", "note", "records":
+			err = p.skipBlock()
+		case "tablepartial":
+			p.warn("table partials are not supported (line %d)", t.line)
+			err = p.skipBlock()
+		default:
+			return p.errorf("unknown element %q", t.text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// skipBlock consumes an element up to and including its closing brace.
+func (p *parser) skipBlock() error {
+	start := p.next()
+	for !p.accept("{") {
+		if p.peek().kind == tokEOF {
+			return &SyntaxError{start.line, fmt.Sprintf("%s has no body", start.text)}
+		}
+		p.next()
+	}
+	for depth := 1; depth > 0; {
+		switch t := p.next(); {
+		case t.kind == tokEOF:
+			return &SyntaxError{start.line, fmt.Sprintf("%s is not closed", start.text)}
+		case t.kind == tokPunct && t.text == "{":
+			depth++
+		case t.kind == tokPunct && t.text == "}":
+			depth--
+		}
+	}
+	return nil
+}
+
+// settings reads a bracketed settings list such as [pk, note: 'id'].
+func (p *parser) settings() ([]setting, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var settings []setting
+	for {
+		p.skipNewlines()
+		if p.accept("]") {
+			return settings, nil
+		}
+		s := setting{line: p.peek().line}
+		var words []string
+		for p.peek().kind == tokIdent {
+			words = append(words, strings.ToLower(p.next().text))
+		}
+		if len(words) == 0 {
+			return nil, p.errorf("expected a setting, found %s", describe(p.peek()))
+		}
+		s.key = strings.Join(words, " ")
+		if p.accept(":") {
+			depth := 0
+			for {
+				t := p.peek()
+				if t.kind == tokEOF || t.kind == tokNewline || depth == 0 && t.kind == tokPunct && (t.text == "," || t.text == "]") {
+					break
+				}
+				if t.kind == tokPunct && t.text == "(" {
+					depth++
+				} else if t.kind == tokPunct && t.text == ")" {
+					depth--
+				}
+				s.value = append(s.value, p.next())
+			}
+			if len(s.value) == 0 {
+				return nil, p.errorf("setting %s has no value", s.key)
+			}
+		}
+		settings = append(settings, s)
+		p.skipNewlines()
+		if !p.accept(",") && !p.isPunct("]") {
+			return nil, p.errorf("expected \",\" or \"]\", found %s", describe(p.peek()))
+		}
+	}
+}
+
+// str returns the value of a setting that takes a string.
+func (s setting) str() (string, error) {
+	if len(s.value) != 1 || s.value[0].kind != tokString && s.value[0].kind != tokQuoted {
+		return "", &SyntaxError{s.line, fmt.Sprintf("%s must be a string", s.key)}
+	}
+	return s.value[0].text, nil
+}
+
+// word returns the value of a setting that takes keywords, e.g. set null.
+func (s setting) word() (string, error) {
+	words := make([]string, len(s.value))
+	for idx, t := range s.value {
+		if t.kind != tokIdent {
+			return "", &SyntaxError{s.line, fmt.Sprintf("invalid %s %s", s.key, describe(t))}
+		}
+		words[idx] = strings.ToLower(t.text)
+	}
+	return strings.Join(words, " "), nil
+}
+
+func (p *parser) parseTable() error {
+	line := p.next().line
+	name, err := p.qualifiedName("table")
+	if err != nil {
+		return err
+	}
+	if _, ok := p.tables[name]; ok {
+		return &SyntaxError{line, fmt.Sprintf("table %s is declared twice", name)}
+	}
+	t := &tableDef{name: name, line: line}
+	if p.isWord("as") {
+		p.next()
+		alias, err := p.name()
+		if err != nil {
+			return err
+		}
+		p.aliases[alias] = name
+	}
+	if p.isPunct("[") {
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			if s.key == "note" {
+				if t.comment, err = s.str(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	p.skipNewlines()
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		p.skipNewlines()
+		if p.accept("}") {
+			break
+		}
+		tok, after := p.peek(), p.peekAt(1)
+		keyword := ""
+		if tok.kind == tokIdent && after.kind == tokPunct && (after.text == ":" || after.text == "{") {
+			keyword = strings.ToLower(tok.text)
+		}
+		switch {
+		case tok.kind == tokEOF:
+			return &SyntaxError{line, fmt.Sprintf("table %s is not closed", name)}
+		case keyword == "note":
+			p.next()
+			if t.comment, err = p.note(); err != nil {
+				return err
+			}
+		case keyword == "indexes" && after.text == "{":
+			p.next()
+			err = p.parseIndexes(t)
+		case keyword == "checks" && after.text == "{":
+			p.next()
+			err = p.parseChecks(t)
+		case keyword == "records" && after.text == "{":
+			err = p.skipBlock() // Sample rows
+		case tok.kind == tokPunct && tok.text == "~":
+			p.warn("table partial used in %s is not supported (line %d)", name, tok.line)
+			for p.peek().kind != tokNewline && p.peek().kind != tokEOF && !p.isPunct("}") {
+				p.next()
+			}
+		default:
+			err = p.parseColumn(t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	p.order = append(p.order, name)
+	p.tables[name] = t
+	return nil
+}
+
+// note reads the body of a Note: either ": 'text'" or "{ 'text' }".
+func (p *parser) note() (string, error) {
+	if p.accept(":") {
+		return p.text()
+	}
+	if err := p.expect("{"); err != nil {
+		return "", err
+	}
+	p.skipNewlines()
+	text, err := p.text()
+	if err != nil {
+		return "", err
+	}
+	p.skipNewlines()
+	return text, p.expect("}")
+}
+
+func (p *parser) parseColumn(t *tableDef) error {
+	line := p.peek().line
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	c := &columnDef{col: schema.Column{Name: name, IsNullable: true}, line: line}
+	if c.typeName, err = p.name(); err != nil {
+		return err
+	}
+	if p.accept(".") { // Schema-qualified type, e.g. an enum
+		if c.typeName, err = p.name(); err != nil {
+			return err
+		}
+	}
+	if p.accept("(") {
+		for !p.accept(")") {
+			arg := p.next()
+			if arg.kind != tokNumber && arg.kind != tokIdent {
+				return &SyntaxError{arg.line, fmt.Sprintf("invalid type modifier %s", describe(arg))}
+			}
+			c.typeArgs = append(c.typeArgs, arg.text)
+			p.accept(",")
+		}
+	}
+	for p.isPunct("[") && p.peekAt(1).kind == tokPunct && p.peekAt(1).text == "]" {
+		p.pos += 2
+		c.arrayDims++
+	}
+
+	if p.isPunct("[") {
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			if err := p.columnSetting(t, c, s); err != nil {
+				return err
+			}
+		}
+	}
+	t.columns = append(t.columns, c)
+	return p.endLine()
+}
+
+func (p *parser) columnSetting(t *tableDef, c *columnDef, s setting) error {
+	var err error
+	switch s.key {
+	case "pk", "primary key":
+		c.pk = true
+	case "not null":
+		c.col.IsNullable = false
+	case "null":
+		c.col.IsNullable = true
+	case "unique":
+		c.unique = true
+	case "increment":
+		c.increment = true
+	case "note":
+		c.col.Comment, err = s.str()
+	case "default":
+		var def string
+		if def, err = defaultExpression(s); err == nil && def != "" {
+			c.col.Default = &def
+		}
+	case "check":
+		if len(s.value) != 1 || s.value[0].kind != tokExpr {
+			return &SyntaxError{s.line, "check must be an expression in backticks"}
+		}
+		t.checks = append(t.checks, schema.CheckConstraint{Name: t.name + "_" + c.col.Name + "_check", Expression: s.value[0].text})
+	case "ref":
+		sub := &parser{tokens: append(slices.Clone(s.value), token{kind: tokEOF, line: s.line})}
+		op := sub.next()
+		if op.kind != tokPunct || !slices.Contains([]string{">", "<", "-", "<>"}, op.text) {
+			return &SyntaxError{s.line, "ref must start with >, <, -, or <>"}
+		}
+		to, err := sub.endpoint()
+		if err != nil {
+			return err
+		}
+		if sub.peek().kind != tokEOF {
+			return sub.errorf("unexpected %s in ref", describe(sub.peek()))
+		}
+		p.refs = append(p.refs, refDef{from: endpoint{t.name, []string{c.col.Name}, s.line}, to: to, op: op.text, line: s.line})
+	default:
+		p.warn("setting %q of column %s.%s is not supported", s.key, t.name, c.col.Name)
+	}
+	return err
+}
+
+// defaultExpression converts a default setting to SQL: strings become
+// literals, and numbers, booleans, and backtick expressions are used as is.
+// A null default returns "".
+func defaultExpression(s setting) (string, error) {
+	v := s.value
+	if len(v) == 2 && v[0].kind == tokPunct && v[0].text == "-" && v[1].kind == tokNumber {
+		return "-" + v[1].text, nil
+	}
+	if len(v) == 1 {
+		switch t := v[0]; t.kind {
+		case tokString, tokQuoted:
+			return "'" + strings.ReplaceAll(t.text, "'", "''") + "'", nil
+		case tokNumber, tokExpr:
+			return t.text, nil
+		case tokIdent:
+			switch word := strings.ToLower(t.text); word {
+			case "true", "false":
+				return word, nil
+			case "null":
+				return "", nil
+			}
+		}
+	}
+	return "", &SyntaxError{s.line, "default must be a string, number, boolean, null, or expression in backticks"}
+}
+
+func (p *parser) parseIndexes(t *tableDef) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		p.skipNewlines()
+		if p.accept("}") {
+			return nil
+		}
+		idx := indexDef{line: p.peek().line}
+		if p.accept("(") {
+			for !p.accept(")") {
+				part, err := p.indexPart()
+				if err != nil {
+					return err
+				}
+				idx.parts = append(idx.parts, part)
+				p.accept(",")
+			}
+		} else {
+			part, err := p.indexPart()
+			if err != nil {
+				return err
+			}
+			idx.parts = append(idx.parts, part)
+		}
+		if len(idx.parts) == 0 {
+			return &SyntaxError{idx.line, "index has no columns"}
+		}
+
+		if p.isPunct("[") {
+			settings, err := p.settings()
+			if err != nil {
+				return err
+			}
+			for _, s := range settings {
+				switch s.key {
+				case "pk", "primary key":
+					idx.pk = true
+				case "unique":
+					idx.unique = true
+				case "name":
+					idx.name, err = s.str()
+				case "type":
+					idx.method, err = s.word()
+				case "note":
+					idx.comment, err = s.str()
+				default:
+					p.warn("setting %q of an index on %s is not supported", s.key, t.name)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+		t.indexes = append(t.indexes, idx)
+		if err := p.endLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) indexPart() (indexPart, error) {
+	if t := p.peek(); t.kind == tokExpr {
+		p.next()
+		return indexPart{t.text, true}, nil
+	}
+	name, err := p.name()
+	return indexPart{text: name}, err
+}
+
+func (p *parser) parseChecks(t *tableDef) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		p.skipNewlines()
+		if p.accept("}") {
+			return nil
+		}
+		expr := p.next()
+		if expr.kind != tokExpr {
+			return &SyntaxError{expr.line, "check must be an expression in backticks"}
+		}
+		check := schema.CheckConstraint{Expression: expr.text}
+		if p.isPunct("[") {
+			settings, err := p.settings()
+			if err != nil {
+				return err
+			}
+			for _, s := range settings {
+				if s.key == "name" {
+					if check.Name, err = s.str(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if check.Name == "" {
+			// PostgreSQL's naming: t_check, then t_check1, t_check2, ...
+			check.Name = t.name + "_check"
+			for n := 1; slices.ContainsFunc(t.checks, func(c schema.CheckConstraint) bool { return c.Name == check.Name }); n++ {
+				check.Name = t.name + "_check" + strconv.Itoa(n)
+			}
+		}
+		t.checks = append(t.checks, check)
+		if err := p.endLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseEnum() error {
+	line := p.next().line
+	name, err := p.qualifiedName("enum")
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(p.enums, func(e schema.EnumType) bool { return e.Name == name }) {
+		return &SyntaxError{line, fmt.Sprintf("enum %s is declared twice", name)}
+	}
+	p.skipNewlines()
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	enum := schema.EnumType{Name: name, Labels: []string{}}
+	for {
+		p.skipNewlines()
+		if p.accept("}") {
+			break
+		}
+		label := p.next()
+		if label.kind != tokIdent && label.kind != tokQuoted && label.kind != tokString {
+			return &SyntaxError{label.line, fmt.Sprintf("expected an enum value, found %s", describe(label))}
+		}
+		enum.Labels = append(enum.Labels, label.text)
+		if p.isPunct("[") {
+			if _, err := p.settings(); err != nil { // Only notes, which enums don't keep
+				return err
+			}
+		}
+		if err := p.endLine(); err != nil {
+			return err
+		}
+	}
+	p.enums = append(p.enums, enum)
+	return nil
+}
+
+// parseRef reads "Ref name: a.x > b.y [settings]" or the same in braces.
+func (p *parser) parseRef() error {
+	p.next()
+	name := ""
+	if t := p.peek(); t.kind == tokIdent || t.kind == tokQuoted {
+		name = p.next().text
+	}
+	if p.accept(":") {
+		if err := p.refBody(name); err != nil {
+			return err
+		}
+		return p.endLine()
+	}
+	p.skipNewlines()
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		p.skipNewlines()
+		if p.accept("}") {
+			return nil
+		}
+		if err := p.refBody(name); err != nil {
+			return err
+		}
+		if err := p.endLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) refBody(name string) error {
+	r := refDef{name: name, line: p.peek().line}
+	var err error
+	if r.from, err = p.endpoint(); err != nil {
+		return err
+	}
+	op := p.next()
+	if op.kind != tokPunct || !slices.Contains([]string{">", "<", "-", "<>"}, op.text) {
+		return &SyntaxError{op.line, fmt.Sprintf("expected >, <, -, or <>, found %s", describe(op))}
+	}
+	r.op = op.text
+	if r.to, err = p.endpoint(); err != nil {
+		return err
+	}
+	if p.isPunct("[") {
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			switch s.key {
+			case "delete":
+				r.onDelete, err = s.word()
+			case "update":
+				r.onUpdate, err = s.word()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	p.refs = append(p.refs, r)
+	return nil
+}
+
+// endpoint reads one side of a relationship: table.column, table.(a, b), or
+// either prefixed with a schema.
+func (p *parser) endpoint() (endpoint, error) {
+	e := endpoint{line: p.peek().line}
+	var parts []string
+	name, err := p.name()
+	if err != nil {
+		return e, err
+	}
+	parts = append(parts, name)
+	if err := p.expect("."); err != nil {
+		return e, err
+	}
+	for {
+		if p.accept("(") {
+			for !p.accept(")") {
+				column, err := p.name()
+				if err != nil {
+					return e, err
+				}
+				e.columns = append(e.columns, column)
+				p.accept(",")
+			}
+			break
+		}
+		if name, err = p.name(); err != nil {
+			return e, err
+		}
+		parts = append(parts, name)
+		if !p.accept(".") {
+			break
+		}
+	}
+	if e.columns == nil {
+		e.columns = []string{parts[len(parts)-1]}
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 0 || len(parts) > 2 || len(e.columns) == 0 {
+		return e, &SyntaxError{e.line, "expected table.column"}
+	}
+	e.table = parts[len(parts)-1]
+	return e, nil
+}
@@ -0,0 +1,178 @@
+package dbml
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF     tokenKind = iota
+	tokNewline           // Ends a column, index, or ref line
+	tokIdent             // Bare word: a name, keyword, or color such as #3498db
+	tokString            // 'single' or '''multi-line''' quoted text
+	tokQuoted            // "double" quoted text, a name with spaces or a string
+	tokExpr              // `backtick` SQL expression
+	tokNumber
+	tokPunct // One of { } [ ] ( ) , : . < > - ~ or <>
+)
+
+type token struct {
+	kind tokenKind
+	text string // Unquoted for strings, names, and expressions
+	line int
+}
+
+// SyntaxError reports where a document could not be read.
+type SyntaxError struct {
+	Line    int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// tokenize splits src into tokens, dropping comments and whitespace other
+// than line breaks.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	line := 1
+	for pos := 0; pos < len(runes); {
+		r := runes[pos]
+		switch {
+		case r == '\n':
+			tokens = append(tokens, token{tokNewline, "", line})
+			line++
+			pos++
+		case unicode.IsSpace(r):
+			pos++
+		case r == '/' && pos+1 < len(runes) && runes[pos+1] == '/':
+			for pos < len(runes) && runes[pos] != '\n' {
+				pos++
+			}
+		case r == '/' && pos+1 < len(runes) && runes[pos+1] == '*':
+			start := line
+			end := strings.Index(string(runes[pos+2:]), "*/")
+			if end < 0 {
+				return nil, &SyntaxError{start, "unterminated comment"}
+			}
+			comment := []rune(string(runes[pos+2:])[:end])
+			line += strings.Count(string(comment), "\n")
+			pos += 2 + len(comment) + 2
+		case r == '\'' && strings.HasPrefix(string(runes[pos:]), "'''"):
+			end := strings.Index(string(runes[pos+3:]), "'''")
+			if end < 0 {
+				return nil, &SyntaxError{line, "unterminated multi-line string"}
+			}
+			text := []rune(string(runes[pos+3:])[:end])
+			tokens = append(tokens, token{tokString, trimIndent(string(text)), line})
+			line += strings.Count(string(text), "\n")
+			pos += 3 + len(text) + 3
+		case r == '\'' || r == '"' || r == '`':
+			text, next, err := readQuoted(runes, pos, line)
+			if err != nil {
+				return nil, err
+			}
+			kind := tokString
+			if r == '"' {
+				kind = tokQuoted
+			} else if r == '`' {
+				kind = tokExpr
+			}
+			tokens = append(tokens, token{kind, text, line})
+			line += strings.Count(string(runes[pos:next]), "\n")
+			pos = next
+		case r >= '0' && r <= '9':
+			start := pos
+			for pos < len(runes) && (runes[pos] >= '0' && runes[pos] <= '9' || runes[pos] == '.') {
+				pos++
+			}
+			kind := tokNumber
+			for pos < len(runes) && isWordRune(runes[pos]) { // A name such as 2fa_codes
+				kind = tokIdent
+				pos++
+			}
+			tokens = append(tokens, token{kind, string(runes[start:pos]), line})
+		case isWordRune(r) || r == '#':
+			start := pos
+			pos++
+			for pos < len(runes) && isWordRune(runes[pos]) {
+				pos++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[start:pos]), line})
+		case r == '<' && pos+1 < len(runes) && runes[pos+1] == '>':
+			tokens = append(tokens, token{tokPunct, "<>", line})
+			pos += 2
+		case strings.ContainsRune("{}[](),:.<>-~", r):
+			tokens = append(tokens, token{tokPunct, string(r), line})
+			pos++
+		default:
+			return nil, &SyntaxError{line, fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(tokens, token{tokEOF, "", line}), nil
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// readQuoted reads the quoted text starting at runes[pos], handling
+// backslash escapes, and returns it with the position after the closing quote.
+func readQuoted(runes []rune, pos, line int) (string, int, error) {
+	quote := runes[pos]
+	var b strings.Builder
+	for i := pos + 1; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == quote:
+			return b.String(), i + 1, nil
+		case r == '\\' && i+1 < len(runes):
+			i++
+			switch runes[i] {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			default:
+				b.WriteRune(runes[i])
+			}
+		case r == '\n' && quote != '`':
+			return "", 0, &SyntaxError{line, "unterminated string"}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", 0, &SyntaxError{line, "unterminated string"}
+}
+
+// trimIndent removes the blank first and last lines of a multi-line string
+// and the indentation its lines share, as dbdiagram.io displays it.
+func trimIndent(s string) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > 1 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for idx, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			lines[idx] = l[indent:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Drift severities, most severe first.
+const (
+	SeverityError   = "error"   // Data or integrity guarantees were lost
+	SeverityWarning = "warning" // Behaviour changed
+	SeverityInfo    = "info"    // Something was added
+)
+
+// SeverityRank orders severities, most severe highest; unknown ones rank 0.
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// DriftItem is one way the live schema departs from a baseline.
+type DriftItem struct {
+	Severity string `json:"severity"`
+	Kind     string `json:"kind"` // table, column, or a constraint or index kind
+	Table    string `json:"table"`
+	Name     string `json:"name,omitempty"` // Column, constraint, or index; empty for tables
+	Status   string `json:"status"`         // added, removed, or modified
+	Message  string `json:"message"`
+}
+
+// Drift lists how live differs from baseline, most severe first. Removed
+// tables, columns, and constraints and changed column types are errors;
+// other removals and changes are warnings, and additions are informational.
+func Drift(baseline, live *schema.Schema) []DriftItem {
+	items := []DriftItem{}
+	for _, td := range Compare(baseline, live).Tables {
+		switch td.Status {
+		case StatusAdded:
+			items = append(items, DriftItem{SeverityInfo, "table", td.Name, "", td.Status, "Table was added"})
+			continue
+		case StatusRemoved:
+			items = append(items, DriftItem{SeverityError, "table", td.Name, "", td.Status, "Table was dropped"})
+			continue
+		}
+
+		for _, c := range td.Columns {
+			item := DriftItem{Kind: "column", Table: td.Name, Name: c.Name, Status: c.Status}
+			switch c.Status {
+			case StatusAdded:
+				item.Severity, item.Message = SeverityInfo, "Column was added"
+			case StatusRemoved:
+				item.Severity, item.Message = SeverityError, "Column was dropped"
+			default:
+				item.Severity = SeverityWarning
+				if slices.Contains(c.Changed, "dataType") {
+					item.Severity = SeverityError
+				}
+				item.Message = "Column changed: " + strings.Join(c.Changed, ", ")
+			}
+			items = append(items, item)
+		}
+
+		for _, c := range slices.Concat(td.Constraints, td.Indexes) {
+			item := DriftItem{Kind: c.Kind, Table: td.Name, Name: c.Name, Status: c.Status}
+			noun := "Constraint"
+			if c.Kind == KindIndex {
+				noun = "Index"
+			}
+			switch c.Status {
+			case StatusAdded:
+				item.Severity, item.Message = SeverityInfo, noun+" was added"
+			case StatusRemoved:
+				item.Severity, item.Message = SeverityWarning, noun+" was dropped"
+				if c.Kind != KindIndex {
+					item.Severity = SeverityError
+				}
+			default:
+				item.Severity = SeverityWarning
+				item.Message = fmt.Sprintf("%s changed from %s to %s", noun, c.Before, c.After)
+			}
+			items = append(items, item)
+		}
+	}
+
+	slices.SortStableFunc(items, func(a, b DriftItem) int { return SeverityRank(b.Severity) - SeverityRank(a.Severity) })
+	return items
+}
@@ -0,0 +1,178 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Migration output formats besides the default JSON of raw SQL scripts.
+const (
+	FormatGoose = "goose" // One file with -- +goose Up and Down sections
+	FormatAtlas = "atlas" // Atlas HCL describing the target schema
+)
+
+// Goose renders the migration as a goose SQL file. Goose runs each section
+// in a transaction of its own, so unlike SQL there is no BEGIN or COMMIT.
+func (m Migration) Goose() string {
+	var b strings.Builder
+	for _, section := range []struct {
+		name   string
+		script MigrationScript
+	}{{"Up", m.Up}, {"Down", m.Down}} {
+		if section.name == "Down" {
+			b.WriteString("\n")
+		}
+		b.WriteString("-- +goose " + section.name + "\n")
+		for _, warning := range section.script.Warnings {
+			b.WriteString("-- WARNING: " + warning + "\n")
+		}
+		for _, stmt := range section.script.Statements {
+			// Goose splits on semicolons ending a line; guard statements that hold their own
+			if strings.ContainsAny(stmt, ";\n") {
+				b.WriteString("-- +goose StatementBegin\n" + stmt + ";\n-- +goose StatementEnd\n")
+			} else {
+				b.WriteString(stmt + ";\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// AtlasHCL renders s as an Atlas schema file, the desired state Atlas diffs a
+// database against. Foreign tables and exclusion constraints have no HCL form
+// and are left out with a comment.
+func AtlasHCL(s *schema.Schema) string {
+	var b strings.Builder
+	b.WriteString("schema \"public\" {\n}\n")
+	for _, t := range s.Tables {
+		b.WriteString("\n")
+		if t.IsForeign {
+			fmt.Fprintf(&b, "# foreign table %s is not exported\n", t.Name)
+			continue
+		}
+		writeAtlasTable(&b, t)
+	}
+	return b.String()
+}
+
+func writeAtlasTable(b *strings.Builder, t schema.Table) {
+	fmt.Fprintf(b, "table %s {\n", hclString(t.Name))
+	b.WriteString("  schema = schema.public\n")
+	if t.Comment != "" {
+		b.WriteString("  comment = " + hclString(t.Comment) + "\n")
+	}
+
+	for _, col := range t.Columns {
+		fmt.Fprintf(b, "  column %s {\n", hclString(col.Name))
+		fmt.Fprintf(b, "    null = %t\n", col.IsNullable)
+		if serial := serialType(col); serial != "" {
+			b.WriteString("    type = " + serial + "\n")
+		} else {
+			b.WriteString("    type = " + atlasType(col) + "\n")
+			if col.Default != nil && !col.IsGenerated {
+				b.WriteString("    default = sql(" + hclString(*col.Default) + ")\n")
+			}
+		}
+		if col.Identity != "" {
+			b.WriteString("    identity {\n      generated = " + strings.ReplaceAll(col.Identity, " ", "_") + "\n    }\n")
+		}
+		if col.IsGenerated && col.GenerationExpression != nil {
+			b.WriteString("    as {\n      expr = " + hclString(*col.GenerationExpression) + "\n      type = STORED\n    }\n")
+		}
+		if col.Comment != "" {
+			b.WriteString("    comment = " + hclString(col.Comment) + "\n")
+		}
+		b.WriteString("  }\n")
+	}
+
+	if pk := t.PrimaryKey; pk != nil {
+		b.WriteString("  primary_key {\n    columns = " + atlasColumnRefs("column.", pk.Columns) + "\n  }\n")
+	}
+	for _, fk := range t.ForeignKeys {
+		fmt.Fprintf(b, "  foreign_key %s {\n", hclString(fk.ConstraintName))
+		b.WriteString("    columns     = " + atlasColumnRefs("column.", fk.Columns) + "\n")
+		b.WriteString("    ref_columns = " + atlasColumnRefs("table."+fk.ReferencesTable+".column.", fk.ReferencesColumns) + "\n")
+		b.WriteString("    on_update   = " + strings.ReplaceAll(fk.OnUpdate, " ", "_") + "\n")
+		b.WriteString("    on_delete   = " + strings.ReplaceAll(fk.OnDelete, " ", "_") + "\n")
+		b.WriteString("  }\n")
+	}
+	for _, u := range t.UniqueConstraints {
+		fmt.Fprintf(b, "  unique %s {\n    columns = %s\n  }\n", hclString(u.Name), atlasColumnRefs("column.", u.Columns))
+	}
+	for _, idx := range t.Indexes {
+		if idx.IsPrimary || slices.ContainsFunc(t.UniqueConstraints, func(u schema.UniqueConstraint) bool { return u.Name == idx.Name }) ||
+			slices.ContainsFunc(t.Exclusions, func(ex schema.ExclusionConstraint) bool { return ex.Name == idx.Name }) {
+			continue
+		}
+		writeAtlasIndex(b, t, idx)
+	}
+	for _, c := range t.CheckConstraints {
+		fmt.Fprintf(b, "  check %s {\n    expr = %s\n  }\n", hclString(c.Name), hclString(c.Expression))
+	}
+	for _, ex := range t.Exclusions {
+		fmt.Fprintf(b, "  # exclusion constraint %s is not exported: %s\n", ex.Name, ex.Definition)
+	}
+	if p := t.Partitioning; p != nil && len(p.Columns) > 0 {
+		b.WriteString("  partition {\n    type    = " + p.Strategy + "\n    columns = " + atlasColumnRefs("column.", p.Columns) + "\n  }\n")
+	}
+	b.WriteString("}\n")
+}
+
+// writeAtlasIndex renders an index. Key parts that are not plain column names
+// are expressions, written as on { expr = ... } blocks.
+func writeAtlasIndex(b *strings.Builder, t schema.Table, idx schema.Index) {
+	fmt.Fprintf(b, "  index %s {\n", hclString(idx.Name))
+	if idx.IsUnique {
+		b.WriteString("    unique = true\n")
+	}
+	if idx.Method != "" && idx.Method != "btree" {
+		b.WriteString("    type = " + strings.ToUpper(idx.Method) + "\n")
+	}
+	isColumn := func(part string) bool {
+		return slices.ContainsFunc(t.Columns, func(c schema.Column) bool { return c.Name == part })
+	}
+	if !slices.ContainsFunc(idx.Columns, func(part string) bool { return !isColumn(part) }) {
+		b.WriteString("    columns = " + atlasColumnRefs("column.", idx.Columns) + "\n")
+	} else {
+		for _, part := range idx.Columns {
+			if isColumn(part) {
+				b.WriteString("    on {\n      column = column." + part + "\n    }\n")
+			} else {
+				b.WriteString("    on {\n      expr = " + hclString(part) + "\n    }\n")
+			}
+		}
+	}
+	if idx.Predicate != nil {
+		b.WriteString("    where = " + hclString(*idx.Predicate) + "\n")
+	}
+	b.WriteString("  }\n")
+}
+
+// atlasType writes built-in single-word types such as integer or text bare,
+// and anything else, e.g. character varying(255), integer[], or an enum,
+// through sql().
+func atlasType(col schema.Column) string {
+	typ := columnType(col)
+	if schema.ValidIdentifier(typ) && col.Domain == "" && col.TypeCategory == schema.TypeCategoryBase {
+		return typ
+	}
+	return "sql(" + hclString(typ) + ")"
+}
+
+func atlasColumnRefs(prefix string, columns []string) string {
+	refs := make([]string, len(columns))
+	for idx, c := range columns {
+		refs[idx] = prefix + c
+	}
+	return "[" + strings.Join(refs, ", ") + "]"
+}
+
+// hclString quotes s as an HCL string literal. Template sequences are escaped
+// so the text is taken literally.
+func hclString(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "${", "$${", "%{", "%%{").Replace(s)
+	return `"` + s + `"`
+}
@@ -0,0 +1,295 @@
+package diff
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// MigrationFiles names a migration written in golang-migrate's layout: a
+// numbered pair NNN_description.up.sql and NNN_description.down.sql.
+type MigrationFiles struct {
+	Version int    `json:"version"`
+	Up      string `json:"up"`   // File name
+	Down    string `json:"down"` // File name; empty for a squash baseline, which has none
+}
+
+// maxDescriptionLength bounds the description part of a file name.
+const maxDescriptionLength = 50
+
+// migrationFilePattern matches golang-migrate file names and captures the
+// version and description.
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.*)\.(up|down)\.sql$`)
+
+// NewMigrationFiles names the files for version. The description is reduced
+// to lowercase letters, digits, and underscores, and defaults to "migration".
+func NewMigrationFiles(version int, description string) MigrationFiles {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(description) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	slug := b.String()
+	if len(slug) > maxDescriptionLength {
+		slug = slug[:maxDescriptionLength]
+	}
+	slug = strings.Trim(slug, "_")
+	if slug == "" {
+		slug = "migration"
+	}
+
+	prefix := fmt.Sprintf("%03d_%s", version, slug)
+	return MigrationFiles{Version: version, Up: prefix + ".up.sql", Down: prefix + ".down.sql"}
+}
+
+// NextMigrationVersion returns one more than the highest version among the
+// golang-migrate files in dir, or 1 when there are none or dir does not exist.
+func NextMigrationVersion(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+	highest := 0
+	for _, e := range entries {
+		m := migrationFilePattern.FindStringSubmatch(e.Name())
+		if m == nil || e.IsDir() {
+			continue
+		}
+		if version, err := strconv.Atoi(m[1]); err == nil && version > highest {
+			highest = version
+		}
+	}
+	return highest + 1, nil
+}
+
+// WriteMigrationFiles writes m to dir as the next numbered migration, creating
+// dir if needed. Existing files are never overwritten.
+func WriteMigrationFiles(dir, description string, m Migration) (MigrationFiles, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return MigrationFiles{}, fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+	version, err := NextMigrationVersion(dir)
+	if err != nil {
+		return MigrationFiles{}, err
+	}
+	files := NewMigrationFiles(version, description)
+	if err := writeMigrationPair(dir, files, m); err != nil {
+		return MigrationFiles{}, err
+	}
+	return files, nil
+}
+
+// writeMigrationPair writes the up and down files of m, or neither.
+func writeMigrationPair(dir string, files MigrationFiles, m Migration) error {
+	if err := writeNewFile(filepath.Join(dir, files.Up), m.Up.SQL()); err != nil {
+		return err
+	}
+	if err := writeNewFile(filepath.Join(dir, files.Down), m.Down.SQL()); err != nil {
+		os.Remove(filepath.Join(dir, files.Up)) // Don't leave half a pair behind
+		return err
+	}
+	return nil
+}
+
+// BaselineMigration generates the migration that builds current from an
+// empty database, to stand in for a squashed history. GenerateMigration
+// covers tables alone, so the up script warns about everything else.
+func BaselineMigration(current *schema.Schema) Migration {
+	m := GenerateMigration(&schema.Schema{}, current)
+	m.Up.Warnings = append(m.Up.Warnings,
+		"this baseline holds tables, columns, constraints, and indexes only; add any views, functions, types, and extensions the squashed migrations created")
+	return m
+}
+
+// squashedDir is the subdirectory of a migrations directory that squashed
+// migration files are moved to, one directory per squash.
+const squashedDir = "squashed"
+
+// SquashMigrationFiles moves the files of sources out of dir, into a new
+// squashed/<timestamp> directory that LoadMigrations does not read, and
+// writes the up script of baseline in their place as version. No down file
+// is written, since reversing a baseline drops every table. It returns the
+// baseline's files and the archive directory, relative to dir. On failure
+// every file is put back.
+func SquashMigrationFiles(dir string, sources []MigrationSource, version int, baseline MigrationScript) (MigrationFiles, string, error) {
+	archive := filepath.Join(squashedDir, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.MkdirAll(filepath.Join(dir, archive), 0o755); err != nil {
+		return MigrationFiles{}, "", fmt.Errorf("failed to create %s: %w", archive, err)
+	}
+
+	var moved []string
+	for _, src := range sources {
+		for _, name := range []string{src.File, src.DownFile} {
+			if name == "" {
+				continue
+			}
+			if err := os.Rename(filepath.Join(dir, name), filepath.Join(dir, archive, name)); err != nil {
+				restoreMigrationFiles(dir, archive, moved)
+				return MigrationFiles{}, "", fmt.Errorf("failed to move %s: %w", name, err)
+			}
+			moved = append(moved, name)
+		}
+	}
+
+	files := NewMigrationFiles(version, "baseline")
+	files.Down = ""
+	if err := writeNewFile(filepath.Join(dir, files.Up), baseline.SQL()); err != nil {
+		restoreMigrationFiles(dir, archive, moved)
+		return MigrationFiles{}, "", err
+	}
+	return files, archive, nil
+}
+
+// UndoSquashMigrationFiles reverses SquashMigrationFiles, removing the
+// baseline files and moving the archived files back.
+func UndoSquashMigrationFiles(dir string, files MigrationFiles, archive string) error {
+	for _, name := range []string{files.Up, files.Down} {
+		if name == "" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, archive))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", archive, err)
+	}
+	names := make([]string, len(entries))
+	for idx, e := range entries {
+		names[idx] = e.Name()
+	}
+	return restoreMigrationFiles(dir, archive, names)
+}
+
+// restoreMigrationFiles moves names back from archive into dir and removes
+// archive once it is empty.
+func restoreMigrationFiles(dir, archive string, names []string) error {
+	var errs []error
+	for _, name := range names {
+		if err := os.Rename(filepath.Join(dir, archive, name), filepath.Join(dir, name)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", name, err))
+		}
+	}
+	os.Remove(filepath.Join(dir, archive)) // Only succeeds when empty
+	return errors.Join(errs...)
+}
+
+// writeNewFile creates path with content, failing if it already exists.
+func writeNewFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Base(path), err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return f.Close()
+}
+
+// WriteMigrationZip writes m as a zip archive holding the pair of files.
+func WriteMigrationZip(w io.Writer, files MigrationFiles, m Migration) error {
+	zw := zip.NewWriter(w)
+	for _, f := range []struct{ name, content string }{
+		{files.Up, m.Up.SQL()},
+		{files.Down, m.Down.SQL()},
+	} {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", f.name, err)
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// MigrationSource is a migration in a migrations directory: its up file and,
+// if present, its down file.
+type MigrationSource struct {
+	Version      int    `json:"version"`
+	Name         string `json:"name"` // The description part of the file name
+	File         string `json:"file"`
+	DownFile     string `json:"downFile,omitempty"`
+	Checksum     string `json:"checksum"` // See schema.Checksum
+	DownChecksum string `json:"downChecksum,omitempty"`
+	Up           string `json:"-"`
+	Down         string `json:"-"`
+}
+
+// LoadMigrations reads the migrations in dir, in version order. A missing dir
+// holds no migrations; two up files with one version are an error, and a down
+// file without an up file is ignored.
+func LoadMigrations(dir string) ([]MigrationSource, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []MigrationSource{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	sources := []MigrationSource{}
+	downs := make(map[string]string) // Up file name -> down file content
+	for _, e := range entries {
+		m := migrationFilePattern.FindStringSubmatch(e.Name())
+		if m == nil || e.IsDir() {
+			continue
+		}
+		version, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", e.Name(), err)
+		}
+		if m[3] == "down" {
+			downs[strings.TrimSuffix(e.Name(), ".down.sql")+".up.sql"] = string(content)
+			continue
+		}
+		sources = append(sources, MigrationSource{
+			Version:  version,
+			Name:     m[2],
+			File:     e.Name(),
+			Checksum: schema.Checksum(string(content)),
+			Up:       string(content),
+		})
+	}
+
+	for idx := range sources {
+		if down, ok := downs[sources[idx].File]; ok {
+			sources[idx].DownFile = strings.TrimSuffix(sources[idx].File, ".up.sql") + ".down.sql"
+			sources[idx].DownChecksum = schema.Checksum(down)
+			sources[idx].Down = down
+		}
+	}
+	slices.SortFunc(sources, func(a, b MigrationSource) int { return a.Version - b.Version })
+	for idx := 1; idx < len(sources); idx++ {
+		if sources[idx].Version == sources[idx-1].Version {
+			return nil, fmt.Errorf("%s and %s share version %d", sources[idx-1].File, sources[idx].File, sources[idx].Version)
+		}
+	}
+	return sources, nil
+}
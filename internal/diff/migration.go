@@ -0,0 +1,435 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// MigrationScript is one direction of a generated migration.
+type MigrationScript struct {
+	Statements []string `json:"statements"`
+	Warnings   []string `json:"warnings"` // Data the script discards, and changes it leaves out
+}
+
+// SQL renders the script as one transaction, warnings first as comments.
+func (s MigrationScript) SQL() string {
+	var b strings.Builder
+	for _, warning := range s.Warnings {
+		b.WriteString("-- WARNING: " + warning + "\n")
+	}
+	if len(s.Statements) == 0 {
+		return b.String()
+	}
+	b.WriteString("BEGIN;\n")
+	for _, stmt := range s.Statements {
+		b.WriteString(stmt + ";\n")
+	}
+	b.WriteString("COMMIT;\n")
+	return b.String()
+}
+
+// Migration is a pair of scripts generated from a schema diff: Up turns the
+// before schema into the after schema and Down turns it back. It is generated
+// for review and never run by the server.
+type Migration struct {
+	Up   MigrationScript `json:"up"`
+	Down MigrationScript `json:"down"`
+}
+
+// GenerateMigration builds the DDL that turns before into after, and the DDL
+// that reverses it. Down can restore structure but not data: a table or
+// column Up drops comes back empty, as Up's warnings note.
+func GenerateMigration(before, after *schema.Schema) Migration {
+	return Migration{
+		Up:   migrationScript(before, after),
+		Down: migrationScript(after, before),
+	}
+}
+
+// migrationPhases collects statements by the order they must run in: foreign
+// keys and other dependents are dropped before what they depend on, and
+// created after it.
+type migrationPhases struct {
+	dropForeignKeys []string
+	dropIndexes     []string
+	dropConstraints []string
+	dropColumns     []string
+	dropPartitions  []string
+	dropTables      []string // Names; dropped together in one statement
+	createTables    []string // Partitions follow their parent
+	addColumns      []string
+	alterColumns    []string
+	addConstraints  []string
+	createIndexes   []string
+	addForeignKeys  []string
+	warnings        []string
+	skippedTables   map[string]bool
+}
+
+// migrationScript orders the statements for every difference Compare reports.
+// Constraints are matched by name, so a renamed constraint is dropped and added.
+func migrationScript(before, after *schema.Schema) MigrationScript {
+	beforeTables := tablesByName(before)
+	afterTables := tablesByName(after)
+
+	p := &migrationPhases{skippedTables: make(map[string]bool)}
+
+	for _, td := range Compare(before, after).Tables {
+		b, a := beforeTables[td.Name], afterTables[td.Name]
+		switch td.Status {
+		case StatusAdded:
+			p.createTable(a)
+		case StatusRemoved:
+			p.dropTable(b)
+		default:
+			if b.IsForeign || a.IsForeign {
+				p.skip(td.Name, "foreign table %s differs; change it by hand", td.Name)
+				continue
+			}
+			for _, col := range td.Columns {
+				p.changeColumn(td.Name, col)
+			}
+		}
+		if p.skippedTables[td.Name] {
+			continue
+		}
+		for _, c := range td.Constraints {
+			p.changeConstraint(td, c, a)
+		}
+		for _, idx := range td.Indexes {
+			p.changeIndex(td, idx, a)
+		}
+	}
+
+	// Partitions are not tables of the schema, so Compare leaves them out
+	names := make([]string, 0, len(afterTables))
+	for name := range afterTables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		b, existed := beforeTables[name]
+		if existed {
+			p.changePartitions(&b, afterTables[name])
+		} else {
+			p.changePartitions(nil, afterTables[name])
+		}
+	}
+
+	statements := make([]string, 0)
+	statements = append(statements, p.dropForeignKeys...)
+	statements = append(statements, p.dropIndexes...)
+	statements = append(statements, p.dropConstraints...)
+	statements = append(statements, p.dropColumns...)
+	statements = append(statements, p.dropPartitions...)
+	if len(p.dropTables) > 0 {
+		quoted := make([]string, len(p.dropTables))
+		for idx, name := range p.dropTables {
+			quoted[idx] = quoteIdent(name)
+		}
+		statements = append(statements, "DROP TABLE "+strings.Join(quoted, ", "))
+	}
+	statements = append(statements, p.createTables...)
+	statements = append(statements, p.addColumns...)
+	statements = append(statements, p.alterColumns...)
+	statements = append(statements, p.addConstraints...)
+	statements = append(statements, p.createIndexes...)
+	statements = append(statements, p.addForeignKeys...)
+
+	warnings := p.warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	return MigrationScript{Statements: statements, Warnings: warnings}
+}
+
+func (p *migrationPhases) warn(format string, args ...any) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+// skip leaves a table out of the script, with a warning saying why.
+func (p *migrationPhases) skip(table, format string, args ...any) {
+	p.skippedTables[table] = true
+	p.warn(format, args...)
+}
+
+func (p *migrationPhases) createTable(t schema.Table) {
+	if t.IsForeign {
+		p.skip(t.Name, "foreign table %s is not created; create it by hand", t.Name)
+		return
+	}
+	defs := make([]string, len(t.Columns))
+	for idx, col := range t.Columns {
+		defs[idx] = columnDefinition(col)
+	}
+	query := fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(t.Name), strings.Join(defs, ", "))
+	if t.Partitioning != nil {
+		query += " PARTITION BY " + t.Partitioning.Key
+	}
+	p.createTables = append(p.createTables, query)
+}
+
+// changePartitions creates the partitions of a partitioned table that b, its
+// before state or nil for a new table, lacks, and detaches and drops those a
+// no longer has. A changed bound or partition key is left to be changed by
+// hand, since either means moving rows.
+func (p *migrationPhases) changePartitions(b *schema.Table, a schema.Table) {
+	if a.IsForeign || p.skippedTables[a.Name] {
+		return
+	}
+	var before []schema.Partition
+	if b != nil {
+		if (b.Partitioning == nil) != (a.Partitioning == nil) || b.Partitioning != nil && b.Partitioning.Key != a.Partitioning.Key {
+			p.warn("partitioning of %s differs; change it by hand", a.Name)
+			return
+		}
+		if b.Partitioning != nil {
+			before = b.Partitioning.Partitions
+		}
+	}
+	if a.Partitioning == nil {
+		return
+	}
+
+	parent := quoteIdent(a.Name)
+	for _, part := range a.Partitioning.Partitions {
+		idx := slices.IndexFunc(before, func(bp schema.Partition) bool { return bp.Name == part.Name })
+		switch {
+		case idx < 0:
+			p.createTables = append(p.createTables, fmt.Sprintf("CREATE TABLE %s PARTITION OF %s %s", quoteIdent(part.Name), parent, part.Bound))
+		case before[idx].Bound != part.Bound:
+			p.warn("bound of partition %s of %s differs; change it by hand", part.Name, a.Name)
+		}
+	}
+	for _, part := range before {
+		if slices.ContainsFunc(a.Partitioning.Partitions, func(ap schema.Partition) bool { return ap.Name == part.Name }) {
+			continue
+		}
+		p.dropPartitions = append(p.dropPartitions,
+			fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", parent, quoteIdent(part.Name)),
+			"DROP TABLE "+quoteIdent(part.Name))
+		p.warn("drops partition %s of %s and its rows", part.Name, a.Name)
+	}
+}
+
+func (p *migrationPhases) dropTable(t schema.Table) {
+	if t.IsForeign {
+		p.skip(t.Name, "foreign table %s is not dropped; drop it by hand", t.Name)
+		return
+	}
+	p.dropTables = append(p.dropTables, t.Name)
+	p.warn("drops table %s and its rows", t.Name)
+	p.skippedTables[t.Name] = true // Its constraints and indexes go with it
+}
+
+func (p *migrationPhases) changeColumn(table string, c ColumnChange) {
+	alter := "ALTER TABLE " + quoteIdent(table)
+	column := quoteIdent(c.Name)
+	switch c.Status {
+	case StatusAdded:
+		p.addColumns = append(p.addColumns, alter+" ADD COLUMN "+columnDefinition(*c.After))
+		if !c.After.IsNullable && c.After.Default == nil && c.After.Identity == "" && !c.After.IsGenerated {
+			p.warn("adds NOT NULL column %s.%s without a default, which fails if the table has rows", table, c.Name)
+		}
+		return
+	case StatusRemoved:
+		p.dropColumns = append(p.dropColumns, alter+" DROP COLUMN "+column)
+		p.warn("drops column %s.%s and its data", table, c.Name)
+		return
+	}
+
+	b, a := *c.Before, *c.After
+	if b.Identity != a.Identity || b.IsGenerated != a.IsGenerated || !equalStringPtr(b.GenerationExpression, a.GenerationExpression) {
+		p.warn("identity or generation of %s.%s differs; change it by hand", table, c.Name)
+		return
+	}
+
+	typeBefore, typeAfter := columnType(b), columnType(a)
+	typeChanged := typeBefore != typeAfter || !equalStringPtr(b.Collation, a.Collation)
+	defaultChanged := !equalDefaults(b, a)
+	if b.Default != nil && (typeChanged || a.Default == nil) {
+		// The old default may not cast to the new type
+		p.alterColumns = append(p.alterColumns, alter+" ALTER COLUMN "+column+" DROP DEFAULT")
+	}
+	if typeChanged {
+		clause := " TYPE " + typeAfter
+		if a.Collation != nil {
+			clause += " COLLATE " + quoteIdent(*a.Collation)
+		}
+		if typeBefore != typeAfter {
+			clause += " USING " + column + "::" + typeAfter
+			p.warn("changes the type of %s.%s from %s to %s, which fails or loses data if values do not convert", table, c.Name, typeBefore, typeAfter)
+		}
+		p.alterColumns = append(p.alterColumns, alter+" ALTER COLUMN "+column+clause)
+	}
+	if a.Default != nil && (typeChanged || defaultChanged) {
+		p.alterColumns = append(p.alterColumns, alter+" ALTER COLUMN "+column+" SET DEFAULT "+*a.Default)
+	}
+	if b.IsNullable != a.IsNullable {
+		if a.IsNullable {
+			p.alterColumns = append(p.alterColumns, alter+" ALTER COLUMN "+column+" DROP NOT NULL")
+		} else {
+			p.alterColumns = append(p.alterColumns, alter+" ALTER COLUMN "+column+" SET NOT NULL")
+			p.warn("sets NOT NULL on %s.%s, which fails if the column holds NULLs", table, c.Name)
+		}
+	}
+}
+
+func (p *migrationPhases) changeConstraint(td TableDiff, c ObjectChange, after schema.Table) {
+	alter := "ALTER TABLE " + quoteIdent(td.Name)
+	if c.Status != StatusAdded && td.Status != StatusAdded {
+		drop := alter + " DROP CONSTRAINT " + quoteIdent(c.Name)
+		if c.Kind == KindForeignKey {
+			p.dropForeignKeys = append(p.dropForeignKeys, drop)
+		} else {
+			p.dropConstraints = append(p.dropConstraints, drop)
+		}
+	}
+	if c.Status == StatusRemoved {
+		return
+	}
+	add := alter + " ADD CONSTRAINT " + quoteIdent(c.Name) + " " + constraintClause(after, c.Name)
+	if c.Kind == KindForeignKey {
+		p.addForeignKeys = append(p.addForeignKeys, add)
+	} else {
+		p.addConstraints = append(p.addConstraints, add)
+	}
+}
+
+func (p *migrationPhases) changeIndex(td TableDiff, c ObjectChange, after schema.Table) {
+	if c.Status != StatusAdded && td.Status != StatusAdded {
+		p.dropIndexes = append(p.dropIndexes, "DROP INDEX "+quoteIdent(c.Name))
+	}
+	if c.Status == StatusRemoved {
+		return
+	}
+	i := slices.IndexFunc(after.Indexes, func(idx schema.Index) bool { return idx.Name == c.Name })
+	idx := after.Indexes[i]
+	query := "CREATE "
+	if idx.IsUnique {
+		query += "UNIQUE "
+	}
+	// Index columns come from pg_get_indexdef, already quoted where needed
+	query += fmt.Sprintf("INDEX %s ON %s USING %s (%s)", quoteIdent(idx.Name), quoteIdent(td.Name), idx.Method, strings.Join(idx.Columns, ", "))
+	if idx.Predicate != nil {
+		query += " WHERE " + *idx.Predicate
+	}
+	p.createIndexes = append(p.createIndexes, query)
+}
+
+// constraintClause renders the named constraint of t as it follows ADD CONSTRAINT.
+func constraintClause(t schema.Table, name string) string {
+	if pk := t.PrimaryKey; pk != nil && pk.Name == name {
+		return "PRIMARY KEY " + quotedColumnList(pk.Columns)
+	}
+	for _, u := range t.UniqueConstraints {
+		if u.Name == name {
+			return "UNIQUE " + quotedColumnList(u.Columns)
+		}
+	}
+	for _, c := range t.CheckConstraints {
+		if c.Name == name {
+			return "CHECK (" + c.Expression + ")"
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		if fk.ConstraintName == name {
+			clause := fmt.Sprintf("FOREIGN KEY %s REFERENCES %s%s ON DELETE %s ON UPDATE %s",
+				quotedColumnList(fk.Columns), quoteIdent(fk.ReferencesTable), quotedColumnList(fk.ReferencesColumns), fk.OnDelete, fk.OnUpdate)
+			if fk.InitiallyDeferred {
+				clause += " DEFERRABLE INITIALLY DEFERRED"
+			} else if fk.Deferrable {
+				clause += " DEFERRABLE"
+			}
+			return clause
+		}
+	}
+	for _, ex := range t.Exclusions {
+		if ex.Name == name {
+			return ex.Definition
+		}
+	}
+	return ""
+}
+
+// columnDefinition renders col as it appears in CREATE TABLE or ADD COLUMN.
+func columnDefinition(col schema.Column) string {
+	def := quoteIdent(col.Name) + " "
+	serial := serialType(col)
+	if serial != "" {
+		def += serial
+	} else {
+		def += columnType(col)
+	}
+	if col.Collation != nil {
+		def += " COLLATE " + quoteIdent(*col.Collation)
+	}
+	switch {
+	case col.IsGenerated && col.GenerationExpression != nil:
+		def += " GENERATED ALWAYS AS (" + *col.GenerationExpression + ") STORED"
+	case col.Identity != "":
+		def += " GENERATED " + col.Identity + " AS IDENTITY"
+	case serial == "" && col.Default != nil:
+		def += " DEFAULT " + *col.Default
+	}
+	if !col.IsNullable {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+// columnType renders a column's declared type with its modifiers.
+func columnType(col schema.Column) string {
+	switch {
+	case col.Domain != "":
+		return quoteTypeName(col.Domain)
+	case col.TypeCategory == schema.TypeCategoryEnum || col.TypeCategory == schema.TypeCategoryComposite:
+		return quoteTypeName(col.DataType)
+	case col.CharacterMaximumLength != nil:
+		return fmt.Sprintf("%s(%d)", col.DataType, *col.CharacterMaximumLength)
+	case col.DataType == "numeric" && col.NumericPrecision != nil && col.NumericScale != nil:
+		return fmt.Sprintf("numeric(%d,%d)", *col.NumericPrecision, *col.NumericScale)
+	}
+	return col.DataType
+}
+
+// serialType returns serial, bigserial, or smallserial for a column whose
+// default draws from the sequence it owns, so the sequence is created with
+// the table. Returns "" for any other column.
+func serialType(col schema.Column) string {
+	if col.Sequence == "" || col.Identity != "" || col.Default == nil || !strings.HasPrefix(*col.Default, "nextval(") {
+		return ""
+	}
+	switch col.DataType {
+	case "integer":
+		return "serial"
+	case "bigint":
+		return "bigserial"
+	case "smallint":
+		return "smallserial"
+	}
+	return ""
+}
+
+// quoteTypeName quotes a user-defined type name unless it is a plain identifier.
+func quoteTypeName(name string) string {
+	if schema.ValidIdentifier(name) {
+		return name
+	}
+	return quoteIdent(name)
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quotedColumnList(columns []string) string {
+	quoted := make([]string, len(columns))
+	for idx, c := range columns {
+		quoted[idx] = quoteIdent(c)
+	}
+	return columnList(quoted)
+}
@@ -0,0 +1,431 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Table lock modes a statement may take, as PostgreSQL names them.
+const (
+	LockShareUpdateExclusive = "SHARE UPDATE EXCLUSIVE" // Blocks other schema changes and VACUUM
+	LockShare                = "SHARE"                  // Blocks writes
+	LockShareRowExclusive    = "SHARE ROW EXCLUSIVE"    // Blocks writes
+	LockAccessExclusive      = "ACCESS EXCLUSIVE"       // Blocks reads and writes
+)
+
+// Risk levels of a plan step, lowest first.
+const (
+	RiskLow     = "low"
+	RiskMedium  = "medium"
+	RiskHigh    = "high"
+	RiskUnknown = "unknown" // The statement was not recognized
+)
+
+// Tables at or past these sizes make a blocking rewrite or scan a medium or
+// high risk.
+const (
+	mediumRiskBytes = 100 << 20
+	mediumRiskRows  = 100_000
+	highRiskBytes   = 1 << 30
+	highRiskRows    = 1_000_000
+)
+
+// PlanStep is one statement of a migration with what running it costs.
+type PlanStep struct {
+	Statement string `json:"statement"`
+	Table     string `json:"table,omitempty"` // The table locked, when there is one
+	Lock      string `json:"lock,omitempty"`  // Strongest lock taken on Table
+	Blocks    string `json:"blocks"`          // What waits while the lock is held
+	Rewrite   bool   `json:"rewrite"`         // Rewrites the table and its indexes
+	Scan      bool   `json:"scan"`            // Reads the whole table while holding the lock
+	// EstimatedRows and SizeBytes are Table's before the migration; 0 for new tables
+	EstimatedRows int64    `json:"estimatedRows"`
+	SizeBytes     int64    `json:"sizeBytes"`
+	Risk          string   `json:"risk"`
+	Impact        string   `json:"impact"`
+	Notes         []string `json:"notes,omitempty"` // Ways to take a lighter lock
+}
+
+// MigrationPlan is a migration script annotated statement by statement.
+type MigrationPlan struct {
+	Steps []PlanStep `json:"steps"`
+	Risk  string     `json:"risk"` // The highest risk of any step
+}
+
+var (
+	identPattern   = `((?:"(?:[^"]|"")+"|[\w$]+)(?:\.(?:"(?:[^"]|"")+"|[\w$]+))?)`
+	createTableRe  = regexp.MustCompile(`(?is)^CREATE\s+(?:UNLOGGED\s+|TEMP\s+|TEMPORARY\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + identPattern)
+	dropTableRe    = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?` + identPattern)
+	createIndexRe  = regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(?:` + identPattern + `\s+)?ON\s+(?:ONLY\s+)?` + identPattern)
+	dropIndexRe    = regexp.MustCompile(`(?is)^DROP\s+INDEX\s+(CONCURRENTLY\s+)?(?:IF\s+EXISTS\s+)?` + identPattern)
+	alterTableRe   = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?` + identPattern + `\s+(.*)$`)
+	referencesRe   = regexp.MustCompile(`(?is)\bREFERENCES\s+` + identPattern)
+	alterColumnRe  = regexp.MustCompile(`(?is)^ALTER\s+(?:COLUMN\s+)?` + identPattern + `\s+(?:SET\s+DATA\s+)?(.*)$`)
+	typeModsRe     = regexp.MustCompile(`^(.*?)\s*\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)$`)
+	volatileCallRe = regexp.MustCompile(`(?i)\b(nextval|random|clock_timestamp|timeofday|gen_random_uuid|uuid_generate_v[14]\w*)\s*\(`)
+	serialTypeRe   = regexp.MustCompile(`(?i)^(small|big)?serial[248]?\b`)
+)
+
+// PlanMigration annotates each statement of a migration from before with the
+// lock it takes, whether it rewrites or scans the table, and how big that
+// table is, so the risky steps can be scheduled for a quiet time. Statements
+// are recognized by their text, as GenerateMigration writes them; what a
+// statement does is judged as on PostgreSQL 12 or later.
+func PlanMigration(before *schema.Schema, statements []string) MigrationPlan {
+	tables := tablesByName(before)
+	plan := MigrationPlan{Steps: make([]PlanStep, len(statements)), Risk: RiskLow}
+	for idx, stmt := range statements {
+		step := annotate(strings.TrimSpace(stmt), tables)
+		if t, ok := tables[step.Table]; ok {
+			step.EstimatedRows, step.SizeBytes = t.EstimatedRows, t.SizeBytes
+		}
+		step.Blocks = blockedBy(step.Lock)
+		step.Risk, step.Impact = assess(step)
+		plan.Steps[idx] = step
+		if riskRank(step.Risk) > riskRank(plan.Risk) {
+			plan.Risk = step.Risk
+		}
+	}
+	return plan
+}
+
+// annotate works out the table, lock, rewrite, and scan of one statement.
+func annotate(stmt string, tables map[string]schema.Table) PlanStep {
+	step := PlanStep{Statement: stmt}
+	switch {
+	case createTableRe.MatchString(stmt):
+		// The new table is locked, but nothing else can use it yet
+		step.Table = UnquoteName(createTableRe.FindStringSubmatch(stmt)[1])
+		if m := referencesRe.FindStringSubmatch(stmt); m != nil {
+			step.Notes = append(step.Notes, fmt.Sprintf("its foreign keys briefly take a SHARE ROW EXCLUSIVE lock on %s", UnquoteName(m[1])))
+		}
+	case dropTableRe.MatchString(stmt):
+		step.Table, step.Lock = UnquoteName(dropTableRe.FindStringSubmatch(stmt)[1]), LockAccessExclusive
+	case createIndexRe.MatchString(stmt):
+		m := createIndexRe.FindStringSubmatch(stmt)
+		step.Table, step.Scan = UnquoteName(m[3]), true
+		if m[1] != "" {
+			step.Lock = LockShareUpdateExclusive
+		} else {
+			step.Lock = LockShare
+			step.Notes = append(step.Notes, "CREATE INDEX CONCURRENTLY builds it without blocking writes, outside a transaction")
+		}
+	case dropIndexRe.MatchString(stmt):
+		m := dropIndexRe.FindStringSubmatch(stmt)
+		step.Table = indexTable(tables, UnquoteName(m[2]))
+		if m[1] != "" {
+			step.Lock = LockShareUpdateExclusive
+		} else {
+			step.Lock = LockAccessExclusive
+			step.Notes = append(step.Notes, "DROP INDEX CONCURRENTLY does not block reads and writes, outside a transaction")
+		}
+	case alterTableRe.MatchString(stmt):
+		m := alterTableRe.FindStringSubmatch(stmt)
+		step.Table = UnquoteName(m[1])
+		for _, action := range SplitTopLevel(m[2]) {
+			alterAction(&step, strings.TrimSpace(action), tables[step.Table])
+		}
+	}
+	return step
+}
+
+// alterAction folds one ALTER TABLE action into step, keeping the strongest
+// lock of the statement's actions.
+func alterAction(step *PlanStep, action string, table schema.Table) {
+	upper := strings.ToUpper(action)
+	lock := LockAccessExclusive
+	switch {
+	case strings.HasPrefix(upper, "VALIDATE CONSTRAINT"):
+		lock, step.Scan = LockShareUpdateExclusive, true
+	case strings.HasPrefix(upper, "ADD CONSTRAINT") || hasAnyPrefix(upper, "ADD PRIMARY KEY", "ADD UNIQUE", "ADD CHECK", "ADD FOREIGN KEY", "ADD EXCLUDE"):
+		notValid := strings.HasSuffix(upper, "NOT VALID")
+		switch {
+		case strings.Contains(upper, "FOREIGN KEY"):
+			lock = LockShareRowExclusive
+			if m := referencesRe.FindStringSubmatch(action); m != nil {
+				step.Notes = append(step.Notes, fmt.Sprintf("also takes a SHARE ROW EXCLUSIVE lock on %s", UnquoteName(m[1])))
+			}
+			if !notValid {
+				step.Scan = true
+				step.Notes = append(step.Notes, "adding it NOT VALID and then running VALIDATE CONSTRAINT checks the rows without blocking writes")
+			}
+		case strings.Contains(upper, "CHECK"):
+			if !notValid {
+				step.Scan = true
+				step.Notes = append(step.Notes, "adding it NOT VALID and then running VALIDATE CONSTRAINT checks the rows without blocking writes")
+			}
+		case strings.Contains(upper, "USING INDEX"):
+			// Takes over an index built beforehand
+		case strings.Contains(upper, "PRIMARY KEY") || strings.Contains(upper, "UNIQUE"):
+			step.Scan = true
+			step.Notes = append(step.Notes, "CREATE UNIQUE INDEX CONCURRENTLY first, then ADD CONSTRAINT ... USING INDEX, builds the index without blocking")
+		case strings.Contains(upper, "EXCLUDE"):
+			step.Scan = true
+		}
+	case strings.HasPrefix(upper, "ADD "):
+		addColumn(step, action)
+	case strings.HasPrefix(upper, "ALTER "):
+		alterColumn(step, action, table)
+	}
+	if lockRank(lock) > lockRank(step.Lock) {
+		step.Lock = lock
+	}
+}
+
+// addColumn marks a rewrite for a new column whose value must be computed
+// row by row. A constant default is stored once, in the catalog.
+func addColumn(step *PlanStep, action string) {
+	def := strings.TrimSpace(action[len("ADD "):])
+	if strings.HasPrefix(strings.ToUpper(def), "COLUMN ") {
+		def = strings.TrimSpace(def[len("COLUMN "):])
+	}
+	upper := strings.ToUpper(def)
+	typeStart := strings.IndexAny(def, " \t\n")
+	switch {
+	case strings.Contains(upper, " GENERATED ALWAYS AS ("):
+		step.Rewrite = true
+		step.Notes = append(step.Notes, "a stored generated column is computed for every row")
+	case strings.Contains(upper, " AS IDENTITY"):
+		step.Rewrite = true
+	case typeStart > 0 && serialTypeRe.MatchString(strings.TrimSpace(def[typeStart:])):
+		step.Rewrite = true
+	case strings.Contains(upper, " DEFAULT ") && volatileCallRe.MatchString(def[strings.Index(upper, " DEFAULT "):]):
+		step.Rewrite = true
+		step.Notes = append(step.Notes, "a volatile default is computed for every row; adding the column without it and backfilling in batches avoids the rewrite")
+	case strings.Contains(upper, " NOT NULL") && !strings.Contains(upper, " DEFAULT "):
+		step.Notes = append(step.Notes, "fails if the table has rows")
+	}
+}
+
+// alterColumn handles ALTER COLUMN actions: type changes rewrite unless the
+// old values are valid as they are, and SET NOT NULL scans.
+func alterColumn(step *PlanStep, action string, table schema.Table) {
+	m := alterColumnRe.FindStringSubmatch(action)
+	if m == nil {
+		return
+	}
+	column, rest := UnquoteName(m[1]), strings.TrimSpace(m[2])
+	upper := strings.ToUpper(rest)
+	switch {
+	case strings.HasPrefix(upper, "TYPE "):
+		newType := strings.TrimSpace(rest[len("TYPE "):])
+		using := ""
+		if idx := strings.Index(strings.ToUpper(newType), " USING "); idx >= 0 {
+			newType, using = strings.TrimSpace(newType[:idx]), strings.TrimSpace(newType[idx+len(" USING "):])
+		}
+		if idx := strings.Index(strings.ToUpper(newType), " COLLATE "); idx >= 0 {
+			newType = strings.TrimSpace(newType[:idx])
+		}
+		col, known := findColumn(table, column)
+		if known && (using == "" || using == quoteIdent(column)+"::"+newType) && binaryCompatible(col, newType) {
+			return
+		}
+		step.Rewrite = true
+		if !known {
+			step.Notes = append(step.Notes, "the column is new or unknown, so a rewrite is assumed")
+		}
+	case strings.HasPrefix(upper, "SET NOT NULL"):
+		step.Scan = true
+		step.Notes = append(step.Notes, "a validated CHECK (column IS NOT NULL) constraint, added NOT VALID first, lets SET NOT NULL skip the scan")
+	}
+}
+
+// binaryCompatible reports whether values of col are already valid as
+// newType, so changing to it only updates the catalog: lengthening or
+// dropping a varchar limit, varchar to text, or widening a numeric's
+// precision at the same scale.
+func binaryCompatible(col schema.Column, newType string) bool {
+	name, mods := strings.ToLower(newType), []int{}
+	if m := typeModsRe.FindStringSubmatch(newType); m != nil {
+		name = strings.ToLower(strings.TrimSpace(m[1]))
+		for _, s := range m[2:] {
+			if s != "" {
+				n, _ := strconv.Atoi(s)
+				mods = append(mods, n)
+			}
+		}
+	}
+	if name == "varchar" {
+		name = "character varying"
+	}
+
+	switch col.DataType {
+	case "character varying":
+		switch {
+		case name == "text" || (name == "character varying" && len(mods) == 0):
+			return true
+		case name == "character varying" && len(mods) == 1:
+			return col.CharacterMaximumLength != nil && int32(mods[0]) >= *col.CharacterMaximumLength
+		}
+	case "text":
+		return name == "character varying" && len(mods) == 0
+	case "numeric":
+		switch {
+		case name == "numeric" && len(mods) == 0:
+			return true
+		case name == "numeric" && len(mods) == 2 && col.NumericPrecision != nil && col.NumericScale != nil:
+			return int32(mods[0]) >= *col.NumericPrecision && int32(mods[1]) == *col.NumericScale
+		}
+	}
+	return col.DataType == name && len(mods) == 0 // Same type, e.g. only the collation changes
+}
+
+// assess rates a step and describes its impact. A lock held only to update
+// the catalog is brief whatever the table's size, though it still waits for
+// running queries on the table, and everything after it waits too.
+func assess(step PlanStep) (string, string) {
+	if step.Lock == "" && step.Table == "" {
+		return RiskUnknown, "Not recognized; check it by hand"
+	}
+	if step.Lock == "" || step.SizeBytes == 0 && step.EstimatedRows == 0 {
+		return RiskLow, "Touches no existing rows"
+	}
+	if !step.Rewrite && !step.Scan {
+		return RiskLow, fmt.Sprintf("Brief %s lock to update the catalog; it waits for, and then blocks, other queries on %s, so a lock_timeout helps",
+			step.Lock, step.Table)
+	}
+
+	verb := "Scans"
+	if step.Rewrite {
+		verb = "Rewrites"
+	}
+	impact := fmt.Sprintf("%s %s (about %d rows) while blocking %s", verb, formatSize(step.SizeBytes), step.EstimatedRows, step.Blocks)
+	if step.Lock == LockShareUpdateExclusive {
+		return RiskLow, impact
+	}
+	switch {
+	case step.SizeBytes >= highRiskBytes || step.EstimatedRows >= highRiskRows:
+		return RiskHigh, impact
+	case step.SizeBytes >= mediumRiskBytes || step.EstimatedRows >= mediumRiskRows:
+		return RiskMedium, impact
+	}
+	return RiskLow, impact
+}
+
+func blockedBy(lock string) string {
+	switch lock {
+	case LockAccessExclusive:
+		return "reads and writes"
+	case LockShare, LockShareRowExclusive:
+		return "writes"
+	case LockShareUpdateExclusive:
+		return "other schema changes and vacuum"
+	}
+	return "nothing"
+}
+
+func lockRank(lock string) int {
+	switch lock {
+	case LockShareUpdateExclusive:
+		return 1
+	case LockShare:
+		return 2
+	case LockShareRowExclusive:
+		return 3
+	case LockAccessExclusive:
+		return 4
+	}
+	return 0
+}
+
+func riskRank(risk string) int {
+	switch risk {
+	case RiskMedium:
+		return 1
+	case RiskUnknown:
+		return 2
+	case RiskHigh:
+		return 3
+	}
+	return 0
+}
+
+// formatSize renders a byte count with a binary unit, e.g. 1.5 GB.
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// SplitTopLevel splits a list, such as the actions of an ALTER TABLE, at
+// commas outside parentheses and quotes.
+func SplitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// UnquoteName returns a possibly quoted and schema-qualified identifier as
+// PostgreSQL stores the name: quoted names as written, unquoted ones
+// lowercased, without the schema.
+func UnquoteName(ident string) string {
+	if strings.HasPrefix(ident, `"`) {
+		if end := strings.LastIndex(ident, `".`); end > 0 && !strings.HasSuffix(ident[:end+1], `""`) {
+			ident = ident[end+2:]
+		}
+	} else if dot := strings.IndexByte(ident, '.'); dot >= 0 {
+		ident = ident[dot+1:]
+	}
+	if strings.HasPrefix(ident, `"`) && strings.HasSuffix(ident, `"`) && len(ident) >= 2 {
+		return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	}
+	return strings.ToLower(ident)
+}
+
+func indexTable(tables map[string]schema.Table, index string) string {
+	for _, t := range tables {
+		for _, idx := range t.Indexes {
+			if idx.Name == index {
+				return t.Name
+			}
+		}
+	}
+	return ""
+}
+
+func findColumn(t schema.Table, name string) (schema.Column, bool) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return schema.Column{}, false
+}
+
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Constraint and index kinds in a SchemaDiff.
+const (
+	KindPrimaryKey = "primary-key"
+	KindUnique     = "unique"
+	KindCheck      = "check"
+	KindForeignKey = "foreign-key"
+	KindExclusion  = "exclusion"
+	KindIndex      = "index"
+)
+
+// ObjectChange is an added, removed, or changed constraint or index.
+// Before and After are normalized definitions; Before is empty for added
+// objects and After for removed ones.
+type ObjectChange struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// TableDiff lists what differs in one table. Unchanged columns, constraints,
+// and indexes are left out; an added or removed table lists all of its own.
+type TableDiff struct {
+	Name        string         `json:"name"`
+	Status      string         `json:"status"`
+	Columns     []ColumnChange `json:"columns"`
+	Constraints []ObjectChange `json:"constraints"`
+	Indexes     []ObjectChange `json:"indexes"`
+}
+
+// Counts tallies changes of one object type by status.
+type Counts struct {
+	Added    int `json:"added"`
+	Removed  int `json:"removed"`
+	Modified int `json:"modified"`
+}
+
+func (c *Counts) add(status string) {
+	switch status {
+	case StatusAdded:
+		c.Added++
+	case StatusRemoved:
+		c.Removed++
+	case StatusModified:
+		c.Modified++
+	}
+}
+
+// SchemaSummary counts changes per object type.
+type SchemaSummary struct {
+	Tables      Counts `json:"tables"`
+	Columns     Counts `json:"columns"`
+	Constraints Counts `json:"constraints"`
+	Indexes     Counts `json:"indexes"`
+}
+
+// SchemaDiff is a structured comparison of two schemas, listing only the
+// tables that differ.
+type SchemaDiff struct {
+	Tables  []TableDiff   `json:"tables"`
+	Summary SchemaSummary `json:"summary"`
+	// Identical is true when no table, column, constraint, or index differs
+	Identical bool `json:"identical"`
+}
+
+// Compare returns what must change to turn before into after: tables,
+// columns, constraints, and indexes added, removed, or changed. Constraints
+// and indexes are matched by name, so a rename is a removal plus an addition.
+func Compare(before, after *schema.Schema) SchemaDiff {
+	beforeTables := tablesByName(before)
+	afterTables := tablesByName(after)
+
+	result := SchemaDiff{Tables: []TableDiff{}}
+	for _, name := range unionKeys(beforeTables, afterTables) {
+		b, inBefore := beforeTables[name]
+		a, inAfter := afterTables[name]
+
+		td := TableDiff{Name: name, Columns: []ColumnChange{}}
+		switch {
+		case !inBefore:
+			td.Status = StatusAdded
+			td.Columns = columnChanges(nil, a.Columns)
+		case !inAfter:
+			td.Status = StatusRemoved
+			td.Columns = columnChanges(b.Columns, nil)
+		default:
+			for _, col := range columnChanges(b.Columns, a.Columns) {
+				if col.Status != StatusUnchanged {
+					td.Columns = append(td.Columns, col)
+				}
+			}
+		}
+		td.Constraints = objectChanges(constraintDefinitions(b, inBefore), constraintDefinitions(a, inAfter))
+		td.Indexes = objectChanges(indexDefinitions(b, inBefore), indexDefinitions(a, inAfter))
+
+		if td.Status == "" {
+			if len(td.Columns) == 0 && len(td.Constraints) == 0 && len(td.Indexes) == 0 {
+				continue
+			}
+			td.Status = StatusModified
+		}
+		result.Summary.Tables.add(td.Status)
+		for _, c := range td.Columns {
+			result.Summary.Columns.add(c.Status)
+		}
+		for _, c := range td.Constraints {
+			result.Summary.Constraints.add(c.Status)
+		}
+		for _, c := range td.Indexes {
+			result.Summary.Indexes.add(c.Status)
+		}
+		result.Tables = append(result.Tables, td)
+	}
+	result.Identical = len(result.Tables) == 0
+	return result
+}
+
+// objectDefinition is a named constraint or index with its normalized definition.
+type objectDefinition struct {
+	kind       string
+	definition string
+}
+
+// objectChanges pairs objects by name, in name order.
+func objectChanges(before, after map[string]objectDefinition) []ObjectChange {
+	changes := []ObjectChange{}
+	for _, name := range unionKeys(before, after) {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		switch {
+		case !inBefore:
+			changes = append(changes, ObjectChange{Name: name, Kind: a.kind, Status: StatusAdded, After: a.definition})
+		case !inAfter:
+			changes = append(changes, ObjectChange{Name: name, Kind: b.kind, Status: StatusRemoved, Before: b.definition})
+		case a != b:
+			changes = append(changes, ObjectChange{Name: name, Kind: a.kind, Status: StatusModified, Before: b.definition, After: a.definition})
+		}
+	}
+	return changes
+}
+
+// constraintDefinitions returns a table's constraints keyed by name.
+// present is false for a table missing from one side.
+func constraintDefinitions(t schema.Table, present bool) map[string]objectDefinition {
+	defs := make(map[string]objectDefinition)
+	if !present {
+		return defs
+	}
+	if pk := t.PrimaryKey; pk != nil {
+		defs[pk.Name] = objectDefinition{KindPrimaryKey, columnList(pk.Columns)}
+	}
+	for _, u := range t.UniqueConstraints {
+		defs[u.Name] = objectDefinition{KindUnique, columnList(u.Columns)}
+	}
+	for _, c := range t.CheckConstraints {
+		defs[c.Name] = objectDefinition{KindCheck, c.Expression}
+	}
+	for _, fk := range t.ForeignKeys {
+		def := fmt.Sprintf("%s REFERENCES %s%s ON DELETE %s ON UPDATE %s",
+			columnList(fk.Columns), fk.ReferencesTable, columnList(fk.ReferencesColumns), fk.OnDelete, fk.OnUpdate)
+		if fk.InitiallyDeferred {
+			def += " DEFERRABLE INITIALLY DEFERRED"
+		} else if fk.Deferrable {
+			def += " DEFERRABLE"
+		}
+		defs[fk.ConstraintName] = objectDefinition{KindForeignKey, def}
+	}
+	for _, ex := range t.Exclusions {
+		defs[ex.Name] = objectDefinition{KindExclusion, ex.Definition}
+	}
+	return defs
+}
+
+// indexDefinitions returns a table's indexes keyed by name, leaving out those
+// backing a primary key, unique, or exclusion constraint.
+func indexDefinitions(t schema.Table, present bool) map[string]objectDefinition {
+	defs := make(map[string]objectDefinition)
+	if !present {
+		return defs
+	}
+	backing := make([]string, 0, len(t.UniqueConstraints)+len(t.Exclusions))
+	for _, u := range t.UniqueConstraints {
+		backing = append(backing, u.Name)
+	}
+	for _, ex := range t.Exclusions {
+		backing = append(backing, ex.Name)
+	}
+	for _, idx := range t.Indexes {
+		if idx.IsPrimary || slices.Contains(backing, idx.Name) {
+			continue
+		}
+		def := "USING " + idx.Method + " " + columnList(idx.Columns)
+		if idx.IsUnique {
+			def = "UNIQUE " + def
+		}
+		if idx.Predicate != nil {
+			def += " WHERE " + *idx.Predicate
+		}
+		defs[idx.Name] = objectDefinition{KindIndex, def}
+	}
+	return defs
+}
+
+func columnList(columns []string) string {
+	return "(" + strings.Join(columns, ", ") + ")"
+}
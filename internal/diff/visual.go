@@ -0,0 +1,258 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Change statuses used in the visual overlay.
+const (
+	StatusAdded     = "added"
+	StatusRemoved   = "removed"
+	StatusModified  = "modified"
+	StatusUnchanged = "unchanged"
+)
+
+// ColumnChange is a column in the overlay with its before/after state.
+// Before is nil for added columns and After is nil for removed ones.
+type ColumnChange struct {
+	Name    string         `json:"name"`
+	Status  string         `json:"status"`
+	Changed []string       `json:"changed,omitempty"` // Names of modified fields
+	Before  *schema.Column `json:"before,omitempty"`
+	After   *schema.Column `json:"after,omitempty"`
+}
+
+// TableChange is a graph node in the overlay.
+type TableChange struct {
+	Name    string         `json:"name"`
+	Status  string         `json:"status"`
+	Columns []ColumnChange `json:"columns"`
+}
+
+// EdgeChange is a foreign key edge in the overlay.
+// ID matches the edge IDs the graph view assigns to foreign keys.
+type EdgeChange struct {
+	ID                string   `json:"id"`
+	Table             string   `json:"table"`
+	Columns           []string `json:"columns"`
+	ReferencesTable   string   `json:"referencesTable"`
+	ReferencesColumns []string `json:"referencesColumns"`
+	Status            string   `json:"status"`
+}
+
+// Summary counts changed tables by status.
+type Summary struct {
+	Added    int `json:"added"`
+	Removed  int `json:"removed"`
+	Modified int `json:"modified"`
+}
+
+// VisualDiff maps the differences between two schemas onto the graph model
+// so the UI can render a red/green overlay.
+type VisualDiff struct {
+	Tables  []TableChange `json:"tables"`
+	Edges   []EdgeChange  `json:"edges"`
+	Summary Summary       `json:"summary"`
+}
+
+// Visual compares before and after and returns every table and edge of
+// both schemas annotated with its change status.
+func Visual(before, after *schema.Schema) VisualDiff {
+	beforeTables := tablesByName(before)
+	afterTables := tablesByName(after)
+
+	result := VisualDiff{Tables: []TableChange{}, Edges: []EdgeChange{}}
+	for _, name := range unionKeys(beforeTables, afterTables) {
+		b, inBefore := beforeTables[name]
+		a, inAfter := afterTables[name]
+
+		change := TableChange{Name: name}
+		switch {
+		case !inBefore:
+			change.Status = StatusAdded
+			change.Columns = columnChanges(nil, a.Columns)
+			result.Summary.Added++
+		case !inAfter:
+			change.Status = StatusRemoved
+			change.Columns = columnChanges(b.Columns, nil)
+			result.Summary.Removed++
+		default:
+			change.Columns = columnChanges(b.Columns, a.Columns)
+			change.Status = StatusUnchanged
+			for _, col := range change.Columns {
+				if col.Status != StatusUnchanged {
+					change.Status = StatusModified
+					break
+				}
+			}
+		}
+
+		edges := edgeChanges(name, b.ForeignKeys, a.ForeignKeys)
+		result.Edges = append(result.Edges, edges...)
+		if change.Status == StatusUnchanged && anyEdgeChanged(edges) {
+			change.Status = StatusModified
+		}
+		if change.Status == StatusModified {
+			result.Summary.Modified++
+		}
+		result.Tables = append(result.Tables, change)
+	}
+	return result
+}
+
+func tablesByName(s *schema.Schema) map[string]schema.Table {
+	m := make(map[string]schema.Table)
+	if s == nil {
+		return m
+	}
+	for _, t := range s.Tables {
+		m[t.Name] = t
+	}
+	return m
+}
+
+// unionKeys returns the sorted union of keys from both maps.
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+	for _, m := range []map[string]V{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// columnChanges pairs columns by name, keeping the after-schema order
+// and appending removed columns at the end.
+func columnChanges(before, after []schema.Column) []ColumnChange {
+	beforeByName := make(map[string]schema.Column, len(before))
+	for _, col := range before {
+		beforeByName[col.Name] = col
+	}
+
+	changes := make([]ColumnChange, 0, len(after))
+	seen := make(map[string]bool, len(after))
+	for _, col := range after {
+		seen[col.Name] = true
+		b, ok := beforeByName[col.Name]
+		if !ok {
+			changes = append(changes, ColumnChange{Name: col.Name, Status: StatusAdded, After: &col})
+			continue
+		}
+		changed := changedFields(b, col)
+		status := StatusUnchanged
+		if len(changed) > 0 {
+			status = StatusModified
+		}
+		changes = append(changes, ColumnChange{Name: col.Name, Status: status, Changed: changed, Before: &b, After: &col})
+	}
+	for _, col := range before {
+		if !seen[col.Name] {
+			changes = append(changes, ColumnChange{Name: col.Name, Status: StatusRemoved, Before: &col})
+		}
+	}
+	return changes
+}
+
+// changedFields lists the JSON names of column fields that differ.
+func changedFields(a, b schema.Column) []string {
+	var changed []string
+	if columnType(a) != columnType(b) {
+		changed = append(changed, "dataType")
+	}
+	if !equalStringPtr(a.Collation, b.Collation) {
+		changed = append(changed, "collation")
+	}
+	if a.Identity != b.Identity {
+		changed = append(changed, "identity")
+	}
+	if a.IsGenerated != b.IsGenerated || !equalStringPtr(a.GenerationExpression, b.GenerationExpression) {
+		changed = append(changed, "generationExpression")
+	}
+	if a.IsNullable != b.IsNullable {
+		changed = append(changed, "isNullable")
+	}
+	if a.IsPrimary != b.IsPrimary {
+		changed = append(changed, "isPrimary")
+	}
+	if a.IsUnique != b.IsUnique {
+		changed = append(changed, "isUnique")
+	}
+	if !equalDefaults(a, b) {
+		changed = append(changed, "default")
+	}
+	return changed
+}
+
+// equalDefaults compares defaults by kind and value when both are classified,
+// so spellings such as now() and CURRENT_TIMESTAMP are not reported as changes.
+func equalDefaults(a, b schema.Column) bool {
+	if a.DefaultValue != nil && b.DefaultValue != nil {
+		return a.DefaultValue.Kind == b.DefaultValue.Kind && a.DefaultValue.Value == b.DefaultValue.Value
+	}
+	return equalStringPtr(a.Default, b.Default)
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func edgeChanges(table string, before, after []schema.ForeignKey) []EdgeChange {
+	// Key by structure rather than constraint name so renamed constraints match
+	beforeEdges := make(map[string]EdgeChange, len(before))
+	for _, fk := range before {
+		e := edgeFor(table, fk)
+		beforeEdges[e.ID+"."+strings.Join(fk.ReferencesColumns, ",")] = e
+	}
+	afterEdges := make(map[string]EdgeChange, len(after))
+	for _, fk := range after {
+		e := edgeFor(table, fk)
+		afterEdges[e.ID+"."+strings.Join(fk.ReferencesColumns, ",")] = e
+	}
+
+	changes := make([]EdgeChange, 0, len(afterEdges))
+	for _, key := range unionKeys(beforeEdges, afterEdges) {
+		e, inAfter := afterEdges[key]
+		switch {
+		case !inAfter:
+			e = beforeEdges[key]
+			e.Status = StatusRemoved
+		case beforeEdges[key].ID == "":
+			e.Status = StatusAdded
+		default:
+			e.Status = StatusUnchanged
+		}
+		changes = append(changes, e)
+	}
+	return changes
+}
+
+func edgeFor(table string, fk schema.ForeignKey) EdgeChange {
+	return EdgeChange{
+		ID:                table + "-" + strings.Join(fk.Columns, ",") + "-" + fk.ReferencesTable,
+		Table:             table,
+		Columns:           fk.Columns,
+		ReferencesTable:   fk.ReferencesTable,
+		ReferencesColumns: fk.ReferencesColumns,
+	}
+}
+
+func anyEdgeChanged(edges []EdgeChange) bool {
+	for _, e := range edges {
+		if e.Status != StatusUnchanged {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job statuses.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// ErrNotFound is returned for unknown job IDs.
+var ErrNotFound = errors.New("job not found")
+
+// ErrNotRunning is returned when cancelling a job that already finished.
+var ErrNotRunning = errors.New("job is not running")
+
+// Job is a snapshot of an asynchronous operation's progress.
+type Job struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"`
+	TotalSteps int        `json:"totalSteps"`
+	DoneSteps  int        `json:"doneSteps"`
+	Current    string     `json:"current,omitempty"` // Description of the step in progress
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Progress lets a running job report which step it is on.
+type Progress struct {
+	m  *Manager
+	id string
+}
+
+// Step marks the previous step done and records the next one.
+// The first call starts step one; it does not count anything as done.
+func (p *Progress) Step(description string) {
+	p.m.mu.Lock()
+	defer p.m.mu.Unlock()
+	e := p.m.jobs[p.id]
+	if e.job.Current != "" {
+		e.job.DoneSteps++
+	}
+	e.job.Current = description
+}
+
+// Manager runs jobs in the background and keeps their state in memory.
+type Manager struct {
+	mu   sync.Mutex
+	jobs map[string]*entry
+	wg   sync.WaitGroup
+}
+
+type entry struct {
+	job    Job
+	cancel context.CancelFunc
+}
+
+// NewManager creates an empty job manager.
+func NewManager() *Manager {
+	return &Manager{jobs: make(map[string]*entry)}
+}
+
+// Start launches run in a new goroutine and returns the initial job state.
+// run receives a context that is cancelled by Cancel or Stop.
+func (m *Manager) Start(kind string, totalSteps int, run func(ctx context.Context, p *Progress) error) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &entry{
+		job: Job{
+			ID:         id,
+			Kind:       kind,
+			Status:     StatusRunning,
+			TotalSteps: totalSteps,
+			StartedAt:  time.Now().UTC(),
+		},
+		cancel: cancel,
+	}
+
+	m.mu.Lock()
+	m.jobs[id] = e
+	snapshot := e.job
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		err := run(ctx, &Progress{m: m, id: id})
+		m.finish(ctx, id, err)
+	}()
+
+	return snapshot, nil
+}
+
+func (m *Manager) finish(ctx context.Context, id string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.jobs[id]
+	now := time.Now().UTC()
+	e.job.FinishedAt = &now
+	switch {
+	case ctx.Err() != nil:
+		e.job.Status = StatusCancelled
+	case err != nil:
+		e.job.Status = StatusFailed
+		e.job.Error = err.Error()
+	default:
+		e.job.Status = StatusSucceeded
+		e.job.DoneSteps = e.job.TotalSteps
+		e.job.Current = ""
+	}
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return e.job, nil
+}
+
+// List returns snapshots of all jobs, newest first.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		list = append(list, e.job)
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].StartedAt.After(list[b].StartedAt) })
+	return list
+}
+
+// Running reports whether any job of the given kind is still running.
+func (m *Manager) Running(kind string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.jobs {
+		if e.job.Kind == kind && e.job.Status == StatusRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// Cancel requests cancellation of a running job.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if e.job.Status != StatusRunning {
+		return ErrNotRunning
+	}
+	e.cancel()
+	return nil
+}
+
+// Stop cancels all running jobs and waits for them to exit.
+// Should be called on graceful shutdown.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	for _, e := range m.jobs {
+		e.cancel()
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("crypto/rand failed: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,178 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Severity levels for findings.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Finding is a single rule violation.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Table    string `json:"table"`
+	Column   string `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Rule is a named check over one table.
+type Rule struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	check       func(t schema.Table) []Finding
+}
+
+// RuleInfo describes a rule and whether it is enabled.
+type RuleInfo struct {
+	Rule
+	Enabled bool `json:"enabled"`
+}
+
+// Rules is the full rule set, in reporting order.
+var Rules = []Rule{
+	{
+		ID:          "require-primary-key",
+		Description: "Every table needs a primary key",
+		Severity:    SeverityError,
+		check:       checkPrimaryKey,
+	},
+	{
+		ID:          "fk-needs-index",
+		Description: "Foreign key columns should lead an index",
+		Severity:    SeverityWarning,
+		check:       checkForeignKeyIndexes,
+	},
+	{
+		ID:          "prefer-timestamptz",
+		Description: "Use timestamptz instead of timestamp",
+		Severity:    SeverityWarning,
+		check:       checkTimestamptz,
+	},
+	{
+		ID:          "no-nullable-boolean",
+		Description: "Boolean columns should be NOT NULL",
+		Severity:    SeverityWarning,
+		check:       checkNullableBooleans,
+	},
+	{
+		ID:          "snake-case-names",
+		Description: "Table and column names should be lowercase snake_case",
+		Severity:    SeverityInfo,
+		check:       checkSnakeCase,
+	},
+}
+
+// Run checks every table against every enabled rule.
+// Rules listed in disabled are skipped.
+func Run(s *schema.Schema, disabled map[string]bool) []Finding {
+	findings := make([]Finding, 0)
+	for _, rule := range Rules {
+		if disabled[rule.ID] {
+			continue
+		}
+		for _, t := range s.Tables {
+			for _, f := range rule.check(t) {
+				f.Rule = rule.ID
+				f.Severity = rule.Severity
+				f.Table = t.Name
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// Describe returns all rules with their enabled state.
+func Describe(disabled map[string]bool) []RuleInfo {
+	infos := make([]RuleInfo, 0, len(Rules))
+	for _, rule := range Rules {
+		infos = append(infos, RuleInfo{Rule: rule, Enabled: !disabled[rule.ID]})
+	}
+	return infos
+}
+
+func checkPrimaryKey(t schema.Table) []Finding {
+	// Foreign tables cannot have constraints; the remote table owns the key
+	if t.PrimaryKey != nil || t.IsForeign {
+		return nil
+	}
+	return []Finding{{Message: "Table has no primary key"}}
+}
+
+func checkForeignKeyIndexes(t schema.Table) []Finding {
+	var findings []Finding
+	for _, fk := range t.ForeignKeys {
+		if !hasLeadingIndex(t.Indexes, fk.Columns) {
+			findings = append(findings, Finding{
+				Column:  strings.Join(fk.Columns, ", "),
+				Message: fmt.Sprintf("Foreign key to %s is not indexed", fk.ReferencesTable),
+			})
+		}
+	}
+	return findings
+}
+
+// hasLeadingIndex reports whether some index starts with exactly the given
+// columns, in any order.
+func hasLeadingIndex(indexes []schema.Index, columns []string) bool {
+	for _, idx := range indexes {
+		if len(idx.Columns) < len(columns) {
+			continue
+		}
+		leading := idx.Columns[:len(columns)]
+		covered := true
+		for _, col := range columns {
+			if !slices.Contains(leading, col) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+func checkTimestamptz(t schema.Table) []Finding {
+	var findings []Finding
+	for _, col := range t.Columns {
+		if col.DataType == "timestamp without time zone" {
+			findings = append(findings, Finding{Column: col.Name, Message: "Column uses timestamp without time zone"})
+		}
+	}
+	return findings
+}
+
+func checkNullableBooleans(t schema.Table) []Finding {
+	var findings []Finding
+	for _, col := range t.Columns {
+		if col.DataType == "boolean" && col.IsNullable {
+			findings = append(findings, Finding{Column: col.Name, Message: "Boolean column allows NULL"})
+		}
+	}
+	return findings
+}
+
+func checkSnakeCase(t schema.Table) []Finding {
+	var findings []Finding
+	if !schema.ValidIdentifier(t.Name) {
+		findings = append(findings, Finding{Message: "Table name is not snake_case"})
+	}
+	for _, col := range t.Columns {
+		if !schema.ValidIdentifier(col.Name) {
+			findings = append(findings, Finding{Column: col.Name, Message: "Column name is not snake_case"})
+		}
+	}
+	return findings
+}
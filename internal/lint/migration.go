@@ -0,0 +1,405 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/diff"
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// MigrationFinding is a rule violation in one statement of a migration.
+type MigrationFinding struct {
+	Finding
+	Statement int    `json:"statement"` // Index of the statement, from 0
+	SQL       string `json:"sql"`
+}
+
+// MigrationContext is what migration statements are checked against: the
+// schema they would run on and the columns its views read.
+type MigrationContext struct {
+	Schema      *schema.Schema
+	ViewColumns []schema.ViewColumnUsage
+}
+
+// MigrationRule is a named check over one statement of a migration.
+type MigrationRule struct {
+	ID          string
+	Description string
+	Severity    string
+	check       func(s migrationStatement, c MigrationContext) []Finding
+}
+
+// migrationStatement is a statement with what the rules need to know: its
+// lock, rewrite, and scan as diff.PlanMigration judges them, and the table it
+// locks as it was before the migration.
+type migrationStatement struct {
+	diff.PlanStep
+	table    schema.Table
+	existing bool     // The table exists before the migration
+	actions  []string // Of an ALTER TABLE, one per action
+}
+
+// MigrationRules is the rule set for migration statements, in reporting order
+// within each statement. Rules that depend on a table's rows only fire for
+// tables that exist before the migration.
+var MigrationRules = []MigrationRule{
+	{
+		ID:          "add-not-null-column",
+		Description: "A NOT NULL column added to an existing table needs a default",
+		Severity:    SeverityError,
+		check:       checkAddNotNullColumn,
+	},
+	{
+		ID:          "drop-view-dependency",
+		Description: "Columns and tables views read cannot be dropped or retyped without dropping the views",
+		Severity:    SeverityError,
+		check:       checkViewDependencies,
+	},
+	{
+		ID:          "table-rewrite",
+		Description: "Rewriting an existing table blocks reads and writes for the whole rewrite",
+		Severity:    SeverityWarning,
+		check:       checkTableRewrite,
+	},
+	{
+		ID:          "set-not-null-scan",
+		Description: "SET NOT NULL scans the table under an ACCESS EXCLUSIVE lock",
+		Severity:    SeverityWarning,
+		check:       checkSetNotNull,
+	},
+	{
+		ID:          "index-concurrently",
+		Description: "Create and drop indexes on existing tables CONCURRENTLY",
+		Severity:    SeverityWarning,
+		check:       checkIndexConcurrently,
+	},
+	{
+		ID:          "constraint-not-valid",
+		Description: "Add check and foreign key constraints to existing tables NOT VALID, then validate them",
+		Severity:    SeverityWarning,
+		check:       checkConstraintNotValid,
+	},
+	{
+		ID:          "constraint-builds-index",
+		Description: "Build the index of a new primary key or unique constraint concurrently first, then add it USING INDEX",
+		Severity:    SeverityWarning,
+		check:       checkConstraintIndex,
+	},
+	{
+		ID:          "rename-used-column",
+		Description: "Renaming a column breaks queries that use the old name",
+		Severity:    SeverityWarning,
+		check:       checkRenameColumn,
+	},
+	{
+		ID:          "destructive-change",
+		Description: "Dropping or truncating a table or column discards its data",
+		Severity:    SeverityWarning,
+		check:       checkDestructive,
+	},
+	{
+		ID:          "unrecognized-statement",
+		Description: "Statements the linter does not recognize are not checked",
+		Severity:    SeverityInfo,
+		check:       checkUnrecognized,
+	},
+}
+
+var (
+	lintIdent       = `("(?:[^"]|"")+"|[\w$]+)`
+	alterPrefixRe   = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(?:(?:"(?:[^"]|"")+"|[\w$]+)\.)?` + lintIdent + `\s+`)
+	addColumnRe     = regexp.MustCompile(`(?is)^ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?` + lintIdent + `\s+(.*)$`)
+	addConstraintRe = regexp.MustCompile(`(?is)^ADD\s+(?:CONSTRAINT\s+` + lintIdent + `\s+)?(PRIMARY\s+KEY|UNIQUE|CHECK|FOREIGN\s+KEY|EXCLUDE)\b`)
+	dropColumnRe    = regexp.MustCompile(`(?is)^DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?` + lintIdent)
+	alterColumnRe   = regexp.MustCompile(`(?is)^ALTER\s+(?:COLUMN\s+)?` + lintIdent + `\s+(SET\s+DATA\s+TYPE|TYPE|SET\s+NOT\s+NULL)\b`)
+	renameColumnRe  = regexp.MustCompile(`(?is)^RENAME\s+(?:COLUMN\s+)?` + lintIdent + `\s+TO\s+` + lintIdent)
+	serialRe        = regexp.MustCompile(`(?i)^(small|big)?serial[248]?\b`)
+	dropTablePrefix = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?`)
+	dropBehaviorRe  = regexp.MustCompile(`(?is)\s+(CASCADE|RESTRICT)\s*$`)
+)
+
+// RunMigration checks each statement against every enabled migration rule.
+// Rules listed in disabled are skipped.
+func RunMigration(statements []string, c MigrationContext, disabled map[string]bool) []MigrationFinding {
+	tables := make(map[string]schema.Table, len(c.Schema.Tables))
+	for _, t := range c.Schema.Tables {
+		tables[t.Name] = t
+	}
+
+	plan := diff.PlanMigration(c.Schema, statements)
+	findings := make([]MigrationFinding, 0)
+	for idx, step := range plan.Steps {
+		s := migrationStatement{PlanStep: step}
+		s.table, s.existing = tables[step.Table]
+		if m := alterPrefixRe.FindStringIndex(step.Statement); m != nil {
+			for _, action := range diff.SplitTopLevel(step.Statement[m[1]:]) {
+				s.actions = append(s.actions, strings.TrimSpace(action))
+			}
+		}
+		for _, rule := range MigrationRules {
+			if disabled[rule.ID] {
+				continue
+			}
+			for _, f := range rule.check(s, c) {
+				f.Rule = rule.ID
+				f.Severity = rule.Severity
+				f.Table = step.Table
+				findings = append(findings, MigrationFinding{Finding: f, Statement: idx, SQL: step.Statement})
+			}
+		}
+	}
+	return findings
+}
+
+// DescribeMigration returns all migration rules with their enabled state.
+func DescribeMigration(disabled map[string]bool) []RuleInfo {
+	infos := make([]RuleInfo, 0, len(MigrationRules))
+	for _, rule := range MigrationRules {
+		infos = append(infos, RuleInfo{
+			Rule:    Rule{ID: rule.ID, Description: rule.Description, Severity: rule.Severity},
+			Enabled: !disabled[rule.ID],
+		})
+	}
+	return infos
+}
+
+func checkAddNotNullColumn(s migrationStatement, _ MigrationContext) []Finding {
+	if !s.existing {
+		return nil
+	}
+	var findings []Finding
+	for _, action := range s.actions {
+		m := addColumnRe.FindStringSubmatch(action)
+		if m == nil || addConstraintRe.MatchString(action) {
+			continue
+		}
+		def := strings.ToUpper(m[2])
+		if !strings.Contains(def, "NOT NULL") || strings.Contains(def, "DEFAULT ") || strings.Contains(def, "GENERATED ") || serialRe.MatchString(m[2]) {
+			continue
+		}
+		column := diff.UnquoteName(m[1])
+		findings = append(findings, Finding{
+			Column:  column,
+			Message: fmt.Sprintf("Adding NOT NULL column %s without a default fails unless the table is empty (about %d rows)", column, s.table.EstimatedRows),
+		})
+	}
+	return findings
+}
+
+func checkViewDependencies(s migrationStatement, c MigrationContext) []Finding {
+	var findings []Finding
+	upper := strings.ToUpper(s.Statement)
+	if strings.HasPrefix(upper, "DROP TABLE") {
+		// One statement may drop several tables; each is checked
+		for _, name := range droppedTables(s.Statement) {
+			if views := viewsReading(c.ViewColumns, name, ""); len(views) > 0 {
+				findings = append(findings, Finding{Message: fmt.Sprintf("Dropping table %s fails while it is read by %s; CASCADE would drop them", name, strings.Join(views, ", "))})
+			}
+		}
+		return findings
+	}
+
+	for _, action := range s.actions {
+		column, verb := "", ""
+		if m := dropColumnRe.FindStringSubmatch(action); m != nil && !strings.HasPrefix(strings.ToUpper(action), "DROP CONSTRAINT") &&
+			!hasWordPrefix(action, "DROP DEFAULT", "DROP NOT NULL", "DROP IDENTITY", "DROP EXPRESSION") {
+			column, verb = diff.UnquoteName(m[1]), "Dropping"
+		} else if m := alterColumnRe.FindStringSubmatch(action); m != nil && !strings.Contains(strings.ToUpper(m[2]), "NULL") {
+			column, verb = diff.UnquoteName(m[1]), "Changing the type of"
+		}
+		if column == "" {
+			continue
+		}
+		if views := viewsReading(c.ViewColumns, s.Table, column); len(views) > 0 {
+			findings = append(findings, Finding{
+				Column:  column,
+				Message: fmt.Sprintf("%s column %s fails while it is read by %s; CASCADE would drop them", verb, column, strings.Join(views, ", ")),
+			})
+		}
+	}
+	return findings
+}
+
+func checkTableRewrite(s migrationStatement, _ MigrationContext) []Finding {
+	if !s.existing || !s.Rewrite {
+		return nil
+	}
+	return []Finding{{Message: s.Impact}}
+}
+
+func checkSetNotNull(s migrationStatement, _ MigrationContext) []Finding {
+	if !s.existing {
+		return nil
+	}
+	var findings []Finding
+	for _, action := range s.actions {
+		m := alterColumnRe.FindStringSubmatch(action)
+		if m == nil || !strings.Contains(strings.ToUpper(m[2]), "NULL") {
+			continue
+		}
+		column := diff.UnquoteName(m[1])
+		findings = append(findings, Finding{
+			Column: column,
+			Message: fmt.Sprintf("SET NOT NULL on %s scans about %d rows while blocking reads and writes; validate a CHECK (%s IS NOT NULL) NOT VALID constraint first",
+				column, s.table.EstimatedRows, column),
+		})
+	}
+	return findings
+}
+
+func checkIndexConcurrently(s migrationStatement, _ MigrationContext) []Finding {
+	if !s.existing || s.Lock == diff.LockShareUpdateExclusive {
+		return nil
+	}
+	upper := strings.ToUpper(s.Statement)
+	switch {
+	case strings.HasPrefix(upper, "CREATE INDEX") || strings.HasPrefix(upper, "CREATE UNIQUE INDEX"):
+		return []Finding{{Message: "CREATE INDEX blocks writes while it builds; use CREATE INDEX CONCURRENTLY, outside a transaction"}}
+	case strings.HasPrefix(upper, "DROP INDEX"):
+		return []Finding{{Message: "DROP INDEX blocks reads and writes until running queries finish; use DROP INDEX CONCURRENTLY, outside a transaction"}}
+	}
+	return nil
+}
+
+func checkConstraintNotValid(s migrationStatement, _ MigrationContext) []Finding {
+	if !s.existing {
+		return nil
+	}
+	var findings []Finding
+	for _, action := range s.actions {
+		m := addConstraintRe.FindStringSubmatch(action)
+		if m == nil || strings.HasSuffix(strings.ToUpper(action), "NOT VALID") {
+			continue
+		}
+		kind := strings.ToUpper(strings.Join(strings.Fields(m[2]), " "))
+		if kind != "CHECK" && kind != "FOREIGN KEY" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("Adding a %s constraint checks every row while blocking writes; add it NOT VALID, then VALIDATE CONSTRAINT", strings.ToLower(kind)),
+		})
+	}
+	return findings
+}
+
+func checkConstraintIndex(s migrationStatement, _ MigrationContext) []Finding {
+	if !s.existing {
+		return nil
+	}
+	var findings []Finding
+	for _, action := range s.actions {
+		m := addConstraintRe.FindStringSubmatch(action)
+		if m == nil || strings.Contains(strings.ToUpper(action), "USING INDEX") {
+			continue
+		}
+		kind := strings.ToUpper(strings.Join(strings.Fields(m[2]), " "))
+		if kind != "PRIMARY KEY" && kind != "UNIQUE" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("Adding a %s constraint builds its index while blocking reads and writes; CREATE UNIQUE INDEX CONCURRENTLY, then ADD CONSTRAINT ... USING INDEX",
+				strings.ToLower(kind)),
+		})
+	}
+	return findings
+}
+
+func checkRenameColumn(s migrationStatement, c MigrationContext) []Finding {
+	if !s.existing {
+		return nil
+	}
+	var findings []Finding
+	for _, action := range s.actions {
+		m := renameColumnRe.FindStringSubmatch(action)
+		if m == nil {
+			continue
+		}
+		column := diff.UnquoteName(m[1])
+		var users []string
+		for _, idx := range s.table.Indexes {
+			if slices.Contains(idx.Columns, column) || slices.Contains(idx.Columns, `"`+column+`"`) {
+				users = append(users, "index "+idx.Name)
+			}
+		}
+		for _, view := range viewsReading(c.ViewColumns, s.Table, column) {
+			users = append(users, view)
+		}
+		message := fmt.Sprintf("Renaming %s to %s breaks application queries that use the old name", column, diff.UnquoteName(m[2]))
+		if len(users) > 0 {
+			message += fmt.Sprintf("; %s follow the rename, though their names and definitions may still mention %s", strings.Join(users, ", "), column)
+		}
+		findings = append(findings, Finding{Column: column, Message: message})
+	}
+	return findings
+}
+
+func checkDestructive(s migrationStatement, _ MigrationContext) []Finding {
+	upper := strings.ToUpper(s.Statement)
+	switch {
+	case strings.HasPrefix(upper, "DROP TABLE"):
+		return []Finding{{Message: "Drops " + strings.Join(droppedTables(s.Statement), ", ") + " and their rows"}}
+	case strings.HasPrefix(upper, "TRUNCATE"):
+		return []Finding{{Message: "Deletes every row"}}
+	}
+	if !s.existing {
+		return nil
+	}
+	var findings []Finding
+	for _, action := range s.actions {
+		m := dropColumnRe.FindStringSubmatch(action)
+		if m == nil || hasWordPrefix(action, "DROP CONSTRAINT", "DROP DEFAULT", "DROP NOT NULL", "DROP IDENTITY", "DROP EXPRESSION") {
+			continue
+		}
+		column := diff.UnquoteName(m[1])
+		findings = append(findings, Finding{
+			Column:  column,
+			Message: fmt.Sprintf("Drops column %s and its values in about %d rows", column, s.table.EstimatedRows),
+		})
+	}
+	return findings
+}
+
+func checkUnrecognized(s migrationStatement, _ MigrationContext) []Finding {
+	if s.Risk != diff.RiskUnknown {
+		return nil
+	}
+	return []Finding{{Message: "Statement not recognized; review its locking by hand"}}
+}
+
+// viewsReading lists the views reading column of table, or any column of
+// it when column is empty.
+func viewsReading(usage []schema.ViewColumnUsage, table, column string) []string {
+	var views []string
+	for _, u := range usage {
+		if u.Table != table || (column != "" && u.Column != column) {
+			continue
+		}
+		if name := u.Kind + " " + u.View; !slices.Contains(views, name) {
+			views = append(views, name)
+		}
+	}
+	return views
+}
+
+// droppedTables returns the names a DROP TABLE statement lists.
+func droppedTables(stmt string) []string {
+	rest := dropBehaviorRe.ReplaceAllString(dropTablePrefix.ReplaceAllString(stmt, ""), "")
+	var names []string
+	for _, name := range diff.SplitTopLevel(rest) {
+		names = append(names, diff.UnquoteName(strings.TrimSpace(name)))
+	}
+	return names
+}
+
+func hasWordPrefix(s string, prefixes ...string) bool {
+	words := strings.ToUpper(strings.Join(strings.Fields(s), " "))
+	for _, p := range prefixes {
+		if strings.HasPrefix(words, p) {
+			return true
+		}
+	}
+	return false
+}
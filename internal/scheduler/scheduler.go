@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// Scheduler runs a task at every time matched by a cron schedule.
+type Scheduler struct {
+	schedule *Schedule
+	task     func(ctx context.Context)
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// Start begins running task on schedule in a background goroutine.
+// Runs never overlap: a run that outlasts its slot delays the next one.
+func Start(schedule *Schedule, task func(ctx context.Context)) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		schedule: schedule,
+		task:     task,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+	for {
+		next := s.schedule.Next(time.Now())
+		if next.IsZero() {
+			return // Expression never matches
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			s.task(s.ctx)
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// Stop cancels any running task and waits for the loop to exit.
+// Should be called on graceful shutdown.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.done
+}
@@ -0,0 +1,248 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Backend is one server process connected to the current database.
+type Backend struct {
+	PID             int32       `json:"pid"`
+	User            string      `json:"user"`
+	ApplicationName string      `json:"applicationName"`
+	ClientAddr      string      `json:"clientAddr,omitempty"`
+	State           string      `json:"state"`
+	WaitEventType   string      `json:"waitEventType,omitempty"`
+	WaitEvent       string      `json:"waitEvent,omitempty"`
+	Query           string      `json:"query"`
+	QueryStart      *time.Time  `json:"queryStart,omitempty"`
+	DurationSeconds float64     `json:"durationSeconds"`      // Time since QueryStart
+	BlockedBy       []int32     `json:"blockedBy"`            // PIDs holding locks this backend waits on
+	WaitingFor      *LockWanted `json:"waitingFor,omitempty"` // Ungranted lock this backend is waiting on
+	FromTool        bool        `json:"fromTool"`             // Connection opened by this tool
+}
+
+// LockWanted is the ungranted lock a backend is waiting to acquire.
+type LockWanted struct {
+	LockType string `json:"lockType"`
+	Mode     string `json:"mode"`
+	Relation string `json:"relation,omitempty"`
+}
+
+// ActivitySummary is a snapshot of pg_stat_activity for the current database.
+type ActivitySummary struct {
+	Backends []Backend      `json:"backends"`
+	ByState  map[string]int `json:"byState"`
+	Blocked  int            `json:"blocked"` // Backends waiting on another backend's lock
+}
+
+// maxQueryLength truncates query text so huge statements don't bloat responses.
+const maxQueryLength = 2000
+
+// GetActivity returns client backends connected to the current database,
+// longest-running first. The introspector's own connection is excluded.
+func (i *Introspector) GetActivity(ctx context.Context, toolApplicationName string) (*ActivitySummary, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			a.pid,
+			COALESCE(a.usename, ''),
+			COALESCE(a.application_name, ''),
+			COALESCE(host(a.client_addr), ''),
+			COALESCE(a.state, ''),
+			COALESCE(a.wait_event_type, ''),
+			COALESCE(a.wait_event, ''),
+			left(COALESCE(a.query, ''), $1),
+			a.query_start,
+			COALESCE(EXTRACT(EPOCH FROM (now() - a.query_start)), 0)::float8,
+			pg_blocking_pids(a.pid),
+			l.locktype,
+			l.mode,
+			COALESCE(l.relation::regclass::text, '')
+		FROM pg_stat_activity a
+		LEFT JOIN LATERAL (
+			-- A backend waits on at most one lock at a time
+			SELECT locktype, mode, relation
+			FROM pg_locks
+			WHERE pid = a.pid AND NOT granted
+			LIMIT 1
+		) l ON true
+		WHERE a.datname = current_database()
+		  AND a.backend_type = 'client backend'
+		  AND a.pid <> pg_backend_pid()
+		ORDER BY a.query_start ASC NULLS LAST
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query, maxQueryLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+	defer rows.Close()
+
+	summary := &ActivitySummary{Backends: make([]Backend, 0, 16), ByState: make(map[string]int)}
+	for rows.Next() {
+		var b Backend
+		var lockType, lockMode *string
+		var lockRelation string
+		if err := rows.Scan(&b.PID, &b.User, &b.ApplicationName, &b.ClientAddr, &b.State,
+			&b.WaitEventType, &b.WaitEvent, &b.Query, &b.QueryStart, &b.DurationSeconds, &b.BlockedBy,
+			&lockType, &lockMode, &lockRelation); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		if lockType != nil && lockMode != nil {
+			b.WaitingFor = &LockWanted{LockType: *lockType, Mode: *lockMode, Relation: lockRelation}
+		}
+		b.FromTool = b.ApplicationName == toolApplicationName
+		if b.BlockedBy == nil {
+			b.BlockedBy = []int32{}
+		}
+		if len(b.BlockedBy) > 0 {
+			summary.Blocked++
+		}
+		summary.ByState[b.State]++
+		summary.Backends = append(summary.Backends, b)
+	}
+
+	return summary, rows.Err()
+}
+
+// LockWait is one backend waiting on a lock held by another.
+type LockWait struct {
+	BlockedPID   int32   `json:"blockedPid"`
+	BlockedQuery string  `json:"blockedQuery"`
+	WaitSeconds  float64 `json:"waitSeconds"`
+	LockType     string  `json:"lockType"`
+	Mode         string  `json:"mode"`
+	Relation     string  `json:"relation,omitempty"`
+	BlockerPID   int32   `json:"blockerPid"`
+	BlockerQuery string  `json:"blockerQuery"`
+	BlockerState string  `json:"blockerState"`
+}
+
+// LockReport lists lock waits and resolves them into blocking chains.
+// Each chain runs from a root blocker (not itself waiting) to a blocked leaf.
+type LockReport struct {
+	Waits  []LockWait `json:"waits"`
+	Chains [][]int32  `json:"chains"`
+}
+
+// GetLockWaits returns ungranted locks in the current database with the
+// backends blocking them.
+func (i *Introspector) GetLockWaits(ctx context.Context) (*LockReport, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			blocked.pid,
+			left(COALESCE(blocked.query, ''), $1),
+			COALESCE(EXTRACT(EPOCH FROM (now() - blocked.query_start)), 0)::float8,
+			l.locktype,
+			l.mode,
+			COALESCE(l.relation::regclass::text, ''),
+			blocker.pid,
+			left(COALESCE(blocker.query, ''), $1),
+			COALESCE(blocker.state, '')
+		FROM pg_stat_activity blocked
+		JOIN pg_locks l ON l.pid = blocked.pid AND NOT l.granted
+		CROSS JOIN LATERAL unnest(pg_blocking_pids(blocked.pid)) AS b(pid)
+		JOIN pg_stat_activity blocker ON blocker.pid = b.pid
+		WHERE blocked.datname = current_database()
+		ORDER BY blocked.pid, blocker.pid
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query, maxQueryLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lock waits: %w", err)
+	}
+	defer rows.Close()
+
+	report := &LockReport{Waits: make([]LockWait, 0), Chains: make([][]int32, 0)}
+	for rows.Next() {
+		var lw LockWait
+		if err := rows.Scan(&lw.BlockedPID, &lw.BlockedQuery, &lw.WaitSeconds, &lw.LockType, &lw.Mode,
+			&lw.Relation, &lw.BlockerPID, &lw.BlockerQuery, &lw.BlockerState); err != nil {
+			return nil, fmt.Errorf("failed to scan lock wait: %w", err)
+		}
+		report.Waits = append(report.Waits, lw)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.Chains = blockingChains(report.Waits)
+	return report, nil
+}
+
+// blockingChains walks blocker -> blocked edges from every root blocker.
+func blockingChains(waits []LockWait) [][]int32 {
+	blocks := make(map[int32][]int32)
+	blocked := make(map[int32]bool)
+	var blockers []int32
+	for _, w := range waits {
+		if len(blocks[w.BlockerPID]) == 0 {
+			blockers = append(blockers, w.BlockerPID)
+		}
+		blocks[w.BlockerPID] = append(blocks[w.BlockerPID], w.BlockedPID)
+		blocked[w.BlockedPID] = true
+	}
+
+	chains := make([][]int32, 0)
+	var walk func(path []int32)
+	walk = func(path []int32) {
+		last := path[len(path)-1]
+		extended := false
+		for _, next := range blocks[last] {
+			if slices.Contains(path, next) {
+				continue // Deadlock cycle; the server will break it
+			}
+			extended = true
+			walk(append(slices.Clone(path), next))
+		}
+		if !extended && len(path) > 1 {
+			chains = append(chains, path)
+		}
+	}
+	for _, pid := range blockers {
+		if !blocked[pid] {
+			walk([]int32{pid})
+		}
+	}
+	return chains
+}
+
+// SignalBackend cancels the current query of a backend (pg_cancel_backend) or,
+// with terminate, closes its connection (pg_terminate_backend). Only backends
+// connected to the current database can be signalled. Returns false if the
+// backend does not exist in this database or the signal was not delivered.
+func (i *Introspector) SignalBackend(ctx context.Context, pid int32, terminate bool) (bool, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	fn := "pg_cancel_backend"
+	if terminate {
+		fn = "pg_terminate_backend"
+	}
+	query := fmt.Sprintf(`
+		SELECT COALESCE((
+			SELECT %s(pid)
+			FROM pg_stat_activity
+			WHERE pid = $1
+			  AND datname = current_database()
+			  AND pid <> pg_backend_pid()
+		), false)
+	`, fn)
+
+	pool := i.getPool()
+	var ok bool
+	if err := pool.QueryRow(ctx, query, pid).Scan(&ok); err != nil {
+		return false, fmt.Errorf("failed to signal backend: %w", err)
+	}
+	return ok, nil
+}
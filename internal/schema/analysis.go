@@ -0,0 +1,313 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OrphanCheck names a suspected (or NOT VALID) foreign key relationship.
+type OrphanCheck struct {
+	Table            string `json:"table"`
+	Column           string `json:"column"`
+	ReferencesTable  string `json:"referencesTable"`
+	ReferencesColumn string `json:"referencesColumn"`
+}
+
+// OrphanReport summarizes referencing rows that have no matching target.
+type OrphanReport struct {
+	OrphanCheck
+	ReferencingRows int64    `json:"referencingRows"` // Rows with a non-NULL referencing value
+	OrphanedRows    int64    `json:"orphanedRows"`
+	SampleValues    []string `json:"sampleValues"` // Up to orphanSampleSize distinct orphaned values
+	CanAddCleanly   bool     `json:"canAddCleanly"`
+}
+
+// orphanSampleSize bounds how many orphaned values are returned for inspection.
+const orphanSampleSize = 10
+
+// CountOrphans counts rows in check.Table whose check.Column value has no
+// match in check.ReferencesTable.check.ReferencesColumn. NULLs are not orphans,
+// matching foreign key semantics.
+func (i *Introspector) CountOrphans(ctx context.Context, check OrphanCheck) (*OrphanReport, error) {
+	for _, name := range []string{check.Table, check.Column, check.ReferencesTable, check.ReferencesColumn} {
+		if !ValidIdentifier(name) {
+			return nil, fmt.Errorf("invalid identifier %q", name)
+		}
+	}
+
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	from := fmt.Sprintf(`
+		FROM %s c
+		WHERE c.%s IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM %s p WHERE p.%s = c.%s)`,
+		sanitizeIdentifier(check.Table),
+		sanitizeIdentifier(check.Column),
+		sanitizeIdentifier(check.ReferencesTable),
+		sanitizeIdentifier(check.ReferencesColumn),
+		sanitizeIdentifier(check.Column))
+
+	countQuery := fmt.Sprintf(`
+		SELECT
+			(SELECT count(*) FROM %s WHERE %s IS NOT NULL),
+			(SELECT count(*) %s)`,
+		sanitizeIdentifier(check.Table), sanitizeIdentifier(check.Column), from)
+
+	report := &OrphanReport{OrphanCheck: check, SampleValues: []string{}}
+
+	pool := i.getPool()
+	if err := pool.QueryRow(ctx, countQuery).Scan(&report.ReferencingRows, &report.OrphanedRows); err != nil {
+		return nil, fmt.Errorf("failed to count orphaned rows: %w", err)
+	}
+	report.CanAddCleanly = report.OrphanedRows == 0
+	if report.CanAddCleanly {
+		return report, nil
+	}
+
+	sampleQuery := fmt.Sprintf(`SELECT DISTINCT c.%s::text %s LIMIT %d`,
+		sanitizeIdentifier(check.Column), from, orphanSampleSize)
+	rows, err := pool.Query(ctx, sampleQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample orphaned rows: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan orphaned value: %w", err)
+		}
+		report.SampleValues = append(report.SampleValues, value)
+	}
+
+	return report, rows.Err()
+}
+
+// InferredRelationship is a probable foreign key that is not declared.
+// Its fields match AddForeignKeyRequest so it can be promoted as-is.
+type InferredRelationship struct {
+	Table            string `json:"table"`
+	Column           string `json:"column"`
+	ReferencesTable  string `json:"referencesTable"`
+	ReferencesColumn string `json:"referencesColumn"`
+}
+
+// InferRelationships finds columns named <name>_id without a foreign key
+// whose prefix matches a table (singular or plural) with a single-column
+// primary key, e.g. orders.user_id -> users.id.
+func InferRelationships(s *Schema) []InferredRelationship {
+	primaryKeys := make(map[string]string, len(s.Tables)) // table -> sole PK column
+	for _, t := range s.Tables {
+		if t.PrimaryKey != nil && len(t.PrimaryKey.Columns) == 1 {
+			primaryKeys[t.Name] = t.PrimaryKey.Columns[0]
+		}
+	}
+
+	inferred := make([]InferredRelationship, 0)
+	for _, t := range s.Tables {
+		declared := make(map[string]bool, len(t.ForeignKeys))
+		for _, fk := range t.ForeignKeys {
+			for _, col := range fk.Columns {
+				declared[col] = true
+			}
+		}
+
+		for _, col := range t.Columns {
+			if declared[col.Name] || !strings.HasSuffix(col.Name, "_id") {
+				continue
+			}
+			prefix := strings.TrimSuffix(col.Name, "_id")
+			for _, candidate := range tableNameCandidates(prefix) {
+				if pk, ok := primaryKeys[candidate]; ok && !(candidate == t.Name && pk == col.Name) {
+					inferred = append(inferred, InferredRelationship{
+						Table:            t.Name,
+						Column:           col.Name,
+						ReferencesTable:  candidate,
+						ReferencesColumn: pk,
+					})
+					break
+				}
+			}
+		}
+	}
+	return inferred
+}
+
+// tableNameCandidates returns likely table names for a column prefix,
+// most specific first.
+func tableNameCandidates(prefix string) []string {
+	candidates := []string{prefix + "s", prefix + "es", prefix}
+	if strings.HasSuffix(prefix, "y") {
+		candidates = append([]string{strings.TrimSuffix(prefix, "y") + "ies"}, candidates...)
+	}
+	return candidates
+}
+
+// Schema warning kinds.
+const (
+	WarningMissingForeignKey = "missing-foreign-key"
+	WarningFKTypeMismatch    = "fk-type-mismatch"
+)
+
+// SchemaWarning flags a likely modeling problem found while introspecting.
+type SchemaWarning struct {
+	Kind    string `json:"kind"`
+	Table   string `json:"table"`
+	Column  string `json:"column"`
+	Message string `json:"message"`
+}
+
+// AnalyzeSchema flags columns named *_id that have no foreign key constraint,
+// and foreign key columns whose type differs from the column they reference.
+func AnalyzeSchema(s *Schema) []SchemaWarning {
+	columnTypes := make(map[string]map[string]string, len(s.Tables)) // table -> column -> type
+	for _, t := range s.Tables {
+		types := make(map[string]string, len(t.Columns))
+		for _, col := range t.Columns {
+			types[col.Name] = columnTypeName(col)
+		}
+		columnTypes[t.Name] = types
+	}
+
+	warnings := make([]SchemaWarning, 0)
+	for _, t := range s.Tables {
+		declared := make(map[string]bool, len(t.ForeignKeys))
+		for _, fk := range t.ForeignKeys {
+			for idx, col := range fk.Columns {
+				declared[col] = true
+
+				refTypes, ok := columnTypes[fk.ReferencesTable]
+				if !ok || idx >= len(fk.ReferencesColumns) {
+					continue // Target outside the introspected schema
+				}
+				refCol := fk.ReferencesColumns[idx]
+				if own, ref := columnTypes[t.Name][col], refTypes[refCol]; own != ref {
+					warnings = append(warnings, SchemaWarning{
+						Kind:    WarningFKTypeMismatch,
+						Table:   t.Name,
+						Column:  col,
+						Message: fmt.Sprintf("Type %s does not match %s.%s (%s)", own, fk.ReferencesTable, refCol, ref),
+					})
+				}
+			}
+		}
+
+		for _, col := range t.Columns {
+			if strings.HasSuffix(col.Name, "_id") && !declared[col.Name] {
+				warnings = append(warnings, SchemaWarning{
+					Kind:    WarningMissingForeignKey,
+					Table:   t.Name,
+					Column:  col.Name,
+					Message: "Column looks like a reference but has no foreign key constraint",
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+// columnTypeName renders a column's type with its modifiers, e.g. varchar(255),
+// so that differently sized references are reported as mismatches.
+func columnTypeName(col Column) string {
+	switch {
+	case col.CharacterMaximumLength != nil:
+		return fmt.Sprintf("%s(%d)", col.DataType, *col.CharacterMaximumLength)
+	case col.DataType == "numeric" && col.NumericPrecision != nil && col.NumericScale != nil:
+		return fmt.Sprintf("numeric(%d,%d)", *col.NumericPrecision, *col.NumericScale)
+	}
+	return col.DataType
+}
+
+// ForeignKeyCycle is a group of tables whose foreign keys reference each other
+// in a loop. Loading such tables needs deferred constraints, or inserting with
+// the keys NULL and filling them in a second pass.
+type ForeignKeyCycle struct {
+	Tables      []string `json:"tables"`      // Sorted by name
+	ForeignKeys []string `json:"foreignKeys"` // Constraints within the cycle, as table.constraint
+	Deferrable  bool     `json:"deferrable"`  // Every constraint in the cycle is DEFERRABLE
+}
+
+// FindForeignKeyCycles returns the strongly connected components of the
+// foreign key graph that contain more than one table. Self-references such as
+// parent_id are not reported: rows can be ordered within a single table.
+func FindForeignKeyCycles(tables []Table) []ForeignKeyCycle {
+	refs := make(map[string][]string, len(tables))
+	for _, t := range tables {
+		for _, fk := range t.ForeignKeys {
+			if fk.ReferencesTable != t.Name {
+				refs[t.Name] = append(refs[t.Name], fk.ReferencesTable)
+			}
+		}
+	}
+
+	// Tarjan's algorithm
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	var strongConnect func(name string)
+	strongConnect = func(name string) {
+		index[name] = len(index)
+		lowlink[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, ref := range refs[name] {
+			if _, visited := index[ref]; !visited {
+				strongConnect(ref)
+				lowlink[name] = min(lowlink[name], lowlink[ref])
+			} else if onStack[ref] {
+				lowlink[name] = min(lowlink[name], index[ref])
+			}
+		}
+
+		if lowlink[name] == index[name] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == name {
+					break
+				}
+			}
+			if len(component) > 1 {
+				components = append(components, component)
+			}
+		}
+	}
+	for _, t := range tables {
+		if _, visited := index[t.Name]; !visited {
+			strongConnect(t.Name)
+		}
+	}
+
+	cycles := make([]ForeignKeyCycle, 0, len(components))
+	for _, component := range components {
+		sort.Strings(component)
+		members := make(map[string]bool, len(component))
+		for _, name := range component {
+			members[name] = true
+		}
+		cycle := ForeignKeyCycle{Tables: component, ForeignKeys: []string{}, Deferrable: true}
+		for _, t := range tables {
+			if !members[t.Name] {
+				continue
+			}
+			for _, fk := range t.ForeignKeys {
+				if members[fk.ReferencesTable] && fk.ReferencesTable != t.Name {
+					cycle.ForeignKeys = append(cycle.ForeignKeys, t.Name+"."+fk.ConstraintName)
+					cycle.Deferrable = cycle.Deferrable && fk.Deferrable
+				}
+			}
+		}
+		cycles = append(cycles, cycle)
+	}
+	sort.Slice(cycles, func(a, b int) bool { return cycles[a].Tables[0] < cycles[b].Tables[0] })
+	return cycles
+}
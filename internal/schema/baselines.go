@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// BaselinesTable keeps baseline schemas inside the database they describe,
+// so every deployment environment checks drift against the same copy. It is
+// created on first use and hidden from the introspected schema.
+const BaselinesTable = "alt_baselines"
+
+// ErrBaselineNotFound is returned when no baseline has the requested name.
+var ErrBaselineNotFound = errors.New("baseline not found")
+
+// SaveBaseline stores s as the baseline called name, replacing any previous one.
+func (i *Introspector) SaveBaseline(ctx context.Context, name string, s *Schema) (time.Time, error) {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	pool := i.getPool()
+	table := sanitizeIdentifier(BaselinesTable)
+	create := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			name text PRIMARY KEY,
+			schema jsonb NOT NULL,
+			created_at timestamptz NOT NULL DEFAULT now()
+		)
+	`, table)
+	if _, err := pool.Exec(ctx, create); err != nil {
+		return time.Time{}, fmt.Errorf("failed to create baselines table: %w", err)
+	}
+
+	var createdAt time.Time
+	upsert := "INSERT INTO " + table + " (name, schema) VALUES ($1, $2) " +
+		"ON CONFLICT (name) DO UPDATE SET schema = EXCLUDED.schema, created_at = now() RETURNING created_at"
+	if err := pool.QueryRow(ctx, upsert, name, body).Scan(&createdAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save baseline: %w", err)
+	}
+	return createdAt, nil
+}
+
+// GetBaseline loads the baseline called name and when it was saved.
+// Returns ErrBaselineNotFound if there is none.
+func (i *Introspector) GetBaseline(ctx context.Context, name string) (*Schema, time.Time, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	pool := i.getPool()
+	var exists bool
+	lookup := `SELECT to_regclass(format('public.%I', $1::text)) IS NOT NULL`
+	if err := pool.QueryRow(ctx, lookup, BaselinesTable).Scan(&exists); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to check for baselines table: %w", err)
+	}
+	if !exists {
+		return nil, time.Time{}, fmt.Errorf("%w: %s", ErrBaselineNotFound, name)
+	}
+
+	var body []byte
+	var createdAt time.Time
+	query := "SELECT schema, created_at FROM " + sanitizeIdentifier(BaselinesTable) + " WHERE name = $1"
+	err := pool.QueryRow(ctx, query, name).Scan(&body, &createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, time.Time{}, fmt.Errorf("%w: %s", ErrBaselineNotFound, name)
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get baseline: %w", err)
+	}
+
+	var s Schema
+	if err := json.Unmarshal(body, &s); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to decode baseline: %w", err)
+	}
+	return &s, createdAt, nil
+}
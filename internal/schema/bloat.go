@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// BloatEstimate compares an object's size on disk with the size its live
+// rows should need. Estimates rely on pg_stats, so run ANALYZE first.
+type BloatEstimate struct {
+	Name          string  `json:"name"`
+	Table         string  `json:"table"`
+	ActualBytes   int64   `json:"actualBytes"`
+	ExpectedBytes int64   `json:"expectedBytes"`
+	BloatBytes    int64   `json:"bloatBytes"` // Reclaimable by VACUUM FULL / REINDEX
+	BloatRatio    float64 `json:"bloatRatio"` // BloatBytes / ActualBytes
+}
+
+// BloatReport holds bloat estimates for tables and btree indexes.
+type BloatReport struct {
+	Tables                []BloatEstimate `json:"tables"`
+	Indexes               []BloatEstimate `json:"indexes"`
+	TotalReclaimableBytes int64           `json:"totalReclaimableBytes"`
+}
+
+// tableBloatQuery is the widely used pgexperts estimate: expected heap size is
+// derived from row count, average column widths, null bitmap, and alignment.
+const tableBloatQuery = `
+	WITH constants AS (
+		SELECT current_setting('block_size')::numeric AS bs, 23 AS hdr, 8 AS ma
+	),
+	null_headers AS (
+		SELECT
+			hdr + 1 + (sum(CASE WHEN null_frac <> 0 THEN 1 ELSE 0 END) / 8) AS nullhdr,
+			sum((1 - null_frac) * avg_width) AS datawidth,
+			max(null_frac) AS maxfracsum,
+			tablename, hdr, ma, bs
+		FROM pg_stats CROSS JOIN constants
+		WHERE schemaname = 'public' AND NOT inherited
+		GROUP BY tablename, hdr, ma, bs
+	),
+	data_headers AS (
+		SELECT
+			ma, bs, tablename,
+			(datawidth + (hdr + ma - (CASE WHEN hdr % ma = 0 THEN ma ELSE hdr % ma END)))::numeric AS datahdr,
+			(maxfracsum * (nullhdr + ma - (CASE WHEN nullhdr % ma = 0 THEN ma ELSE nullhdr % ma END))) AS nullhdr2
+		FROM null_headers
+	),
+	estimates AS (
+		SELECT
+			c.relname,
+			c.relpages::numeric * bs AS actual_bytes,
+			ceil(c.reltuples * (datahdr + nullhdr2 + 4 + ma -
+				(CASE WHEN datahdr % ma = 0 THEN ma ELSE datahdr % ma END)) / (bs - 20)) * bs AS expected_bytes
+		FROM data_headers d
+		JOIN pg_class c ON c.relname = d.tablename
+		JOIN pg_namespace n ON n.oid = c.relnamespace AND n.nspname = 'public'
+		WHERE c.relkind = 'r'
+	)
+	SELECT relname, relname, actual_bytes::int8, LEAST(expected_bytes, actual_bytes)::int8
+	FROM estimates
+	ORDER BY actual_bytes - expected_bytes DESC
+`
+
+// indexBloatQuery estimates btree size from tuple count and key widths:
+// each entry carries an 8-byte header plus aligned key data and a 4-byte
+// line pointer, with pages filled to the default 90%.
+const indexBloatQuery = `
+	WITH index_info AS (
+		SELECT
+			ic.relname AS index_name,
+			t.relname AS table_name,
+			ic.relpages::numeric AS relpages,
+			ic.reltuples::numeric AS reltuples,
+			current_setting('block_size')::numeric AS bs,
+			(SELECT COALESCE(sum(s.avg_width), 0)
+			 FROM pg_attribute a
+			 JOIN pg_stats s ON s.schemaname = n.nspname AND s.tablename = t.relname AND s.attname = a.attname
+			 WHERE a.attrelid = t.oid AND a.attnum = ANY(i.indkey::int2[])) AS key_width
+		FROM pg_index i
+		JOIN pg_class ic ON ic.oid = i.indexrelid
+		JOIN pg_class t ON t.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_am am ON am.oid = ic.relam
+		WHERE n.nspname = 'public' AND am.amname = 'btree'
+	),
+	estimates AS (
+		SELECT
+			index_name, table_name,
+			relpages * bs AS actual_bytes,
+			GREATEST(ceil(reltuples * (12 + ceil(key_width / 8.0) * 8) / ((bs - 40) * 0.9)), 1) * bs AS expected_bytes
+		FROM index_info
+	)
+	SELECT index_name, table_name, actual_bytes::int8, LEAST(expected_bytes, actual_bytes)::int8
+	FROM estimates
+	ORDER BY actual_bytes - expected_bytes DESC
+`
+
+// GetBloatReport estimates reclaimable space for tables and btree indexes.
+func (i *Introspector) GetBloatReport(ctx context.Context) (*BloatReport, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	tables, err := i.queryBloat(ctx, tableBloatQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate table bloat: %w", err)
+	}
+	indexes, err := i.queryBloat(ctx, indexBloatQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate index bloat: %w", err)
+	}
+
+	report := &BloatReport{Tables: tables, Indexes: indexes}
+	for _, est := range append(tables, indexes...) {
+		report.TotalReclaimableBytes += est.BloatBytes
+	}
+	return report, nil
+}
+
+func (i *Introspector) queryBloat(ctx context.Context, query string) ([]BloatEstimate, error) {
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	estimates := make([]BloatEstimate, 0)
+	for rows.Next() {
+		var est BloatEstimate
+		if err := rows.Scan(&est.Name, &est.Table, &est.ActualBytes, &est.ExpectedBytes); err != nil {
+			return nil, err
+		}
+		est.BloatBytes = est.ActualBytes - est.ExpectedBytes
+		if est.ActualBytes > 0 {
+			est.BloatRatio = float64(est.BloatBytes) / float64(est.ActualBytes)
+		}
+		estimates = append(estimates, est)
+	}
+	return estimates, rows.Err()
+}
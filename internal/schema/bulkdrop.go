@@ -0,0 +1,121 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// BulkDropPlan is the order and statements for dropping several tables at once.
+type BulkDropPlan struct {
+	// Tables lists the tables in drop order, dependents first
+	Tables []string `json:"tables"`
+	// Cyclic lists tables that reference each other in a cycle, or depend on
+	// such tables; they are dropped together in the first statement.
+	Cyclic []string `json:"cyclic"`
+	// External lists objects outside the set that depend on it: foreign keys
+	// on other tables and views. Dropping requires CASCADE, which drops them too.
+	External   []DependentObject `json:"external"`
+	Cascade    bool              `json:"cascade"`
+	Statements []string          `json:"statements"`
+}
+
+// BuildDropTablesDDL constructs a DROP TABLE statement for several tables,
+// which PostgreSQL drops together so references among them need no CASCADE.
+func BuildDropTablesDDL(tableNames []string, cascade bool) (string, error) {
+	if len(tableNames) == 0 {
+		return "", fmt.Errorf("at least one table is required")
+	}
+	quoted := make([]string, len(tableNames))
+	for idx, name := range tableNames {
+		if !ValidIdentifier(name) {
+			return "", fmt.Errorf("invalid table name")
+		}
+		quoted[idx] = sanitizeIdentifier(name)
+	}
+	query := "DROP TABLE " + strings.Join(quoted, ", ")
+	if cascade {
+		query += " CASCADE"
+	}
+	return query, nil
+}
+
+// PlanBulkDrop orders tables so each is dropped before the tables it
+// references, and lists the objects outside the set that would block the drop
+// without cascade. Returns ErrTableNotFound if a table does not exist.
+func (i *Introspector) PlanBulkDrop(ctx context.Context, tableNames []string, cascade bool) (*BulkDropPlan, error) {
+	plan := &BulkDropPlan{Tables: []string{}, Cyclic: []string{}, External: []DependentObject{}, Cascade: cascade}
+
+	// Tables with dependents outside the set are the only ones needing CASCADE
+	needsCascade := make(map[string]bool)
+	for _, name := range tableNames {
+		dependents, err := i.GetTableDependents(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, name)
+		}
+		for _, d := range dependents {
+			if d.Kind == "foreign-key" && slices.Contains(tableNames, d.Table) {
+				continue
+			}
+			if !slices.Contains(plan.External, d) {
+				plan.External = append(plan.External, d)
+			}
+			needsCascade[name] = true
+		}
+	}
+
+	graph, err := i.GetDependencyGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []DependencyNode
+	for _, name := range tableNames {
+		nodes = append(nodes, dependencyNode("r", name))
+	}
+	var edges []DependencyEdge
+	for _, e := range graph.Edges {
+		if e.Via == "foreign-key" && slices.ContainsFunc(nodes, func(n DependencyNode) bool { return n.ID == e.From }) &&
+			slices.ContainsFunc(nodes, func(n DependencyNode) bool { return n.ID == e.To }) {
+			edges = append(edges, e)
+		}
+	}
+	order, cyclic := topologicalOrder(nodes, edges)
+
+	name := func(id string) string { return strings.TrimPrefix(id, NodeTable+":") }
+	if len(cyclic) > 0 {
+		group := make([]string, len(cyclic))
+		groupCascade := false
+		for idx, id := range cyclic {
+			group[idx] = name(id)
+			groupCascade = groupCascade || needsCascade[group[idx]]
+		}
+		query, err := BuildDropTablesDDL(group, cascade && groupCascade)
+		if err != nil {
+			return nil, err
+		}
+		plan.Cyclic = group
+		plan.Tables = append(plan.Tables, group...)
+		plan.Statements = append(plan.Statements, query)
+	}
+	// order is dependencies-first; drop in reverse
+	for _, id := range slices.Backward(order) {
+		table := name(id)
+		query, err := BuildDropTableDDL(table, cascade && needsCascade[table])
+		if err != nil {
+			return nil, err
+		}
+		plan.Tables = append(plan.Tables, table)
+		plan.Statements = append(plan.Statements, query)
+	}
+	return plan, nil
+}
+
+// DropTables runs a plan from PlanBulkDrop in one transaction.
+func (i *Introspector) DropTables(ctx context.Context, plan *BulkDropPlan) error {
+	return i.applyChange(ctx, Change{
+		Description:  "Drop tables " + strings.Join(plan.Tables, ", "),
+		Statements:   plan.Statements,
+		Irreversible: "dropping a table discards its rows",
+	})
+}
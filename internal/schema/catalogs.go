@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetSystemTables returns the tables and views of pg_catalog and
+// information_schema with their columns. Names are schema-qualified, e.g.
+// pg_catalog.pg_class, so they cannot collide with tables in public. Only
+// columns are populated; catalogs are read-only and have no user constraints.
+func (i *Introspector) GetSystemTables(ctx context.Context) ([]Table, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			n.nspname || '.' || c.relname,
+			COALESCE(obj_description(c.oid, 'pg_class'), ''),
+			GREATEST(c.reltuples, 0)::bigint,
+			a.attname,
+			format_type(a.atttypid, a.atttypmod),
+			NOT a.attnotnull,
+			ty.typtype::text,
+			ty.typcategory::text,
+			COALESCE(col_description(c.oid, a.attnum), '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+		JOIN pg_type ty ON ty.oid = a.atttypid
+		WHERE n.nspname IN ('pg_catalog', 'information_schema')
+		  AND c.relkind IN ('r', 'v')
+		ORDER BY n.nspname, c.relname, a.attnum
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make([]Table, 0, 256)
+	for rows.Next() {
+		var name, comment, typType, typCategory string
+		var estimatedRows int64
+		var col Column
+		if err := rows.Scan(&name, &comment, &estimatedRows, &col.Name, &col.DataType, &col.IsNullable,
+			&typType, &typCategory, &col.Comment); err != nil {
+			return nil, fmt.Errorf("failed to scan system table column: %w", err)
+		}
+		col.TypeCategory = typeCategory(typType, typCategory)
+
+		// Rows arrive grouped by table
+		if len(tables) == 0 || tables[len(tables)-1].Name != name {
+			tables = append(tables, Table{
+				Name:              name,
+				Comment:           comment,
+				EstimatedRows:     estimatedRows,
+				IsSystem:          true,
+				ForeignKeys:       []ForeignKey{},
+				Indexes:           []Index{},
+				UniqueConstraints: []UniqueConstraint{},
+				CheckConstraints:  []CheckConstraint{},
+				Exclusions:        []ExclusionConstraint{},
+				Triggers:          []Trigger{},
+			})
+		}
+		t := &tables[len(tables)-1]
+		col.Position = len(t.Columns) + 1
+		t.Columns = append(t.Columns, col)
+	}
+
+	return tables, rows.Err()
+}
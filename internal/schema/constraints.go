@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// TableConstraint is any named constraint on a table, with the definition
+// needed to recreate it (ALTER TABLE ... ADD CONSTRAINT name definition).
+type TableConstraint struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`       // PRIMARY KEY, FOREIGN KEY, UNIQUE, CHECK, or EXCLUDE
+	Columns    []string `json:"columns"`    // Constrained columns; empty for expression-only checks
+	Definition string   `json:"definition"` // As in pg_get_constraintdef, e.g. "CHECK ((qty > 0))"
+	Deferrable bool     `json:"deferrable"`
+	Deferred   bool     `json:"deferred"`  // INITIALLY DEFERRED
+	Validated  bool     `json:"validated"` // False after ADD CONSTRAINT ... NOT VALID
+	Comment    string   `json:"comment,omitempty"`
+}
+
+// GetTableConstraints returns every primary key, foreign key, unique, check,
+// and exclusion constraint on a table, ordered by type and then name.
+func (i *Introspector) GetTableConstraints(ctx context.Context, tableName string) ([]TableConstraint, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			con.conname,
+			CASE con.contype
+				WHEN 'p' THEN 'PRIMARY KEY'
+				WHEN 'f' THEN 'FOREIGN KEY'
+				WHEN 'u' THEN 'UNIQUE'
+				WHEN 'c' THEN 'CHECK'
+				WHEN 'x' THEN 'EXCLUDE'
+			END,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			pg_get_constraintdef(con.oid, true),
+			con.condeferrable,
+			con.condeferred,
+			con.convalidated,
+			COALESCE(obj_description(con.oid, 'pg_constraint'), '')
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = 'public'
+		  AND t.relname = $1
+		  AND con.contype IN ('p', 'f', 'u', 'c', 'x')
+		ORDER BY array_position(ARRAY['p', 'f', 'u', 'c', 'x'], con.contype::text), con.conname
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table constraints: %w", err)
+	}
+	defer rows.Close()
+
+	constraints := make([]TableConstraint, 0)
+	for rows.Next() {
+		var c TableConstraint
+		if err := rows.Scan(&c.Name, &c.Type, &c.Columns, &c.Definition, &c.Deferrable, &c.Deferred, &c.Validated, &c.Comment); err != nil {
+			return nil, fmt.Errorf("failed to scan table constraint: %w", err)
+		}
+		constraints = append(constraints, c)
+	}
+
+	return constraints, rows.Err()
+}
@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// databaseEncodings lists the server encodings CREATE DATABASE may request.
+var databaseEncodings = map[string]bool{
+	"UTF8": true, "SQL_ASCII": true, "LATIN1": true, "LATIN2": true, "LATIN9": true,
+	"WIN1250": true, "WIN1251": true, "WIN1252": true, "EUC_JP": true, "EUC_KR": true,
+}
+
+// CreateDatabaseRequest represents a request to create a database on the
+// current server. Empty options use the server defaults; an encoding or
+// locale that differs from the template's generally needs template0.
+type CreateDatabaseRequest struct {
+	Name     string `json:"name"`
+	Template string `json:"template,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Locale   string `json:"locale,omitempty"` // Sets both LC_COLLATE and LC_CTYPE, e.g. en_US.UTF-8
+}
+
+// BuildCreateDatabaseDDL constructs a CREATE DATABASE statement safely.
+func BuildCreateDatabaseDDL(req CreateDatabaseRequest) (string, error) {
+	if !ValidIdentifier(req.Name) {
+		return "", fmt.Errorf("invalid database name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
+	}
+	query := "CREATE DATABASE " + sanitizeIdentifier(req.Name)
+	if req.Template != "" {
+		if !ValidIdentifier(req.Template) {
+			return "", fmt.Errorf("invalid template name")
+		}
+		query += " TEMPLATE " + sanitizeIdentifier(req.Template)
+	}
+	if req.Encoding != "" {
+		if !databaseEncodings[req.Encoding] {
+			return "", fmt.Errorf("unsupported encoding %q", req.Encoding)
+		}
+		query += " ENCODING " + QuoteLiteral(req.Encoding)
+	}
+	if req.Locale != "" {
+		if len(req.Locale) > 63 {
+			return "", fmt.Errorf("locale name is too long")
+		}
+		query += " LOCALE " + QuoteLiteral(req.Locale)
+	}
+	return query, nil
+}
+
+// BuildRenameDatabaseDDL constructs an ALTER DATABASE ... RENAME TO statement safely.
+func BuildRenameDatabaseDDL(name, newName string) (string, error) {
+	if !ValidIdentifier(name) {
+		return "", fmt.Errorf("invalid database name")
+	}
+	if !ValidIdentifier(newName) {
+		return "", fmt.Errorf("invalid new database name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
+	}
+	return fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", sanitizeIdentifier(name), sanitizeIdentifier(newName)), nil
+}
+
+// CreateDatabase creates a database on the server of the current connection.
+// Database-level changes are not recorded for undo, whose history is per database.
+func (i *Introspector) CreateDatabase(ctx context.Context, req CreateDatabaseRequest) error {
+	query, err := BuildCreateDatabaseDDL(req)
+	if err != nil {
+		return err
+	}
+
+	return i.execDDL(ctx, query)
+}
+
+// RenameDatabase renames another database on the server. PostgreSQL refuses
+// while anyone, including this tool, is connected to it.
+func (i *Introspector) RenameDatabase(ctx context.Context, name, newName string) error {
+	if name == i.CurrentDatabase() {
+		return fmt.Errorf("cannot rename the current database")
+	}
+	query, err := BuildRenameDatabaseDDL(name, newName)
+	if err != nil {
+		return err
+	}
+
+	return i.execDDL(ctx, query)
+}
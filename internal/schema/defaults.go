@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Column default kinds.
+const (
+	DefaultSequence   = "sequence"   // nextval('seq'::regclass); Value is the sequence
+	DefaultNow        = "now"        // Current date/time; Value is now, current_date, ...
+	DefaultLiteral    = "literal"    // A constant; Value is its text without quotes
+	DefaultNull       = "null"       // An explicit NULL, usually NULL::type
+	DefaultFunction   = "function"   // A bare function call; Value is the function name
+	DefaultExpression = "expression" // Anything else; Value is the raw text
+)
+
+// ColumnDefault is a column default classified for semantic comparison, so
+// that e.g. now() and CURRENT_TIMESTAMP, or 'a' and 'a'::text, compare equal.
+type ColumnDefault struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+	Cast  string `json:"cast,omitempty"` // Explicit cast, e.g. character varying
+}
+
+var (
+	nextvalPattern  = regexp.MustCompile(`^nextval\('((?:[^']|'')+)'(?:::regclass)?\)$`)
+	functionPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*)\(\)$`)
+	numberPattern   = regexp.MustCompile(`^\(?-?[0-9]+(?:\.[0-9]+)?(?:[eE][-+]?[0-9]+)?\)?$`)
+	// castPattern matches a trailing type cast such as ::character varying(20)[]
+	castPattern = regexp.MustCompile(`^::([A-Za-z_][A-Za-z0-9_ ".,()\[\]]*)$`)
+)
+
+// nowFunctions maps the spellings PostgreSQL keeps for time defaults to a
+// canonical name. now(), CURRENT_TIMESTAMP, and transaction_timestamp() agree.
+var nowFunctions = map[string]string{
+	"now()":                   "now",
+	"current_timestamp":       "now",
+	"transaction_timestamp()": "now",
+	"statement_timestamp()":   "statement_timestamp",
+	"clock_timestamp()":       "clock_timestamp",
+	"current_date":            "current_date",
+	"current_time":            "current_time",
+	"localtimestamp":          "localtimestamp",
+	"localtime":               "localtime",
+}
+
+// ParseDefault classifies a column default expression as reported by
+// information_schema.columns.column_default.
+func ParseDefault(raw string) ColumnDefault {
+	expr := strings.TrimSpace(raw)
+
+	if m := nextvalPattern.FindStringSubmatch(expr); m != nil {
+		return ColumnDefault{Kind: DefaultSequence, Value: strings.ReplaceAll(m[1], "''", "'")}
+	}
+
+	body, cast := splitCast(expr)
+	lower := strings.ToLower(body)
+	if name, ok := nowFunctions[lower]; ok {
+		return ColumnDefault{Kind: DefaultNow, Value: name, Cast: cast}
+	}
+	switch {
+	case lower == "null":
+		return ColumnDefault{Kind: DefaultNull, Cast: cast}
+	case lower == "true" || lower == "false":
+		return ColumnDefault{Kind: DefaultLiteral, Value: lower, Cast: cast}
+	case numberPattern.MatchString(body):
+		return ColumnDefault{Kind: DefaultLiteral, Value: strings.Trim(body, "()"), Cast: cast}
+	case strings.HasPrefix(body, "'"):
+		if value, ok := unquoteLiteral(body); ok {
+			return ColumnDefault{Kind: DefaultLiteral, Value: value, Cast: cast}
+		}
+	}
+	if m := functionPattern.FindStringSubmatch(body); m != nil && cast == "" {
+		return ColumnDefault{Kind: DefaultFunction, Value: m[1]}
+	}
+
+	return ColumnDefault{Kind: DefaultExpression, Value: expr}
+}
+
+// splitCast separates a single trailing ::type from a quoted literal or
+// simple value. Expressions with operators after the cast are left whole.
+func splitCast(expr string) (body, cast string) {
+	end := 0
+	if strings.HasPrefix(expr, "'") {
+		// Skip past the literal so a :: inside the quotes is not mistaken for a cast
+		end = literalEnd(expr)
+		if end < 0 {
+			return expr, ""
+		}
+	}
+	idx := strings.Index(expr[end:], "::")
+	if idx < 0 {
+		return expr, ""
+	}
+	idx += end
+	if m := castPattern.FindStringSubmatch(expr[idx:]); m != nil {
+		return expr[:idx], m[1]
+	}
+	return expr, ""
+}
+
+// literalEnd returns the index just past the closing quote of the string
+// literal that starts expr, or -1 if it is unterminated.
+func literalEnd(expr string) int {
+	for i := 1; i < len(expr); i++ {
+		if expr[i] != '\'' {
+			continue
+		}
+		if i+1 < len(expr) && expr[i+1] == '\'' {
+			i++ // Escaped quote
+			continue
+		}
+		return i + 1
+	}
+	return -1
+}
+
+// unquoteLiteral returns the contents of a lone single-quoted literal.
+func unquoteLiteral(s string) (string, bool) {
+	if literalEnd(s) != len(s) {
+		return "", false
+	}
+	return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), true
+}
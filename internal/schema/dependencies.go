@@ -0,0 +1,319 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Dependency node kinds.
+const (
+	NodeTable            = "table"
+	NodeView             = "view"
+	NodeMaterializedView = "materialized view"
+	NodeFunction         = "function"
+)
+
+// DependencyNode is one object in the dependency graph.
+// IDs are "<kind>:<name>" since a function and a table may share a name.
+type DependencyNode struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// DependencyEdge records that From depends on To, so To must exist first.
+type DependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Via  string `json:"via"` // foreign-key, view, or trigger
+}
+
+// DependencyGraph holds objects, their dependencies, and a safe creation order.
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+	// Order lists node IDs dependencies-first: create in this order, drop in reverse.
+	Order []string `json:"order"`
+	// Cyclic lists node IDs that could not be ordered because they sit on, or
+	// depend on, a dependency cycle (e.g. two tables referencing each other).
+	Cyclic []string `json:"cyclic"`
+}
+
+// dependencyQuery returns (from kind, from name, to kind, to name, via) rows:
+// foreign keys between tables, view and materialized view references to
+// relations and functions (pg_depend via pg_rewrite), and trigger functions.
+const dependencyQuery = `
+	SELECT 'r', t.relname, 'r', rt.relname, 'foreign-key'
+	FROM pg_constraint con
+	JOIN pg_class t ON t.oid = con.conrelid
+	JOIN pg_class rt ON rt.oid = con.confrelid
+	JOIN pg_namespace n ON n.oid = t.relnamespace
+	JOIN pg_namespace rn ON rn.oid = rt.relnamespace
+	WHERE con.contype = 'f'
+	  AND n.nspname = 'public' AND rn.nspname = 'public'
+	  AND NOT t.relispartition
+
+	UNION
+
+	SELECT v.relkind::text, v.relname, c.relkind::text, c.relname, 'view'
+	FROM pg_depend d
+	JOIN pg_rewrite rw ON rw.oid = d.objid
+	JOIN pg_class v ON v.oid = rw.ev_class
+	JOIN pg_class c ON c.oid = d.refobjid
+	JOIN pg_namespace vn ON vn.oid = v.relnamespace
+	JOIN pg_namespace cn ON cn.oid = c.relnamespace
+	WHERE d.classid = 'pg_rewrite'::regclass
+	  AND d.refclassid = 'pg_class'::regclass
+	  AND v.oid <> c.oid
+	  AND v.relkind IN ('v', 'm')
+	  AND c.relkind IN ('r', 'p', 'v', 'm')
+	  AND vn.nspname = 'public' AND cn.nspname = 'public'
+
+	UNION
+
+	SELECT v.relkind::text, v.relname, 'f', p.proname, 'view'
+	FROM pg_depend d
+	JOIN pg_rewrite rw ON rw.oid = d.objid
+	JOIN pg_class v ON v.oid = rw.ev_class
+	JOIN pg_proc p ON p.oid = d.refobjid
+	JOIN pg_namespace vn ON vn.oid = v.relnamespace
+	JOIN pg_namespace pn ON pn.oid = p.pronamespace
+	WHERE d.classid = 'pg_rewrite'::regclass
+	  AND d.refclassid = 'pg_proc'::regclass
+	  AND v.relkind IN ('v', 'm')
+	  AND vn.nspname = 'public' AND pn.nspname = 'public'
+
+	UNION
+
+	SELECT 'r', t.relname, 'f', p.proname, 'trigger'
+	FROM pg_trigger tg
+	JOIN pg_class t ON t.oid = tg.tgrelid
+	JOIN pg_proc p ON p.oid = tg.tgfoid
+	JOIN pg_namespace n ON n.oid = t.relnamespace
+	JOIN pg_namespace pn ON pn.oid = p.pronamespace
+	WHERE NOT tg.tgisinternal
+	  AND n.nspname = 'public' AND pn.nspname = 'public'
+`
+
+// GetDependencyGraph returns tables and views in the public schema, the
+// functions they depend on, and the dependencies between them.
+func (i *Introspector) GetDependencyGraph(ctx context.Context) (*DependencyGraph, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	pool := i.getPool()
+
+	// Every table and view is a node, even without dependencies
+	rows, err := pool.Query(ctx, `
+		SELECT c.relkind::text, c.relname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public'
+		  AND c.relkind IN ('r', 'p', 'v', 'm')
+		  AND NOT c.relispartition
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relations: %w", err)
+	}
+	defer rows.Close()
+
+	nodes := make(map[string]DependencyNode)
+	for rows.Next() {
+		var kind, name string
+		if err := rows.Scan(&kind, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan relation: %w", err)
+		}
+		node := dependencyNode(kind, name)
+		nodes[node.ID] = node
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = pool.Query(ctx, dependencyQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	edges := make([]DependencyEdge, 0)
+	for rows.Next() {
+		var fromKind, fromName, toKind, toName, via string
+		if err := rows.Scan(&fromKind, &fromName, &toKind, &toName, &via); err != nil {
+			return nil, fmt.Errorf("failed to scan dependency: %w", err)
+		}
+		from, to := dependencyNode(fromKind, fromName), dependencyNode(toKind, toName)
+		if from.ID == to.ID {
+			continue // Self-referencing foreign keys don't constrain ordering
+		}
+		nodes[from.ID] = from
+		nodes[to.ID] = to
+		edges = append(edges, DependencyEdge{From: from.ID, To: to.ID, Via: via})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	graph := &DependencyGraph{Nodes: make([]DependencyNode, 0, len(nodes)), Edges: edges}
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Slice(graph.Nodes, func(a, b int) bool { return graph.Nodes[a].ID < graph.Nodes[b].ID })
+	sort.Slice(graph.Edges, func(a, b int) bool {
+		if graph.Edges[a].From != graph.Edges[b].From {
+			return graph.Edges[a].From < graph.Edges[b].From
+		}
+		return graph.Edges[a].To < graph.Edges[b].To
+	})
+
+	graph.Order, graph.Cyclic = topologicalOrder(graph.Nodes, graph.Edges)
+	return graph, nil
+}
+
+func dependencyNode(relkind, name string) DependencyNode {
+	kind := NodeTable
+	switch relkind {
+	case "v":
+		kind = NodeView
+	case "m":
+		kind = NodeMaterializedView
+	case "f":
+		kind = NodeFunction
+	}
+	return DependencyNode{ID: kind + ":" + name, Name: name, Kind: kind}
+}
+
+// topologicalOrder sorts nodes so every node comes after the nodes it depends
+// on (Kahn's algorithm, ties broken by ID for stable output). Nodes left over
+// once no dependency-free node remains are blocked by a cycle and returned separately.
+func topologicalOrder(nodes []DependencyNode, edges []DependencyEdge) (order, cyclic []string) {
+	pending := make(map[string]int, len(nodes))         // node -> unmet dependencies
+	dependents := make(map[string][]string, len(nodes)) // node -> nodes depending on it
+	for _, n := range nodes {
+		pending[n.ID] = 0
+	}
+	for _, e := range edges {
+		pending[e.From]++
+		dependents[e.To] = append(dependents[e.To], e.From)
+	}
+
+	var ready []string
+	for _, n := range nodes {
+		if pending[n.ID] == 0 {
+			ready = append(ready, n.ID)
+		}
+	}
+
+	order = make([]string, 0, len(nodes))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, dep := range dependents[id] {
+			pending[dep]--
+			if pending[dep] == 0 {
+				ready = append(ready, dep)
+			}
+		}
+	}
+
+	cyclic = make([]string, 0)
+	for _, n := range nodes {
+		if pending[n.ID] > 0 {
+			cyclic = append(cyclic, n.ID)
+		}
+	}
+	return order, cyclic
+}
+
+// OrderByForeignKeys sorts the named tables so those their foreign keys
+// reference come first, breaking ties by name, for copying or generating rows
+// parents first. Foreign keys to tables not named, and self-references, are
+// ignored. Tables left in cycles are returned at the end, in name order, and
+// listed separately.
+func OrderByForeignKeys(tables map[string]Table, names []string) (ordered, cyclic []string) {
+	included := make(map[string]bool, len(names))
+	for _, name := range names {
+		included[name] = true
+	}
+	pending := slices.Clone(names)
+	slices.Sort(pending)
+
+	ordered = make([]string, 0, len(names))
+	done := make(map[string]bool, len(names))
+	for len(pending) > 0 {
+		progressed := false
+		for idx := 0; idx < len(pending); {
+			name := pending[idx]
+			ready := !slices.ContainsFunc(tables[name].ForeignKeys, func(fk ForeignKey) bool {
+				return included[fk.ReferencesTable] && fk.ReferencesTable != name && !done[fk.ReferencesTable]
+			})
+			if !ready {
+				idx++
+				continue
+			}
+			ordered = append(ordered, name)
+			done[name] = true
+			pending = slices.Delete(pending, idx, idx+1)
+			progressed = true
+		}
+		if !progressed {
+			break
+		}
+	}
+	return append(ordered, pending...), pending
+}
+
+// ViewColumnUsage records that a view or materialized view reads a column.
+type ViewColumnUsage struct {
+	View   string `json:"view"`
+	Kind   string `json:"kind"` // NodeView or NodeMaterializedView
+	Table  string `json:"table"`
+	Column string `json:"column"`
+}
+
+// GetViewColumnUsage returns the table and view columns that views in the
+// public schema read, which cannot be dropped or retyped without dropping
+// the views too.
+func (i *Introspector) GetViewColumnUsage(ctx context.Context) ([]ViewColumnUsage, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT DISTINCT v.relkind::text, v.relname, c.relname, a.attname
+		FROM pg_depend d
+		JOIN pg_rewrite rw ON rw.oid = d.objid
+		JOIN pg_class v ON v.oid = rw.ev_class
+		JOIN pg_class c ON c.oid = d.refobjid
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = d.refobjsubid
+		JOIN pg_namespace vn ON vn.oid = v.relnamespace
+		JOIN pg_namespace cn ON cn.oid = c.relnamespace
+		WHERE d.classid = 'pg_rewrite'::regclass
+		  AND d.refclassid = 'pg_class'::regclass
+		  AND d.refobjsubid > 0
+		  AND v.oid <> c.oid
+		  AND v.relkind IN ('v', 'm')
+		  AND vn.nspname = 'public' AND cn.nspname = 'public'
+		ORDER BY 3, 4, 2
+	`
+	rows, err := i.getPool().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get view column usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make([]ViewColumnUsage, 0)
+	for rows.Next() {
+		var kind string
+		var u ViewColumnUsage
+		if err := rows.Scan(&kind, &u.View, &u.Table, &u.Column); err != nil {
+			return nil, fmt.Errorf("failed to scan view column usage: %w", err)
+		}
+		u.Kind = dependencyNode(kind, u.View).Kind
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
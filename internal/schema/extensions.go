@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// Extension is an installed PostgreSQL extension.
+type Extension struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Schema      string `json:"schema"`
+	Description string `json:"description,omitempty"`
+}
+
+// ExtensionType is a data type installed by an extension, e.g. citext or geometry.
+type ExtensionType struct {
+	Name      string `json:"name"`
+	Extension string `json:"extension"`
+}
+
+// extensionTypesQuery lists types owned by extensions (pg_depend deptype 'e').
+const extensionTypesQuery = `
+	SELECT tn.nspname AS type_schema, t.typname AS type_name, e.extname AS extension
+	FROM pg_type t
+	JOIN pg_namespace tn ON tn.oid = t.typnamespace
+	JOIN pg_depend d ON d.classid = 'pg_type'::regclass AND d.objid = t.oid AND d.deptype = 'e'
+	JOIN pg_extension e ON e.oid = d.refobjid
+`
+
+// ListExtensions returns the extensions installed in the current database.
+func (i *Introspector) ListExtensions(ctx context.Context) ([]Extension, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT e.extname, e.extversion, n.nspname, COALESCE(obj_description(e.oid, 'pg_extension'), '')
+		FROM pg_extension e
+		JOIN pg_namespace n ON n.oid = e.extnamespace
+		ORDER BY e.extname
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list extensions: %w", err)
+	}
+	defer rows.Close()
+
+	extensions := make([]Extension, 0)
+	for rows.Next() {
+		var e Extension
+		if err := rows.Scan(&e.Name, &e.Version, &e.Schema, &e.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan extension: %w", err)
+		}
+		extensions = append(extensions, e)
+	}
+
+	return extensions, rows.Err()
+}
+
+// GetExtensionTypes returns the extension-owned types in the public schema.
+// Types installed into other schemas are skipped, since DDL names them unqualified.
+func (i *Introspector) GetExtensionTypes(ctx context.Context) ([]ExtensionType, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT type_name, extension
+		FROM (` + extensionTypesQuery + `) ext
+		WHERE type_schema = 'public'
+		  AND type_name NOT LIKE '\_%' -- Array types
+		ORDER BY extension, type_name
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get extension types: %w", err)
+	}
+	defer rows.Close()
+
+	types := make([]ExtensionType, 0)
+	for rows.Next() {
+		var t ExtensionType
+		if err := rows.Scan(&t.Name, &t.Extension); err != nil {
+			return nil, fmt.Errorf("failed to scan extension type: %w", err)
+		}
+		types = append(types, t)
+	}
+
+	return types, rows.Err()
+}
+
+// ExtensionTypeInfo describes extension types in the same shape as AllowedTypes.
+func ExtensionTypeInfo(types []ExtensionType) []TypeInfo {
+	info := make([]TypeInfo, len(types))
+	for idx, t := range types {
+		info[idx] = TypeInfo{
+			Name:        t.Name,
+			Description: "From extension " + t.Extension,
+			Category:    "Extension",
+		}
+	}
+	return info
+}
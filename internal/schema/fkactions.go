@@ -0,0 +1,189 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNotForeignKey is returned when a foreign key operation names a
+// constraint that is not a foreign key.
+var ErrNotForeignKey = errors.New("constraint is not a foreign key")
+
+// referentialActions are the ON DELETE and ON UPDATE actions a foreign key may use.
+var referentialActions = []string{"NO ACTION", "RESTRICT", "CASCADE", "SET NULL", "SET DEFAULT"}
+
+// IsReferentialAction reports whether action, in any case, is a valid ON
+// DELETE or ON UPDATE action.
+func IsReferentialAction(action string) bool {
+	return slices.Contains(referentialActions, strings.ToUpper(action))
+}
+
+// ForeignKeyActionsRequest changes what a foreign key does when the row it
+// references is deleted or updated. An empty action keeps the current one.
+type ForeignKeyActionsRequest struct {
+	OnDelete string `json:"onDelete,omitempty"`
+	OnUpdate string `json:"onUpdate,omitempty"`
+}
+
+// BuildRecreateForeignKeyDDL constructs the statements that replace a foreign
+// key with fk: drop it, add it back as NOT VALID so existing rows are not
+// checked under the ADD's lock, then, if validate is set, VALIDATE CONSTRAINT.
+// Run them in one transaction so the table is never left without the key.
+// fk.Comment, which the drop would lose, is set again.
+func BuildRecreateForeignKeyDDL(tableName string, fk ForeignKey, validate bool) ([]string, error) {
+	if !ValidIdentifier(tableName) {
+		return nil, fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(fk.ConstraintName) {
+		return nil, fmt.Errorf("invalid constraint name")
+	}
+	if !ValidIdentifier(fk.ReferencesTable) {
+		return nil, fmt.Errorf("invalid foreign key table name")
+	}
+	if len(fk.Columns) == 0 || len(fk.Columns) != len(fk.ReferencesColumns) {
+		return nil, fmt.Errorf("foreign key needs matching referencing and referenced columns")
+	}
+	columns := make([]string, len(fk.Columns))
+	references := make([]string, len(fk.ReferencesColumns))
+	for idx := range fk.Columns {
+		if !ValidIdentifier(fk.Columns[idx]) || !ValidIdentifier(fk.ReferencesColumns[idx]) {
+			return nil, fmt.Errorf("invalid foreign key column name")
+		}
+		columns[idx] = sanitizeIdentifier(fk.Columns[idx])
+		references[idx] = sanitizeIdentifier(fk.ReferencesColumns[idx])
+	}
+	if !slices.Contains(referentialActions, fk.OnDelete) {
+		return nil, fmt.Errorf("unsupported ON DELETE action %q", fk.OnDelete)
+	}
+	if !slices.Contains(referentialActions, fk.OnUpdate) {
+		return nil, fmt.Errorf("unsupported ON UPDATE action %q", fk.OnUpdate)
+	}
+
+	drop, err := BuildDropConstraintDDL(tableName, fk.ConstraintName, false)
+	if err != nil {
+		return nil, err
+	}
+	definition := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s) ON DELETE %s ON UPDATE %s",
+		strings.Join(columns, ", "),
+		sanitizeIdentifier(fk.ReferencesTable),
+		strings.Join(references, ", "),
+		fk.OnDelete,
+		fk.OnUpdate)
+	deferral, err := deferrableClause(fk.Deferrable, fk.InitiallyDeferred)
+	if err != nil {
+		return nil, err
+	}
+	if deferral != "" {
+		definition += " " + deferral
+	}
+	add, err := BuildAddConstraintDDL(tableName, fk.ConstraintName, definition+" NOT VALID")
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []string{drop, add}
+	if validate {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s",
+			sanitizeIdentifier(tableName),
+			sanitizeIdentifier(fk.ConstraintName)))
+	}
+	if fk.Comment != "" {
+		comment, err := BuildConstraintCommentDDL(tableName, fk.ConstraintName, fk.Comment)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, comment)
+	}
+	return statements, nil
+}
+
+// getForeignKey loads one foreign key of tableName and whether it has been
+// validated. Returns ErrNotForeignKey if there is no such foreign key.
+func (i *Introspector) getForeignKey(ctx context.Context, tableName, constraintName string) (*ForeignKey, bool, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			rt.relname,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			con.confdeltype::text,
+			con.confupdtype::text,
+			con.condeferrable,
+			con.condeferred,
+			con.convalidated,
+			COALESCE(obj_description(con.oid, 'pg_constraint'), '')
+		FROM pg_constraint con
+		JOIN pg_class rt ON rt.oid = con.confrelid
+		WHERE con.conrelid = to_regclass(format('public.%I', $1::text))
+		  AND con.conname = $2
+		  AND con.contype = 'f'
+	`
+	fk := ForeignKey{ConstraintName: constraintName}
+	var onDelete, onUpdate string
+	var validated bool
+	err := i.getPool().QueryRow(ctx, query, tableName, constraintName).Scan(
+		&fk.Columns, &fk.ReferencesTable, &fk.ReferencesColumns, &onDelete, &onUpdate,
+		&fk.Deferrable, &fk.InitiallyDeferred, &validated, &fk.Comment)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("%w: %s", ErrNotForeignKey, constraintName)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get foreign key: %w", err)
+	}
+	fk.OnDelete = referentialAction(onDelete)
+	fk.OnUpdate = referentialAction(onUpdate)
+	return &fk, validated, nil
+}
+
+// SetForeignKeyActions changes a foreign key's ON DELETE and ON UPDATE actions
+// by recreating it in one transaction, and returns the updated foreign key.
+// A key that was NOT VALID stays unvalidated.
+func (i *Introspector) SetForeignKeyActions(ctx context.Context, tableName, constraintName string, req ForeignKeyActionsRequest) (*ForeignKey, error) {
+	current, validated, err := i.getForeignKey(ctx, tableName, constraintName)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *current
+	if req.OnDelete != "" {
+		updated.OnDelete = strings.ToUpper(req.OnDelete)
+	}
+	if req.OnUpdate != "" {
+		updated.OnUpdate = strings.ToUpper(req.OnUpdate)
+	}
+	statements, err := BuildRecreateForeignKeyDDL(tableName, updated, validated)
+	if err != nil {
+		return nil, err
+	}
+	undo, err := BuildRecreateForeignKeyDDL(tableName, *current, validated)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Set actions of foreign key %s on %s", constraintName, tableName),
+		Statements:  statements,
+		Inverse:     undo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
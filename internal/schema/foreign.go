@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ForeignServer is a server defined for a foreign-data wrapper, e.g. postgres_fdw.
+type ForeignServer struct {
+	Name    string   `json:"name"`
+	Wrapper string   `json:"wrapper"`
+	Type    string   `json:"type,omitempty"`
+	Version string   `json:"version,omitempty"`
+	Options []string `json:"options"` // As key=value, e.g. host=replica.internal
+}
+
+// ForeignTable records where a foreign table's rows actually live.
+type ForeignTable struct {
+	Server  string   `json:"server"`
+	Wrapper string   `json:"wrapper"`
+	Options []string `json:"options"` // As key=value, e.g. table_name=orders
+}
+
+func (i *Introspector) getAllForeignServers(ctx context.Context, pool *pgxpool.Pool) ([]ForeignServer, error) {
+	query := `
+		SELECT
+			s.srvname,
+			w.fdwname,
+			COALESCE(s.srvtype, ''),
+			COALESCE(s.srvversion, ''),
+			COALESCE(s.srvoptions, '{}')
+		FROM pg_foreign_server s
+		JOIN pg_foreign_data_wrapper w ON w.oid = s.srvfdw
+		ORDER BY s.srvname
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign servers: %w", err)
+	}
+	defer rows.Close()
+
+	servers := make([]ForeignServer, 0)
+	for rows.Next() {
+		var s ForeignServer
+		if err := rows.Scan(&s.Name, &s.Wrapper, &s.Type, &s.Version, &s.Options); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign server: %w", err)
+		}
+		servers = append(servers, s)
+	}
+
+	return servers, rows.Err()
+}
+
+func (i *Introspector) getAllForeignTables(ctx context.Context, pool *pgxpool.Pool) (map[string]*ForeignTable, error) {
+	query := `
+		SELECT c.relname, s.srvname, w.fdwname, COALESCE(ft.ftoptions, '{}')
+		FROM pg_foreign_table ft
+		JOIN pg_class c ON c.oid = ft.ftrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_foreign_server s ON s.oid = ft.ftserver
+		JOIN pg_foreign_data_wrapper w ON w.oid = s.srvfdw
+		WHERE n.nspname = 'public'
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign tables: %w", err)
+	}
+	defer rows.Close()
+
+	foreignByTable := make(map[string]*ForeignTable)
+	for rows.Next() {
+		var tableName string
+		var ft ForeignTable
+		if err := rows.Scan(&tableName, &ft.Server, &ft.Wrapper, &ft.Options); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign table: %w", err)
+		}
+		foreignByTable[tableName] = &ft
+	}
+
+	return foreignByTable, rows.Err()
+}
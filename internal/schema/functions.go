@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// Function kinds, from pg_proc.prokind.
+const (
+	FunctionKindFunction  = "function"
+	FunctionKindProcedure = "procedure"
+	FunctionKindAggregate = "aggregate"
+	FunctionKindWindow    = "window"
+)
+
+// Function describes a stored function or procedure.
+type Function struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Arguments  string `json:"arguments"`            // As in pg_get_function_arguments, e.g. "a integer, b text DEFAULT ''"
+	ReturnType string `json:"returnType,omitempty"` // Empty for procedures
+	Language   string `json:"language"`
+	Source     string `json:"source"`
+}
+
+// FunctionPage is one page of functions plus the total matching count.
+type FunctionPage struct {
+	Functions []Function `json:"functions"`
+	Total     int        `json:"total"`
+	Limit     int        `json:"limit"`
+	Offset    int        `json:"offset"`
+}
+
+// functionsFrom selects functions in schema $1, skipping those owned by
+// extensions: they are installed by CREATE EXTENSION, not the application.
+const functionsFrom = `
+	FROM pg_proc p
+	JOIN pg_namespace n ON n.oid = p.pronamespace
+	JOIN pg_language l ON l.oid = p.prolang
+	WHERE n.nspname = $1
+	  AND NOT EXISTS (
+		SELECT 1 FROM pg_depend d
+		WHERE d.classid = 'pg_proc'::regclass
+		  AND d.objid = p.oid
+		  AND d.deptype = 'e'
+	  )
+`
+
+// ListFunctions returns one page of the functions and procedures defined in
+// schemaName, ordered by name and signature.
+func (i *Introspector) ListFunctions(ctx context.Context, schemaName string, limit, offset int) (*FunctionPage, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	pool := i.getPool()
+	page := &FunctionPage{Functions: make([]Function, 0), Limit: limit, Offset: offset}
+
+	if err := pool.QueryRow(ctx, "SELECT count(*)"+functionsFrom, schemaName).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count functions: %w", err)
+	}
+
+	query := `
+		SELECT
+			p.proname,
+			p.prokind::text,
+			pg_get_function_arguments(p.oid),
+			COALESCE(pg_get_function_result(p.oid), ''),
+			l.lanname,
+			COALESCE(p.prosrc, '')
+	` + functionsFrom + `
+		ORDER BY p.proname, pg_get_function_identity_arguments(p.oid)
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := pool.Query(ctx, query, schemaName, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list functions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f Function
+		var kind string
+		if err := rows.Scan(&f.Name, &kind, &f.Arguments, &f.ReturnType, &f.Language, &f.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan function: %w", err)
+		}
+		f.Kind = functionKind(kind)
+		page.Functions = append(page.Functions, f)
+	}
+
+	return page, rows.Err()
+}
+
+func functionKind(prokind string) string {
+	switch prokind {
+	case "p":
+		return FunctionKindProcedure
+	case "a":
+		return FunctionKindAggregate
+	case "w":
+		return FunctionKindWindow
+	}
+	return FunctionKindFunction
+}
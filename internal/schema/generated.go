@@ -0,0 +1,186 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidGenerated is returned when a generated column's expression refers
+// to columns that do not exist or are themselves generated.
+var ErrInvalidGenerated = errors.New("invalid generated column")
+
+// Generated expression kinds accepted by BuildGeneratedExpr.
+const (
+	GeneratedColumn     = "column"     // A column of the same table
+	GeneratedText       = "text"       // A string literal
+	GeneratedNumber     = "number"     // A numeric literal
+	GeneratedConcat     = "concat"     // args joined with ||, each as text
+	GeneratedArithmetic = "arithmetic" // args[0] <op> args[1]
+	GeneratedLower      = "lower"      // lower(args[0])
+	GeneratedUpper      = "upper"      // upper(args[0])
+)
+
+// arithmeticOperators are the operators an arithmetic expression may use.
+var arithmeticOperators = []string{"+", "-", "*", "/"}
+
+// maxGeneratedDepth bounds how deeply generated expressions may nest.
+const maxGeneratedDepth = 8
+
+var numberLiteral = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// GeneratedExpr is a node of a generated column expression, built from a
+// fixed grammar so no user-written SQL reaches the definition. For example, a
+// search key lower(first_name || ' ' || last_name) is
+//
+//	{"kind": "lower", "args": [{"kind": "concat", "args": [
+//		{"kind": "column", "column": "first_name"},
+//		{"kind": "text", "value": " "},
+//		{"kind": "column", "column": "last_name"}]}]}
+type GeneratedExpr struct {
+	Kind     string          `json:"kind"`
+	Column   string          `json:"column,omitempty"`   // For column
+	Value    string          `json:"value,omitempty"`    // For text and number
+	Operator string          `json:"operator,omitempty"` // For arithmetic: +, -, *, or /
+	Args     []GeneratedExpr `json:"args,omitempty"`     // For concat, arithmetic, lower, and upper
+}
+
+// BuildGeneratedExpr renders e as SQL, validating every node.
+func BuildGeneratedExpr(e GeneratedExpr) (string, error) {
+	return generatedExpr(e, 0)
+}
+
+func generatedExpr(e GeneratedExpr, depth int) (string, error) {
+	if depth > maxGeneratedDepth {
+		return "", fmt.Errorf("expression is nested too deeply")
+	}
+
+	args := make([]string, len(e.Args))
+	for idx, arg := range e.Args {
+		sql, err := generatedExpr(arg, depth+1)
+		if err != nil {
+			return "", err
+		}
+		args[idx] = sql
+	}
+
+	switch e.Kind {
+	case GeneratedColumn:
+		if !ValidIdentifier(e.Column) {
+			return "", fmt.Errorf("invalid column name %q", e.Column)
+		}
+		return sanitizeIdentifier(e.Column), nil
+	case GeneratedText:
+		return QuoteLiteral(e.Value), nil
+	case GeneratedNumber:
+		if !numberLiteral.MatchString(e.Value) {
+			return "", fmt.Errorf("invalid number %q", e.Value)
+		}
+		if strings.HasPrefix(e.Value, "-") {
+			return "(" + e.Value + ")", nil
+		}
+		return e.Value, nil
+	case GeneratedConcat:
+		if len(args) < 2 {
+			return "", fmt.Errorf("concat needs at least two arguments")
+		}
+		// || between text and another type is only stable, which generated
+		// columns reject, so every non-text operand is cast explicitly
+		for idx, arg := range e.Args {
+			if !slices.Contains([]string{GeneratedText, GeneratedConcat, GeneratedLower, GeneratedUpper}, arg.Kind) {
+				args[idx] = "(" + args[idx] + ")::text"
+			}
+		}
+		return "(" + strings.Join(args, " || ") + ")", nil
+	case GeneratedArithmetic:
+		if !slices.Contains(arithmeticOperators, e.Operator) {
+			return "", fmt.Errorf("unsupported operator %q", e.Operator)
+		}
+		if len(args) != 2 {
+			return "", fmt.Errorf("arithmetic needs exactly two arguments")
+		}
+		return fmt.Sprintf("(%s %s %s)", args[0], e.Operator, args[1]), nil
+	case GeneratedLower, GeneratedUpper:
+		if len(args) != 1 {
+			return "", fmt.Errorf("%s needs exactly one argument", e.Kind)
+		}
+		return fmt.Sprintf("%s(%s)", e.Kind, args[0]), nil
+	default:
+		return "", fmt.Errorf("unsupported expression kind %q", e.Kind)
+	}
+}
+
+// Columns returns the columns e refers to, in order of first use.
+func (e GeneratedExpr) Columns() []string {
+	var columns []string
+	var walk func(GeneratedExpr)
+	walk = func(n GeneratedExpr) {
+		if n.Kind == GeneratedColumn && !slices.Contains(columns, n.Column) {
+			columns = append(columns, n.Column)
+		}
+		for _, arg := range n.Args {
+			walk(arg)
+		}
+	}
+	walk(e)
+	return columns
+}
+
+// checkGeneratedColumns checks that the generated columns among cols refer
+// only to ordinary columns: existing ones of tableName or other new columns.
+// PostgreSQL does not allow a generated column to use another.
+func (i *Introspector) checkGeneratedColumns(ctx context.Context, tableName string, cols []ColumnDef) error {
+	if !slices.ContainsFunc(cols, func(c ColumnDef) bool { return c.Generated != nil }) {
+		return nil
+	}
+
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT a.attname, a.attgenerated <> ''
+		FROM pg_attribute a
+		WHERE a.attrelid = to_regclass(format('public.%I', $1::text))
+		  AND a.attnum > 0 AND NOT a.attisdropped
+	`
+	rows, err := i.getPool().Query(ctx, query, tableName)
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+	defer rows.Close()
+
+	generated := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var isGenerated bool
+		if err := rows.Scan(&name, &isGenerated); err != nil {
+			return fmt.Errorf("failed to scan column: %w", err)
+		}
+		generated[name] = isGenerated
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+	for _, c := range cols {
+		generated[c.Name] = c.Generated != nil
+	}
+
+	for _, c := range cols {
+		if c.Generated == nil {
+			continue
+		}
+		for _, ref := range c.Generated.Columns() {
+			isGenerated, ok := generated[ref]
+			switch {
+			case !ok:
+				return fmt.Errorf("%w: %s refers to unknown column %s", ErrInvalidGenerated, c.Name, ref)
+			case isGenerated:
+				return fmt.Errorf("%w: %s refers to generated column %s", ErrInvalidGenerated, c.Name, ref)
+			}
+		}
+	}
+	return nil
+}
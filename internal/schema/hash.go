@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// volatileKeys are JSON fields that change with data or usage rather than
+// structure, so two environments with identical DDL still hash the same.
+var volatileKeys = map[string]bool{
+	"estimatedRows": true,
+	"sizeBytes":     true,
+	"scans":         true,
+	"lastUsed":      true,
+	"currentValue":  true,
+	"grants":        true,
+}
+
+// Hash returns a SHA-256 hex digest of the schema's normalized JSON form:
+// volatile statistics are dropped and object keys are sorted, so the result
+// only changes when the schema itself does.
+func Hash(s *Schema) (string, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode schema: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", fmt.Errorf("failed to decode schema: %w", err)
+	}
+	// encoding/json writes map keys in sorted order
+	normalized, err := json.Marshal(stripVolatile(doc))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode normalized schema: %w", err)
+	}
+	sum := sha256.Sum256(normalized)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func stripVolatile(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if volatileKeys[k] {
+				delete(v, k)
+				continue
+			}
+			v[k] = stripVolatile(child)
+		}
+	case []any:
+		for idx, child := range v {
+			v[idx] = stripVolatile(child)
+		}
+	}
+	return v
+}
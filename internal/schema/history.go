@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxHistory bounds how many applied changes are kept for undo.
+const maxHistory = 100
+
+// ErrNothingToUndo is returned by Undo when no change has been applied.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// Change is a schema mutation applied through the Introspector.
+type Change struct {
+	ID          int      `json:"id"`
+	Database    string   `json:"database"`
+	Description string   `json:"description"`
+	Statements  []string `json:"statements"`
+	Inverse     []string `json:"inverse,omitempty"` // Statements that reverse the change
+	// Irreversible explains why Inverse is empty, e.g. because the change discarded data.
+	Irreversible string    `json:"irreversible,omitempty"`
+	AppliedAt    time.Time `json:"appliedAt"`
+}
+
+// CannotUndoError is returned by Undo when the most recent change has no
+// inverse or belongs to another database.
+type CannotUndoError struct {
+	Change Change
+	Reason string
+}
+
+func (e *CannotUndoError) Error() string {
+	return fmt.Sprintf("cannot undo %q: %s", e.Change.Description, e.Reason)
+}
+
+// changeHistory is the in-memory log of applied changes, oldest first.
+// It is lost on restart.
+type changeHistory struct {
+	mu      sync.Mutex
+	changes []Change
+	nextID  int
+}
+
+// applyChange runs the change's statements and, unless ctx carries a
+// DDLPreview, records it in the history so it can be undone.
+func (i *Introspector) applyChange(ctx context.Context, c Change) error {
+	if err := i.execDDL(ctx, c.Statements...); err != nil {
+		return err
+	}
+	if !isPreview(ctx) {
+		i.recordChange(c)
+	}
+	return nil
+}
+
+// recordChange appends an applied change to the history.
+func (i *Introspector) recordChange(c Change) {
+	h := &i.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID++
+	c.ID = h.nextID
+	c.Database = i.CurrentDatabase()
+	c.AppliedAt = time.Now().UTC()
+	h.changes = append(h.changes, c)
+	if len(h.changes) > maxHistory {
+		h.changes = slices.Delete(h.changes, 0, len(h.changes)-maxHistory)
+	}
+}
+
+// History returns the applied changes, newest first.
+func (i *Introspector) History() []Change {
+	h := &i.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	changes := slices.Clone(h.changes)
+	slices.Reverse(changes)
+	return changes
+}
+
+// Undo runs the inverse of the most recent change and removes it from the
+// history. Changes are undone strictly newest first: one that cannot be undone
+// blocks the ones before it, since reversing those out of order is unsafe.
+// Under a DDLPreview the inverse is recorded and the history left as is.
+func (i *Introspector) Undo(ctx context.Context) (Change, error) {
+	h := &i.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.changes) == 0 {
+		return Change{}, ErrNothingToUndo
+	}
+	last := h.changes[len(h.changes)-1]
+	if db := i.CurrentDatabase(); last.Database != db {
+		return last, &CannotUndoError{Change: last, Reason: fmt.Sprintf("it was applied to database %s, not %s", last.Database, db)}
+	}
+	if len(last.Inverse) == 0 {
+		return last, &CannotUndoError{Change: last, Reason: last.Irreversible}
+	}
+
+	if err := i.execDDL(ctx, last.Inverse...); err != nil {
+		return last, err
+	}
+	if !isPreview(ctx) {
+		h.changes = h.changes[:len(h.changes)-1]
+	}
+	return last, nil
+}
@@ -15,6 +15,7 @@ type Introspector struct {
 	dbName       string
 	queryTimeout time.Duration
 	mu           sync.RWMutex
+	history      changeHistory
 }
 
 // NewIntrospector creates a new schema introspector.
@@ -74,6 +75,8 @@ func (i *Introspector) ListDatabases(ctx context.Context) ([]string, error) {
 		SELECT datname FROM pg_database
 		WHERE datistemplate = false
 		  AND datname NOT IN ('postgres', 'template0', 'template1')
+		  AND datname NOT LIKE 'altdb\_import\_%' -- Scratch databases of SQL imports
+		  AND datname NOT LIKE 'altdb\_shadow\_%' -- Shadow databases of migration validation
 		ORDER BY datname
 	`
 	pool := i.getPool()
@@ -94,8 +97,136 @@ func (i *Introspector) ListDatabases(ctx context.Context) ([]string, error) {
 	return databases, rows.Err()
 }
 
+// SchemaInfo summarizes one schema (namespace) in the current database.
+type SchemaInfo struct {
+	Name   string `json:"name"`
+	Tables int    `json:"tables"`
+	Views  int    `json:"views"` // Including materialized views
+}
+
+// Tablespace is a storage location objects can be assigned to.
+type Tablespace struct {
+	Name      string `json:"name"`
+	Owner     string `json:"owner"`
+	Location  string `json:"location"` // Empty for pg_default and pg_global
+	SizeBytes int64  `json:"sizeBytes"`
+}
+
+// ListTablespaces returns every tablespace on the server.
+func (i *Introspector) ListTablespaces(ctx context.Context) ([]Tablespace, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			ts.spcname,
+			pg_get_userbyid(ts.spcowner),
+			pg_tablespace_location(ts.oid),
+			pg_tablespace_size(ts.oid)
+		FROM pg_tablespace ts
+		ORDER BY ts.spcname
+	`
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tablespaces: %w", err)
+	}
+	defer rows.Close()
+
+	tablespaces := make([]Tablespace, 0)
+	for rows.Next() {
+		var ts Tablespace
+		if err := rows.Scan(&ts.Name, &ts.Owner, &ts.Location, &ts.SizeBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan tablespace: %w", err)
+		}
+		tablespaces = append(tablespaces, ts)
+	}
+	return tablespaces, rows.Err()
+}
+
+// DatabaseInfo holds the settings that must match between two databases
+// before data can be copied between them safely.
+type DatabaseInfo struct {
+	Name              string `json:"name"`
+	Encoding          string `json:"encoding"` // e.g. UTF8
+	Collate           string `json:"collate"`  // LC_COLLATE; affects text ordering and indexes
+	Ctype             string `json:"ctype"`    // LC_CTYPE; affects character classification
+	ServerVersion     string `json:"serverVersion"`
+	ServerVersionNum  int    `json:"serverVersionNum"` // e.g. 160002 for 16.2
+	DefaultTablespace string `json:"defaultTablespace"`
+}
+
+// GetDatabaseInfo returns the encoding, locale, server version, and default
+// tablespace of the current database.
+func (i *Introspector) GetDatabaseInfo(ctx context.Context) (*DatabaseInfo, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			d.datname,
+			pg_encoding_to_char(d.encoding),
+			d.datcollate::text,
+			d.datctype::text,
+			current_setting('server_version'),
+			current_setting('server_version_num')::int,
+			ts.spcname
+		FROM pg_database d
+		JOIN pg_tablespace ts ON ts.oid = d.dattablespace
+		WHERE d.datname = current_database()
+	`
+	pool := i.getPool()
+	var info DatabaseInfo
+	if err := pool.QueryRow(ctx, query).Scan(&info.Name, &info.Encoding, &info.Collate, &info.Ctype,
+		&info.ServerVersion, &info.ServerVersionNum, &info.DefaultTablespace); err != nil {
+		return nil, fmt.Errorf("failed to get database info: %w", err)
+	}
+	return &info, nil
+}
+
+// IntrospectedSchema is the schema GetSchema and the other introspection queries read.
+const IntrospectedSchema = "public"
+
+// ListSchemas returns all non-system schemas with their table and view counts.
+func (i *Introspector) ListSchemas(ctx context.Context) ([]SchemaInfo, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			n.nspname,
+			count(c.oid) FILTER (WHERE c.relkind IN ('r', 'p') AND NOT c.relispartition),
+			count(c.oid) FILTER (WHERE c.relkind IN ('v', 'm'))
+		FROM pg_namespace n
+		LEFT JOIN pg_class c ON c.relnamespace = n.oid
+		WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND n.nspname NOT LIKE 'pg\_toast%'
+		  AND n.nspname NOT LIKE 'pg\_temp\_%'
+		GROUP BY n.nspname
+		ORDER BY n.nspname
+	`
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := make([]SchemaInfo, 0)
+	for rows.Next() {
+		var s SchemaInfo
+		var tables, views int64
+		if err := rows.Scan(&s.Name, &tables, &views); err != nil {
+			return nil, fmt.Errorf("failed to scan schema: %w", err)
+		}
+		s.Tables, s.Views = int(tables), int(views)
+		schemas = append(schemas, s)
+	}
+	return schemas, rows.Err()
+}
+
 // GetSchema returns the complete database schema for the public schema.
-// Uses batch queries to avoid N+1 query problem (3 queries total).
+// Uses one batch query per object kind to avoid the N+1 query problem.
 func (i *Introspector) GetSchema(ctx context.Context) (*Schema, error) {
 	ctx, cancel := i.withTimeout(ctx)
 	defer cancel()
@@ -108,39 +239,129 @@ func (i *Introspector) GetSchema(ctx context.Context) (*Schema, error) {
 		return nil, err
 	}
 
+	// Query 2: Get all sequences (standalone ones exist without tables)
+	sequences, err := i.getAllSequences(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query 3: Get all foreign servers (defined independently of tables)
+	servers, err := i.getAllForeignServers(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(tables) == 0 {
-		return &Schema{Tables: []Table{}}, nil
+		return &Schema{Tables: []Table{}, Sequences: sequences, ForeignServers: servers, Warnings: []SchemaWarning{}, ForeignKeyCycles: []ForeignKeyCycle{}}, nil
 	}
 
-	// Query 2: Get all columns for all tables (batch)
+	// Query 4: Get all columns for all tables (batch)
 	columnsByTable, err := i.getAllColumns(ctx, pool)
 	if err != nil {
 		return nil, err
 	}
 
-	// Query 3: Get all foreign keys for all tables (batch)
+	// Query 5: Get all foreign keys for all tables (batch)
 	fksByTable, err := i.getAllForeignKeys(ctx, pool)
 	if err != nil {
 		return nil, err
 	}
 
+	// Query 6: Get all indexes for all tables (batch)
+	indexesByTable, err := i.getAllIndexes(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query 7: Get all primary and unique constraints for all tables (batch)
+	pksByTable, uniquesByTable, err := i.getAllKeyConstraints(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query 8: Get all check constraints for all tables (batch)
+	checksByTable, err := i.getAllCheckConstraints(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query 9: Get all exclusion constraints for all tables (batch)
+	exclusionsByTable, err := i.getAllExclusionConstraints(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query 10: Get all triggers for all tables (batch)
+	triggersByTable, err := i.getAllTriggers(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query 11: Get partitioning for all partitioned tables (batch)
+	partitioningByTable, err := i.getAllPartitioning(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query 12: Get INHERITS parents for all tables (batch)
+	parentsByTable, err := i.getAllParentTables(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query 13: Get server and options for all foreign tables (batch)
+	foreignByTable, err := i.getAllForeignTables(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
 	// Assemble the schema
 	for idx := range tables {
 		tableName := tables[idx].Name
 		tables[idx].Columns = columnsByTable[tableName]
 		tables[idx].ForeignKeys = fksByTable[tableName]
+		tables[idx].Indexes = indexesByTable[tableName]
+		tables[idx].PrimaryKey = pksByTable[tableName]
+		tables[idx].UniqueConstraints = uniquesByTable[tableName]
+		tables[idx].CheckConstraints = checksByTable[tableName]
+		tables[idx].Exclusions = exclusionsByTable[tableName]
+		tables[idx].Triggers = triggersByTable[tableName]
+		tables[idx].ParentTables = parentsByTable[tableName]
+		tables[idx].Foreign = foreignByTable[tableName]
+		if p := partitioningByTable[tableName]; p != nil {
+			// A partitioned parent holds no data itself; report its partitions' totals
+			for _, part := range p.Partitions {
+				tables[idx].EstimatedRows += part.EstimatedRows
+				tables[idx].SizeBytes += part.SizeBytes
+			}
+			tables[idx].Partitioning = p
+		}
 	}
 
-	return &Schema{Tables: tables}, nil
+	s := &Schema{Tables: tables, Sequences: sequences, ForeignServers: servers}
+	s.Warnings = AnalyzeSchema(s)
+	s.ForeignKeyCycles = FindForeignKeyCycles(s.Tables)
+	return s, nil
 }
 
 func (i *Introspector) getAllTables(ctx context.Context, pool *pgxpool.Pool) ([]Table, error) {
 	query := `
-		SELECT table_name
-		FROM information_schema.tables
-		WHERE table_schema = 'public'
-		  AND table_type = 'BASE TABLE'
-		ORDER BY table_name
+		SELECT
+			t.table_name,
+			COALESCE(obj_description(c.oid, 'pg_class'), ''),
+			GREATEST(c.reltuples, 0)::bigint,
+			pg_total_relation_size(c.oid),
+			COALESCE(ts.spcname, ''),
+			COALESCE(c.reloptions, '{}'),
+			t.table_type = 'FOREIGN'
+		FROM information_schema.tables t
+		JOIN pg_class c ON c.oid = format('%I.%I', t.table_schema, t.table_name)::regclass
+		LEFT JOIN pg_tablespace ts ON ts.oid = c.reltablespace
+		WHERE t.table_schema = 'public'
+		  AND t.table_type IN ('BASE TABLE', 'FOREIGN')
+		  AND NOT c.relispartition -- Partitions are nested under their parent
+		  AND t.table_name NOT IN ('altdb_tags', 'alt_migrations', 'alt_migrations_squashed', 'alt_baselines') -- The tool's own metadata; see TagsTable, MigrationsTable, MigrationsBackupTable, and BaselinesTable
+		ORDER BY t.table_name
 	`
 
 	rows, err := pool.Query(ctx, query)
@@ -151,11 +372,11 @@ func (i *Introspector) getAllTables(ctx context.Context, pool *pgxpool.Pool) ([]
 
 	tables := make([]Table, 0, 64) // Pre-allocate for typical schema
 	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
+		var t Table
+		if err := rows.Scan(&t.Name, &t.Comment, &t.EstimatedRows, &t.SizeBytes, &t.Tablespace, &t.StorageParameters, &t.IsForeign); err != nil {
 			return nil, fmt.Errorf("failed to scan table name: %w", err)
 		}
-		tables = append(tables, Table{Name: name})
+		tables = append(tables, t)
 	}
 
 	return tables, rows.Err()
@@ -166,12 +387,38 @@ func (i *Introspector) getAllColumns(ctx context.Context, pool *pgxpool.Pool) (m
 		SELECT
 			c.table_name,
 			c.column_name,
-			c.data_type,
+			CASE
+				WHEN c.data_type = 'USER-DEFINED' THEN c.udt_name
+				WHEN c.data_type = 'ARRAY' THEN format_type(a.atttypid, NULL) -- e.g. integer[]
+				ELSE c.data_type
+			END,
 			c.is_nullable = 'YES' as is_nullable,
 			c.column_default,
 			COALESCE(pk.is_pk, false) as is_primary,
-			COALESCE(uq.is_unique, false) as is_unique
+			COALESCE(uq.is_unique, false) as is_unique,
+			COALESCE(c.identity_generation, ''),
+			COALESCE(seq.sequence_name, ''),
+			COALESCE(col_description(a.attrelid, a.attnum), ''),
+			COALESCE(ext.extension, ''),
+			c.character_maximum_length::int,
+			c.numeric_precision::int,
+			c.numeric_scale::int,
+			c.collation_name::text,
+			c.is_generated = 'ALWAYS',
+			c.generation_expression::text,
+			COALESCE(c.domain_name, ''),
+			ty.typtype::text,
+			ty.typcategory::text,
+			CASE WHEN ty.typelem <> 0 AND ty.typcategory = 'A' THEN format_type(ty.typelem, NULL) ELSE '' END,
+			CASE WHEN a.attstorage <> ty.typstorage THEN
+				CASE a.attstorage WHEN 'p' THEN 'plain' WHEN 'e' THEN 'external' WHEN 'x' THEN 'extended' WHEN 'm' THEN 'main' ELSE a.attstorage::text END
+			ELSE '' END,
+			NULLIF(COALESCE(a.attstattarget, -1), -1)::int -- NULL for the default since PostgreSQL 17, -1 before
 		FROM information_schema.columns c
+		JOIN pg_attribute a
+		  ON a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass
+		 AND a.attnum = c.ordinal_position::int
+		JOIN pg_type ty ON ty.oid = a.atttypid
 		LEFT JOIN (
 			SELECT DISTINCT kcu.table_name, kcu.column_name, true as is_pk
 			FROM information_schema.table_constraints tc
@@ -193,6 +440,8 @@ func (i *Introspector) getAllColumns(ctx context.Context, pool *pgxpool.Pool) (m
 			       WHERE kcu2.constraint_name = tc.constraint_name
 			         AND kcu2.table_schema = tc.table_schema) = 1
 		) uq ON c.table_name = uq.table_name AND c.column_name = uq.column_name
+		LEFT JOIN (` + ownedSequencesQuery + `) seq ON c.table_name = seq.table_name AND c.column_name = seq.column_name
+		LEFT JOIN (` + extensionTypesQuery + `) ext ON c.udt_schema = ext.type_schema AND c.udt_name = ext.type_name
 		WHERE c.table_schema = 'public'
 		ORDER BY c.table_name, c.ordinal_position
 	`
@@ -205,34 +454,138 @@ func (i *Introspector) getAllColumns(ctx context.Context, pool *pgxpool.Pool) (m
 
 	columnsByTable := make(map[string][]Column)
 	for rows.Next() {
-		var tableName string
+		var tableName, typType, typCategory string
 		var col Column
-		if err := rows.Scan(&tableName, &col.Name, &col.DataType, &col.IsNullable, &col.Default, &col.IsPrimary, &col.IsUnique); err != nil {
+		if err := rows.Scan(&tableName, &col.Name, &col.DataType, &col.IsNullable, &col.Default, &col.IsPrimary, &col.IsUnique,
+			&col.Identity, &col.Sequence, &col.Comment, &col.Extension, &col.CharacterMaximumLength, &col.NumericPrecision, &col.NumericScale, &col.Collation,
+			&col.IsGenerated, &col.GenerationExpression, &col.Domain,
+			&typType, &typCategory, &col.ElementType, &col.Storage, &col.StatisticsTarget); err != nil {
 			return nil, fmt.Errorf("failed to scan column: %w", err)
 		}
+		col.TypeCategory = typeCategory(typType, typCategory)
+		col.Position = len(columnsByTable[tableName]) + 1 // Rows arrive in column order
+		if col.Default != nil {
+			d := ParseDefault(*col.Default)
+			col.DefaultValue = &d
+		}
 		columnsByTable[tableName] = append(columnsByTable[tableName], col)
 	}
 
 	return columnsByTable, rows.Err()
 }
 
+// Column type categories.
+const (
+	TypeCategoryBase       = "base"
+	TypeCategoryArray      = "array"
+	TypeCategoryRange      = "range"
+	TypeCategoryMultirange = "multirange"
+	TypeCategoryEnum       = "enum"
+	TypeCategoryComposite  = "composite"
+	TypeCategoryDomain     = "domain"
+)
+
+// typeCategory classifies a column type from pg_type.typtype and typcategory.
+func typeCategory(typType, typCategory string) string {
+	switch typType {
+	case "r":
+		return TypeCategoryRange
+	case "m":
+		return TypeCategoryMultirange
+	case "e":
+		return TypeCategoryEnum
+	case "c":
+		return TypeCategoryComposite
+	case "d":
+		return TypeCategoryDomain
+	}
+	if typCategory == "A" {
+		return TypeCategoryArray
+	}
+	return TypeCategoryBase
+}
+
+// ownedSequencesQuery maps sequences to the columns that own them:
+// deptype 'a' links serial sequences, 'i' links identity sequences.
+const ownedSequencesQuery = `
+	SELECT s.relname AS sequence_name, t.relname AS table_name, a.attname AS column_name
+	FROM pg_depend d
+	JOIN pg_class s ON s.oid = d.objid AND s.relkind = 'S'
+	JOIN pg_class t ON t.oid = d.refobjid
+	JOIN pg_namespace n ON n.oid = t.relnamespace
+	JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+	WHERE d.classid = 'pg_class'::regclass
+	  AND d.refclassid = 'pg_class'::regclass
+	  AND d.deptype IN ('a', 'i')
+	  AND n.nspname = 'public'
+`
+
+func (i *Introspector) getAllSequences(ctx context.Context, pool *pgxpool.Pool) ([]Sequence, error) {
+	query := `
+		SELECT
+			s.sequencename,
+			s.data_type::text,
+			s.start_value,
+			s.increment_by,
+			s.last_value,
+			COALESCE(o.table_name, ''),
+			COALESCE(o.column_name, '')
+		FROM pg_sequences s
+		LEFT JOIN (` + ownedSequencesQuery + `) o ON o.sequence_name = s.sequencename
+		WHERE s.schemaname = 'public'
+		ORDER BY s.sequencename
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sequences: %w", err)
+	}
+	defer rows.Close()
+
+	sequences := make([]Sequence, 0)
+	for rows.Next() {
+		var s Sequence
+		if err := rows.Scan(&s.Name, &s.DataType, &s.StartValue, &s.Increment, &s.CurrentValue, &s.OwnedByTable, &s.OwnedByColumn); err != nil {
+			return nil, fmt.Errorf("failed to scan sequence: %w", err)
+		}
+		sequences = append(sequences, s)
+	}
+
+	return sequences, rows.Err()
+}
+
 func (i *Introspector) getAllForeignKeys(ctx context.Context, pool *pgxpool.Pool) (map[string][]ForeignKey, error) {
+	// One row per constraint; conkey/confkey are unnested in parallel so
+	// each referencing column lines up with the column it references
 	query := `
 		SELECT
-			tc.table_name,
-			kcu.column_name,
-			ccu.table_name AS references_table,
-			ccu.column_name AS references_column
-		FROM information_schema.table_constraints tc
-		JOIN information_schema.key_column_usage kcu
-		  ON tc.constraint_name = kcu.constraint_name
-		 AND tc.table_schema = kcu.table_schema
-		JOIN information_schema.constraint_column_usage ccu
-		  ON ccu.constraint_name = tc.constraint_name
-		 AND ccu.table_schema = tc.table_schema
-		WHERE tc.constraint_type = 'FOREIGN KEY'
-		  AND tc.table_schema = 'public'
-		ORDER BY tc.table_name, kcu.column_name
+			t.relname,
+			con.conname,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			rt.relname,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			con.confdeltype::text,
+			con.confupdtype::text,
+			con.condeferrable,
+			con.condeferred,
+			COALESCE(obj_description(con.oid, 'pg_constraint'), '')
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_class rt ON rt.oid = con.confrelid
+		WHERE con.contype = 'f'
+		  AND n.nspname = 'public'
+		ORDER BY t.relname, con.conname
 	`
 
 	rows, err := pool.Query(ctx, query)
@@ -243,13 +596,453 @@ func (i *Introspector) getAllForeignKeys(ctx context.Context, pool *pgxpool.Pool
 
 	fksByTable := make(map[string][]ForeignKey)
 	for rows.Next() {
-		var tableName string
+		var tableName, onDelete, onUpdate string
 		var fk ForeignKey
-		if err := rows.Scan(&tableName, &fk.ColumnName, &fk.ReferencesTable, &fk.ReferencesColumn); err != nil {
+		if err := rows.Scan(&tableName, &fk.ConstraintName, &fk.Columns, &fk.ReferencesTable, &fk.ReferencesColumns, &onDelete, &onUpdate, &fk.Deferrable, &fk.InitiallyDeferred, &fk.Comment); err != nil {
 			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
 		}
+		fk.OnDelete = referentialAction(onDelete)
+		fk.OnUpdate = referentialAction(onUpdate)
 		fksByTable[tableName] = append(fksByTable[tableName], fk)
 	}
 
 	return fksByTable, rows.Err()
 }
+
+// referentialAction maps pg_constraint.confdeltype/confupdtype codes to SQL keywords.
+func referentialAction(code string) string {
+	switch code {
+	case "r":
+		return "RESTRICT"
+	case "c":
+		return "CASCADE"
+	case "n":
+		return "SET NULL"
+	case "d":
+		return "SET DEFAULT"
+	}
+	return "NO ACTION"
+}
+
+func (i *Introspector) getAllIndexes(ctx context.Context, pool *pgxpool.Pool) (map[string][]Index, error) {
+	query := `
+		SELECT
+			t.relname,
+			ic.relname,
+			ARRAY(
+				SELECT pg_get_indexdef(ix.indexrelid, k, true)
+				FROM generate_series(1, ix.indnkeyatts) k
+				ORDER BY k
+			),
+			ix.indisunique,
+			ix.indisprimary,
+			am.amname,
+			pg_get_expr(ix.indpred, ix.indrelid),
+			COALESCE(s.idx_scan, 0),
+			(to_jsonb(s) ->> 'last_idx_scan')::timestamptz, -- PostgreSQL 16+; NULL on older servers
+			COALESCE(ts.spcname, ''),
+			COALESCE(obj_description(ix.indexrelid, 'pg_class'), '')
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class ic ON ic.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_am am ON am.oid = ic.relam
+		LEFT JOIN pg_tablespace ts ON ts.oid = ic.reltablespace
+		LEFT JOIN pg_stat_user_indexes s ON s.indexrelid = ix.indexrelid
+		WHERE n.nspname = 'public'
+		ORDER BY t.relname, ic.relname
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexesByTable := make(map[string][]Index)
+	for rows.Next() {
+		var tableName string
+		var index Index
+		if err := rows.Scan(&tableName, &index.Name, &index.Columns, &index.IsUnique, &index.IsPrimary, &index.Method, &index.Predicate,
+			&index.Scans, &index.LastUsed, &index.Tablespace, &index.Comment); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		indexesByTable[tableName] = append(indexesByTable[tableName], index)
+	}
+
+	return indexesByTable, rows.Err()
+}
+
+func (i *Introspector) getAllKeyConstraints(ctx context.Context, pool *pgxpool.Pool) (map[string]*PrimaryKey, map[string][]UniqueConstraint, error) {
+	query := `
+		SELECT
+			t.relname,
+			con.contype::text,
+			con.conname,
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			COALESCE(obj_description(con.oid, 'pg_constraint'), '')
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE con.contype IN ('p', 'u')
+		  AND n.nspname = 'public'
+		ORDER BY t.relname, con.conname
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get key constraints: %w", err)
+	}
+	defer rows.Close()
+
+	pksByTable := make(map[string]*PrimaryKey)
+	uniquesByTable := make(map[string][]UniqueConstraint)
+	for rows.Next() {
+		var tableName, conType, name, comment string
+		var columns []string
+		if err := rows.Scan(&tableName, &conType, &name, &columns, &comment); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan key constraint: %w", err)
+		}
+		if conType == "p" {
+			pksByTable[tableName] = &PrimaryKey{Name: name, Columns: columns, Comment: comment}
+		} else {
+			uniquesByTable[tableName] = append(uniquesByTable[tableName], UniqueConstraint{Name: name, Columns: columns, Comment: comment})
+		}
+	}
+
+	return pksByTable, uniquesByTable, rows.Err()
+}
+
+func (i *Introspector) getAllCheckConstraints(ctx context.Context, pool *pgxpool.Pool) (map[string][]CheckConstraint, error) {
+	query := `
+		SELECT t.relname, con.conname, pg_get_expr(con.conbin, con.conrelid, true),
+		       COALESCE(obj_description(con.oid, 'pg_constraint'), '')
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE con.contype = 'c'
+		  AND n.nspname = 'public'
+		ORDER BY t.relname, con.conname
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check constraints: %w", err)
+	}
+	defer rows.Close()
+
+	checksByTable := make(map[string][]CheckConstraint)
+	for rows.Next() {
+		var tableName string
+		var check CheckConstraint
+		if err := rows.Scan(&tableName, &check.Name, &check.Expression, &check.Comment); err != nil {
+			return nil, fmt.Errorf("failed to scan check constraint: %w", err)
+		}
+		checksByTable[tableName] = append(checksByTable[tableName], check)
+	}
+
+	return checksByTable, rows.Err()
+}
+
+func (i *Introspector) getAllExclusionConstraints(ctx context.Context, pool *pgxpool.Pool) (map[string][]ExclusionConstraint, error) {
+	// Elements come from the backing index; conexclop holds one operator per key column
+	query := `
+		SELECT
+			t.relname,
+			con.conname,
+			am.amname,
+			ARRAY(
+				SELECT pg_get_indexdef(con.conindid, k, true)
+				FROM generate_series(1, ix.indnkeyatts) AS k
+				ORDER BY k
+			),
+			ARRAY(
+				SELECT o.oprname
+				FROM unnest(con.conexclop) WITH ORDINALITY AS op(oid, ord)
+				JOIN pg_operator o ON o.oid = op.oid
+				ORDER BY op.ord
+			),
+			pg_get_expr(ix.indpred, ix.indrelid, true),
+			pg_get_constraintdef(con.oid, true),
+			COALESCE(obj_description(con.oid, 'pg_constraint'), '')
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_index ix ON ix.indexrelid = con.conindid
+		JOIN pg_class ic ON ic.oid = con.conindid
+		JOIN pg_am am ON am.oid = ic.relam
+		WHERE con.contype = 'x'
+		  AND n.nspname = 'public'
+		ORDER BY t.relname, con.conname
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exclusion constraints: %w", err)
+	}
+	defer rows.Close()
+
+	exclusionsByTable := make(map[string][]ExclusionConstraint)
+	for rows.Next() {
+		var tableName string
+		var columns, operators []string
+		var ex ExclusionConstraint
+		if err := rows.Scan(&tableName, &ex.Name, &ex.Method, &columns, &operators, &ex.Predicate, &ex.Definition, &ex.Comment); err != nil {
+			return nil, fmt.Errorf("failed to scan exclusion constraint: %w", err)
+		}
+		ex.Elements = make([]ExclusionElement, 0, len(columns))
+		for idx, col := range columns {
+			el := ExclusionElement{Column: col}
+			if idx < len(operators) {
+				el.Operator = operators[idx]
+			}
+			ex.Elements = append(ex.Elements, el)
+		}
+		exclusionsByTable[tableName] = append(exclusionsByTable[tableName], ex)
+	}
+
+	return exclusionsByTable, rows.Err()
+}
+
+func (i *Introspector) getAllTriggers(ctx context.Context, pool *pgxpool.Pool) (map[string][]Trigger, error) {
+	// Internal triggers implement foreign keys and are already reported as such
+	query := `
+		SELECT t.relname, tg.tgname, tg.tgtype, p.proname, tg.tgenabled <> 'D'
+		FROM pg_trigger tg
+		JOIN pg_class t ON t.oid = tg.tgrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_proc p ON p.oid = tg.tgfoid
+		WHERE NOT tg.tgisinternal
+		  AND n.nspname = 'public'
+		ORDER BY t.relname, tg.tgname
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get triggers: %w", err)
+	}
+	defer rows.Close()
+
+	triggersByTable := make(map[string][]Trigger)
+	for rows.Next() {
+		var tableName string
+		var tgType int16
+		var trigger Trigger
+		if err := rows.Scan(&tableName, &trigger.Name, &tgType, &trigger.Function, &trigger.Enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		trigger.Timing, trigger.Events, trigger.Level = decodeTriggerType(tgType)
+		triggersByTable[tableName] = append(triggersByTable[tableName], trigger)
+	}
+
+	return triggersByTable, rows.Err()
+}
+
+// pg_trigger.tgtype bits, from PostgreSQL's catalog/pg_trigger.h.
+const (
+	triggerTypeRow      = 1 << 0
+	triggerTypeBefore   = 1 << 1
+	triggerTypeInsert   = 1 << 2
+	triggerTypeDelete   = 1 << 3
+	triggerTypeUpdate   = 1 << 4
+	triggerTypeTruncate = 1 << 5
+	triggerTypeInstead  = 1 << 6
+)
+
+// decodeTriggerType splits pg_trigger.tgtype into timing, events, and level.
+func decodeTriggerType(tgType int16) (timing string, events []string, level string) {
+	switch {
+	case tgType&triggerTypeInstead != 0:
+		timing = "INSTEAD OF"
+	case tgType&triggerTypeBefore != 0:
+		timing = "BEFORE"
+	default:
+		timing = "AFTER"
+	}
+
+	events = make([]string, 0, 1)
+	if tgType&triggerTypeInsert != 0 {
+		events = append(events, "INSERT")
+	}
+	if tgType&triggerTypeUpdate != 0 {
+		events = append(events, "UPDATE")
+	}
+	if tgType&triggerTypeDelete != 0 {
+		events = append(events, "DELETE")
+	}
+	if tgType&triggerTypeTruncate != 0 {
+		events = append(events, "TRUNCATE")
+	}
+
+	level = "STATEMENT"
+	if tgType&triggerTypeRow != 0 {
+		level = "ROW"
+	}
+	return timing, events, level
+}
+
+func (i *Introspector) getAllPartitioning(ctx context.Context, pool *pgxpool.Pool) (map[string]*Partitioning, error) {
+	query := `
+		SELECT
+			t.relname,
+			pt.partstrat::text,
+			pg_get_partkeydef(t.oid),
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(pt.partattrs::int2[]) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			)
+		FROM pg_partitioned_table pt
+		JOIN pg_class t ON t.oid = pt.partrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = 'public'
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitioned tables: %w", err)
+	}
+	defer rows.Close()
+
+	partitioningByTable := make(map[string]*Partitioning)
+	for rows.Next() {
+		var tableName, strategy string
+		p := &Partitioning{Partitions: []Partition{}}
+		if err := rows.Scan(&tableName, &strategy, &p.Key, &p.Columns); err != nil {
+			return nil, fmt.Errorf("failed to scan partitioned table: %w", err)
+		}
+		p.Strategy = partitionStrategy(strategy)
+		partitioningByTable[tableName] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(partitioningByTable) == 0 {
+		return partitioningByTable, nil
+	}
+
+	query = `
+		SELECT
+			parent.relname,
+			child.relname,
+			pg_get_expr(child.relpartbound, child.oid),
+			GREATEST(child.reltuples, 0)::bigint,
+			pg_total_relation_size(child.oid)
+		FROM pg_inherits inh
+		JOIN pg_class parent ON parent.oid = inh.inhparent
+		JOIN pg_class child ON child.oid = inh.inhrelid
+		JOIN pg_namespace n ON n.oid = parent.relnamespace
+		WHERE child.relispartition
+		  AND n.nspname = 'public'
+		ORDER BY parent.relname, child.relname
+	`
+
+	rows, err = pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var parentName string
+		var part Partition
+		if err := rows.Scan(&parentName, &part.Name, &part.Bound, &part.EstimatedRows, &part.SizeBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+		if p := partitioningByTable[parentName]; p != nil {
+			p.Partitions = append(p.Partitions, part)
+		}
+	}
+
+	return partitioningByTable, rows.Err()
+}
+
+// partitionStrategy maps pg_partitioned_table.partstrat codes to SQL keywords.
+func partitionStrategy(code string) string {
+	switch code {
+	case "r":
+		return "RANGE"
+	case "l":
+		return "LIST"
+	}
+	return "HASH"
+}
+
+func (i *Introspector) getAllParentTables(ctx context.Context, pool *pgxpool.Pool) (map[string][]string, error) {
+	// Declarative partitions also live in pg_inherits; they are reported under Partitioning
+	query := `
+		SELECT child.relname, parent.relname
+		FROM pg_inherits inh
+		JOIN pg_class child ON child.oid = inh.inhrelid
+		JOIN pg_class parent ON parent.oid = inh.inhparent
+		JOIN pg_namespace n ON n.oid = child.relnamespace
+		WHERE NOT child.relispartition
+		  AND child.relkind IN ('r', 'f')
+		  AND n.nspname = 'public'
+		ORDER BY child.relname, inh.inhseqno
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table inheritance: %w", err)
+	}
+	defer rows.Close()
+
+	parentsByTable := make(map[string][]string)
+	for rows.Next() {
+		var child, parent string
+		if err := rows.Scan(&child, &parent); err != nil {
+			return nil, fmt.Errorf("failed to scan table inheritance: %w", err)
+		}
+		parentsByTable[child] = append(parentsByTable[child], parent)
+	}
+
+	return parentsByTable, rows.Err()
+}
+
+// GetTableGrants returns SELECT/INSERT/UPDATE/DELETE grants per table, keyed by
+// table name. It is separate from GetSchema because grants are only needed for
+// access audits and the query is comparatively slow on large catalogs.
+func (i *Introspector) GetTableGrants(ctx context.Context) (map[string][]Grant, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			table_name::text,
+			grantee::text,
+			array_agg(privilege_type::text ORDER BY array_position(
+				ARRAY['SELECT', 'INSERT', 'UPDATE', 'DELETE'], privilege_type::text))
+		FROM information_schema.role_table_grants
+		WHERE table_schema = 'public'
+		  AND privilege_type IN ('SELECT', 'INSERT', 'UPDATE', 'DELETE')
+		GROUP BY table_name, grantee
+		ORDER BY table_name, grantee
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table grants: %w", err)
+	}
+	defer rows.Close()
+
+	grantsByTable := make(map[string][]Grant)
+	for rows.Next() {
+		var tableName string
+		var g Grant
+		if err := rows.Scan(&tableName, &g.Role, &g.Privileges); err != nil {
+			return nil, fmt.Errorf("failed to scan table grant: %w", err)
+		}
+		grantsByTable[tableName] = append(grantsByTable[tableName], g)
+	}
+
+	return grantsByTable, rows.Err()
+}
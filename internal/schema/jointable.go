@@ -0,0 +1,186 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNoSingleColumnKey is returned when a table to be joined lacks a
+// single-column primary key to reference.
+var ErrNoSingleColumnKey = errors.New("table has no single-column primary key")
+
+// ManyToManyRequest asks for a join table linking two existing tables.
+// Only LeftTable and RightTable are required; names default from the table names,
+// e.g. users and roles give users_roles(user_id, role_id).
+type ManyToManyRequest struct {
+	LeftTable   string             `json:"leftTable"`
+	RightTable  string             `json:"rightTable"`
+	JoinTable   string             `json:"joinTable,omitempty"`
+	LeftColumn  string             `json:"leftColumn,omitempty"`
+	RightColumn string             `json:"rightColumn,omitempty"`
+	Columns     []AddColumnRequest `json:"columns,omitempty"` // Extra columns, e.g. created_at
+}
+
+// JoinSide is one table a join table references, with its key as introspected.
+type JoinSide struct {
+	Table     string
+	Column    string // Referencing column in the join table
+	KeyColumn string // Primary key column of Table
+	KeyType   string // Type of KeyColumn as reported by format_type
+}
+
+// JoinTableDef holds the resolved parts of a join table for DDL building.
+type JoinTableDef struct {
+	Name        string
+	Left, Right JoinSide
+	Columns     []ColumnDef
+}
+
+// keyTypePattern guards the catalog-supplied key types spliced into DDL,
+// e.g. integer, character varying(36), or timestamp(3) with time zone.
+var keyTypePattern = regexp.MustCompile(`^[a-z][a-z0-9 _]*(\([0-9, ]+\))?[a-z ]*$`)
+
+// BuildJoinTableDDL constructs the statements creating a join table: the table
+// with a composite primary key over both references, whose rows are deleted
+// along with either referenced row, plus an index leading with the right-hand
+// column for lookups from that side (the primary key covers the left).
+func BuildJoinTableDDL(def JoinTableDef, customTypes []string) ([]string, error) {
+	if !ValidIdentifier(def.Name) {
+		return nil, fmt.Errorf("invalid join table name")
+	}
+	if def.Left.Column == def.Right.Column {
+		return nil, fmt.Errorf("join columns must have different names")
+	}
+
+	var defs []string
+	names := make(map[string]bool, len(def.Columns)+2)
+	for _, side := range []JoinSide{def.Left, def.Right} {
+		if !ValidIdentifier(side.Table) || !ValidIdentifier(side.Column) || !ValidIdentifier(side.KeyColumn) {
+			return nil, fmt.Errorf("invalid name for reference to %q", side.Table)
+		}
+		if !keyTypePattern.MatchString(side.KeyType) {
+			return nil, fmt.Errorf("unsupported key type %q on %s", side.KeyType, side.Table)
+		}
+		names[side.Column] = true
+		defs = append(defs, fmt.Sprintf("%s %s NOT NULL REFERENCES %s(%s) ON DELETE CASCADE",
+			sanitizeIdentifier(side.Column),
+			side.KeyType,
+			sanitizeIdentifier(side.Table),
+			sanitizeIdentifier(side.KeyColumn)))
+	}
+	for _, col := range def.Columns {
+		if names[col.Name] {
+			return nil, fmt.Errorf("duplicate column name %q", col.Name)
+		}
+		names[col.Name] = true
+		if col.PrimaryKey {
+			return nil, fmt.Errorf("column %q cannot be a primary key; the join columns form the key", col.Name)
+		}
+		colDef, err := columnDefinition(col, customTypes)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, colDef)
+	}
+	defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s, %s)",
+		sanitizeIdentifier(def.Left.Column),
+		sanitizeIdentifier(def.Right.Column)))
+
+	return []string{
+		fmt.Sprintf("CREATE TABLE %s (%s)", sanitizeIdentifier(def.Name), strings.Join(defs, ", ")),
+		fmt.Sprintf("CREATE INDEX ON %s (%s)", sanitizeIdentifier(def.Name), sanitizeIdentifier(def.Right.Column)),
+	}, nil
+}
+
+// singular guesses the singular of a plural table name, the reverse of
+// tableNameCandidates: categories -> category, users -> user.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ses"), strings.HasSuffix(name, "xes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// primaryKeyColumn returns the column and type of a table's single-column primary key.
+func (i *Introspector) primaryKeyColumn(ctx context.Context, tableName string) (column, dataType string, err error) {
+	query := `
+		SELECT a.attname, format_type(a.atttypid, a.atttypmod)
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ix.indkey[0]
+		WHERE ix.indisprimary
+		  AND ix.indnatts = 1
+		  AND n.nspname = 'public'
+		  AND t.relname = $1
+	`
+
+	pool := i.getPool()
+	if err := pool.QueryRow(ctx, query, tableName).Scan(&column, &dataType); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", fmt.Errorf("%s: %w", tableName, ErrNoSingleColumnKey)
+		}
+		return "", "", fmt.Errorf("failed to get primary key of %s: %w", tableName, err)
+	}
+	return column, dataType, nil
+}
+
+// CreateManyToMany creates a join table between two tables in one transaction
+// and returns its name. customTypes lists the user-defined types the extra
+// columns may use; see IsValidType.
+func (i *Introspector) CreateManyToMany(ctx context.Context, req ManyToManyRequest, customTypes []string) (string, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	def := JoinTableDef{
+		Name:  req.JoinTable,
+		Left:  JoinSide{Table: req.LeftTable, Column: req.LeftColumn},
+		Right: JoinSide{Table: req.RightTable, Column: req.RightColumn},
+	}
+	if def.Name == "" {
+		def.Name = req.LeftTable + "_" + req.RightTable
+	}
+	for _, side := range []*JoinSide{&def.Left, &def.Right} {
+		keyColumn, keyType, err := i.primaryKeyColumn(ctx, side.Table)
+		if err != nil {
+			return "", err
+		}
+		side.KeyColumn, side.KeyType = keyColumn, keyType
+		if side.Column == "" {
+			side.Column = singular(side.Table) + "_" + keyColumn
+		}
+	}
+	for _, c := range req.Columns {
+		def.Columns = append(def.Columns, columnDef(c))
+	}
+
+	statements, err := BuildJoinTableDDL(def, customTypes)
+	if err != nil {
+		return "", err
+	}
+
+	undo, err := BuildDropTableDDL(def.Name, false)
+	if err != nil {
+		return "", err
+	}
+
+	change := Change{
+		Description: fmt.Sprintf("Create join table %s between %s and %s", def.Name, def.Left.Table, def.Right.Table),
+		Statements:  statements,
+		Inverse:     []string{undo},
+	}
+	if err := i.applyChange(ctx, change); err != nil {
+		return "", err
+	}
+	return def.Name, nil
+}
@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// Maintenance operations that may be run from the API.
+const (
+	MaintenanceAnalyze = "analyze"
+	MaintenanceVacuum  = "vacuum"
+	MaintenanceReindex = "reindex"
+)
+
+// MaintenanceOptions tune a maintenance operation.
+type MaintenanceOptions struct {
+	Full    bool `json:"full"`    // VACUUM FULL: rewrites the table under an exclusive lock
+	Analyze bool `json:"analyze"` // VACUUM ANALYZE: refresh planner stats in the same pass
+}
+
+// BuildMaintenanceSQL constructs an ANALYZE, VACUUM, or REINDEX statement safely.
+// REINDEX always runs CONCURRENTLY so reads and writes continue.
+func BuildMaintenanceSQL(operation, tableName string, opts MaintenanceOptions) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	table := sanitizeIdentifier(tableName)
+
+	switch operation {
+	case MaintenanceAnalyze:
+		return "ANALYZE " + table, nil
+	case MaintenanceVacuum:
+		switch {
+		case opts.Full && opts.Analyze:
+			return "VACUUM (FULL, ANALYZE) " + table, nil
+		case opts.Full:
+			return "VACUUM (FULL) " + table, nil
+		case opts.Analyze:
+			return "VACUUM (ANALYZE) " + table, nil
+		}
+		return "VACUUM " + table, nil
+	case MaintenanceReindex:
+		return "REINDEX TABLE CONCURRENTLY " + table, nil
+	}
+	return "", fmt.Errorf("unsupported maintenance operation %q", operation)
+}
+
+// RunMaintenance executes a maintenance statement built by BuildMaintenanceSQL.
+// No query timeout is applied: maintenance on large tables routinely outlasts it,
+// so callers control duration through ctx.
+func (i *Introspector) RunMaintenance(ctx context.Context, operation, tableName string, opts MaintenanceOptions) error {
+	query, err := BuildMaintenanceSQL(operation, tableName, opts)
+	if err != nil {
+		return err
+	}
+
+	pool := i.getPool()
+	_, err = pool.Exec(ctx, query)
+	return err
+}
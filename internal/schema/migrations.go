@@ -0,0 +1,478 @@
+package schema
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MigrationsTable records the migrations ApplyMigration has run. It is created
+// on first use and hidden from the introspected schema.
+const MigrationsTable = "alt_migrations"
+
+// MigrationsBackupTable keeps the records SquashMigrations replaced with a
+// baseline. It is hidden from the introspected schema too.
+const MigrationsBackupTable = "alt_migrations_squashed"
+
+// Migration runner errors.
+var (
+	ErrMigrationApplied    = errors.New("migration already applied")
+	ErrMigrationNotApplied = errors.New("migration is not applied")
+	ErrNoDownScript        = errors.New("migration has no down script")
+	ErrChecksumMismatch    = errors.New("checksum mismatch")
+	ErrHistoryChanged      = errors.New("migration history changed")
+)
+
+// PendingMigration is a migration as ApplyMigration runs and records it.
+type PendingMigration struct {
+	Version int
+	Name    string
+	Up      string
+	// Down is stored for RollbackMigration; empty when there is none
+	Down string
+}
+
+// AppliedMigration is one row of the migrations table.
+type AppliedMigration struct {
+	Version      int       `json:"version"`
+	Name         string    `json:"name"`
+	Checksum     string    `json:"checksum"`               // SHA-256 of the up script when it ran
+	DownChecksum string    `json:"downChecksum,omitempty"` // SHA-256 of the stored down script
+	AppliedAt    time.Time `json:"appliedAt"`
+	ExecutionMs  int64     `json:"executionMs"`
+}
+
+// StatementLog is how one statement of an applied migration ran, kept for
+// post-mortems.
+type StatementLog struct {
+	// Statement is empty when the script could not be split into as many
+	// statements as the server ran
+	Statement    string            `json:"statement,omitempty"`
+	Command      string            `json:"command"` // Command tag, e.g. "ALTER TABLE" or "UPDATE 42"
+	DurationMs   float64           `json:"durationMs"`
+	RowsAffected int64             `json:"rowsAffected"`
+	Notices      []StatementNotice `json:"notices,omitempty"`
+	// Batched marks statements of a script that could not be split reliably,
+	// so it ran as one query. Their durations are not per statement: the
+	// server sends its replies together, so the first statement is charged
+	// with most of the run.
+	Batched bool `json:"batched,omitempty"`
+}
+
+// StatementNotice is a notice or warning the server sent while a statement
+// ran, such as those of RAISE NOTICE or of dropping what does not exist.
+type StatementNotice struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Detail   string `json:"detail,omitempty"`
+	Hint     string `json:"hint,omitempty"`
+}
+
+// MigrationLog is an applied migration with the log of its statements.
+type MigrationLog struct {
+	AppliedMigration
+	// Statements is null for migrations recorded before statement logs were
+	// kept, and for squash baselines, which were never run
+	Statements []StatementLog `json:"statements"`
+}
+
+// Checksum returns the hex-encoded SHA-256 of a migration script.
+func Checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// stripTransaction removes the BEGIN and COMMIT lines generated scripts are
+// wrapped in, since the runner executes them inside its own transaction.
+func stripTransaction(script string) string {
+	lines := strings.Split(script, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		switch strings.ToUpper(strings.TrimSpace(line)) {
+		case "BEGIN;", "COMMIT;":
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// GetAppliedMigrations returns the recorded migrations in version order, or
+// none if the migrations table does not exist yet.
+func (i *Introspector) GetAppliedMigrations(ctx context.Context) ([]AppliedMigration, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	pool := i.getPool()
+	exists, err := migrationsTableExists(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []AppliedMigration{}, nil
+	}
+
+	// Read down_checksum through to_jsonb, since a table no migration has
+	// been applied to since down scripts were added lacks the column
+	query := "SELECT version, name, checksum, COALESCE(to_jsonb(m) ->> 'down_checksum', ''), applied_at, execution_ms FROM " +
+		sanitizeIdentifier(MigrationsTable) + " m ORDER BY version"
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := []AppliedMigration{}
+	for rows.Next() {
+		var m AppliedMigration
+		if err := rows.Scan(&m.Version, &m.Name, &m.Checksum, &m.DownChecksum, &m.AppliedAt, &m.ExecutionMs); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, rows.Err()
+}
+
+// GetMigrationLog returns the record of an applied migration with the log of
+// its statements. Returns ErrMigrationNotApplied if version is not recorded.
+func (i *Introspector) GetMigrationLog(ctx context.Context, version int) (*MigrationLog, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	pool := i.getPool()
+	exists, err := migrationsTableExists(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %d", ErrMigrationNotApplied, version)
+	}
+
+	// Read through to_jsonb, since a table no migration has been applied to
+	// since down scripts or statement logs were added lacks the columns
+	query := "SELECT version, name, checksum, COALESCE(to_jsonb(m) ->> 'down_checksum', ''), applied_at, execution_ms, to_jsonb(m) -> 'statement_log' FROM " +
+		sanitizeIdentifier(MigrationsTable) + " m WHERE version = $1"
+	var m MigrationLog
+	var statements []byte
+	err = pool.QueryRow(ctx, query, version).Scan(&m.Version, &m.Name, &m.Checksum, &m.DownChecksum, &m.AppliedAt, &m.ExecutionMs, &statements)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", ErrMigrationNotApplied, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration: %w", err)
+	}
+	if statements != nil && string(statements) != "null" {
+		if err := json.Unmarshal(statements, &m.Statements); err != nil {
+			return nil, fmt.Errorf("failed to read statement log: %w", err)
+		}
+	}
+	return &m, nil
+}
+
+// migrationsTableExists reports whether the migrations table has been created.
+func migrationsTableExists(ctx context.Context, pool *pgxpool.Pool) (bool, error) {
+	var exists bool
+	lookup := `SELECT to_regclass(format('public.%I', $1::text)) IS NOT NULL`
+	if err := pool.QueryRow(ctx, lookup, MigrationsTable).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check for migrations table: %w", err)
+	}
+	return exists, nil
+}
+
+// beginner is a pool or connection transactions can be opened on.
+type beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// beginMigration creates the migrations table if needed and opens a
+// transaction on db holding its lock, so concurrent runners work one at a
+// time. No query timeout is applied, since migrations routinely outlast it;
+// callers control duration through ctx.
+func (i *Introspector) beginMigration(ctx context.Context, db beginner) (pgx.Tx, error) {
+	pool := i.getPool()
+	table := sanitizeIdentifier(MigrationsTable)
+
+	createCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	create := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			checksum text NOT NULL,
+			down_sql text,
+			down_checksum text,
+			applied_at timestamptz NOT NULL DEFAULT now(),
+			execution_ms bigint NOT NULL,
+			statement_log jsonb
+		)
+	`, table)
+	if _, err := pool.Exec(createCtx, create); err != nil {
+		return nil, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	// Tables created before down scripts and statement logs were kept
+	alter := "ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS down_sql text, ADD COLUMN IF NOT EXISTS down_checksum text, " +
+		"ADD COLUMN IF NOT EXISTS statement_log jsonb"
+	if _, err := pool.Exec(createCtx, alter); err != nil {
+		return nil, fmt.Errorf("failed to update migrations table: %w", err)
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// SHARE ROW EXCLUSIVE conflicts with itself but not with readers
+	if _, err := tx.Exec(ctx, "LOCK TABLE "+table+" IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to lock migrations table: %w", err)
+	}
+	return tx, nil
+}
+
+// ApplyMigration runs m.Up and records it, with m.Down for a later rollback
+// and the log of its statements, in one transaction, so a failed migration
+// leaves neither changes nor a record. It runs on a connection of its own,
+// outside the pool, to collect the notices the server sends. Returns
+// ErrMigrationApplied if m.Version is already recorded.
+func (i *Introspector) ApplyMigration(ctx context.Context, m PendingMigration) (*AppliedMigration, error) {
+	var notices []StatementNotice
+	config := i.getPool().Config().ConnConfig
+	config.OnNotice = func(_ *pgconn.PgConn, n *pgconn.Notice) {
+		notices = append(notices, StatementNotice{Severity: n.Severity, Code: n.Code, Message: n.Message, Detail: n.Detail, Hint: n.Hint})
+	}
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	tx, err := i.beginMigration(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	table := sanitizeIdentifier(MigrationsTable)
+	var applied bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM "+table+" WHERE version = $1)", m.Version).Scan(&applied); err != nil {
+		return nil, fmt.Errorf("failed to check migration: %w", err)
+	}
+	if applied {
+		return nil, fmt.Errorf("%w: %d", ErrMigrationApplied, m.Version)
+	}
+
+	notices = nil // Sent while locking, if any
+	start := time.Now()
+	statements, err := execLogged(ctx, tx, stripTransaction(m.Up), &notices)
+	if err != nil {
+		return nil, fmt.Errorf("migration failed at statement %d: %w", len(statements)+1, err)
+	}
+	result := AppliedMigration{Version: m.Version, Name: m.Name, Checksum: Checksum(m.Up), ExecutionMs: time.Since(start).Milliseconds()}
+
+	var down *string
+	if m.Down != "" {
+		down = &m.Down
+		result.DownChecksum = Checksum(m.Down)
+	}
+	statementLog, err := json.Marshal(statements)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statement log: %w", err)
+	}
+	insert := "INSERT INTO " + table + " (version, name, checksum, down_sql, down_checksum, execution_ms, statement_log) " +
+		"VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7::jsonb) RETURNING applied_at"
+	if err := tx.QueryRow(ctx, insert, m.Version, m.Name, result.Checksum, down, result.DownChecksum, result.ExecutionMs, string(statementLog)).Scan(&result.AppliedAt); err != nil {
+		return nil, fmt.Errorf("failed to record migration: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+	return &result, nil
+}
+
+// escapeStringPattern matches the start of an E” string, whose backslash
+// escapes SplitStatements does not follow.
+var escapeStringPattern = regexp.MustCompile(`(?i)(^|[^a-z0-9_$])e'`)
+
+// execLogged runs the statements of script one at a time, logging each: its
+// command tag, rows affected, duration, and the notices collected into
+// notices while it ran. Scripts SplitStatements may split wrongly run as one
+// query instead, as tx.Exec would, and are logged by execBatched. On failure
+// it returns the statements that completed with the error.
+func execLogged(ctx context.Context, tx pgx.Tx, script string, notices *[]StatementNotice) ([]StatementLog, error) {
+	if escapeStringPattern.MatchString(script) {
+		return execBatched(ctx, tx, script, notices)
+	}
+
+	statements := []StatementLog{}
+	for _, stmt := range SplitStatements(script) {
+		start := time.Now()
+		tag, err := tx.Exec(ctx, stmt)
+		if err != nil {
+			return statements, err
+		}
+		statements = append(statements, StatementLog{
+			Statement:    stmt,
+			Command:      tag.String(),
+			DurationMs:   float64(time.Since(start).Microseconds()) / 1000,
+			RowsAffected: tag.RowsAffected(),
+			Notices:      *notices,
+		})
+		*notices = nil
+	}
+	return statements, nil
+}
+
+// execBatched runs script as one simple query and logs each statement as its
+// result is read, marked Batched, since the server holds back the results
+// until the script ends.
+func execBatched(ctx context.Context, tx pgx.Tx, script string, notices *[]StatementNotice) ([]StatementLog, error) {
+	statements := []StatementLog{}
+	results := tx.Conn().PgConn().Exec(ctx, script)
+	last := time.Now()
+	for results.NextResult() {
+		tag, err := results.ResultReader().Close()
+		if err != nil {
+			break // Returned by Close below
+		}
+		statements = append(statements, StatementLog{
+			Command:      tag.String(),
+			DurationMs:   float64(time.Since(last).Microseconds()) / 1000,
+			RowsAffected: tag.RowsAffected(),
+			Notices:      *notices,
+			Batched:      true,
+		})
+		*notices = nil
+		last = time.Now()
+	}
+	if err := results.Close(); err != nil {
+		return statements, err
+	}
+
+	// Label statements only when the splitter finds as many as the server ran
+	if split := SplitStatements(script); len(split) == len(statements) {
+		for idx := range statements {
+			statements[idx].Statement = split[idx]
+		}
+	}
+	return statements, nil
+}
+
+// RollbackMigration runs the down script stored for version and removes its
+// record in one transaction. The script is checked against the checksum
+// recorded with it before it runs. Returns ErrMigrationNotApplied,
+// ErrNoDownScript, or ErrChecksumMismatch.
+func (i *Introspector) RollbackMigration(ctx context.Context, version int) error {
+	tx, err := i.beginMigration(ctx, i.getPool())
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	table := sanitizeIdentifier(MigrationsTable)
+	var down, downChecksum *string
+	err = tx.QueryRow(ctx, "SELECT down_sql, down_checksum FROM "+table+" WHERE version = $1", version).Scan(&down, &downChecksum)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %d", ErrMigrationNotApplied, version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get migration: %w", err)
+	}
+	if down == nil {
+		return fmt.Errorf("%w: %d", ErrNoDownScript, version)
+	}
+	if downChecksum == nil || Checksum(*down) != *downChecksum {
+		return fmt.Errorf("%w: stored down script of %d", ErrChecksumMismatch, version)
+	}
+
+	if _, err := tx.Exec(ctx, stripTransaction(*down)); err != nil {
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM "+table+" WHERE version = $1", version); err != nil {
+		return fmt.Errorf("failed to remove migration record: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// SquashMigrations replaces the records of the applied migrations, which must
+// be exactly versions, with one record of baseline, in one transaction. The
+// baseline is recorded as applied without running its up script, since it
+// describes the schema those migrations built. The replaced records are
+// copied to MigrationsBackupTable first, marked with the baseline's version.
+// Returns ErrHistoryChanged if other migrations were applied or rolled back
+// since versions were listed.
+func (i *Introspector) SquashMigrations(ctx context.Context, versions []int, baseline PendingMigration) (*AppliedMigration, error) {
+	tx, err := i.beginMigration(ctx, i.getPool())
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	table, backup := sanitizeIdentifier(MigrationsTable), sanitizeIdentifier(MigrationsBackupTable)
+	var recorded []int
+	if err := tx.QueryRow(ctx, "SELECT COALESCE(array_agg(version ORDER BY version), '{}') FROM "+table).Scan(&recorded); err != nil {
+		return nil, fmt.Errorf("failed to check migrations: %w", err)
+	}
+	if !slices.Equal(recorded, versions) {
+		return nil, ErrHistoryChanged
+	}
+
+	create := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			version bigint NOT NULL,
+			name text NOT NULL,
+			checksum text NOT NULL,
+			down_sql text,
+			down_checksum text,
+			applied_at timestamptz NOT NULL,
+			execution_ms bigint NOT NULL,
+			squashed_into bigint NOT NULL,
+			squashed_at timestamptz NOT NULL DEFAULT now(),
+			statement_log jsonb
+		)
+	`, backup)
+	if _, err := tx.Exec(ctx, create); err != nil {
+		return nil, fmt.Errorf("failed to create migrations backup table: %w", err)
+	}
+	// Tables created before statement logs were kept
+	if _, err := tx.Exec(ctx, "ALTER TABLE "+backup+" ADD COLUMN IF NOT EXISTS statement_log jsonb"); err != nil {
+		return nil, fmt.Errorf("failed to update migrations backup table: %w", err)
+	}
+	copyRecords := "INSERT INTO " + backup + " (version, name, checksum, down_sql, down_checksum, applied_at, execution_ms, squashed_into, statement_log) " +
+		"SELECT version, name, checksum, down_sql, down_checksum, applied_at, execution_ms, $1, statement_log FROM " + table
+	if _, err := tx.Exec(ctx, copyRecords, baseline.Version); err != nil {
+		return nil, fmt.Errorf("failed to back up migration records: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM "+table); err != nil {
+		return nil, fmt.Errorf("failed to remove migration records: %w", err)
+	}
+
+	result := AppliedMigration{Version: baseline.Version, Name: baseline.Name, Checksum: Checksum(baseline.Up)}
+	var down *string
+	if baseline.Down != "" {
+		down = &baseline.Down
+		result.DownChecksum = Checksum(baseline.Down)
+	}
+	insert := "INSERT INTO " + table + " (version, name, checksum, down_sql, down_checksum, execution_ms) " +
+		"VALUES ($1, $2, $3, $4, NULLIF($5, ''), 0) RETURNING applied_at"
+	if err := tx.QueryRow(ctx, insert, result.Version, result.Name, result.Checksum, down, result.DownChecksum).Scan(&result.AppliedAt); err != nil {
+		return nil, fmt.Errorf("failed to record baseline: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+	return &result, nil
+}
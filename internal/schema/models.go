@@ -1,30 +1,190 @@
 package schema
 
+import "time"
+
 // Column represents a single column in a database table.
 type Column struct {
 	Name       string  `json:"name"`
+	Position   int     `json:"position"` // 1-based place in the column order; dropped columns leave no gaps
 	DataType   string  `json:"dataType"`
 	IsNullable bool    `json:"isNullable"`
 	IsPrimary  bool    `json:"isPrimary"`
 	IsUnique   bool    `json:"isUnique"`
 	Default    *string `json:"default,omitempty"`
+	// DefaultValue classifies Default for semantic comparison; nil when there is no default
+	DefaultValue *ColumnDefault `json:"defaultValue,omitempty"`
+	// TypeCategory is base, array, range, multirange, enum, composite, or domain
+	TypeCategory string `json:"typeCategory"`
+	ElementType  string `json:"elementType,omitempty"` // For arrays, e.g. integer for integer[]
+	// Type modifiers, e.g. varchar(255) or numeric(10,2); nil when not applicable
+	CharacterMaximumLength *int32  `json:"characterMaximumLength,omitempty"`
+	NumericPrecision       *int32  `json:"numericPrecision,omitempty"`
+	NumericScale           *int32  `json:"numericScale,omitempty"`
+	Collation              *string `json:"collation,omitempty"` // Only set when not the database default
+	// Generated columns (GENERATED ALWAYS AS (...) STORED) are computed and read-only
+	IsGenerated          bool    `json:"isGenerated"`
+	GenerationExpression *string `json:"generationExpression,omitempty"`
+	// Identity is ALWAYS or BY DEFAULT for GENERATED ... AS IDENTITY columns.
+	Identity string `json:"identity,omitempty"`
+	// Sequence is the sequence owned by this column, set for serial and identity columns.
+	Sequence string `json:"sequence,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+	// Storage is set when the column's storage mode differs from its type's
+	// default: plain, external, extended, or main.
+	Storage string `json:"storage,omitempty"`
+	// StatisticsTarget is set when the column overrides default_statistics_target.
+	StatisticsTarget *int32 `json:"statisticsTarget,omitempty"`
+	// Domain names the domain the column is declared with; DataType is its base type.
+	Domain string `json:"domain,omitempty"`
+	// Extension names the extension that provides this column's type, e.g. citext.
+	Extension string `json:"extension,omitempty"`
 }
 
 // ForeignKey represents a foreign key constraint.
+// Columns[i] references ReferencesColumns[i]; composite keys have several pairs.
 type ForeignKey struct {
-	ColumnName       string `json:"columnName"`
-	ReferencesTable  string `json:"referencesTable"`
-	ReferencesColumn string `json:"referencesColumn"`
+	ConstraintName    string   `json:"constraintName"`
+	Columns           []string `json:"columns"`
+	ReferencesTable   string   `json:"referencesTable"`
+	ReferencesColumns []string `json:"referencesColumns"`
+	OnDelete          string   `json:"onDelete"` // NO ACTION, RESTRICT, CASCADE, SET NULL, SET DEFAULT
+	OnUpdate          string   `json:"onUpdate"`
+	Deferrable        bool     `json:"deferrable"`        // Can be checked at commit with SET CONSTRAINTS ... DEFERRED
+	InitiallyDeferred bool     `json:"initiallyDeferred"` // Checked at commit unless a transaction sets it IMMEDIATE
+	Comment           string   `json:"comment,omitempty"`
+}
+
+// Index represents an index on a table.
+// Columns holds column names, or the expression text for expression indexes.
+type Index struct {
+	Name      string   `json:"name"`
+	Columns   []string `json:"columns"`
+	IsUnique  bool     `json:"isUnique"`
+	IsPrimary bool     `json:"isPrimary"`
+	Method    string   `json:"method"`              // btree, hash, gin, gist, brin, ...
+	Predicate *string  `json:"predicate,omitempty"` // WHERE clause of a partial index
+	// Usage since statistics were last reset; LastUsed needs PostgreSQL 16+
+	Scans    int64      `json:"scans"`
+	LastUsed *time.Time `json:"lastUsed,omitempty"`
+	// Tablespace is empty when the index uses the database default
+	Tablespace string `json:"tablespace,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// PrimaryKey represents a table's primary key constraint.
+type PrimaryKey struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"` // In key order, which matters for composite keys
+	Comment string   `json:"comment,omitempty"`
+}
+
+// UniqueConstraint represents a UNIQUE constraint, possibly spanning several columns.
+// Column.IsUnique is only set for single-column constraints.
+type UniqueConstraint struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"` // In constraint order
+	Comment string   `json:"comment,omitempty"`
+}
+
+// CheckConstraint represents a CHECK constraint on a table.
+type CheckConstraint struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// ExclusionConstraint represents an EXCLUDE constraint: no two rows may have
+// every element compare true under its operator.
+type ExclusionConstraint struct {
+	Name       string             `json:"name"`
+	Method     string             `json:"method"` // Index access method, usually gist
+	Elements   []ExclusionElement `json:"elements"`
+	Predicate  *string            `json:"predicate,omitempty"` // WHERE clause, if partial
+	Definition string             `json:"definition"`          // As in pg_get_constraintdef
+	Comment    string             `json:"comment,omitempty"`
+}
+
+// ExclusionElement pairs a column (or expression) with its exclusion operator.
+type ExclusionElement struct {
+	Column   string `json:"column"`
+	Operator string `json:"operator"` // e.g. "=" or "&&"
+}
+
+// Trigger represents a user-defined trigger on a table.
+type Trigger struct {
+	Name     string   `json:"name"`
+	Timing   string   `json:"timing"`   // BEFORE, AFTER, INSTEAD OF
+	Events   []string `json:"events"`   // INSERT, UPDATE, DELETE, TRUNCATE
+	Level    string   `json:"level"`    // ROW or STATEMENT
+	Function string   `json:"function"` // Trigger function called
+	Enabled  bool     `json:"enabled"`
+}
+
+// Partitioning describes a declaratively partitioned table.
+type Partitioning struct {
+	Strategy   string      `json:"strategy"`   // RANGE, LIST, or HASH
+	Key        string      `json:"key"`        // Full key definition, e.g. "RANGE (created_at)"
+	Columns    []string    `json:"columns"`    // Key columns; expression keys are omitted
+	Partitions []Partition `json:"partitions"` // Direct children
+}
+
+// Partition is one child of a partitioned table.
+type Partition struct {
+	Name          string `json:"name"`
+	Bound         string `json:"bound"` // e.g. "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')"
+	EstimatedRows int64  `json:"estimatedRows"`
+	SizeBytes     int64  `json:"sizeBytes"`
+}
+
+// Grant lists the table privileges held by one role.
+type Grant struct {
+	Role       string   `json:"role"`
+	Privileges []string `json:"privileges"` // Subset of SELECT, INSERT, UPDATE, DELETE
 }
 
 // Table represents a database table with its columns and relationships.
 type Table struct {
-	Name        string       `json:"name"`
-	Columns     []Column     `json:"columns"`
-	ForeignKeys []ForeignKey `json:"foreignKeys"`
+	Name              string                `json:"name"`
+	Comment           string                `json:"comment,omitempty"`
+	EstimatedRows     int64                 `json:"estimatedRows"`               // pg_class.reltuples; 0 until analyzed
+	SizeBytes         int64                 `json:"sizeBytes"`                   // Including indexes and TOAST
+	Tablespace        string                `json:"tablespace,omitempty"`        // Empty means the database default
+	StorageParameters []string              `json:"storageParameters,omitempty"` // reloptions, key=value
+	Columns           []Column              `json:"columns"`
+	PrimaryKey        *PrimaryKey           `json:"primaryKey,omitempty"`
+	ForeignKeys       []ForeignKey          `json:"foreignKeys"`
+	Indexes           []Index               `json:"indexes"`
+	UniqueConstraints []UniqueConstraint    `json:"uniqueConstraints"`
+	CheckConstraints  []CheckConstraint     `json:"checkConstraints"`
+	Exclusions        []ExclusionConstraint `json:"exclusions"`
+	Triggers          []Trigger             `json:"triggers"`
+	Partitioning      *Partitioning         `json:"partitioning,omitempty"`
+	ParentTables      []string              `json:"parentTables,omitempty"` // INHERITS parents, in declaration order
+	Grants            []Grant               `json:"grants,omitempty"`       // Only populated on request; see GetTableGrants
+	// Foreign tables hold no local rows; Foreign names the server that serves them
+	IsForeign bool          `json:"isForeign"`
+	Foreign   *ForeignTable `json:"foreign,omitempty"`
+	// IsSystem marks pg_catalog and information_schema tables; see GetSystemTables
+	IsSystem bool `json:"isSystem,omitempty"`
+}
+
+// Sequence represents a sequence and, for serial and identity columns, its owner.
+type Sequence struct {
+	Name          string `json:"name"`
+	DataType      string `json:"dataType"`
+	StartValue    int64  `json:"startValue"`
+	Increment     int64  `json:"increment"`
+	CurrentValue  *int64 `json:"currentValue,omitempty"` // Nil until nextval is first called
+	OwnedByTable  string `json:"ownedByTable,omitempty"`
+	OwnedByColumn string `json:"ownedByColumn,omitempty"`
 }
 
 // Schema represents the complete database schema.
 type Schema struct {
-	Tables []Table `json:"tables"`
+	Tables         []Table         `json:"tables"`
+	Sequences      []Sequence      `json:"sequences"`
+	ForeignServers []ForeignServer `json:"foreignServers"`
+	Warnings       []SchemaWarning `json:"warnings"`
+	// ForeignKeyCycles lists groups of tables that reference each other
+	ForeignKeyCycles []ForeignKeyCycle `json:"foreignKeyCycles"`
 }
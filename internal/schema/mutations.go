@@ -2,57 +2,881 @@ package schema
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 )
 
+// ErrTableNotFound is returned when a mutation targets a table that does not exist.
+var ErrTableNotFound = errors.New("table not found")
+
+// ErrIndexNotFound is returned when a mutation targets an index that is not on the given table.
+var ErrIndexNotFound = errors.New("index not found")
+
+type ddlPreviewKey struct{}
+
+// DDLPreview collects the statements a mutation would run. See WithDDLPreview.
+type DDLPreview struct {
+	Statements []string `json:"statements"`
+}
+
+// WithDDLPreview returns a context under which mutations record their DDL in
+// the returned preview instead of executing it. Read-only lookups a mutation
+// needs, such as resolving primary keys, still run.
+func WithDDLPreview(ctx context.Context) (context.Context, *DDLPreview) {
+	preview := &DDLPreview{Statements: []string{}}
+	return context.WithValue(ctx, ddlPreviewKey{}, preview), preview
+}
+
+// isPreview reports whether ctx was returned by WithDDLPreview.
+func isPreview(ctx context.Context) bool {
+	_, ok := ctx.Value(ddlPreviewKey{}).(*DDLPreview)
+	return ok
+}
+
+// execDDL runs the statements of one mutation, in a transaction when there
+// are several, or records them when ctx carries a DDLPreview.
+func (i *Introspector) execDDL(ctx context.Context, statements ...string) error {
+	if preview, ok := ctx.Value(ddlPreviewKey{}).(*DDLPreview); ok {
+		preview.Statements = append(preview.Statements, statements...)
+		return nil
+	}
+
+	pool := i.getPool()
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	if len(statements) == 1 {
+		_, err := pool.Exec(ctx, statements[0])
+		return err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// CreateTableRequest represents a request to create a table.
+type CreateTableRequest struct {
+	Name    string             `json:"name"`
+	Columns []AddColumnRequest `json:"columns,omitempty"`
+	// PrimaryKey lists key columns, in order, for a composite key such as a
+	// join table's. Empty adds an id SERIAL PRIMARY KEY column instead, or a
+	// bigint identity column when IDIdentity is set.
+	PrimaryKey []string     `json:"primaryKey,omitempty"`
+	IDIdentity *IdentityDef `json:"idIdentity,omitempty"`
+}
+
 // AddColumnRequest represents a request to add a column to a table.
 type AddColumnRequest struct {
-	Name       string      `json:"name"`
-	Type       string      `json:"type"`
-	Nullable   bool        `json:"nullable"`
-	PrimaryKey bool        `json:"primaryKey"`
-	Unique     bool        `json:"unique"`
-	ForeignKey *ForeignKey `json:"foreignKey,omitempty"`
+	Name       string           `json:"name"`
+	Type       string           `json:"type"`
+	Nullable   bool             `json:"nullable"`
+	PrimaryKey bool             `json:"primaryKey"`
+	Unique     bool             `json:"unique"`
+	ForeignKey *ColumnReference `json:"foreignKey,omitempty"`
+	Generated  *GeneratedExpr   `json:"generated,omitempty"` // Computed from other columns and stored
+	Identity   *IdentityDef     `json:"identity,omitempty"`  // Instead of a serial type
+}
+
+// ColumnReference names the column a new single-column foreign key points at.
+type ColumnReference struct {
+	ReferencesTable   string `json:"referencesTable"`
+	ReferencesColumn  string `json:"referencesColumn"`
+	Deferrable        bool   `json:"deferrable"`
+	InitiallyDeferred bool   `json:"initiallyDeferred"` // Requires Deferrable
+}
+
+// AlterColumnTypeRequest represents a request to change a column's type.
+type AlterColumnTypeRequest struct {
+	Type  string `json:"type"`
+	Using string `json:"using,omitempty"` // Name of a ConversionTemplate
+}
+
+// AddForeignKeyRequest represents a request to add a foreign key to existing columns.
+type AddForeignKeyRequest struct {
+	Column            string `json:"column"`
+	ReferencesTable   string `json:"referencesTable"`
+	ReferencesColumn  string `json:"referencesColumn"`
+	NotValid          bool   `json:"notValid"`
+	Deferrable        bool   `json:"deferrable"`
+	InitiallyDeferred bool   `json:"initiallyDeferred"` // Requires Deferrable
 }
 
-// CreateTable creates a new table with an auto-incrementing id primary key.
-func (i *Introspector) CreateTable(ctx context.Context, tableName string) error {
-	query, err := BuildCreateTableDDL(tableName)
+// CreateTable creates a new table. Without req.PrimaryKey it gets an
+// auto-incrementing id primary key. customTypes lists the user-defined types
+// the columns may use; see IsValidType.
+func (i *Introspector) CreateTable(ctx context.Context, req CreateTableRequest, customTypes []string) error {
+	columns := make([]ColumnDef, len(req.Columns))
+	for idx, c := range req.Columns {
+		columns[idx] = columnDef(c)
+	}
+
+	query, err := BuildCreateTableDDL(req.Name, columns, req.PrimaryKey, req.IDIdentity, customTypes)
 	if err != nil {
 		return err
 	}
+	known := columns
+	if len(req.PrimaryKey) == 0 {
+		// The id column BuildCreateTableDDL adds, which generated columns may use
+		known = append([]ColumnDef{{Name: "id"}}, columns...)
+	}
+	if err := i.checkGeneratedColumns(ctx, req.Name, known); err != nil {
+		return err
+	}
 
-	pool := i.getPool()
-	ctx, cancel := i.withTimeout(ctx)
-	defer cancel()
+	undo, err := BuildDropTableDDL(req.Name, false)
+	if err != nil {
+		return err
+	}
 
-	_, err = pool.Exec(ctx, query)
-	return err
+	return i.applyChange(ctx, Change{
+		Description: "Create table " + req.Name,
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
 }
 
-// AddColumn adds a new column to an existing table.
-func (i *Introspector) AddColumn(ctx context.Context, tableName string, req AddColumnRequest) error {
+// CreateObjects runs statements generated from a design, such as an imported
+// DBML document, as one undoable change; inverse drops what they create. The
+// statements run as given, so callers build them from validated names.
+func (i *Introspector) CreateObjects(ctx context.Context, description string, statements, inverse []string) error {
+	return i.applyChange(ctx, Change{
+		Description: description,
+		Statements:  statements,
+		Inverse:     inverse,
+	})
+}
+
+// ApplyTemplate runs statements expanded from a migration template as one
+// undoable change; inverse reverses them, and without it the change cannot be
+// undone. The statements run as given, so callers validate what went into them.
+func (i *Introspector) ApplyTemplate(ctx context.Context, description string, statements, inverse []string) error {
+	c := Change{
+		Description: description,
+		Statements:  statements,
+		Inverse:     inverse,
+	}
+	if len(inverse) == 0 {
+		c.Irreversible = "the template has no down statements"
+	}
+	return i.applyChange(ctx, c)
+}
+
+// CloneTable creates an empty copy of a table's structure under a new name.
+func (i *Introspector) CloneTable(ctx context.Context, sourceTable, newName string, opts CloneOptions) error {
+	query, err := BuildCloneTableDDL(sourceTable, newName, opts)
+	if err != nil {
+		return err
+	}
+	undo, err := BuildDropTableDDL(newName, false)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Clone table %s as %s", sourceTable, newName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// columnDef converts a column request into its DDL definition.
+func columnDef(req AddColumnRequest) ColumnDef {
 	col := ColumnDef{
 		Name:       req.Name,
 		Type:       req.Type,
 		NotNull:    !req.Nullable,
 		PrimaryKey: req.PrimaryKey,
 		Unique:     req.Unique,
+		Generated:  req.Generated,
+		Identity:   req.Identity,
 	}
 
 	if req.ForeignKey != nil {
 		col.ReferencesTable = req.ForeignKey.ReferencesTable
 		col.ReferencesColumn = req.ForeignKey.ReferencesColumn
+		col.Deferrable = req.ForeignKey.Deferrable
+		col.InitiallyDeferred = req.ForeignKey.InitiallyDeferred
+	}
+	return col
+}
+
+// AddColumn adds a new column to an existing table.
+// customTypes lists the user-defined types the column may use; see IsValidType.
+func (i *Introspector) AddColumn(ctx context.Context, tableName string, req AddColumnRequest, customTypes []string) error {
+	col := columnDef(req)
+	query, err := BuildAddColumnDDL(tableName, col, customTypes)
+	if err != nil {
+		return err
+	}
+	if err := i.checkGeneratedColumns(ctx, tableName, []ColumnDef{col}); err != nil {
+		return err
+	}
+
+	undo, err := BuildDropColumnDDL(tableName, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Add column %s.%s", tableName, req.Name),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// AddColumns adds several columns to an existing table in one ALTER TABLE
+// statement. customTypes lists the user-defined types the columns may use.
+func (i *Introspector) AddColumns(ctx context.Context, tableName string, reqs []AddColumnRequest, customTypes []string) error {
+	columns := make([]ColumnDef, len(reqs))
+	names := make([]string, len(reqs))
+	for idx, req := range reqs {
+		columns[idx] = columnDef(req)
+		names[idx] = req.Name
+	}
+
+	query, err := BuildAddColumnsDDL(tableName, columns, customTypes)
+	if err != nil {
+		return err
+	}
+	if err := i.checkGeneratedColumns(ctx, tableName, columns); err != nil {
+		return err
+	}
+
+	undo := make([]string, len(names))
+	for idx, name := range names {
+		if undo[idx], err = BuildDropColumnDDL(tableName, name); err != nil {
+			return err
+		}
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Add columns %s to %s", strings.Join(names, ", "), tableName),
+		Statements:  []string{query},
+		Inverse:     undo,
+	})
+}
+
+// AddForeignKey adds a foreign key constraint between existing columns.
+func (i *Introspector) AddForeignKey(ctx context.Context, tableName string, req AddForeignKeyRequest) error {
+	name, err := i.newConstraintName(ctx, tableName, req.Column, "fkey")
+	if err != nil {
+		return err
+	}
+	query, err := BuildAddForeignKeyDDL(tableName, ForeignKeyDef{
+		Name:              name,
+		Column:            req.Column,
+		ReferencesTable:   req.ReferencesTable,
+		ReferencesColumn:  req.ReferencesColumn,
+		NotValid:          req.NotValid,
+		Deferrable:        req.Deferrable,
+		InitiallyDeferred: req.InitiallyDeferred,
+	})
+	if err != nil {
+		return err
+	}
+
+	undo, err := BuildDropConstraintDDL(tableName, name, false)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Add foreign key %s.%s -> %s.%s", tableName, req.Column, req.ReferencesTable, req.ReferencesColumn),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// newConstraintName names a new constraint on a table's column the way
+// PostgreSQL would: <table>_<column>_<suffix>, shortening the longer of table
+// and column until it fits in 63 bytes, and numbering it when a constraint in
+// the schema already has the name. Naming it up front lets undo drop exactly
+// the constraint that was added.
+func (i *Introspector) newConstraintName(ctx context.Context, tableName, column, suffix string) (string, error) {
+	table := tableName
+	for len(table)+len(column)+len(suffix)+2 > 63 {
+		if len(table) >= len(column) {
+			table = table[:len(table)-1]
+		} else {
+			column = column[:len(column)-1]
+		}
+	}
+	base := table + "_" + column
+
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	lookup := `SELECT EXISTS (SELECT 1 FROM pg_constraint WHERE connamespace = 'public'::regnamespace AND conname = $1)`
+	for n := 0; ; n++ {
+		name := base + "_" + suffix
+		if n > 0 {
+			// Shorten further so the number fits too
+			number := strconv.Itoa(n)
+			trimmed := base
+			if over := len(base) + len(suffix) + len(number) + 1 - 63; over > 0 {
+				trimmed = base[:len(base)-over]
+			}
+			name = trimmed + "_" + suffix + number
+		}
+		var taken bool
+		if err := i.getPool().QueryRow(ctx, lookup, name).Scan(&taken); err != nil {
+			return "", fmt.Errorf("failed to look up constraint names: %w", err)
+		}
+		if !taken {
+			return name, nil
+		}
+	}
+}
+
+// DependentObject is an object that would be dropped along with a table.
+type DependentObject struct {
+	Kind  string `json:"kind"` // foreign-key, view, or materialized view
+	Name  string `json:"name"`
+	Table string `json:"table"` // Table holding the foreign key; the view itself for views
+}
+
+// GetTableDependents lists the foreign keys on other tables and the views that
+// depend on a table. Returns ErrTableNotFound if the table does not exist.
+func (i *Introspector) GetTableDependents(ctx context.Context, tableName string) ([]DependentObject, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	pool := i.getPool()
+	var exists bool
+	existsQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = 'public' AND c.relname = $1 AND c.relkind IN ('r', 'p', 'f')
+		)
+	`
+	if err := pool.QueryRow(ctx, existsQuery, tableName).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up table: %w", err)
+	}
+	if !exists {
+		return nil, ErrTableNotFound
+	}
+
+	query := `
+		WITH target AS (SELECT format('public.%I', $1::text)::regclass AS oid)
+		SELECT 'foreign-key', con.conname, t.relname
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		WHERE con.contype = 'f'
+		  AND con.confrelid = (SELECT oid FROM target)
+		  AND con.conrelid <> con.confrelid -- Self-references go with the table
+
+		UNION
+
+		SELECT CASE v.relkind WHEN 'm' THEN 'materialized view' ELSE 'view' END, v.relname, v.relname
+		FROM pg_depend d
+		JOIN pg_rewrite rw ON rw.oid = d.objid
+		JOIN pg_class v ON v.oid = rw.ev_class
+		WHERE d.classid = 'pg_rewrite'::regclass
+		  AND d.refclassid = 'pg_class'::regclass
+		  AND d.refobjid = (SELECT oid FROM target)
+		  AND v.oid <> d.refobjid
+
+		ORDER BY 1, 3, 2
+	`
+
+	rows, err := pool.Query(ctx, query, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table dependents: %w", err)
+	}
+	defer rows.Close()
+
+	dependents := make([]DependentObject, 0)
+	for rows.Next() {
+		var d DependentObject
+		if err := rows.Scan(&d.Kind, &d.Name, &d.Table); err != nil {
+			return nil, fmt.Errorf("failed to scan table dependent: %w", err)
+		}
+		dependents = append(dependents, d)
+	}
+
+	return dependents, rows.Err()
+}
+
+// DropTable drops a table. With cascade, dependent foreign keys and views
+// are dropped too; without it PostgreSQL refuses if any exist.
+func (i *Introspector) DropTable(ctx context.Context, tableName string, cascade bool) error {
+	query, err := BuildDropTableDDL(tableName, cascade)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description:  "Drop table " + tableName,
+		Statements:   []string{query},
+		Irreversible: "dropping a table discards its rows",
+	})
+}
+
+// ErrRowCountChanged is returned by TruncateTable when the table no longer
+// holds the number of rows the caller confirmed.
+var ErrRowCountChanged = errors.New("row count changed")
+
+// CountRows returns the exact number of rows in a table. This scans the table.
+func (i *Introspector) CountRows(ctx context.Context, tableName string) (int64, error) {
+	if !ValidIdentifier(tableName) {
+		return 0, fmt.Errorf("invalid table name")
+	}
+	query := "SELECT count(*) FROM " + sanitizeIdentifier(tableName)
+
+	pool := i.getPool()
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	var count int64
+	if err := pool.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return count, nil
+}
+
+// TruncateTable removes every row of a table, provided it still holds
+// expectedRows. The count is rechecked under an exclusive lock in the same
+// transaction, so rows written since the caller looked are never lost silently.
+func (i *Introspector) TruncateTable(ctx context.Context, tableName string, expectedRows int64, restartIdentity, cascade bool) error {
+	query, err := BuildTruncateDDL(tableName, restartIdentity, cascade)
+	if err != nil {
+		return err
+	}
+	change := Change{
+		Description:  fmt.Sprintf("Truncate table %s (%d rows)", tableName, expectedRows),
+		Statements:   []string{query},
+		Irreversible: "truncating discards the table's rows",
+	}
+	if isPreview(ctx) {
+		return i.execDDL(ctx, query)
+	}
+
+	pool := i.getPool()
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	table := sanitizeIdentifier(tableName)
+	if _, err := tx.Exec(ctx, "LOCK TABLE "+table+" IN ACCESS EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock table: %w", err)
+	}
+	var count int64
+	if err := tx.QueryRow(ctx, "SELECT count(*) FROM "+table).Scan(&count); err != nil {
+		return fmt.Errorf("failed to count rows: %w", err)
+	}
+	if count != expectedRows {
+		return fmt.Errorf("%w: expected %d, found %d", ErrRowCountChanged, expectedRows, count)
+	}
+	if _, err := tx.Exec(ctx, query); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	i.recordChange(change)
+	return nil
+}
+
+// RenameTable renames a table. Foreign keys, indexes, and views follow the
+// table automatically since PostgreSQL tracks them by OID.
+func (i *Introspector) RenameTable(ctx context.Context, tableName, newName string) error {
+	query, err := BuildRenameTableDDL(tableName, newName)
+	if err != nil {
+		return err
+	}
+
+	undo, err := BuildRenameTableDDL(newName, tableName)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Rename table %s to %s", tableName, newName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// RenameColumn renames a column of a table.
+func (i *Introspector) RenameColumn(ctx context.Context, tableName, columnName, newName string) error {
+	query, err := BuildRenameColumnDDL(tableName, columnName, newName)
+	if err != nil {
+		return err
 	}
 
-	query, err := BuildAddColumnDDL(tableName, col)
+	undo, err := BuildRenameColumnDDL(tableName, newName, columnName)
 	if err != nil {
 		return err
 	}
 
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Rename column %s.%s to %s", tableName, columnName, newName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// AlterColumnType changes a column's type, converting existing values with the
+// requested template. customTypes lists the user-defined types allowed; see IsValidType.
+func (i *Introspector) AlterColumnType(ctx context.Context, tableName, columnName string, req AlterColumnTypeRequest, customTypes []string) error {
+	query, err := BuildAlterColumnTypeDDL(tableName, columnName, req.Type, req.Using, customTypes)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description:  fmt.Sprintf("Change type of %s.%s to %s", tableName, columnName, req.Type),
+		Statements:   []string{query},
+		Irreversible: "converting values back to the previous type may not restore them",
+	})
+}
+
+// CountNulls returns how many rows hold NULL in a column. This scans the
+// table, so it is only run before SET NOT NULL, which scans it anyway.
+func (i *Introspector) CountNulls(ctx context.Context, tableName, columnName string) (int64, error) {
+	if !ValidIdentifier(tableName) || !ValidIdentifier(columnName) {
+		return 0, fmt.Errorf("invalid table or column name")
+	}
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE %s IS NULL",
+		sanitizeIdentifier(tableName), sanitizeIdentifier(columnName))
+
 	pool := i.getPool()
 	ctx, cancel := i.withTimeout(ctx)
 	defer cancel()
 
-	_, err = pool.Exec(ctx, query)
-	return err
+	var count int64
+	if err := pool.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count nulls: %w", err)
+	}
+	return count, nil
+}
+
+// SetNullable adds (nullable false) or removes a column's NOT NULL constraint.
+func (i *Introspector) SetNullable(ctx context.Context, tableName, columnName string, nullable bool) error {
+	query, err := BuildSetNullableDDL(tableName, columnName, nullable)
+	if err != nil {
+		return err
+	}
+
+	// Undo restores the previous setting, which may be the same one
+	var wasNullable bool
+	pool := i.getPool()
+	lookup := `
+		SELECT NOT a.attnotnull FROM pg_attribute a
+		WHERE a.attrelid = to_regclass(format('public.%I', $1::text))
+		  AND a.attname = $2 AND NOT a.attisdropped
+	`
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	if err := pool.QueryRow(lookupCtx, lookup, tableName, columnName).Scan(&wasNullable); err != nil {
+		return fmt.Errorf("failed to look up column: %w", err)
+	}
+	undo, err := BuildSetNullableDDL(tableName, columnName, wasNullable)
+	if err != nil {
+		return err
+	}
+
+	action := "Set NOT NULL on"
+	if nullable {
+		action = "Drop NOT NULL from"
+	}
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("%s %s.%s", action, tableName, columnName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// AddCheckConstraint adds a CHECK constraint built from a fixed pattern.
+func (i *Introspector) AddCheckConstraint(ctx context.Context, tableName string, check CheckDef) error {
+	if check.Name == "" {
+		name, err := i.newConstraintName(ctx, tableName, check.Column, "check")
+		if err != nil {
+			return err
+		}
+		check.Name = name
+	}
+	query, err := BuildAddCheckDDL(tableName, check)
+	if err != nil {
+		return err
+	}
+
+	name := check.Name
+	undo, err := BuildDropConstraintDDL(tableName, name, false)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Add check constraint %s on %s", name, tableName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// DropConstraint drops a constraint of any kind by name.
+func (i *Introspector) DropConstraint(ctx context.Context, tableName, constraintName string, cascade bool) error {
+	query, err := BuildDropConstraintDDL(tableName, constraintName, cascade)
+	if err != nil {
+		return err
+	}
+
+	change := Change{
+		Description: fmt.Sprintf("Drop constraint %s on %s", constraintName, tableName),
+		Statements:  []string{query},
+	}
+	if cascade {
+		change.Irreversible = "CASCADE may have dropped constraints on other tables"
+		return i.applyChange(ctx, change)
+	}
+
+	// Keep the definition so undo can add the constraint back
+	var definition *string
+	pool := i.getPool()
+	lookup := `
+		SELECT (
+			SELECT pg_get_constraintdef(oid) FROM pg_constraint
+			WHERE conrelid = to_regclass(format('public.%I', $1::text)) AND conname = $2
+		)
+	`
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	if err := pool.QueryRow(lookupCtx, lookup, tableName, constraintName).Scan(&definition); err != nil {
+		return fmt.Errorf("failed to look up constraint: %w", err)
+	}
+	if definition == nil {
+		change.Irreversible = "the constraint's definition could not be read"
+		return i.applyChange(ctx, change)
+	}
+	undo, err := BuildAddConstraintDDL(tableName, constraintName, *definition)
+	if err != nil {
+		return err
+	}
+	change.Inverse = []string{undo}
+
+	return i.applyChange(ctx, change)
+}
+
+// RenameConstraint renames a constraint of any kind. Renaming a primary key or
+// unique constraint also renames its backing index.
+func (i *Introspector) RenameConstraint(ctx context.Context, tableName, constraintName, newName string) error {
+	query, err := BuildRenameConstraintDDL(tableName, constraintName, newName)
+	if err != nil {
+		return err
+	}
+
+	undo, err := BuildRenameConstraintDDL(tableName, newName, constraintName)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Rename constraint %s on %s to %s", constraintName, tableName, newName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// CreateEnum creates an enum type with the given labels in order.
+func (i *Introspector) CreateEnum(ctx context.Context, name string, labels []string) error {
+	query, err := BuildCreateEnumDDL(name, labels)
+	if err != nil {
+		return err
+	}
+
+	undo, err := BuildDropTypeDDL(name)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: "Create enum " + name,
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// AddEnumValue adds a label to an enum, placed before or after an existing
+// label or, when both are empty, at the end. Labels cannot be removed again.
+func (i *Introspector) AddEnumValue(ctx context.Context, name, label, before, after string) error {
+	query, err := BuildAddEnumValueDDL(name, label, before, after)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description:  fmt.Sprintf("Add value %q to enum %s", label, name),
+		Statements:   []string{query},
+		Irreversible: "PostgreSQL cannot remove a value from an enum",
+	})
+}
+
+// DropType drops a user-defined type that no column uses.
+func (i *Introspector) DropType(ctx context.Context, name string) error {
+	query, err := BuildDropTypeDDL(name)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description:  "Drop type " + name,
+		Statements:   []string{query},
+		Irreversible: "the type's definition is not kept",
+	})
+}
+
+// SetTableComment sets or, when comment is empty, removes a table's comment.
+func (i *Introspector) SetTableComment(ctx context.Context, tableName, comment string) error {
+	query, err := BuildTableCommentDDL(tableName, comment)
+	if err != nil {
+		return err
+	}
+
+	// Undo restores the previous comment, or removes it if there was none
+	var previous string
+	pool := i.getPool()
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	lookup := `SELECT COALESCE(obj_description(to_regclass(format('public.%I', $1::text)), 'pg_class'), '')`
+	if err := pool.QueryRow(lookupCtx, lookup, tableName).Scan(&previous); err != nil {
+		return fmt.Errorf("failed to get current comment: %w", err)
+	}
+	undo, err := BuildTableCommentDDL(tableName, previous)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: "Set comment on table " + tableName,
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// SetColumnComment sets or, when comment is empty, removes a column's comment.
+func (i *Introspector) SetColumnComment(ctx context.Context, tableName, columnName, comment string) error {
+	query, err := BuildColumnCommentDDL(tableName, columnName, comment)
+	if err != nil {
+		return err
+	}
+
+	// Undo restores the previous comment, or removes it if there was none
+	var previous string
+	pool := i.getPool()
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	lookup := `
+		SELECT COALESCE((
+			SELECT col_description(a.attrelid, a.attnum) FROM pg_attribute a
+			WHERE a.attrelid = to_regclass(format('public.%I', $1::text))
+			  AND a.attname = $2 AND NOT a.attisdropped
+		), '')
+	`
+	if err := pool.QueryRow(lookupCtx, lookup, tableName, columnName).Scan(&previous); err != nil {
+		return fmt.Errorf("failed to get current comment: %w", err)
+	}
+	undo, err := BuildColumnCommentDDL(tableName, columnName, previous)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Set comment on column %s.%s", tableName, columnName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// SetConstraintComment sets or, when comment is empty, removes a constraint's comment.
+func (i *Introspector) SetConstraintComment(ctx context.Context, tableName, constraintName, comment string) error {
+	query, err := BuildConstraintCommentDDL(tableName, constraintName, comment)
+	if err != nil {
+		return err
+	}
+
+	// Undo restores the previous comment, or removes it if there was none
+	var previous string
+	pool := i.getPool()
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	lookup := `
+		SELECT COALESCE((
+			SELECT obj_description(con.oid, 'pg_constraint') FROM pg_constraint con
+			WHERE con.conrelid = to_regclass(format('public.%I', $1::text))
+			  AND con.conname = $2
+		), '')
+	`
+	if err := pool.QueryRow(lookupCtx, lookup, tableName, constraintName).Scan(&previous); err != nil {
+		return fmt.Errorf("failed to get current comment: %w", err)
+	}
+	undo, err := BuildConstraintCommentDDL(tableName, constraintName, previous)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Set comment on constraint %s of %s", constraintName, tableName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// SetIndexComment sets or, when comment is empty, removes the comment of an
+// index on tableName. Returns ErrIndexNotFound if the table has no such index.
+func (i *Introspector) SetIndexComment(ctx context.Context, tableName, indexName, comment string) error {
+	query, err := BuildIndexCommentDDL(indexName, comment)
+	if err != nil {
+		return err
+	}
+
+	// Undo restores the previous comment, or removes it if there was none
+	var previous *string
+	pool := i.getPool()
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	lookup := `
+		SELECT (
+			SELECT COALESCE(obj_description(ix.indexrelid, 'pg_class'), '')
+			FROM pg_index ix
+			WHERE ix.indrelid = to_regclass(format('public.%I', $1::text))
+			  AND ix.indexrelid = to_regclass(format('public.%I', $2::text))
+		)
+	`
+	if err := pool.QueryRow(lookupCtx, lookup, tableName, indexName).Scan(&previous); err != nil {
+		return fmt.Errorf("failed to get current comment: %w", err)
+	}
+	if previous == nil {
+		return fmt.Errorf("%w: %s on %s", ErrIndexNotFound, indexName, tableName)
+	}
+	undo, err := BuildIndexCommentDDL(indexName, *previous)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Set comment on index %s", indexName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
 }
@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// NotNullStep is one statement of the zero-downtime NOT NULL pattern.
+type NotNullStep struct {
+	Description string `json:"description"`
+	Statement   string `json:"statement"`
+}
+
+// notNullCheckName names the temporary CHECK constraint for tableName.columnName,
+// truncated to PostgreSQL's identifier limit.
+func notNullCheckName(tableName, columnName string) string {
+	name := tableName + "_" + columnName + "_not_null"
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+// BuildSafeNotNullSteps constructs the steps that set NOT NULL without holding
+// an ACCESS EXCLUSIVE lock while the table is scanned: add CHECK (col IS NOT
+// NULL) NOT VALID, validate it under a lock that allows reads and writes, then
+// SET NOT NULL, which PostgreSQL 12+ proves from the valid check without a
+// scan, and drop the now redundant check. Each step is its own transaction.
+func BuildSafeNotNullSteps(tableName, columnName string) ([]NotNullStep, error) {
+	if !ValidIdentifier(tableName) {
+		return nil, fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(columnName) {
+		return nil, fmt.Errorf("invalid column name")
+	}
+
+	table := sanitizeIdentifier(tableName)
+	check := sanitizeIdentifier(notNullCheckName(tableName, columnName))
+	setNotNull, err := BuildSetNullableDDL(tableName, columnName, false)
+	if err != nil {
+		return nil, err
+	}
+	return []NotNullStep{
+		{
+			Description: "Add NOT VALID check",
+			Statement: fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s IS NOT NULL) NOT VALID",
+				table, check, sanitizeIdentifier(columnName)),
+		},
+		{
+			Description: "Validate check",
+			Statement:   fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s", table, check),
+		},
+		{
+			Description: "Set NOT NULL",
+			Statement:   setNotNull,
+		},
+		{
+			Description: "Drop check",
+			Statement:   fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, check),
+		},
+	}, nil
+}
+
+// SetNotNullSafely sets NOT NULL on a column using BuildSafeNotNullSteps,
+// calling step before each one. No query timeout is applied, since validating
+// a large table routinely outlasts it; callers control duration through ctx.
+// If a step fails the temporary check is dropped again.
+func (i *Introspector) SetNotNullSafely(ctx context.Context, tableName, columnName string, step func(description string)) error {
+	steps, err := BuildSafeNotNullSteps(tableName, columnName)
+	if err != nil {
+		return err
+	}
+
+	// Undo restores the previous setting, which may be the same one
+	var wasNullable bool
+	pool := i.getPool()
+	lookup := `
+		SELECT NOT a.attnotnull FROM pg_attribute a
+		WHERE a.attrelid = to_regclass(format('public.%I', $1::text))
+		  AND a.attname = $2 AND NOT a.attisdropped
+	`
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	if err := pool.QueryRow(lookupCtx, lookup, tableName, columnName).Scan(&wasNullable); err != nil {
+		return fmt.Errorf("failed to look up column: %w", err)
+	}
+	undo, err := BuildSetNullableDDL(tableName, columnName, wasNullable)
+	if err != nil {
+		return err
+	}
+
+	statements := make([]string, len(steps))
+	for idx, s := range steps {
+		statements[idx] = s.Statement
+	}
+	if isPreview(ctx) {
+		return i.execDDL(ctx, statements...)
+	}
+
+	for idx, s := range steps {
+		step(s.Description)
+		if _, err := pool.Exec(ctx, s.Statement); err != nil {
+			if idx > 0 {
+				// The check was added; don't leave it behind. ctx may be the
+				// reason the step failed, so the cleanup gets its own.
+				cleanupCtx, cancel := i.withTimeout(context.Background())
+				defer cancel()
+				_, _ = pool.Exec(cleanupCtx, steps[len(steps)-1].Statement)
+			}
+			return fmt.Errorf("%s: %w", s.Description, err)
+		}
+	}
+
+	i.recordChange(Change{
+		Description: fmt.Sprintf("Set NOT NULL on %s.%s", tableName, columnName),
+		Statements:  statements,
+		Inverse:     []string{undo},
+	})
+	return nil
+}
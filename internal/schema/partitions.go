@@ -0,0 +1,240 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidPartition is returned when a partition operation targets a table
+// that is not partitioned or gives a bound that does not fit its strategy.
+var ErrInvalidPartition = errors.New("invalid partition")
+
+// PartitionBound is the FOR VALUES clause of a partition. Set exactly one of
+// From/To (RANGE), In (LIST), Modulus/Remainder (HASH), or Default. Values are
+// sent as quoted literals that PostgreSQL coerces to the key types; MINVALUE
+// and MAXVALUE are passed through in range bounds.
+type PartitionBound struct {
+	From      []string `json:"from,omitempty"` // One value per key column
+	To        []string `json:"to,omitempty"`   // Exclusive
+	In        []string `json:"in,omitempty"`
+	Modulus   int      `json:"modulus,omitempty"`
+	Remainder int      `json:"remainder,omitempty"`
+	Default   bool     `json:"default,omitempty"` // Rows no other partition accepts; RANGE and LIST only
+}
+
+// PartitionRequest creates a new partition or attaches an existing table.
+type PartitionRequest struct {
+	Name string `json:"name"`
+	PartitionBound
+}
+
+// rangeBoundValue renders one value of a range bound.
+func rangeBoundValue(v string) string {
+	if v == "MINVALUE" || v == "MAXVALUE" {
+		return v
+	}
+	return QuoteLiteral(v)
+}
+
+// BuildPartitionBound renders a FOR VALUES (or DEFAULT) clause for a parent
+// partitioned by strategy (RANGE, LIST, or HASH).
+func BuildPartitionBound(strategy string, b PartitionBound) (string, error) {
+	if b.Default {
+		if strategy == "HASH" {
+			return "", fmt.Errorf("hash partitioned tables cannot have a default partition")
+		}
+		if len(b.From) > 0 || len(b.To) > 0 || len(b.In) > 0 || b.Modulus != 0 {
+			return "", fmt.Errorf("a default partition takes no values")
+		}
+		return "DEFAULT", nil
+	}
+
+	switch strategy {
+	case "RANGE":
+		if len(b.From) == 0 || len(b.From) != len(b.To) {
+			return "", fmt.Errorf("range partitions need from and to values, one per key column")
+		}
+		from := make([]string, len(b.From))
+		to := make([]string, len(b.To))
+		for idx := range b.From {
+			from[idx] = rangeBoundValue(b.From[idx])
+			to[idx] = rangeBoundValue(b.To[idx])
+		}
+		return fmt.Sprintf("FOR VALUES FROM (%s) TO (%s)", strings.Join(from, ", "), strings.Join(to, ", ")), nil
+	case "LIST":
+		if len(b.In) == 0 {
+			return "", fmt.Errorf("list partitions need at least one value")
+		}
+		values := make([]string, len(b.In))
+		for idx, v := range b.In {
+			values[idx] = QuoteLiteral(v)
+		}
+		return fmt.Sprintf("FOR VALUES IN (%s)", strings.Join(values, ", ")), nil
+	default:
+		if b.Modulus <= 0 || b.Remainder < 0 || b.Remainder >= b.Modulus {
+			return "", fmt.Errorf("hash partitions need a positive modulus and a remainder below it")
+		}
+		return fmt.Sprintf("FOR VALUES WITH (MODULUS %d, REMAINDER %d)", b.Modulus, b.Remainder), nil
+	}
+}
+
+// BuildCreatePartitionDDL constructs a CREATE TABLE ... PARTITION OF statement safely.
+func BuildCreatePartitionDDL(parent, name, bound string) (string, error) {
+	if !ValidIdentifier(parent) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(name) {
+		return "", fmt.Errorf("invalid partition name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
+	}
+	return fmt.Sprintf("CREATE TABLE %s PARTITION OF %s %s", sanitizeIdentifier(name), sanitizeIdentifier(parent), bound), nil
+}
+
+// BuildAttachPartitionDDL constructs an ALTER TABLE ... ATTACH PARTITION statement safely.
+func BuildAttachPartitionDDL(parent, name, bound string) (string, error) {
+	if !ValidIdentifier(parent) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(name) {
+		return "", fmt.Errorf("invalid partition name")
+	}
+	return fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s %s", sanitizeIdentifier(parent), sanitizeIdentifier(name), bound), nil
+}
+
+// BuildDetachPartitionDDL constructs an ALTER TABLE ... DETACH PARTITION
+// statement safely. CONCURRENTLY avoids blocking queries on the parent but
+// cannot run in a transaction or while a default partition exists.
+func BuildDetachPartitionDDL(parent, name string, concurrently bool) (string, error) {
+	if !ValidIdentifier(parent) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(name) {
+		return "", fmt.Errorf("invalid partition name")
+	}
+	query := fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", sanitizeIdentifier(parent), sanitizeIdentifier(name))
+	if concurrently {
+		query += " CONCURRENTLY"
+	}
+	return query, nil
+}
+
+// partitionStrategyOf returns the strategy of a partitioned table.
+func (i *Introspector) partitionStrategyOf(ctx context.Context, tableName string) (string, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	var code string
+	query := `
+		SELECT pt.partstrat::text
+		FROM pg_partitioned_table pt
+		WHERE pt.partrelid = to_regclass(format('public.%I', $1::text))
+	`
+	err := i.getPool().QueryRow(ctx, query, tableName).Scan(&code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("%w: %s is not a partitioned table", ErrInvalidPartition, tableName)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get partition strategy: %w", err)
+	}
+	return partitionStrategy(code), nil
+}
+
+// partitionBound renders b for parent, checking it fits the parent's strategy.
+func (i *Introspector) partitionBound(ctx context.Context, parent string, b PartitionBound) (string, error) {
+	strategy, err := i.partitionStrategyOf(ctx, parent)
+	if err != nil {
+		return "", err
+	}
+	bound, err := BuildPartitionBound(strategy, b)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPartition, err)
+	}
+	return bound, nil
+}
+
+// CreatePartition creates a new partition of a partitioned table.
+func (i *Introspector) CreatePartition(ctx context.Context, parent string, req PartitionRequest) error {
+	bound, err := i.partitionBound(ctx, parent, req.PartitionBound)
+	if err != nil {
+		return err
+	}
+	query, err := BuildCreatePartitionDDL(parent, req.Name, bound)
+	if err != nil {
+		return err
+	}
+	undo, err := BuildDropTableDDL(req.Name, false)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Create partition %s of %s", req.Name, parent),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// AttachPartition attaches an existing table as a partition. PostgreSQL scans
+// the table to check its rows fit the bound unless a matching CHECK constraint
+// already proves it.
+func (i *Introspector) AttachPartition(ctx context.Context, parent string, req PartitionRequest) error {
+	bound, err := i.partitionBound(ctx, parent, req.PartitionBound)
+	if err != nil {
+		return err
+	}
+	query, err := BuildAttachPartitionDDL(parent, req.Name, bound)
+	if err != nil {
+		return err
+	}
+	undo, err := BuildDetachPartitionDDL(parent, req.Name, false)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Attach %s as a partition of %s", req.Name, parent),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// DetachPartition detaches a partition, leaving it as a standalone table.
+func (i *Introspector) DetachPartition(ctx context.Context, parent, name string, concurrently bool) error {
+	query, err := BuildDetachPartitionDDL(parent, name, concurrently)
+	if err != nil {
+		return err
+	}
+
+	// Keep the bound so undo can attach the table again
+	var bound *string
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	lookup := `
+		SELECT (
+			SELECT pg_get_expr(c.relpartbound, c.oid)
+			FROM pg_class c
+			JOIN pg_inherits inh ON inh.inhrelid = c.oid
+			WHERE c.oid = to_regclass(format('public.%I', $2::text))
+			  AND inh.inhparent = to_regclass(format('public.%I', $1::text))
+		)
+	`
+	if err := i.getPool().QueryRow(lookupCtx, lookup, parent, name).Scan(&bound); err != nil {
+		return fmt.Errorf("failed to look up partition: %w", err)
+	}
+	if bound == nil {
+		return fmt.Errorf("%w: %s is not a partition of %s", ErrInvalidPartition, name, parent)
+	}
+	undo, err := BuildAttachPartitionDDL(parent, name, *bound)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Detach partition %s from %s", name, parent),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
@@ -0,0 +1,382 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrCannotReorder is returned when a column order is invalid or the table
+// cannot be recreated by a generated script.
+var ErrCannotReorder = errors.New("cannot reorder columns")
+
+// ReorderScript is a migration that recreates a table with its columns in a
+// new order, since PostgreSQL cannot reorder columns in place. It is generated
+// for review and never run by the server.
+type ReorderScript struct {
+	Table      string   `json:"table"`
+	Columns    []string `json:"columns"`    // The new order
+	Statements []string `json:"statements"` // Run in order; the first and last are BEGIN and COMMIT
+	Warnings   []string `json:"warnings"`   // What the script does not carry over
+}
+
+// reorderColumn is a column as it must be declared in the recreated table.
+type reorderColumn struct {
+	name       string
+	dataType   string // format_type output, including modifiers
+	notNull    bool
+	defaultDef *string
+	generated  string // "s" stored, "v" virtual, "" not generated
+	identity   string // "a" always, "d" by default, "" none
+	collation  *string
+	comment    *string
+}
+
+// constraintDef is a named constraint with its pg_get_constraintdef text.
+type constraintDef struct {
+	table      string
+	name       string
+	definition string
+}
+
+// reorderPlan is everything gathered from the catalogs to recreate a table.
+type reorderPlan struct {
+	table         string
+	tempName      string
+	columns       []reorderColumn // In the new order
+	comment       *string
+	constraints   []constraintDef // Own constraints, foreign keys last
+	indexes       []string        // CREATE INDEX statements for indexes not backing constraints
+	triggers      []string        // CREATE TRIGGER statements
+	incomingFKs   []constraintDef // Foreign keys on other tables referencing this one
+	ownedSequence map[string]string
+}
+
+// BuildReorderScript generates the statements that recreate tableName with
+// its columns in order, which must name every column exactly once.
+func (i *Introspector) BuildReorderScript(ctx context.Context, tableName string, order []string) (*ReorderScript, error) {
+	if !ValidIdentifier(tableName) {
+		return nil, fmt.Errorf("invalid table name")
+	}
+
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	pool := i.getPool()
+
+	var relkind string
+	var inherited, hasGrants, hasPolicies bool
+	var comment *string
+	tableQuery := `
+		SELECT c.relkind::text,
+		       EXISTS (SELECT 1 FROM pg_inherits WHERE inhrelid = c.oid OR inhparent = c.oid),
+		       c.relacl IS NOT NULL,
+		       c.relrowsecurity OR EXISTS (SELECT 1 FROM pg_policy WHERE polrelid = c.oid),
+		       obj_description(c.oid, 'pg_class')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public' AND c.relname = $1
+	`
+	err := pool.QueryRow(ctx, tableQuery, tableName).Scan(&relkind, &inherited, &hasGrants, &hasPolicies, &comment)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTableNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up table: %w", err)
+	}
+	if relkind != "r" || inherited {
+		return nil, fmt.Errorf("%w: only plain tables outside partitioning and inheritance can be recreated", ErrCannotReorder)
+	}
+
+	plan := reorderPlan{table: tableName, tempName: tableName + "_reordered", comment: comment}
+	if !ValidIdentifier(plan.tempName) {
+		return nil, fmt.Errorf("%w: table name is too long for a temporary copy", ErrCannotReorder)
+	}
+
+	columns, err := getReorderColumns(ctx, pool, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if plan.columns, err = orderColumns(columns, order); err != nil {
+		return nil, err
+	}
+	if plan.constraints, err = getConstraintDefs(ctx, pool, tableName, false); err != nil {
+		return nil, err
+	}
+	if plan.incomingFKs, err = getConstraintDefs(ctx, pool, tableName, true); err != nil {
+		return nil, err
+	}
+	if plan.indexes, err = queryStrings(ctx, pool, `
+		SELECT pg_get_indexdef(ix.indexrelid)
+		FROM pg_index ix
+		WHERE ix.indrelid = format('public.%I', $1::text)::regclass
+		  AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = ix.indexrelid AND con.conrelid = ix.indrelid)
+		ORDER BY ix.indexrelid
+	`, tableName); err != nil {
+		return nil, fmt.Errorf("failed to get indexes: %w", err)
+	}
+	if plan.triggers, err = queryStrings(ctx, pool, `
+		SELECT pg_get_triggerdef(oid)
+		FROM pg_trigger
+		WHERE tgrelid = format('public.%I', $1::text)::regclass AND NOT tgisinternal
+		ORDER BY tgname
+	`, tableName); err != nil {
+		return nil, fmt.Errorf("failed to get triggers: %w", err)
+	}
+
+	plan.ownedSequence = make(map[string]string)
+	rows, err := pool.Query(ctx, `SELECT column_name, sequence_name FROM (`+ownedSequencesQuery+`
+		  AND d.deptype = 'a') seq WHERE table_name = $1`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owned sequences: %w", err)
+	}
+	for rows.Next() {
+		var column, sequence string
+		if err := rows.Scan(&column, &sequence); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan owned sequence: %w", err)
+		}
+		plan.ownedSequence[column] = sequence
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	script := &ReorderScript{
+		Table:      tableName,
+		Columns:    order,
+		Statements: buildReorderStatements(plan),
+		Warnings:   []string{},
+	}
+
+	views, err := queryStrings(ctx, pool, `
+		SELECT DISTINCT v.relname
+		FROM pg_depend d
+		JOIN pg_rewrite rw ON rw.oid = d.objid
+		JOIN pg_class v ON v.oid = rw.ev_class
+		WHERE d.classid = 'pg_rewrite'::regclass
+		  AND d.refobjid = format('public.%I', $1::text)::regclass
+		  AND v.oid <> d.refobjid
+		ORDER BY 1
+	`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependent views: %w", err)
+	}
+	if len(views) > 0 {
+		script.Warnings = append(script.Warnings, fmt.Sprintf(
+			"Views depend on this table and block DROP TABLE; drop them first and recreate them afterwards: %s",
+			strings.Join(views, ", ")))
+	}
+	if hasGrants {
+		script.Warnings = append(script.Warnings, "Grants are not copied; re-grant privileges on the new table")
+	}
+	if hasPolicies {
+		script.Warnings = append(script.Warnings, "Row-level security settings and policies are not copied")
+	}
+	return script, nil
+}
+
+// getReorderColumns returns a table's columns as they must be redeclared.
+func getReorderColumns(ctx context.Context, pool *pgxpool.Pool, tableName string) ([]reorderColumn, error) {
+	query := `
+		SELECT
+			a.attname,
+			format_type(a.atttypid, a.atttypmod),
+			a.attnotnull,
+			pg_get_expr(ad.adbin, ad.adrelid),
+			a.attgenerated::text,
+			a.attidentity::text,
+			CASE WHEN a.attcollation <> ty.typcollation THEN co.collname END,
+			col_description(a.attrelid, a.attnum)
+		FROM pg_attribute a
+		JOIN pg_type ty ON ty.oid = a.atttypid
+		LEFT JOIN pg_attrdef ad ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+		LEFT JOIN pg_collation co ON co.oid = a.attcollation
+		WHERE a.attrelid = format('public.%I', $1::text)::regclass
+		  AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum
+	`
+
+	rows, err := pool.Query(ctx, query, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []reorderColumn
+	for rows.Next() {
+		var c reorderColumn
+		if err := rows.Scan(&c.name, &c.dataType, &c.notNull, &c.defaultDef, &c.generated, &c.identity,
+			&c.collation, &c.comment); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+// getConstraintDefs returns the constraints on a table or, with incoming, the
+// foreign keys on other tables that reference it. NOT NULL constraints are
+// left out; they are declared with the columns.
+func getConstraintDefs(ctx context.Context, pool *pgxpool.Pool, tableName string, incoming bool) ([]constraintDef, error) {
+	query := `
+		WITH target AS (SELECT format('public.%I', $1::text)::regclass AS oid)
+		SELECT t.relname, con.conname, pg_get_constraintdef(con.oid)
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		WHERE con.contype IN ('p', 'u', 'x', 'c', 'f')
+		  AND con.conrelid = (SELECT oid FROM target)
+		ORDER BY con.contype = 'f', con.conname
+	`
+	if incoming {
+		query = `
+			WITH target AS (SELECT format('public.%I', $1::text)::regclass AS oid)
+			SELECT t.relname, con.conname, pg_get_constraintdef(con.oid)
+			FROM pg_constraint con
+			JOIN pg_class t ON t.oid = con.conrelid
+			WHERE con.contype = 'f'
+			  AND con.confrelid = (SELECT oid FROM target)
+			  AND con.conrelid <> con.confrelid -- Self-references are recreated with the table
+			ORDER BY t.relname, con.conname
+		`
+	}
+
+	rows, err := pool.Query(ctx, query, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var defs []constraintDef
+	for rows.Next() {
+		var d constraintDef
+		if err := rows.Scan(&d.table, &d.name, &d.definition); err != nil {
+			return nil, fmt.Errorf("failed to scan constraint: %w", err)
+		}
+		defs = append(defs, d)
+	}
+	return defs, rows.Err()
+}
+
+// queryStrings runs a query returning one text column.
+func queryStrings(ctx context.Context, pool *pgxpool.Pool, query string, args ...any) ([]string, error) {
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// orderColumns arranges columns by name in the given order, which must name
+// every column exactly once.
+func orderColumns(columns []reorderColumn, order []string) ([]reorderColumn, error) {
+	if len(order) != len(columns) {
+		return nil, fmt.Errorf("%w: the table has %d columns but %d were given", ErrCannotReorder, len(columns), len(order))
+	}
+	ordered := make([]reorderColumn, 0, len(columns))
+	for idx, name := range order {
+		if slices.Contains(order[:idx], name) {
+			return nil, fmt.Errorf("%w: column %s is listed twice", ErrCannotReorder, name)
+		}
+		pos := slices.IndexFunc(columns, func(c reorderColumn) bool { return c.name == name })
+		if pos < 0 {
+			return nil, fmt.Errorf("%w: column %s does not exist", ErrCannotReorder, name)
+		}
+		ordered = append(ordered, columns[pos])
+	}
+	return ordered, nil
+}
+
+// buildReorderStatements renders the recreation script: copy the rows into a
+// new table, move owned sequences over, drop the old table, then restore
+// constraints, indexes, triggers, comments, and foreign keys pointing in.
+func buildReorderStatements(plan reorderPlan) []string {
+	table := sanitizeIdentifier(plan.table)
+	temp := sanitizeIdentifier(plan.tempName)
+
+	defs := make([]string, len(plan.columns))
+	var copied []string
+	for idx, c := range plan.columns {
+		def := sanitizeIdentifier(c.name) + " " + c.dataType
+		if c.collation != nil {
+			def += " COLLATE " + sanitizeIdentifier(*c.collation)
+		}
+		switch {
+		case c.generated == "s" && c.defaultDef != nil:
+			def += " GENERATED ALWAYS AS (" + *c.defaultDef + ") STORED"
+		case c.generated == "v" && c.defaultDef != nil:
+			def += " GENERATED ALWAYS AS (" + *c.defaultDef + ") VIRTUAL"
+		case c.identity == "a":
+			def += " GENERATED ALWAYS AS IDENTITY"
+		case c.identity == "d":
+			def += " GENERATED BY DEFAULT AS IDENTITY"
+		case c.defaultDef != nil:
+			def += " DEFAULT " + *c.defaultDef
+		}
+		if c.notNull {
+			def += " NOT NULL"
+		}
+		defs[idx] = def
+		if c.generated == "" {
+			copied = append(copied, sanitizeIdentifier(c.name))
+		}
+	}
+
+	statements := []string{
+		"BEGIN",
+		"LOCK TABLE " + table + " IN ACCESS EXCLUSIVE MODE",
+		fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", temp, strings.Join(defs, ",\n  ")),
+		fmt.Sprintf("INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT %s FROM %s",
+			temp, strings.Join(copied, ", "), strings.Join(copied, ", "), table),
+	}
+	for _, fk := range plan.incomingFKs {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s",
+			sanitizeIdentifier(fk.table), sanitizeIdentifier(fk.name)))
+	}
+	// Serial sequences would otherwise be dropped with the old table
+	for _, c := range plan.columns {
+		if seq, ok := plan.ownedSequence[c.name]; ok {
+			statements = append(statements, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.%s",
+				sanitizeIdentifier(seq), temp, sanitizeIdentifier(c.name)))
+		}
+	}
+	statements = append(statements,
+		"DROP TABLE "+table,
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", temp, table))
+
+	for _, con := range plan.constraints {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s",
+			table, sanitizeIdentifier(con.name), con.definition))
+	}
+	statements = append(statements, plan.indexes...)
+	statements = append(statements, plan.triggers...)
+
+	for _, c := range plan.columns {
+		if c.identity != "" {
+			// New identity sequences start over; continue after the copied values
+			col := sanitizeIdentifier(c.name)
+			statements = append(statements, fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), max(%s)) FROM %s",
+				QuoteLiteral(table), QuoteLiteral(c.name), col, table))
+		}
+	}
+	if plan.comment != nil {
+		statements = append(statements, fmt.Sprintf("COMMENT ON TABLE %s IS %s", table, QuoteLiteral(*plan.comment)))
+	}
+	for _, c := range plan.columns {
+		if c.comment != nil {
+			statements = append(statements, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s",
+				table, sanitizeIdentifier(c.name), QuoteLiteral(*c.comment)))
+		}
+	}
+	for _, fk := range plan.incomingFKs {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s",
+			sanitizeIdentifier(fk.table), sanitizeIdentifier(fk.name), fk.definition))
+	}
+
+	return append(statements, "COMMIT")
+}
@@ -0,0 +1,178 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Publication is a logical replication publication and the tables it publishes.
+type Publication struct {
+	Name      string   `json:"name"`
+	Owner     string   `json:"owner"`
+	AllTables bool     `json:"allTables"` // FOR ALL TABLES, including ones created later
+	Actions   []string `json:"actions"`   // Published operations: INSERT, UPDATE, DELETE, TRUNCATE
+	Tables    []string `json:"tables"`    // Schema-qualified, e.g. public.orders
+}
+
+// SubscriptionTable is the sync state of one table in a subscription.
+type SubscriptionTable struct {
+	Table string `json:"table"` // Schema-qualified
+	State string `json:"state"` // initialize, data copy, finished copy, synchronized, or ready
+}
+
+// Subscription is a logical replication subscription in the current database.
+type Subscription struct {
+	Name         string   `json:"name"`
+	Owner        string   `json:"owner"`
+	Enabled      bool     `json:"enabled"`
+	SlotName     string   `json:"slotName,omitempty"`
+	Publications []string `json:"publications"`
+	// Apply worker status from pg_stat_subscription; nil when no worker runs
+	WorkerPID     *int32              `json:"workerPid,omitempty"`
+	ReceivedLSN   *string             `json:"receivedLsn,omitempty"`
+	LastMessageAt *time.Time          `json:"lastMessageAt,omitempty"`
+	Tables        []SubscriptionTable `json:"tables"`
+}
+
+// Replication lists the logical replication objects in the current database.
+type Replication struct {
+	Publications  []Publication  `json:"publications"`
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// GetReplication returns publications with their member tables and
+// subscriptions with their worker and per-table sync status.
+func (i *Introspector) GetReplication(ctx context.Context) (*Replication, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	publications, err := i.getPublications(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := i.getSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Replication{Publications: publications, Subscriptions: subscriptions}, nil
+}
+
+func (i *Introspector) getPublications(ctx context.Context) ([]Publication, error) {
+	query := `
+		SELECT
+			p.pubname,
+			pg_get_userbyid(p.pubowner),
+			p.puballtables,
+			array_remove(ARRAY[
+				CASE WHEN p.pubinsert THEN 'INSERT' END,
+				CASE WHEN p.pubupdate THEN 'UPDATE' END,
+				CASE WHEN p.pubdelete THEN 'DELETE' END,
+				CASE WHEN p.pubtruncate THEN 'TRUNCATE' END
+			], NULL),
+			COALESCE((
+				SELECT array_agg(format('%s.%s', pt.schemaname, pt.tablename) ORDER BY pt.schemaname, pt.tablename)
+				FROM pg_publication_tables pt
+				WHERE pt.pubname = p.pubname
+			), '{}')
+		FROM pg_publication p
+		ORDER BY p.pubname
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publications: %w", err)
+	}
+	defer rows.Close()
+
+	publications := make([]Publication, 0)
+	for rows.Next() {
+		var p Publication
+		if err := rows.Scan(&p.Name, &p.Owner, &p.AllTables, &p.Actions, &p.Tables); err != nil {
+			return nil, fmt.Errorf("failed to scan publication: %w", err)
+		}
+		publications = append(publications, p)
+	}
+
+	return publications, rows.Err()
+}
+
+func (i *Introspector) getSubscriptions(ctx context.Context) ([]Subscription, error) {
+	// Columns are listed explicitly: subconninfo is readable only by superusers.
+	// pg_stat_subscription also has rows for table sync workers (relid set) and,
+	// on PostgreSQL 16+, parallel apply workers; only the leader receives WAL.
+	query := `
+		SELECT
+			s.subname,
+			pg_get_userbyid(s.subowner),
+			s.subenabled,
+			COALESCE(s.subslotname::text, ''),
+			s.subpublications,
+			st.pid,
+			st.received_lsn::text,
+			st.last_msg_receipt_time,
+			COALESCE((
+				SELECT array_agg(sr.srrelid::regclass::text ORDER BY sr.srrelid::regclass::text)
+				FROM pg_subscription_rel sr
+				WHERE sr.srsubid = s.oid
+			), '{}'),
+			COALESCE((
+				SELECT array_agg(sr.srsubstate::text ORDER BY sr.srrelid::regclass::text)
+				FROM pg_subscription_rel sr
+				WHERE sr.srsubid = s.oid
+			), '{}')
+		FROM pg_subscription s
+		JOIN pg_database d ON d.oid = s.subdbid AND d.datname = current_database()
+		LEFT JOIN LATERAL (
+			SELECT pid, received_lsn, last_msg_receipt_time
+			FROM pg_stat_subscription
+			WHERE subid = s.oid AND relid IS NULL
+			ORDER BY received_lsn IS NULL, pid
+			LIMIT 1
+		) st ON true
+		ORDER BY s.subname
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := make([]Subscription, 0)
+	for rows.Next() {
+		var s Subscription
+		var tables, states []string
+		if err := rows.Scan(&s.Name, &s.Owner, &s.Enabled, &s.SlotName, &s.Publications,
+			&s.WorkerPID, &s.ReceivedLSN, &s.LastMessageAt, &tables, &states); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		s.Tables = make([]SubscriptionTable, len(tables))
+		for idx := range tables {
+			s.Tables[idx] = SubscriptionTable{Table: tables[idx], State: subscriptionRelState(states[idx])}
+		}
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// subscriptionRelState maps pg_subscription_rel.srsubstate codes to names.
+func subscriptionRelState(code string) string {
+	switch code {
+	case "i":
+		return "initialize"
+	case "d":
+		return "data copy"
+	case "f":
+		return "finished copy"
+	case "s":
+		return "synchronized"
+	case "r":
+		return "ready"
+	}
+	return code
+}
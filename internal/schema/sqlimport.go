@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// scratchDatabasePrefix names the throwaway databases SQL imports are loaded into.
+const scratchDatabasePrefix = "altdb_import_"
+
+// shadowDatabasePrefix names the throwaway databases migrations are validated in.
+const shadowDatabasePrefix = "altdb_shadow_"
+
+// ErrInvalidSQL is returned when an imported script fails to run.
+var ErrInvalidSQL = errors.New("SQL failed")
+
+// CreateScratchDatabase creates an empty database with a random name for
+// loading an imported script, and returns its name. Drop it with
+// DropScratchDatabase once done.
+func (i *Introspector) CreateScratchDatabase(ctx context.Context) (string, error) {
+	// template0 so objects added to template1 don't show up as imported
+	return i.createThrowawayDatabase(ctx, scratchDatabasePrefix, "template0")
+}
+
+// CreateShadowDatabase creates a database with a random name to validate a
+// migration in, as a copy of template or, without one, empty, and returns its
+// name. Copying requires that nobody is connected to template. Drop it with
+// DropScratchDatabase once done.
+func (i *Introspector) CreateShadowDatabase(ctx context.Context, template string) (string, error) {
+	if template == "" {
+		template = "template0"
+	}
+	return i.createThrowawayDatabase(ctx, shadowDatabasePrefix, template)
+}
+
+func (i *Introspector) createThrowawayDatabase(ctx context.Context, prefix, template string) (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate database name: %w", err)
+	}
+	name := prefix + hex.EncodeToString(b)
+	if err := i.CreateDatabase(ctx, CreateDatabaseRequest{Name: name, Template: template}); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// DropScratchDatabase drops a database made by CreateScratchDatabase or
+// CreateShadowDatabase, disconnecting any sessions left in it (PostgreSQL 13+).
+func (i *Introspector) DropScratchDatabase(ctx context.Context, name string) error {
+	if !strings.HasPrefix(name, scratchDatabasePrefix) && !strings.HasPrefix(name, shadowDatabasePrefix) || !ValidIdentifier(name) {
+		return fmt.Errorf("not a scratch database: %s", name)
+	}
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	if _, err := i.getPool().Exec(ctx, "DROP DATABASE IF EXISTS "+sanitizeIdentifier(name)+" WITH (FORCE)"); err != nil {
+		return fmt.Errorf("failed to drop scratch database: %w", err)
+	}
+	return nil
+}
+
+// ExecScript runs a multi-statement script such as a schema.sql dump in one
+// transaction. A failing statement is reported as ErrInvalidSQL with the
+// server's message and, when known, the line it failed on.
+func (i *Introspector) ExecScript(ctx context.Context, script string) error {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := i.getPool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if _, err := tx.Exec(ctx, script); err != nil {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			return fmt.Errorf("failed to run script: %w", err)
+		}
+		// Position counts characters from 1
+		if runes := []rune(script); pgErr.Position > 0 && int(pgErr.Position) <= len(runes) {
+			line := strings.Count(string(runes[:pgErr.Position-1]), "\n") + 1
+			return fmt.Errorf("%w on line %d: %s", ErrInvalidSQL, line, pgErr.Message)
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidSQL, pgErr.Message)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"regexp"
+	"strings"
+)
+
+// dollarTagPattern matches the opening tag of a dollar-quoted string.
+var dollarTagPattern = regexp.MustCompile(`^\$(?:[A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// SplitStatements splits a SQL script, generated or written by hand, into
+// its statements, without the trailing semicolons. Comments are removed;
+// semicolons inside quotes, quoted identifiers, and dollar-quoted bodies do
+// not split. BEGIN and COMMIT, as SQL wraps a script in, are dropped.
+func SplitStatements(script string) []string {
+	var statements []string
+	var b strings.Builder
+	flush := func() {
+		stmt := strings.TrimSpace(b.String())
+		b.Reset()
+		switch strings.ToUpper(stmt) {
+		case "", "BEGIN", "COMMIT", "START TRANSACTION", "END":
+			return
+		}
+		statements = append(statements, stmt)
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			i += end - 1
+			b.WriteByte(' ')
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i - 4
+			}
+			i += end + 3
+			b.WriteByte(' ')
+		case c == '\'' || c == '"':
+			// A doubled quote closes and reopens, which copies through unchanged
+			end := strings.IndexByte(script[i+1:], c)
+			if end < 0 {
+				end = len(script) - i - 2
+			}
+			b.WriteString(script[i : i+end+2])
+			i += end + 1
+		case c == '$' && dollarTagPattern.MatchString(script[i:]):
+			tag := dollarTagPattern.FindString(script[i:])
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				end = len(script) - i - 2*len(tag)
+			}
+			b.WriteString(script[i : i+end+2*len(tag)])
+			i += end + 2*len(tag) - 1
+		case c == ';':
+			flush()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ColumnStats holds planner statistics for one column, as gathered by ANALYZE.
+type ColumnStats struct {
+	Column       string  `json:"column"`
+	NullFraction float64 `json:"nullFraction"`
+	// DistinctEstimate follows pg_stats.n_distinct: positive values are a
+	// distinct count, negative values are minus the fraction of rows that are distinct.
+	DistinctEstimate      float64   `json:"distinctEstimate"`
+	MostCommonValues      []string  `json:"mostCommonValues"`
+	MostCommonFrequencies []float64 `json:"mostCommonFrequencies"`
+	// Migration hints derived from the sample ANALYZE took; verify before relying on them.
+	NotNullCandidate bool `json:"notNullCandidate"` // No NULLs were sampled
+	UniqueCandidate  bool `json:"uniqueCandidate"`  // Every sampled value was distinct
+}
+
+// GetColumnStats returns pg_stats entries for every analyzed column of a table.
+// Columns that have never been analyzed are omitted.
+func (i *Introspector) GetColumnStats(ctx context.Context, tableName string) ([]ColumnStats, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			s.attname,
+			s.null_frac,
+			s.n_distinct,
+			COALESCE(s.most_common_vals::text::text[], '{}'),
+			COALESCE(s.most_common_freqs, '{}')
+		FROM pg_stats s
+		JOIN information_schema.columns c
+		  ON c.table_schema = s.schemaname
+		 AND c.table_name = s.tablename
+		 AND c.column_name = s.attname
+		WHERE s.schemaname = 'public'
+		  AND s.tablename = $1
+		  AND NOT s.inherited
+		ORDER BY c.ordinal_position
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]ColumnStats, 0)
+	for rows.Next() {
+		var s ColumnStats
+		var nullFrac, nDistinct float32
+		var freqs []float32
+		if err := rows.Scan(&s.Column, &nullFrac, &nDistinct, &s.MostCommonValues, &freqs); err != nil {
+			return nil, fmt.Errorf("failed to scan column stats: %w", err)
+		}
+		s.NullFraction = float64(nullFrac)
+		s.DistinctEstimate = float64(nDistinct)
+		s.NotNullCandidate = nullFrac == 0
+		s.UniqueCandidate = nDistinct == -1
+		s.MostCommonFrequencies = make([]float64, len(freqs))
+		for idx, f := range freqs {
+			s.MostCommonFrequencies[idx] = float64(f)
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// TableActivity holds cumulative read/write counters for one table from
+// pg_stat_user_tables. Counters accumulate since the last stats reset.
+type TableActivity struct {
+	Table            string     `json:"table"`
+	SeqScans         int64      `json:"seqScans"`
+	SeqTuplesRead    int64      `json:"seqTuplesRead"`
+	IdxScans         int64      `json:"idxScans"`
+	IdxTuplesFetched int64      `json:"idxTuplesFetched"`
+	TuplesInserted   int64      `json:"tuplesInserted"`
+	TuplesUpdated    int64      `json:"tuplesUpdated"`
+	TuplesDeleted    int64      `json:"tuplesDeleted"`
+	LiveTuples       int64      `json:"liveTuples"`
+	DeadTuples       int64      `json:"deadTuples"`
+	LastVacuum       *time.Time `json:"lastVacuum,omitempty"`
+	LastAnalyze      *time.Time `json:"lastAnalyze,omitempty"`
+	Reads            int64      `json:"reads"`  // Tuples read by sequential and index scans
+	Writes           int64      `json:"writes"` // Tuples inserted, updated, and deleted
+	// Heat is Reads+Writes relative to the busiest table (0 to 1), for shading the diagram.
+	Heat float64 `json:"heat"`
+}
+
+// GetTableActivity returns activity counters for every table in the public schema.
+func (i *Introspector) GetTableActivity(ctx context.Context) ([]TableActivity, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			relname,
+			seq_scan,
+			seq_tup_read,
+			COALESCE(idx_scan, 0),
+			COALESCE(idx_tup_fetch, 0),
+			n_tup_ins,
+			n_tup_upd,
+			n_tup_del,
+			n_live_tup,
+			n_dead_tup,
+			GREATEST(last_vacuum, last_autovacuum),
+			GREATEST(last_analyze, last_autoanalyze)
+		FROM pg_stat_user_tables
+		WHERE schemaname = 'public'
+		ORDER BY relname
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table activity: %w", err)
+	}
+	defer rows.Close()
+
+	activity := make([]TableActivity, 0, 64)
+	var busiest int64
+	for rows.Next() {
+		var a TableActivity
+		if err := rows.Scan(&a.Table, &a.SeqScans, &a.SeqTuplesRead, &a.IdxScans, &a.IdxTuplesFetched,
+			&a.TuplesInserted, &a.TuplesUpdated, &a.TuplesDeleted, &a.LiveTuples, &a.DeadTuples,
+			&a.LastVacuum, &a.LastAnalyze); err != nil {
+			return nil, fmt.Errorf("failed to scan table activity: %w", err)
+		}
+		a.Reads = a.SeqTuplesRead + a.IdxTuplesFetched
+		a.Writes = a.TuplesInserted + a.TuplesUpdated + a.TuplesDeleted
+		busiest = max(busiest, a.Reads+a.Writes)
+		activity = append(activity, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if busiest > 0 {
+		for idx := range activity {
+			activity[idx].Heat = float64(activity[idx].Reads+activity[idx].Writes) / float64(busiest)
+		}
+	}
+	return activity, nil
+}
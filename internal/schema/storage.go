@@ -0,0 +1,259 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Storage parameter value kinds.
+const (
+	paramInt   = "int"
+	paramFloat = "float"
+	paramBool  = "bool"
+)
+
+// storageParam is the kind and accepted range of a table storage parameter.
+type storageParam struct {
+	kind     string
+	min, max float64
+}
+
+// storageParams lists the table storage parameters that may be set, with the
+// ranges PostgreSQL accepts.
+var storageParams = map[string]storageParam{
+	"fillfactor":                            {paramInt, 10, 100},
+	"toast_tuple_target":                    {paramInt, 128, 8160},
+	"parallel_workers":                      {paramInt, 0, 1024},
+	"vacuum_truncate":                       {paramBool, 0, 1},
+	"autovacuum_enabled":                    {paramBool, 0, 1},
+	"autovacuum_vacuum_threshold":           {paramInt, 0, math.MaxInt32},
+	"autovacuum_vacuum_scale_factor":        {paramFloat, 0, 100},
+	"autovacuum_vacuum_insert_threshold":    {paramInt, -1, math.MaxInt32},
+	"autovacuum_vacuum_insert_scale_factor": {paramFloat, 0, 100},
+	"autovacuum_analyze_threshold":          {paramInt, 0, math.MaxInt32},
+	"autovacuum_analyze_scale_factor":       {paramFloat, 0, 100},
+	"autovacuum_vacuum_cost_delay":          {paramFloat, -1, 100},
+	"autovacuum_vacuum_cost_limit":          {paramInt, -1, 10000},
+	"autovacuum_freeze_min_age":             {paramInt, -1, 1000000000},
+	"autovacuum_freeze_max_age":             {paramInt, -1, 2000000000},
+	"autovacuum_freeze_table_age":           {paramInt, -1, 2000000000},
+}
+
+// StorageParameterNames returns the table storage parameters that may be set, sorted.
+func StorageParameterNames() []string {
+	return slices.Sorted(maps.Keys(storageParams))
+}
+
+// columnStorageModes maps SET STORAGE modes to their pg_attribute.attstorage codes.
+var columnStorageModes = map[string]string{
+	"plain":    "p",
+	"external": "e",
+	"extended": "x",
+	"main":     "m",
+}
+
+// StorageParametersRequest sets and resets table storage parameters.
+// Values are given as text, e.g. "70" or "true".
+type StorageParametersRequest struct {
+	Set   map[string]string `json:"set,omitempty"`
+	Reset []string          `json:"reset,omitempty"`
+}
+
+// ColumnStorageRequest changes how a column is stored and sampled.
+type ColumnStorageRequest struct {
+	Storage    string `json:"storage,omitempty"`    // plain, external, extended, or main
+	Statistics *int   `json:"statistics,omitempty"` // 0 to 10000; -1 restores the default
+}
+
+// storageValue validates a storage parameter value and renders it for DDL.
+func storageValue(name, value string) (string, error) {
+	param, ok := storageParams[name]
+	if !ok {
+		return "", fmt.Errorf("unsupported storage parameter %q", name)
+	}
+	switch param.kind {
+	case paramBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s must be true or false", name)
+		}
+		return strconv.FormatBool(b), nil
+	case paramInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || float64(n) < param.min || float64(n) > param.max {
+			return "", fmt.Errorf("%s must be an integer from %v to %v", name, param.min, param.max)
+		}
+		return strconv.FormatInt(n, 10), nil
+	default:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(f) || f < param.min || f > param.max {
+			return "", fmt.Errorf("%s must be a number from %v to %v", name, param.min, param.max)
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	}
+}
+
+// BuildStorageParametersDDL constructs an ALTER TABLE ... SET (...), RESET (...)
+// statement from whitelisted parameters with validated values.
+func BuildStorageParametersDDL(tableName string, req StorageParametersRequest) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if len(req.Set) == 0 && len(req.Reset) == 0 {
+		return "", fmt.Errorf("no storage parameters given")
+	}
+
+	var actions []string
+	if len(req.Set) > 0 {
+		var params []string
+		for _, name := range slices.Sorted(maps.Keys(req.Set)) {
+			value, err := storageValue(name, req.Set[name])
+			if err != nil {
+				return "", err
+			}
+			params = append(params, name+" = "+value)
+		}
+		actions = append(actions, "SET ("+strings.Join(params, ", ")+")")
+	}
+	if len(req.Reset) > 0 {
+		for _, name := range req.Reset {
+			if _, ok := storageParams[name]; !ok {
+				return "", fmt.Errorf("unsupported storage parameter %q", name)
+			}
+			if _, ok := req.Set[name]; ok {
+				return "", fmt.Errorf("%s is both set and reset", name)
+			}
+		}
+		actions = append(actions, "RESET ("+strings.Join(req.Reset, ", ")+")")
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s %s", sanitizeIdentifier(tableName), strings.Join(actions, ", ")), nil
+}
+
+// BuildColumnStorageDDL constructs an ALTER TABLE ... ALTER COLUMN ... SET STORAGE
+// and/or SET STATISTICS statement safely.
+func BuildColumnStorageDDL(tableName, columnName string, req ColumnStorageRequest) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(columnName) {
+		return "", fmt.Errorf("invalid column name")
+	}
+	if req.Storage == "" && req.Statistics == nil {
+		return "", fmt.Errorf("storage or statistics is required")
+	}
+
+	column := "ALTER COLUMN " + sanitizeIdentifier(columnName)
+	var actions []string
+	if req.Storage != "" {
+		if _, ok := columnStorageModes[req.Storage]; !ok {
+			return "", fmt.Errorf("storage must be plain, external, extended, or main")
+		}
+		actions = append(actions, column+" SET STORAGE "+strings.ToUpper(req.Storage))
+	}
+	if req.Statistics != nil {
+		if *req.Statistics < -1 || *req.Statistics > 10000 {
+			return "", fmt.Errorf("statistics must be from 0 to 10000, or -1 for the default")
+		}
+		actions = append(actions, fmt.Sprintf("%s SET STATISTICS %d", column, *req.Statistics))
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s %s", sanitizeIdentifier(tableName), strings.Join(actions, ", ")), nil
+}
+
+// SetStorageParameters sets and resets storage parameters of a table.
+func (i *Introspector) SetStorageParameters(ctx context.Context, tableName string, req StorageParametersRequest) error {
+	query, err := BuildStorageParametersDDL(tableName, req)
+	if err != nil {
+		return err
+	}
+
+	// Undo restores each touched parameter to its previous value or default
+	var options []string
+	pool := i.getPool()
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	lookup := `SELECT COALESCE(reloptions, '{}') FROM pg_class WHERE oid = to_regclass(format('public.%I', $1::text))`
+	if err := pool.QueryRow(lookupCtx, lookup, tableName).Scan(&options); err != nil {
+		return fmt.Errorf("failed to get current storage parameters: %w", err)
+	}
+	previous := make(map[string]string, len(options))
+	for _, opt := range options {
+		if name, value, ok := strings.Cut(opt, "="); ok {
+			previous[name] = value
+		}
+	}
+	var restore StorageParametersRequest
+	for _, name := range slices.Concat(slices.Collect(maps.Keys(req.Set)), req.Reset) {
+		if value, ok := previous[name]; ok {
+			if restore.Set == nil {
+				restore.Set = make(map[string]string)
+			}
+			restore.Set[name] = value
+		} else {
+			restore.Reset = append(restore.Reset, name)
+		}
+	}
+	slices.Sort(restore.Reset)
+	undo, err := BuildStorageParametersDDL(tableName, restore)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: "Set storage parameters on " + tableName,
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
+
+// SetColumnStorage changes a column's storage mode and/or statistics target.
+func (i *Introspector) SetColumnStorage(ctx context.Context, tableName, columnName string, req ColumnStorageRequest) error {
+	query, err := BuildColumnStorageDDL(tableName, columnName, req)
+	if err != nil {
+		return err
+	}
+
+	// Undo restores the previous mode and target; attstattarget is NULL for
+	// the default since PostgreSQL 17 and -1 before
+	var storageCode string
+	var statistics int
+	pool := i.getPool()
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	lookup := `
+		SELECT a.attstorage::text, COALESCE(a.attstattarget, -1)::int
+		FROM pg_attribute a
+		WHERE a.attrelid = to_regclass(format('public.%I', $1::text))
+		  AND a.attname = $2 AND NOT a.attisdropped
+	`
+	if err := pool.QueryRow(lookupCtx, lookup, tableName, columnName).Scan(&storageCode, &statistics); err != nil {
+		return fmt.Errorf("failed to look up column: %w", err)
+	}
+	var restore ColumnStorageRequest
+	if req.Storage != "" {
+		for mode, code := range columnStorageModes {
+			if code == storageCode {
+				restore.Storage = mode
+			}
+		}
+	}
+	if req.Statistics != nil {
+		restore.Statistics = &statistics
+	}
+	undo, err := BuildColumnStorageDDL(tableName, columnName, restore)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Set storage of column %s.%s", tableName, columnName),
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
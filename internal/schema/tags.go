@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// TagsTable is the metadata table tags are kept in. It is created on request
+// by EnableTags and hidden from the introspected schema.
+const TagsTable = "altdb_tags"
+
+// MaxTags bounds how many tags one table or column may carry.
+const MaxTags = 20
+
+// ErrTagsDisabled is returned when tags are written before EnableTags
+// created the metadata table.
+var ErrTagsDisabled = errors.New("tags are not enabled")
+
+// tagPattern matches tags such as pii, deprecated, or to-migrate.
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ValidTag reports whether tag is 1 to 32 lowercase letters, digits,
+// hyphens, and underscores, starting with a letter or digit.
+func ValidTag(tag string) bool {
+	return tagPattern.MatchString(tag)
+}
+
+// ObjectTags are the tags of a table, or of one of its columns when Column is set.
+type ObjectTags struct {
+	Table  string   `json:"table"`
+	Column string   `json:"column,omitempty"`
+	Tags   []string `json:"tags"`
+}
+
+// tagsEnabled reports whether the metadata table exists.
+func (i *Introspector) tagsEnabled(ctx context.Context) (bool, error) {
+	var exists bool
+	query := `SELECT to_regclass(format('public.%I', $1::text)) IS NOT NULL`
+	if err := i.getPool().QueryRow(ctx, query, TagsTable).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check for tags table: %w", err)
+	}
+	return exists, nil
+}
+
+// EnableTags creates the metadata table if it does not exist yet.
+func (i *Introspector) EnableTags(ctx context.Context) error {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			table_name text NOT NULL,
+			column_name text NOT NULL DEFAULT '', -- Empty for table tags
+			tag text NOT NULL,
+			PRIMARY KEY (table_name, column_name, tag)
+		)
+	`, sanitizeIdentifier(TagsTable))
+	if _, err := i.getPool().Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create tags table: %w", err)
+	}
+	return nil
+}
+
+// GetTags returns the tags of every tagged table and column, and whether tags
+// are enabled. Tags are keyed by name, so they stay behind when a table or
+// column is dropped and do not follow a rename.
+func (i *Introspector) GetTags(ctx context.Context) ([]ObjectTags, bool, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	enabled, err := i.tagsEnabled(ctx)
+	if err != nil || !enabled {
+		return []ObjectTags{}, false, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT table_name, column_name, array_agg(tag ORDER BY tag)
+		FROM %s
+		GROUP BY table_name, column_name
+		ORDER BY table_name, column_name
+	`, sanitizeIdentifier(TagsTable))
+	rows, err := i.getPool().Query(ctx, query)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []ObjectTags{}
+	for rows.Next() {
+		var t ObjectTags
+		if err := rows.Scan(&t.Table, &t.Column, &t.Tags); err != nil {
+			return nil, false, fmt.Errorf("failed to scan tags: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, true, rows.Err()
+}
+
+// SetTags replaces the tags of a table, or of one of its columns when
+// columnName is set. An empty list removes them.
+func (i *Introspector) SetTags(ctx context.Context, tableName, columnName string, tags []string) error {
+	if !ValidIdentifier(tableName) {
+		return fmt.Errorf("invalid table name")
+	}
+	if columnName != "" && !ValidIdentifier(columnName) {
+		return fmt.Errorf("invalid column name")
+	}
+	if len(tags) > MaxTags {
+		return fmt.Errorf("at most %d tags are allowed", MaxTags)
+	}
+	for _, tag := range tags {
+		if !ValidTag(tag) {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+	tags = slices.Compact(slices.Sorted(slices.Values(tags)))
+
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	enabled, err := i.tagsEnabled(ctx)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return ErrTagsDisabled
+	}
+
+	tx, err := i.getPool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	table := sanitizeIdentifier(TagsTable)
+	if _, err := tx.Exec(ctx, "DELETE FROM "+table+" WHERE table_name = $1 AND column_name = $2", tableName, columnName); err != nil {
+		return fmt.Errorf("failed to clear tags: %w", err)
+	}
+	if len(tags) > 0 {
+		insert := "INSERT INTO " + table + " (table_name, column_name, tag) SELECT $1, $2, unnest($3::text[])"
+		if _, err := tx.Exec(ctx, insert, tableName, columnName, tags); err != nil {
+			return fmt.Errorf("failed to save tags: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
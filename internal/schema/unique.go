@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotUnique is returned by DropUnique when nothing makes the column unique.
+var ErrNotUnique = errors.New("column is not unique")
+
+// UniqueEnforcer is a constraint or standalone index that makes a single
+// column unique.
+type UniqueEnforcer struct {
+	Kind       string `json:"kind"` // constraint or index
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+	Partial    bool   `json:"partial,omitempty"` // Index with a WHERE clause; unique only for matching rows
+}
+
+// GetUniqueEnforcers returns the unique constraints and standalone unique
+// indexes covering exactly one column. Primary keys and multi-column or
+// expression indexes are not included.
+func (i *Introspector) GetUniqueEnforcers(ctx context.Context, tableName, columnName string) ([]UniqueEnforcer, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		WITH col AS (
+			SELECT a.attrelid, a.attnum
+			FROM pg_attribute a
+			WHERE a.attrelid = to_regclass(format('public.%I', $1::text))
+			  AND a.attname = $2 AND NOT a.attisdropped
+		)
+		SELECT 'constraint', con.conname, pg_get_constraintdef(con.oid), false
+		FROM pg_constraint con, col
+		WHERE con.conrelid = col.attrelid
+		  AND con.contype = 'u'
+		  AND con.conkey = ARRAY[col.attnum]
+
+		UNION ALL
+
+		SELECT 'index', ic.relname, pg_get_indexdef(ix.indexrelid), ix.indpred IS NOT NULL
+		FROM pg_index ix
+		JOIN pg_class ic ON ic.oid = ix.indexrelid, col
+		WHERE ix.indrelid = col.attrelid
+		  AND ix.indisunique AND NOT ix.indisprimary
+		  AND ix.indnkeyatts = 1 AND ix.indkey[0] = col.attnum
+		  AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = ix.indexrelid)
+
+		ORDER BY 1, 2
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query, tableName, columnName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unique constraints: %w", err)
+	}
+	defer rows.Close()
+
+	enforcers := make([]UniqueEnforcer, 0)
+	for rows.Next() {
+		var u UniqueEnforcer
+		if err := rows.Scan(&u.Kind, &u.Name, &u.Definition, &u.Partial); err != nil {
+			return nil, fmt.Errorf("failed to scan unique constraint: %w", err)
+		}
+		enforcers = append(enforcers, u)
+	}
+	return enforcers, rows.Err()
+}
+
+// DropUnique removes uniqueness from a column, dropping each unique constraint
+// with ALTER TABLE ... DROP CONSTRAINT and each standalone unique index with
+// DROP INDEX, in one transaction. Returns what was dropped, or ErrNotUnique.
+func (i *Introspector) DropUnique(ctx context.Context, tableName, columnName string) ([]UniqueEnforcer, error) {
+	if !ValidIdentifier(tableName) || !ValidIdentifier(columnName) {
+		return nil, fmt.Errorf("invalid table or column name")
+	}
+	enforcers, err := i.GetUniqueEnforcers(ctx, tableName, columnName)
+	if err != nil {
+		return nil, err
+	}
+	if len(enforcers) == 0 {
+		return nil, ErrNotUnique
+	}
+
+	// Names come from the catalogs, so they are quoted rather than validated
+	table := sanitizeIdentifier(tableName)
+	var statements, undo []string
+	for _, u := range enforcers {
+		if u.Kind == "constraint" {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, sanitizeIdentifier(u.Name)))
+			undo = append(undo, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s", table, sanitizeIdentifier(u.Name), u.Definition))
+		} else {
+			statements = append(statements, "DROP INDEX "+sanitizeIdentifier(u.Name))
+			undo = append(undo, u.Definition)
+		}
+	}
+
+	err = i.applyChange(ctx, Change{
+		Description: fmt.Sprintf("Remove uniqueness from %s.%s", tableName, columnName),
+		Statements:  statements,
+		Inverse:     undo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return enforcers, nil
+}
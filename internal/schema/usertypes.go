@@ -0,0 +1,260 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// EnumType is a user-defined enum type and its labels in sort order.
+type EnumType struct {
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+}
+
+// GetEnumTypes returns the enum types defined in the public schema.
+func (i *Introspector) GetEnumTypes(ctx context.Context) ([]EnumType, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			t.typname,
+			array_agg(e.enumlabel ORDER BY e.enumsortorder)
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_enum e ON e.enumtypid = t.oid
+		WHERE n.nspname = 'public'
+		GROUP BY t.typname
+		ORDER BY t.typname
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enum types: %w", err)
+	}
+	defer rows.Close()
+
+	enums := make([]EnumType, 0)
+	for rows.Next() {
+		var e EnumType
+		if err := rows.Scan(&e.Name, &e.Labels); err != nil {
+			return nil, fmt.Errorf("failed to scan enum type: %w", err)
+		}
+		enums = append(enums, e)
+	}
+
+	return enums, rows.Err()
+}
+
+// GetTypeUsage returns the columns, as table.column, whose type is the named
+// user-defined type or an array of it.
+func (i *Introspector) GetTypeUsage(ctx context.Context, typeName string) ([]string, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT c.relname || '.' || a.attname
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_type t ON t.oid = a.atttypid
+		LEFT JOIN pg_type elem ON elem.oid = t.typelem AND t.typcategory = 'A'
+		JOIN pg_namespace tn ON tn.oid = COALESCE(elem.typnamespace, t.typnamespace)
+		WHERE COALESCE(elem.typname, t.typname) = $1
+		  AND tn.nspname = 'public'
+		  AND a.attnum > 0 AND NOT a.attisdropped
+		  AND c.relkind IN ('r', 'p', 'f', 'v', 'm', 'c')
+		ORDER BY 1
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query, typeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type usage: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make([]string, 0)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("failed to scan type usage: %w", err)
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
+
+// TypeNames returns just the names of types, for type validation.
+func TypeNames(types []TypeInfo) []string {
+	names := make([]string, len(types))
+	for idx, t := range types {
+		names[idx] = t.Name
+	}
+	return names
+}
+
+// EnumTypeInfo describes enums in the same shape as AllowedTypes,
+// so clients can offer them alongside the built-in types.
+func EnumTypeInfo(enums []EnumType) []TypeInfo {
+	types := make([]TypeInfo, len(enums))
+	for idx, e := range enums {
+		types[idx] = TypeInfo{
+			Name:        e.Name,
+			Description: strings.Join(e.Labels, ", "),
+			Category:    "Enum",
+			Labels:      e.Labels,
+		}
+	}
+	return types
+}
+
+// DomainType is a user-defined domain: a base type plus optional constraints.
+type DomainType struct {
+	Name        string            `json:"name"`
+	BaseType    string            `json:"baseType"` // e.g. "character varying(254)"
+	NotNull     bool              `json:"notNull"`
+	Default     *string           `json:"default,omitempty"`
+	Constraints []CheckConstraint `json:"constraints"`
+}
+
+// GetDomainTypes returns the domains defined in the public schema.
+func (i *Introspector) GetDomainTypes(ctx context.Context) ([]DomainType, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			t.typname,
+			format_type(t.typbasetype, t.typtypmod),
+			t.typnotnull,
+			t.typdefault,
+			COALESCE(array_agg(c.conname ORDER BY c.conname) FILTER (WHERE c.oid IS NOT NULL), '{}'),
+			COALESCE(array_agg(pg_get_constraintdef(c.oid, true) ORDER BY c.conname) FILTER (WHERE c.oid IS NOT NULL), '{}')
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		LEFT JOIN pg_constraint c ON c.contypid = t.oid AND c.contype = 'c'
+		WHERE t.typtype = 'd'
+		  AND n.nspname = 'public'
+		GROUP BY t.oid, t.typname, t.typbasetype, t.typtypmod, t.typnotnull, t.typdefault
+		ORDER BY t.typname
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain types: %w", err)
+	}
+	defer rows.Close()
+
+	domains := make([]DomainType, 0)
+	for rows.Next() {
+		var d DomainType
+		var names, exprs []string
+		if err := rows.Scan(&d.Name, &d.BaseType, &d.NotNull, &d.Default, &names, &exprs); err != nil {
+			return nil, fmt.Errorf("failed to scan domain type: %w", err)
+		}
+		d.Constraints = make([]CheckConstraint, len(names))
+		for idx := range names {
+			d.Constraints[idx] = CheckConstraint{Name: names[idx], Expression: exprs[idx]}
+		}
+		domains = append(domains, d)
+	}
+
+	return domains, rows.Err()
+}
+
+// CompositeType is a standalone composite type created with CREATE TYPE ... AS (...).
+type CompositeType struct {
+	Name       string               `json:"name"`
+	Attributes []CompositeAttribute `json:"attributes"`
+}
+
+// CompositeAttribute is one field of a composite type.
+type CompositeAttribute struct {
+	Name     string `json:"name"`
+	DataType string `json:"dataType"`
+}
+
+// GetCompositeTypes returns the standalone composite types in the public schema.
+// Row types that PostgreSQL creates implicitly for tables and views are skipped.
+func (i *Introspector) GetCompositeTypes(ctx context.Context) ([]CompositeType, error) {
+	ctx, cancel := i.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			t.typname,
+			array_agg(a.attname ORDER BY a.attnum),
+			array_agg(format_type(a.atttypid, a.atttypmod) ORDER BY a.attnum)
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_class r ON r.oid = t.typrelid AND r.relkind = 'c'
+		JOIN pg_attribute a ON a.attrelid = r.oid AND a.attnum > 0 AND NOT a.attisdropped
+		WHERE t.typtype = 'c'
+		  AND n.nspname = 'public'
+		GROUP BY t.typname
+		ORDER BY t.typname
+	`
+
+	pool := i.getPool()
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get composite types: %w", err)
+	}
+	defer rows.Close()
+
+	composites := make([]CompositeType, 0)
+	for rows.Next() {
+		var c CompositeType
+		var names, types []string
+		if err := rows.Scan(&c.Name, &names, &types); err != nil {
+			return nil, fmt.Errorf("failed to scan composite type: %w", err)
+		}
+		c.Attributes = make([]CompositeAttribute, len(names))
+		for idx := range names {
+			c.Attributes[idx] = CompositeAttribute{Name: names[idx], DataType: types[idx]}
+		}
+		composites = append(composites, c)
+	}
+
+	return composites, rows.Err()
+}
+
+// DomainTypeInfo describes domains in the same shape as AllowedTypes.
+func DomainTypeInfo(domains []DomainType) []TypeInfo {
+	types := make([]TypeInfo, len(domains))
+	for idx, d := range domains {
+		desc := "Domain over " + d.BaseType
+		for _, c := range d.Constraints {
+			desc += " " + c.Expression
+		}
+		types[idx] = TypeInfo{
+			Name:        d.Name,
+			Description: desc,
+			Category:    "Domain",
+			BaseType:    d.BaseType,
+		}
+	}
+	return types
+}
+
+// CompositeTypeInfo describes composite types in the same shape as AllowedTypes.
+func CompositeTypeInfo(composites []CompositeType) []TypeInfo {
+	types := make([]TypeInfo, len(composites))
+	for idx, c := range composites {
+		fields := make([]string, len(c.Attributes))
+		for a, attr := range c.Attributes {
+			fields[a] = attr.Name + " " + attr.DataType
+		}
+		types[idx] = TypeInfo{
+			Name:        c.Name,
+			Description: "(" + strings.Join(fields, ", ") + ")",
+			Category:    "Composite",
+			Attributes:  c.Attributes,
+		}
+	}
+	return types
+}
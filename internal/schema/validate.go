@@ -2,14 +2,19 @@ package schema
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
 // TypeInfo represents a PostgreSQL data type with metadata.
 type TypeInfo struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Labels      []string `json:"labels,omitempty"` // Enum labels, for user-defined enums
+
+	BaseType   string               `json:"baseType,omitempty"`   // Underlying type, for domains
+	Attributes []CompositeAttribute `json:"attributes,omitempty"` // Fields, for composite types
 }
 
 // AllowedTypes is the canonical list of supported PostgreSQL types.
@@ -91,20 +96,31 @@ func sanitizeIdentifier(name string) string {
 	return `"` + escaped + `"`
 }
 
-// IsValidType checks if the given type name is in the allowed types list.
-func IsValidType(t string) bool {
-	return allowedTypesMap[t]
+// IsValidType checks if the given type name is in the allowed types list
+// or is one of customTypes, the user-defined types (enums, domains, composite
+// types and, when enabled, extension types) present in the target database.
+func IsValidType(t string, customTypes []string) bool {
+	return allowedTypesMap[t] || slices.Contains(customTypes, t)
 }
 
 // sanitizeType validates and returns a safe type name.
-// Returns error if type is not in allowed list.
-func sanitizeType(t string) (string, error) {
+// Returns error if type is neither in the allowed list nor one of customTypes.
+// Custom type names come from the catalog and may need quoting, so they are quoted.
+func sanitizeType(t string, customTypes []string) (string, error) {
 	if allowedTypesMap[t] {
 		return t, nil
 	}
+	if slices.Contains(customTypes, t) {
+		return sanitizeIdentifier(t), nil
+	}
 	return "", fmt.Errorf("unsupported column type %q", t)
 }
 
+// QuoteLiteral quotes s as a SQL string literal, doubling embedded single quotes.
+func QuoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
 // ColumnDef holds validated column definition parts for DDL building.
 type ColumnDef struct {
 	Name             string
@@ -114,23 +130,173 @@ type ColumnDef struct {
 	Unique           bool
 	ReferencesTable  string
 	ReferencesColumn string
+	// Deferrable and InitiallyDeferred apply to the REFERENCES constraint
+	Deferrable        bool
+	InitiallyDeferred bool
+	// Generated makes the column GENERATED ALWAYS AS (...) STORED
+	Generated *GeneratedExpr
+	// Identity makes the column GENERATED ... AS IDENTITY; see IdentityDef
+	Identity *IdentityDef
+}
+
+// Identity generation kinds.
+const (
+	IdentityAlways    = "always"     // Explicit values are rejected unless OVERRIDING SYSTEM VALUE
+	IdentityByDefault = "by-default" // Explicit values are accepted
+)
+
+// identityTypes are the column types an identity column may have.
+var identityTypes = []string{"smallint", "integer", "bigint"}
+
+// IdentityDef describes an identity column, the standard replacement for
+// serial. Start and Increment default to 1.
+type IdentityDef struct {
+	Generation string `json:"generation"` // always or by-default
+	Start      *int64 `json:"start,omitempty"`
+	Increment  *int64 `json:"increment,omitempty"` // Must not be zero
+}
+
+// BuildIdentityClause renders the GENERATED ... AS IDENTITY clause of a
+// column of type colType.
+func BuildIdentityClause(colType string, id IdentityDef) (string, error) {
+	if !slices.Contains(identityTypes, colType) {
+		return "", fmt.Errorf("identity columns must be smallint, integer, or bigint")
+	}
+
+	var clause string
+	switch id.Generation {
+	case IdentityAlways:
+		clause = "GENERATED ALWAYS AS IDENTITY"
+	case IdentityByDefault:
+		clause = "GENERATED BY DEFAULT AS IDENTITY"
+	default:
+		return "", fmt.Errorf("identity generation must be always or by-default")
+	}
+
+	var options []string
+	if id.Start != nil {
+		options = append(options, fmt.Sprintf("START WITH %d", *id.Start))
+	}
+	if id.Increment != nil {
+		if *id.Increment == 0 {
+			return "", fmt.Errorf("identity increment must not be zero")
+		}
+		options = append(options, fmt.Sprintf("INCREMENT BY %d", *id.Increment))
+	}
+	if len(options) > 0 {
+		clause += " (" + strings.Join(options, " ") + ")"
+	}
+	return clause, nil
 }
 
-// BuildCreateTableDDL constructs a CREATE TABLE statement safely.
-// Returns error if tableName is invalid.
-func BuildCreateTableDDL(tableName string) (string, error) {
+// BuildCreateTableDDL constructs a CREATE TABLE statement safely. With no
+// primaryKey the table gets an auto-incrementing id primary key ahead of
+// columns: a bigint identity column when idIdentity is set, otherwise serial.
+// primaryKey names the key columns, in key order, which must all be among
+// columns (e.g. both references of a join table).
+// Returns error if tableName or any column definition is invalid.
+func BuildCreateTableDDL(tableName string, columns []ColumnDef, primaryKey []string, idIdentity *IdentityDef, customTypes []string) (string, error) {
 	if !ValidIdentifier(tableName) {
 		return "", fmt.Errorf("invalid table name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
 	}
-	return fmt.Sprintf("CREATE TABLE %s (id SERIAL PRIMARY KEY)", sanitizeIdentifier(tableName)), nil
+
+	var defs []string
+	switch {
+	case len(primaryKey) > 0 && idIdentity != nil:
+		return "", fmt.Errorf("an identity id column cannot be combined with a table primary key")
+	case idIdentity != nil:
+		identity, err := BuildIdentityClause("bigint", *idIdentity)
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, "id bigint "+identity+" PRIMARY KEY")
+	case len(primaryKey) == 0:
+		defs = append(defs, "id SERIAL PRIMARY KEY")
+	}
+	names := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		if names[col.Name] {
+			return "", fmt.Errorf("duplicate column name %q", col.Name)
+		}
+		names[col.Name] = true
+		if col.PrimaryKey && len(primaryKey) > 0 {
+			return "", fmt.Errorf("column %q cannot be a primary key by itself when a table primary key is given", col.Name)
+		}
+		def, err := columnDefinition(col, customTypes)
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, def)
+	}
+
+	if len(primaryKey) > 0 {
+		keyColumns := make([]string, len(primaryKey))
+		seen := make(map[string]bool, len(primaryKey))
+		for idx, name := range primaryKey {
+			if !names[name] {
+				return "", fmt.Errorf("primary key column %q is not defined", name)
+			}
+			if seen[name] {
+				return "", fmt.Errorf("primary key column %q is listed twice", name)
+			}
+			seen[name] = true
+			keyColumns[idx] = sanitizeIdentifier(name)
+		}
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keyColumns, ", ")))
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (%s)", sanitizeIdentifier(tableName), strings.Join(defs, ", ")), nil
 }
 
 // BuildAddColumnDDL constructs an ALTER TABLE ADD COLUMN statement safely.
+// customTypes lists the types col.Type may name in addition to AllowedTypes.
 // Returns error if tableName or column definition is invalid.
-func BuildAddColumnDDL(tableName string, col ColumnDef) (string, error) {
+func BuildAddColumnDDL(tableName string, col ColumnDef, customTypes []string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+
+	def, err := columnDefinition(col, customTypes)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s",
+		sanitizeIdentifier(tableName),
+		def), nil
+}
+
+// BuildAddColumnsDDL constructs a single ALTER TABLE statement with one ADD
+// COLUMN clause per column, so the table is rewritten at most once.
+// customTypes lists the types the columns may name in addition to AllowedTypes.
+func BuildAddColumnsDDL(tableName string, cols []ColumnDef, customTypes []string) (string, error) {
 	if !ValidIdentifier(tableName) {
 		return "", fmt.Errorf("invalid table name")
 	}
+	if len(cols) == 0 {
+		return "", fmt.Errorf("at least one column is required")
+	}
+
+	clauses := make([]string, len(cols))
+	seen := make(map[string]bool, len(cols))
+	for idx, col := range cols {
+		if seen[col.Name] {
+			return "", fmt.Errorf("duplicate column %q", col.Name)
+		}
+		seen[col.Name] = true
+
+		def, err := columnDefinition(col, customTypes)
+		if err != nil {
+			return "", fmt.Errorf("column %q: %w", col.Name, err)
+		}
+		clauses[idx] = "ADD COLUMN " + def
+	}
+
+	return fmt.Sprintf("ALTER TABLE %s %s", sanitizeIdentifier(tableName), strings.Join(clauses, ", ")), nil
+}
+
+// columnDefinition renders col as it appears in CREATE TABLE or ADD COLUMN.
+func columnDefinition(col ColumnDef, customTypes []string) (string, error) {
 	if !ValidIdentifier(col.Name) {
 		return "", fmt.Errorf("invalid column name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
 	}
@@ -139,12 +305,31 @@ func BuildAddColumnDDL(tableName string, col ColumnDef) (string, error) {
 	var parts []string
 	parts = append(parts, sanitizeIdentifier(col.Name))
 
-	safeType, err := sanitizeType(col.Type)
+	safeType, err := sanitizeType(col.Type, customTypes)
 	if err != nil {
 		return "", err
 	}
 	parts = append(parts, safeType)
 
+	if col.Identity != nil {
+		if col.Generated != nil {
+			return "", fmt.Errorf("a column cannot be both generated and an identity")
+		}
+		identity, err := BuildIdentityClause(col.Type, *col.Identity)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, identity)
+	}
+
+	if col.Generated != nil {
+		expr, err := BuildGeneratedExpr(*col.Generated)
+		if err != nil {
+			return "", fmt.Errorf("invalid generated expression: %w", err)
+		}
+		parts = append(parts, fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", expr))
+	}
+
 	if col.NotNull {
 		parts = append(parts, "NOT NULL")
 	}
@@ -167,9 +352,523 @@ func BuildAddColumnDDL(tableName string, col ColumnDef) (string, error) {
 		parts = append(parts, fmt.Sprintf("REFERENCES %s(%s)",
 			sanitizeIdentifier(col.ReferencesTable),
 			sanitizeIdentifier(col.ReferencesColumn)))
+		deferral, err := deferrableClause(col.Deferrable, col.InitiallyDeferred)
+		if err != nil {
+			return "", err
+		}
+		if deferral != "" {
+			parts = append(parts, deferral)
+		}
 	}
 
-	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s",
+	return strings.Join(parts, " "), nil
+}
+
+// ForeignKeyDef holds validated parts of a table-level foreign key constraint.
+type ForeignKeyDef struct {
+	Name             string // Empty lets PostgreSQL name it <table>_<column>_fkey
+	Column           string
+	ReferencesTable  string
+	ReferencesColumn string
+	NotValid         bool // Skip checking existing rows; validate later
+	// Deferrable lets transactions postpone the check to commit, which
+	// circular references need; InitiallyDeferred makes that the default.
+	Deferrable        bool
+	InitiallyDeferred bool
+}
+
+// deferrableClause renders the deferral attributes of a foreign key.
+// Returns an empty string for the default, NOT DEFERRABLE.
+func deferrableClause(deferrable, initiallyDeferred bool) (string, error) {
+	switch {
+	case initiallyDeferred && !deferrable:
+		return "", fmt.Errorf("initially deferred requires deferrable")
+	case initiallyDeferred:
+		return "DEFERRABLE INITIALLY DEFERRED", nil
+	case deferrable:
+		return "DEFERRABLE", nil
+	}
+	return "", nil
+}
+
+// BuildAddForeignKeyDDL constructs an ALTER TABLE ADD FOREIGN KEY statement safely.
+// Without fk.Name the constraint name is left to PostgreSQL's default
+// (<table>_<column>_fkey).
+func BuildAddForeignKeyDDL(tableName string, fk ForeignKeyDef) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(fk.Column) {
+		return "", fmt.Errorf("invalid column name")
+	}
+	if !ValidIdentifier(fk.ReferencesTable) {
+		return "", fmt.Errorf("invalid foreign key table name")
+	}
+	if !ValidIdentifier(fk.ReferencesColumn) {
+		return "", fmt.Errorf("invalid foreign key column name")
+	}
+	if fk.Name != "" && !ValidIdentifier(fk.Name) {
+		return "", fmt.Errorf("invalid constraint name")
+	}
+
+	constraint := ""
+	if fk.Name != "" {
+		constraint = "CONSTRAINT " + sanitizeIdentifier(fk.Name) + " "
+	}
+	query := fmt.Sprintf("ALTER TABLE %s ADD %sFOREIGN KEY (%s) REFERENCES %s(%s)",
+		sanitizeIdentifier(tableName),
+		constraint,
+		sanitizeIdentifier(fk.Column),
+		sanitizeIdentifier(fk.ReferencesTable),
+		sanitizeIdentifier(fk.ReferencesColumn))
+	deferral, err := deferrableClause(fk.Deferrable, fk.InitiallyDeferred)
+	if err != nil {
+		return "", err
+	}
+	if deferral != "" {
+		query += " " + deferral
+	}
+	if fk.NotValid {
+		query += " NOT VALID"
+	}
+	return query, nil
+}
+
+// BuildDropTableDDL constructs a DROP TABLE statement safely. Without
+// cascade PostgreSQL refuses to drop a table other objects depend on.
+func BuildDropTableDDL(tableName string, cascade bool) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	query := "DROP TABLE " + sanitizeIdentifier(tableName)
+	if cascade {
+		query += " CASCADE"
+	}
+	return query, nil
+}
+
+// CloneOptions selects what CREATE TABLE ... LIKE copies besides columns,
+// their types, and NOT NULL. The zero value copies everything.
+type CloneOptions struct {
+	ExcludeIndexes     bool `json:"excludeIndexes"`     // Also drops primary key and unique constraints
+	ExcludeDefaults    bool `json:"excludeDefaults"`    // Serial columns otherwise share the source's sequence
+	ExcludeConstraints bool `json:"excludeConstraints"` // CHECK constraints; foreign keys are never copied
+}
+
+// BuildCloneTableDDL constructs a CREATE TABLE ... (LIKE ... INCLUDING ALL)
+// statement safely, excluding what opts asks for.
+func BuildCloneTableDDL(sourceTable, newName string, opts CloneOptions) (string, error) {
+	if !ValidIdentifier(sourceTable) {
+		return "", fmt.Errorf("invalid source table name")
+	}
+	if !ValidIdentifier(newName) {
+		return "", fmt.Errorf("invalid table name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
+	}
+	like := "LIKE " + sanitizeIdentifier(sourceTable) + " INCLUDING ALL"
+	if opts.ExcludeIndexes {
+		like += " EXCLUDING INDEXES"
+	}
+	if opts.ExcludeDefaults {
+		like += " EXCLUDING DEFAULTS"
+	}
+	if opts.ExcludeConstraints {
+		like += " EXCLUDING CONSTRAINTS"
+	}
+	return fmt.Sprintf("CREATE TABLE %s (%s)", sanitizeIdentifier(newName), like), nil
+}
+
+// BuildTruncateDDL constructs a TRUNCATE statement safely. restartIdentity
+// resets the table's identity and serial sequences; cascade also truncates
+// tables with foreign keys to it.
+func BuildTruncateDDL(tableName string, restartIdentity, cascade bool) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	query := "TRUNCATE TABLE " + sanitizeIdentifier(tableName)
+	if restartIdentity {
+		query += " RESTART IDENTITY"
+	}
+	if cascade {
+		query += " CASCADE"
+	}
+	return query, nil
+}
+
+// BuildDropColumnDDL constructs an ALTER TABLE ... DROP COLUMN statement safely.
+// Indexes and constraints on the column go with it.
+func BuildDropColumnDDL(tableName, columnName string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(columnName) {
+		return "", fmt.Errorf("invalid column name")
+	}
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", sanitizeIdentifier(tableName), sanitizeIdentifier(columnName)), nil
+}
+
+// BuildDropViewDDL constructs a DROP VIEW statement safely. It never cascades.
+func BuildDropViewDDL(viewName string) (string, error) {
+	if !ValidIdentifier(viewName) {
+		return "", fmt.Errorf("invalid view name")
+	}
+	return "DROP VIEW " + sanitizeIdentifier(viewName), nil
+}
+
+// BuildRenameTableDDL constructs an ALTER TABLE ... RENAME TO statement safely.
+func BuildRenameTableDDL(tableName, newName string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(newName) {
+		return "", fmt.Errorf("invalid new table name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
+	}
+	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", sanitizeIdentifier(tableName), sanitizeIdentifier(newName)), nil
+}
+
+// BuildRenameColumnDDL constructs an ALTER TABLE ... RENAME COLUMN statement safely.
+func BuildRenameColumnDDL(tableName, columnName, newName string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(columnName) {
+		return "", fmt.Errorf("invalid column name")
+	}
+	if !ValidIdentifier(newName) {
+		return "", fmt.Errorf("invalid new column name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
+	}
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s",
+		sanitizeIdentifier(tableName),
+		sanitizeIdentifier(columnName),
+		sanitizeIdentifier(newName)), nil
+}
+
+// ConversionTemplate is a USING expression offered when changing a column's
+// type. Expression is a format string: %[1]s is the quoted column and %[2]s
+// the target type.
+type ConversionTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Expression  string `json:"-"`
+}
+
+// ConversionTemplates are the only USING expressions ALTER COLUMN TYPE accepts,
+// so no user-supplied SQL reaches the statement.
+var ConversionTemplates = []ConversionTemplate{
+	{Name: "cast", Description: "Explicit cast, e.g. amount::integer", Expression: "%[1]s::%[2]s"},
+	{Name: "trim-cast", Description: "Trim whitespace, then cast (text to number)", Expression: "btrim(%[1]s::text)::%[2]s"},
+	{Name: "empty-to-null", Description: "Empty strings become NULL, then cast", Expression: "NULLIF(btrim(%[1]s::text), '')::%[2]s"},
+	{Name: "epoch-seconds", Description: "Unix seconds to timestamp: to_timestamp(col)", Expression: "to_timestamp(%[1]s)::%[2]s"},
+	{Name: "epoch-millis", Description: "Unix milliseconds to timestamp: to_timestamp(col / 1000.0)", Expression: "to_timestamp(%[1]s / 1000.0)::%[2]s"},
+	{Name: "to-json", Description: "Wrap the value as JSON: to_jsonb(col)", Expression: "to_jsonb(%[1]s)::%[2]s"},
+}
+
+// conversionTemplate looks up a template by name.
+func conversionTemplate(name string) (ConversionTemplate, bool) {
+	for _, t := range ConversionTemplates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return ConversionTemplate{}, false
+}
+
+// BuildAlterColumnTypeDDL constructs an ALTER TABLE ... ALTER COLUMN ... TYPE
+// statement safely. using names one of ConversionTemplates; empty means no
+// USING clause, which only works where PostgreSQL has an implicit cast.
+func BuildAlterColumnTypeDDL(tableName, columnName, newType, using string, customTypes []string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(columnName) {
+		return "", fmt.Errorf("invalid column name")
+	}
+	if newType == "serial" || newType == "bigserial" {
+		// Serial is CREATE-time shorthand for integer plus a sequence default
+		return "", fmt.Errorf("cannot change a column to %s", newType)
+	}
+	safeType, err := sanitizeType(newType, customTypes)
+	if err != nil {
+		return "", err
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s",
+		sanitizeIdentifier(tableName),
+		sanitizeIdentifier(columnName),
+		safeType)
+	if using != "" {
+		tmpl, ok := conversionTemplate(using)
+		if !ok {
+			return "", fmt.Errorf("unknown conversion %q", using)
+		}
+		query += " USING " + fmt.Sprintf(tmpl.Expression, sanitizeIdentifier(columnName), safeType)
+	}
+	return query, nil
+}
+
+// BuildSetNullableDDL constructs an ALTER COLUMN ... SET/DROP NOT NULL statement safely.
+func BuildSetNullableDDL(tableName, columnName string, nullable bool) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(columnName) {
+		return "", fmt.Errorf("invalid column name")
+	}
+	action := "SET NOT NULL"
+	if nullable {
+		action = "DROP NOT NULL"
+	}
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s",
+		sanitizeIdentifier(tableName),
+		sanitizeIdentifier(columnName),
+		action), nil
+}
+
+// BuildDropConstraintDDL constructs an ALTER TABLE ... DROP CONSTRAINT statement
+// safely. cascade also drops foreign keys that rely on a dropped unique or
+// primary key constraint.
+func BuildDropConstraintDDL(tableName, constraintName string, cascade bool) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(constraintName) {
+		return "", fmt.Errorf("invalid constraint name")
+	}
+	query := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s",
+		sanitizeIdentifier(tableName),
+		sanitizeIdentifier(constraintName))
+	if cascade {
+		query += " CASCADE"
+	}
+	return query, nil
+}
+
+// BuildAddConstraintDDL constructs an ALTER TABLE ... ADD CONSTRAINT statement
+// that restores a dropped constraint. definition is used verbatim, so it must
+// come from pg_get_constraintdef, never from a request.
+func BuildAddConstraintDDL(tableName, constraintName, definition string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(constraintName) {
+		return "", fmt.Errorf("invalid constraint name")
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s",
+		sanitizeIdentifier(tableName),
+		sanitizeIdentifier(constraintName),
+		definition), nil
+}
+
+// BuildRenameConstraintDDL constructs an ALTER TABLE ... RENAME CONSTRAINT statement safely.
+func BuildRenameConstraintDDL(tableName, constraintName, newName string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(constraintName) {
+		return "", fmt.Errorf("invalid constraint name")
+	}
+	if !ValidIdentifier(newName) {
+		return "", fmt.Errorf("invalid new constraint name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
+	}
+	return fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT %s TO %s",
 		sanitizeIdentifier(tableName),
-		strings.Join(parts, " ")), nil
+		sanitizeIdentifier(constraintName),
+		sanitizeIdentifier(newName)), nil
+}
+
+// Check constraint kinds accepted by BuildAddCheckDDL.
+const (
+	CheckCompare   = "compare"    // column <op> value
+	CheckIn        = "in"         // column IN (values...)
+	CheckMaxLength = "max-length" // length(column) <= length
+	CheckRegex     = "regex"      // column ~ pattern
+)
+
+// checkOperators are the comparison operators a compare check may use.
+var checkOperators = []string{"=", "<>", "<", "<=", ">", ">="}
+
+// CheckDef describes a CHECK constraint built from a fixed pattern. Values are
+// always sent as quoted literals, which PostgreSQL coerces to the column type.
+type CheckDef struct {
+	Name     string   `json:"name,omitempty"` // Empty names it <table>_<column>_check, as PostgreSQL would
+	Column   string   `json:"column"`
+	Kind     string   `json:"kind"`
+	Operator string   `json:"operator,omitempty"` // For compare
+	Value    string   `json:"value,omitempty"`    // For compare
+	Values   []string `json:"values,omitempty"`   // For in
+	Length   int      `json:"length,omitempty"`   // For max-length
+	Pattern  string   `json:"pattern,omitempty"`  // For regex; POSIX syntax
+	NotValid bool     `json:"notValid"`           // Skip checking existing rows
+}
+
+// BuildAddCheckDDL constructs an ALTER TABLE ... ADD CHECK statement from a
+// CheckDef, so no user-written SQL reaches the expression.
+func BuildAddCheckDDL(tableName string, check CheckDef) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(check.Column) {
+		return "", fmt.Errorf("invalid column name")
+	}
+	if check.Name != "" && !ValidIdentifier(check.Name) {
+		return "", fmt.Errorf("invalid constraint name")
+	}
+
+	column := sanitizeIdentifier(check.Column)
+	var expr string
+	switch check.Kind {
+	case CheckCompare:
+		if !slices.Contains(checkOperators, check.Operator) {
+			return "", fmt.Errorf("unsupported operator %q", check.Operator)
+		}
+		expr = fmt.Sprintf("%s %s %s", column, check.Operator, QuoteLiteral(check.Value))
+	case CheckIn:
+		if len(check.Values) == 0 {
+			return "", fmt.Errorf("at least one value is required")
+		}
+		values := make([]string, len(check.Values))
+		for idx, v := range check.Values {
+			values[idx] = QuoteLiteral(v)
+		}
+		expr = fmt.Sprintf("%s IN (%s)", column, strings.Join(values, ", "))
+	case CheckMaxLength:
+		if check.Length <= 0 {
+			return "", fmt.Errorf("length must be positive")
+		}
+		expr = fmt.Sprintf("length(%s) <= %d", column, check.Length)
+	case CheckRegex:
+		if check.Pattern == "" {
+			return "", fmt.Errorf("pattern is required")
+		}
+		expr = fmt.Sprintf("%s ~ %s", column, QuoteLiteral(check.Pattern))
+	default:
+		return "", fmt.Errorf("unsupported check kind %q", check.Kind)
+	}
+
+	query := "ALTER TABLE " + sanitizeIdentifier(tableName) + " ADD "
+	if check.Name != "" {
+		query += "CONSTRAINT " + sanitizeIdentifier(check.Name) + " "
+	}
+	query += "CHECK (" + expr + ")"
+	if check.NotValid {
+		query += " NOT VALID"
+	}
+	return query, nil
+}
+
+// validEnumLabel checks an enum label: PostgreSQL allows any non-empty text up
+// to 63 bytes, and labels are always sent as quoted literals.
+func validEnumLabel(label string) bool {
+	return label != "" && len(label) <= 63
+}
+
+// BuildCreateEnumDDL constructs a CREATE TYPE ... AS ENUM statement safely.
+// Names of built-in types are rejected so an enum cannot shadow them.
+func BuildCreateEnumDDL(name string, labels []string) (string, error) {
+	if !ValidIdentifier(name) {
+		return "", fmt.Errorf("invalid type name: must be lowercase letters, numbers, underscores, and start with letter or underscore")
+	}
+	if allowedTypesMap[name] {
+		return "", fmt.Errorf("type name %q is a built-in type", name)
+	}
+	if len(labels) == 0 {
+		return "", fmt.Errorf("at least one value is required")
+	}
+	quoted := make([]string, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for idx, label := range labels {
+		if !validEnumLabel(label) {
+			return "", fmt.Errorf("invalid enum value %q: must be 1 to 63 bytes", label)
+		}
+		if seen[label] {
+			return "", fmt.Errorf("duplicate enum value %q", label)
+		}
+		seen[label] = true
+		quoted[idx] = QuoteLiteral(label)
+	}
+	return fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", sanitizeIdentifier(name), strings.Join(quoted, ", ")), nil
+}
+
+// BuildAddEnumValueDDL constructs an ALTER TYPE ... ADD VALUE statement safely.
+// At most one of before and after may be set; with neither the value is appended.
+func BuildAddEnumValueDDL(name, label, before, after string) (string, error) {
+	if !ValidIdentifier(name) {
+		return "", fmt.Errorf("invalid type name")
+	}
+	if !validEnumLabel(label) {
+		return "", fmt.Errorf("invalid enum value: must be 1 to 63 bytes")
+	}
+	query := fmt.Sprintf("ALTER TYPE %s ADD VALUE %s", sanitizeIdentifier(name), QuoteLiteral(label))
+	switch {
+	case before != "" && after != "":
+		return "", fmt.Errorf("only one of before and after may be given")
+	case before != "":
+		query += " BEFORE " + QuoteLiteral(before)
+	case after != "":
+		query += " AFTER " + QuoteLiteral(after)
+	}
+	return query, nil
+}
+
+// BuildDropTypeDDL constructs a DROP TYPE statement safely. It never cascades:
+// PostgreSQL refuses while any column still uses the type.
+func BuildDropTypeDDL(name string) (string, error) {
+	if !ValidIdentifier(name) {
+		return "", fmt.Errorf("invalid type name")
+	}
+	return "DROP TYPE " + sanitizeIdentifier(name), nil
+}
+
+// BuildTableCommentDDL constructs a COMMENT ON TABLE statement safely.
+// An empty comment removes the existing one.
+func BuildTableCommentDDL(tableName, comment string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	return fmt.Sprintf("COMMENT ON TABLE %s IS %s", sanitizeIdentifier(tableName), commentValue(comment)), nil
+}
+
+// BuildColumnCommentDDL constructs a COMMENT ON COLUMN statement safely.
+// An empty comment removes the existing one.
+func BuildColumnCommentDDL(tableName, columnName, comment string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(columnName) {
+		return "", fmt.Errorf("invalid column name")
+	}
+	return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s",
+		sanitizeIdentifier(tableName),
+		sanitizeIdentifier(columnName),
+		commentValue(comment)), nil
+}
+
+// BuildConstraintCommentDDL constructs a COMMENT ON CONSTRAINT statement safely.
+// An empty comment removes the existing one.
+func BuildConstraintCommentDDL(tableName, constraintName, comment string) (string, error) {
+	if !ValidIdentifier(tableName) {
+		return "", fmt.Errorf("invalid table name")
+	}
+	if !ValidIdentifier(constraintName) {
+		return "", fmt.Errorf("invalid constraint name")
+	}
+	return fmt.Sprintf("COMMENT ON CONSTRAINT %s ON %s IS %s",
+		sanitizeIdentifier(constraintName),
+		sanitizeIdentifier(tableName),
+		commentValue(comment)), nil
+}
+
+// BuildIndexCommentDDL constructs a COMMENT ON INDEX statement safely.
+// An empty comment removes the existing one.
+func BuildIndexCommentDDL(indexName, comment string) (string, error) {
+	if !ValidIdentifier(indexName) {
+		return "", fmt.Errorf("invalid index name")
+	}
+	return fmt.Sprintf("COMMENT ON INDEX %s IS %s", sanitizeIdentifier(indexName), commentValue(comment)), nil
+}
+
+func commentValue(comment string) string {
+	if comment == "" {
+		return "NULL"
+	}
+	return QuoteLiteral(comment)
 }
@@ -0,0 +1,233 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrViewExists is returned by CreateView when a view, or another relation
+// such as a sequence, already has the new view's name.
+var ErrViewExists = errors.New("a view or other relation with that name already exists")
+
+// ViewDefinition is a structured view definition, turned into CREATE VIEW by
+// BuildCreateViewDDL. Tables are joined only along existing foreign keys and
+// each table appears once, so every name can be checked against the schema.
+type ViewDefinition struct {
+	Name    string       `json:"name"`
+	From    string       `json:"from"`
+	Joins   []ViewJoin   `json:"joins,omitempty"`
+	Columns []ViewColumn `json:"columns"`
+	Filters []ViewFilter `json:"filters,omitempty"` // Combined with AND
+}
+
+// ViewJoin adds a table joined to one already in the view by a foreign key in
+// either direction. ForeignKey picks the constraint when there are several.
+type ViewJoin struct {
+	Table      string `json:"table"`
+	ForeignKey string `json:"foreignKey,omitempty"`
+	Left       bool   `json:"left"` // LEFT JOIN instead of an inner join
+}
+
+// ViewColumn selects a column, optionally renamed.
+type ViewColumn struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Alias  string `json:"alias,omitempty"`
+}
+
+// ViewFilter compares a column with a literal value, or tests it for NULL.
+type ViewFilter struct {
+	Table    string `json:"table"`
+	Column   string `json:"column"`
+	Operator string `json:"operator"`        // =, <>, <, <=, >, >=, IS NULL, IS NOT NULL
+	Value    string `json:"value,omitempty"` // Sent as a quoted literal; ignored for NULL tests
+}
+
+// viewFilterOperators lists the operators a ViewFilter may use.
+var viewFilterOperators = []string{"=", "<>", "<", "<=", ">", ">=", "IS NULL", "IS NOT NULL"}
+
+// BuildCreateViewDDL constructs a CREATE VIEW statement from def, checking
+// every table, column, and join against s.
+func BuildCreateViewDDL(def ViewDefinition, s *Schema) (string, error) {
+	if !ValidIdentifier(def.Name) {
+		return "", fmt.Errorf("invalid view name")
+	}
+	if len(def.Columns) == 0 {
+		return "", fmt.Errorf("at least one column is required")
+	}
+
+	tables := make(map[string]*Table, len(s.Tables))
+	for idx := range s.Tables {
+		tables[s.Tables[idx].Name] = &s.Tables[idx]
+	}
+	if def.Name == def.From || tables[def.Name] != nil {
+		return "", fmt.Errorf("view name %q is already a table", def.Name)
+	}
+	if tables[def.From] == nil {
+		return "", fmt.Errorf("unknown table %q", def.From)
+	}
+
+	included := []string{def.From}
+	from := sanitizeIdentifier(def.From)
+	for _, join := range def.Joins {
+		t := tables[join.Table]
+		if t == nil {
+			return "", fmt.Errorf("unknown table %q", join.Table)
+		}
+		if slices.Contains(included, join.Table) {
+			return "", fmt.Errorf("table %q is already in the view", join.Table)
+		}
+		on, err := joinCondition(join, included, tables)
+		if err != nil {
+			return "", err
+		}
+		kind := "JOIN"
+		if join.Left {
+			kind = "LEFT JOIN"
+		}
+		from += fmt.Sprintf(" %s %s ON %s", kind, sanitizeIdentifier(join.Table), on)
+		included = append(included, join.Table)
+	}
+
+	columnRef := func(table, column string) (string, error) {
+		if !slices.Contains(included, table) {
+			return "", fmt.Errorf("table %q is not in the view", table)
+		}
+		if !slices.ContainsFunc(tables[table].Columns, func(c Column) bool { return c.Name == column }) {
+			return "", fmt.Errorf("unknown column %s.%s", table, column)
+		}
+		return sanitizeIdentifier(table) + "." + sanitizeIdentifier(column), nil
+	}
+
+	selected := make([]string, len(def.Columns))
+	outputNames := make(map[string]bool, len(def.Columns))
+	for idx, col := range def.Columns {
+		ref, err := columnRef(col.Table, col.Column)
+		if err != nil {
+			return "", err
+		}
+		name := col.Column
+		if col.Alias != "" {
+			if !ValidIdentifier(col.Alias) {
+				return "", fmt.Errorf("invalid alias %q", col.Alias)
+			}
+			name = col.Alias
+			ref += " AS " + sanitizeIdentifier(col.Alias)
+		}
+		if outputNames[name] {
+			return "", fmt.Errorf("duplicate output column %q; give one an alias", name)
+		}
+		outputNames[name] = true
+		selected[idx] = ref
+	}
+
+	conditions := make([]string, len(def.Filters))
+	for idx, f := range def.Filters {
+		ref, err := columnRef(f.Table, f.Column)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case !slices.Contains(viewFilterOperators, f.Operator):
+			return "", fmt.Errorf("unsupported operator %q", f.Operator)
+		case strings.HasPrefix(f.Operator, "IS "):
+			conditions[idx] = ref + " " + f.Operator
+		default:
+			conditions[idx] = fmt.Sprintf("%s %s %s", ref, f.Operator, QuoteLiteral(f.Value))
+		}
+	}
+
+	query := fmt.Sprintf("CREATE VIEW %s AS SELECT %s FROM %s",
+		sanitizeIdentifier(def.Name), strings.Join(selected, ", "), from)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return query, nil
+}
+
+// joinCondition finds the foreign key linking join.Table to a table already
+// in the view and renders it as an ON condition.
+func joinCondition(join ViewJoin, included []string, tables map[string]*Table) (string, error) {
+	type candidate struct {
+		from string // Table holding the foreign key
+		fk   ForeignKey
+	}
+	var candidates []candidate
+	for _, fk := range tables[join.Table].ForeignKeys {
+		if slices.Contains(included, fk.ReferencesTable) {
+			candidates = append(candidates, candidate{join.Table, fk})
+		}
+	}
+	for _, name := range included {
+		for _, fk := range tables[name].ForeignKeys {
+			if fk.ReferencesTable == join.Table {
+				candidates = append(candidates, candidate{name, fk})
+			}
+		}
+	}
+	if join.ForeignKey != "" {
+		candidates = slices.DeleteFunc(candidates, func(c candidate) bool { return c.fk.ConstraintName != join.ForeignKey })
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("no foreign key links %q to the tables already in the view", join.Table)
+	case 1:
+	default:
+		return "", fmt.Errorf("several foreign keys link %q; choose one with foreignKey", join.Table)
+	}
+
+	c := candidates[0]
+	parts := make([]string, len(c.fk.Columns))
+	for idx := range c.fk.Columns {
+		parts[idx] = fmt.Sprintf("%s.%s = %s.%s",
+			sanitizeIdentifier(c.from), sanitizeIdentifier(c.fk.Columns[idx]),
+			sanitizeIdentifier(c.fk.ReferencesTable), sanitizeIdentifier(c.fk.ReferencesColumns[idx]))
+	}
+	return strings.Join(parts, " AND "), nil
+}
+
+// InvalidViewError wraps definition problems found by BuildCreateViewDDL, as
+// opposed to failures talking to the database.
+type InvalidViewError struct{ Err error }
+
+func (e *InvalidViewError) Error() string { return e.Err.Error() }
+func (e *InvalidViewError) Unwrap() error { return e.Err }
+
+// CreateView creates a view from a structured definition, validated against
+// the current schema.
+func (i *Introspector) CreateView(ctx context.Context, def ViewDefinition) error {
+	s, err := i.GetSchema(ctx)
+	if err != nil {
+		return err
+	}
+	query, err := BuildCreateViewDDL(def, s)
+	if err != nil {
+		return &InvalidViewError{Err: err}
+	}
+	// Views are not part of the schema, so the name is looked up among all
+	// relations
+	var taken bool
+	lookupCtx, cancel := i.withTimeout(ctx)
+	defer cancel()
+	lookup := `SELECT to_regclass(format('public.%I', $1::text)) IS NOT NULL`
+	if err := i.getPool().QueryRow(lookupCtx, lookup, def.Name).Scan(&taken); err != nil {
+		return fmt.Errorf("failed to look up view name: %w", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: %s", ErrViewExists, def.Name)
+	}
+	undo, err := BuildDropViewDDL(def.Name)
+	if err != nil {
+		return err
+	}
+
+	return i.applyChange(ctx, Change{
+		Description: "Create view " + def.Name,
+		Statements:  []string{query},
+		Inverse:     []string{undo},
+	})
+}
@@ -0,0 +1,228 @@
+// Package seed fills tables with generated rows, so a new schema can be
+// demoed and tested before it has real data.
+package seed
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// MaxRows caps the rows generated for one table.
+const MaxRows = 100000
+
+// Column generators, named in a plan so it can be reviewed before it runs.
+const (
+	GenForeignKey = "foreign-key" // A key of a row in the referenced table
+	GenEnum       = "enum"        // One of the enum's labels
+	GenSequence   = "sequence"    // Unique ascending integers after the column's current maximum
+)
+
+// ColumnPlan is how one column is filled.
+type ColumnPlan struct {
+	Column    string `json:"column"`
+	Generator string `json:"generator"` // e.g. email, first-name, integer, foreign-key
+	Unique    bool   `json:"unique,omitempty"`
+	// References is the referenced table and column, for foreign key columns
+	References string `json:"references,omitempty"`
+
+	maxLength int      // For character types; 0 when unlimited
+	precision int      // Integer digits allowed, for numeric columns; 0 when unlimited
+	scale     int      // Fraction digits, for numeric columns
+	labels    []string // For enums
+	array     bool     // Values are arrays of the generator's type
+	nullable  bool
+	fk        int // Index into TablePlan.foreignKeys, for foreign key columns
+	fkColumn  int // Position in that foreign key
+}
+
+// foreignKeyPlan is a foreign key whose columns are filled together from the
+// keys of one referenced row.
+type foreignKeyPlan struct {
+	table    string
+	columns  []string // Referenced columns
+	nullable bool     // Every referencing column is nullable
+	// distinct gives each row a different referenced row, for keys that are
+	// also unique, as in one-to-one relationships
+	distinct bool
+	self     bool
+}
+
+// TablePlan is one table of a seed: the columns filled, in table order, and
+// how many rows. Columns left out get their defaults: serial, identity,
+// generated, and columns with a default, as well as nullable columns of types
+// there is no generator for.
+type TablePlan struct {
+	Table   string       `json:"table"`
+	Rows    int          `json:"rows"`
+	Columns []ColumnPlan `json:"columns"`
+
+	foreignKeys []foreignKeyPlan
+	uniques     [][]string // Column sets no two rows may share: primary key, unique constraints and indexes
+}
+
+// Plan lists the tables to seed with rows rows each, or the counts given by
+// table, parents before the tables whose foreign keys reference them. With
+// counts, only those tables are seeded; foreign keys to other tables take
+// keys from the rows they already have. Partitions are filled through their
+// parent, and foreign and system tables are skipped. Tables in a foreign key
+// cycle cannot be ordered; they come last, leaving nullable references to
+// tables after them empty, with a warning.
+func Plan(s *schema.Schema, enums []schema.EnumType, rows int, counts map[string]int) ([]TablePlan, []string, error) {
+	warnings := []string{}
+	partitions := make(map[string]bool)
+	tables := make(map[string]schema.Table, len(s.Tables))
+	for _, t := range s.Tables {
+		tables[t.Name] = t
+		if t.Partitioning != nil {
+			for _, part := range t.Partitioning.Partitions {
+				partitions[part.Name] = true
+			}
+		}
+	}
+	labels := make(map[string][]string, len(enums))
+	for _, e := range enums {
+		labels[e.Name] = e.Labels
+	}
+
+	if len(counts) == 0 {
+		counts = make(map[string]int)
+		for _, t := range s.Tables {
+			if !t.IsForeign && !t.IsSystem && !partitions[t.Name] {
+				counts[t.Name] = rows
+			}
+		}
+	}
+
+	plans := make(map[string]TablePlan, len(counts))
+	for name, n := range counts {
+		t, ok := tables[name]
+		switch {
+		case !ok:
+			return nil, nil, fmt.Errorf("table %s does not exist", name)
+		case t.IsForeign:
+			return nil, nil, fmt.Errorf("table %s is a foreign table", name)
+		case partitions[name]:
+			return nil, nil, fmt.Errorf("table %s is a partition; seed its parent", name)
+		case n < 1 || n > MaxRows:
+			return nil, nil, fmt.Errorf("row count for %s must be between 1 and %d", name, MaxRows)
+		}
+		p, err := planTable(t, n, labels, &warnings)
+		if err != nil {
+			return nil, nil, err
+		}
+		plans[name] = p
+	}
+
+	seedOrder, cyclic := schema.OrderByForeignKeys(tables, slices.Collect(maps.Keys(plans)))
+	ordered := make([]TablePlan, len(seedOrder))
+	for idx, name := range seedOrder {
+		ordered[idx] = plans[name]
+	}
+	if len(cyclic) > 0 {
+		warnings = append(warnings, fmt.Sprintf("tables %s are in or depend on a foreign key cycle and are seeded last; references to tables not yet seeded are left empty",
+			strings.Join(cyclic, ", ")))
+	}
+	slices.Sort(warnings)
+	return ordered, warnings, nil
+}
+
+// planTable picks a generator for every column of t that needs a value.
+func planTable(t schema.Table, rows int, labels map[string][]string, warnings *[]string) (TablePlan, error) {
+	p := TablePlan{Table: t.Name, Rows: rows, Columns: []ColumnPlan{}}
+	if t.PrimaryKey != nil {
+		p.uniques = append(p.uniques, t.PrimaryKey.Columns)
+	}
+	for _, u := range t.UniqueConstraints {
+		p.uniques = append(p.uniques, u.Columns)
+	}
+	for _, idx := range t.Indexes {
+		if idx.IsUnique && !idx.IsPrimary && idx.Predicate == nil && !slices.ContainsFunc(p.uniques, func(u []string) bool { return slices.Equal(u, idx.Columns) }) {
+			p.uniques = append(p.uniques, idx.Columns)
+		}
+	}
+	unique := func(columns ...string) bool {
+		return slices.ContainsFunc(p.uniques, func(u []string) bool { return slices.Equal(u, columns) })
+	}
+
+	fkOf := make(map[string][2]int) // Column -> foreign key, position
+	for _, fk := range t.ForeignKeys {
+		columns := make([]schema.Column, 0, len(fk.Columns))
+		for _, name := range fk.Columns {
+			if idx := slices.IndexFunc(t.Columns, func(c schema.Column) bool { return c.Name == name }); idx >= 0 {
+				columns = append(columns, t.Columns[idx])
+			}
+		}
+		// A column in several foreign keys follows the first
+		if slices.ContainsFunc(fk.Columns, func(name string) bool { _, taken := fkOf[name]; return taken }) {
+			continue
+		}
+		fp := foreignKeyPlan{
+			table:    fk.ReferencesTable,
+			columns:  fk.ReferencesColumns,
+			nullable: !slices.ContainsFunc(columns, func(c schema.Column) bool { return !c.IsNullable }),
+			distinct: unique(fk.Columns...),
+			self:     fk.ReferencesTable == t.Name,
+		}
+		if fp.self && !fp.nullable {
+			return TablePlan{}, fmt.Errorf("table %s has a NOT NULL foreign key to itself, which cannot be seeded", t.Name)
+		}
+		for pos, name := range fk.Columns {
+			fkOf[name] = [2]int{len(p.foreignKeys), pos}
+		}
+		p.foreignKeys = append(p.foreignKeys, fp)
+	}
+
+	for _, col := range t.Columns {
+		if col.IsGenerated || col.Identity != "" || col.Sequence != "" {
+			continue
+		}
+		c := ColumnPlan{
+			Column:   col.Name,
+			Unique:   unique(col.Name),
+			nullable: col.IsNullable,
+		}
+		if col.CharacterMaximumLength != nil {
+			c.maxLength = int(*col.CharacterMaximumLength)
+		}
+		if col.NumericPrecision != nil && col.NumericScale != nil && col.DataType == "numeric" {
+			c.precision, c.scale = int(*col.NumericPrecision-*col.NumericScale), int(*col.NumericScale)
+		}
+
+		if ref, ok := fkOf[col.Name]; ok {
+			fk := p.foreignKeys[ref[0]]
+			c.Generator, c.fk, c.fkColumn = GenForeignKey, ref[0], ref[1]
+			c.References = fk.table + "." + fk.columns[ref[1]]
+			p.Columns = append(p.Columns, c)
+			continue
+		}
+		if col.Default != nil {
+			continue // Defaults such as now() or gen_random_uuid() beat generated values
+		}
+
+		switch {
+		case col.TypeCategory == "enum":
+			c.Generator, c.labels = GenEnum, labels[col.DataType]
+		case col.TypeCategory == "array":
+			c.array = true
+			c.Generator = generatorFor(t.Name, col.Name, col.ElementType, false)
+		default:
+			c.Generator = generatorFor(t.Name, col.Name, col.DataType, c.Unique)
+		}
+		if c.Generator == "" || c.Generator == GenEnum && len(c.labels) == 0 {
+			if col.IsNullable {
+				*warnings = append(*warnings, fmt.Sprintf("%s.%s has type %s, which cannot be generated; it is left empty", t.Name, col.Name, col.DataType))
+				continue
+			}
+			return TablePlan{}, fmt.Errorf("%s.%s is NOT NULL with type %s, which cannot be generated", t.Name, col.Name, col.DataType)
+		}
+		p.Columns = append(p.Columns, c)
+	}
+	if len(p.Columns) == 0 {
+		*warnings = append(*warnings, fmt.Sprintf("table %s has no columns to fill; its rows get defaults only", t.Name))
+	}
+	return p, nil
+}
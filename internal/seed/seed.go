@@ -0,0 +1,322 @@
+package seed
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// batchSize is how many rows go into each COPY.
+const batchSize = 10000
+
+// maxAttempts bounds how often a row is regenerated when it repeats a unique
+// key before it is left out.
+const maxAttempts = 10
+
+// TableResult is how many rows were generated for one table.
+type TableResult struct {
+	Table    string `json:"table"`
+	Rows     int    `json:"rows"` // Planned
+	Inserted int    `json:"inserted"`
+	// Skipped counts rows left out because no unique key could be found for
+	// them, or no unused referenced row was left for a one-to-one key
+	Skipped int `json:"skipped"`
+}
+
+// Seeder fills the tables of a plan and tracks what it inserted.
+type Seeder struct {
+	pool *pgxpool.Pool
+	plan []TablePlan
+	seed uint64
+
+	mu      sync.Mutex
+	results []TableResult
+}
+
+// NewSeeder prepares a seed of plan, as made by Plan. The same seed, plan,
+// and existing rows generate the same values, except for a random tag that
+// keeps unique text apart from that of earlier seeds.
+func NewSeeder(pool *pgxpool.Pool, plan []TablePlan, seed uint64) *Seeder {
+	return &Seeder{pool: pool, plan: plan, seed: seed}
+}
+
+// Results returns what was inserted into each table, in seed order. Rows are
+// committed together, so they count only once Run returns without error.
+func (s *Seeder) Results() []TableResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]TableResult(nil), s.results...)
+}
+
+// Run generates the rows of every table in order, calling step before each
+// one, in a single transaction: a failure or cancellation leaves the
+// database as it was.
+func (s *Seeder) Run(ctx context.Context, step func(description string)) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(context.Background()) // No-op once committed
+
+	token := make([]byte, 2)
+	if _, err := rand.Read(token); err != nil {
+		return fmt.Errorf("failed to generate seed token: %w", err)
+	}
+	v := &values{
+		rng:   mathrand.New(mathrand.NewPCG(s.seed, s.seed)),
+		now:   time.Now().UTC().Truncate(time.Second),
+		token: hex.EncodeToString(token),
+	}
+
+	for _, t := range s.plan {
+		step(fmt.Sprintf("Seed %s (%d rows)", t.Table, t.Rows))
+		result, err := s.seedTable(ctx, tx, v, t)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.Table, err)
+		}
+		s.mu.Lock()
+		s.results = append(s.results, result)
+		s.mu.Unlock()
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// seedTable generates and copies in the rows of one table.
+func (s *Seeder) seedTable(ctx context.Context, tx pgx.Tx, v *values, t TablePlan) (TableResult, error) {
+	result := TableResult{Table: t.Table, Rows: t.Rows}
+	if len(t.Columns) == 0 {
+		// Nothing to generate; every column takes its default
+		tag, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s SELECT FROM generate_series(1, %d)", quoteIdent(t.Table), t.Rows))
+		if err != nil {
+			return result, err
+		}
+		result.Inserted = int(tag.RowsAffected())
+		return result, nil
+	}
+
+	v.next = make(map[string]int64)
+	for _, c := range t.Columns {
+		if c.Generator != GenSequence {
+			continue
+		}
+		var max int64
+		query := fmt.Sprintf("SELECT coalesce(max(%s), 0) FROM %s", quoteIdent(c.Column), quoteIdent(t.Table))
+		if err := tx.QueryRow(ctx, query).Scan(&max); err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", c.Column, err)
+		}
+		v.next[c.Column] = max
+	}
+
+	keys := make([][][]string, len(t.foreignKeys))
+	for idx, fk := range t.foreignKeys {
+		if fk.self {
+			continue // Nullable, checked by Plan; left empty
+		}
+		k, err := referencedKeys(ctx, tx, fk)
+		if err != nil {
+			return result, err
+		}
+		if len(k) == 0 && !fk.nullable {
+			return result, fmt.Errorf("references %s, which has no rows", fk.table)
+		}
+		if fk.distinct {
+			v.rng.Shuffle(len(k), func(i, j int) { k[i], k[j] = k[j], k[i] })
+		}
+		keys[idx] = k
+	}
+
+	// Unique keys checked as rows are generated: those made only of filled
+	// columns, since defaults are unknown here
+	var uniques [][]int
+	for _, u := range t.uniques {
+		positions := make([]int, len(u))
+		for n, col := range u {
+			positions[n] = slices.IndexFunc(t.Columns, func(c ColumnPlan) bool { return c.Column == col })
+		}
+		if !slices.Contains(positions, -1) {
+			uniques = append(uniques, positions)
+		}
+	}
+	seen := make([]map[string]bool, len(uniques))
+	for idx := range seen {
+		seen[idx] = make(map[string]bool)
+	}
+
+	columns := make([]string, len(t.Columns))
+	for idx, c := range t.Columns {
+		columns[idx] = quoteIdent(c.Column)
+	}
+	copySQL := fmt.Sprintf("COPY %s (%s) FROM STDIN", quoteIdent(t.Table), strings.Join(columns, ", "))
+
+	var batch bytes.Buffer
+	rows := 0
+	flush := func() error {
+		if rows == 0 {
+			return nil
+		}
+		if _, err := tx.Conn().PgConn().CopyFrom(ctx, &batch, copySQL); err != nil {
+			return fmt.Errorf("failed to write rows: %w", err)
+		}
+		result.Inserted += rows
+		batch.Reset()
+		rows = 0
+		return nil
+	}
+
+	row := make([]*string, len(t.Columns))
+	used := make([]int, len(t.foreignKeys)) // Referenced rows given out, for distinct keys
+	for n := 1; n <= t.Rows; n++ {
+		ok := false
+		for attempt := 0; attempt < maxAttempts && !ok; attempt++ {
+			picked := make([][]string, len(t.foreignKeys))
+			exhausted := false
+			for idx, fk := range t.foreignKeys {
+				switch {
+				case len(keys[idx]) == 0:
+				case fk.distinct && used[idx] < len(keys[idx]):
+					picked[idx] = keys[idx][used[idx]]
+				case fk.distinct && !fk.nullable:
+					exhausted = true
+				case !fk.distinct:
+					picked[idx] = keys[idx][v.rng.IntN(len(keys[idx]))]
+				}
+			}
+			if exhausted {
+				break
+			}
+
+			for idx, c := range t.Columns {
+				row[idx] = nil
+				if c.Generator == GenForeignKey {
+					if k := picked[c.fk]; k != nil {
+						value := k[c.fkColumn]
+						row[idx] = &value
+					}
+					continue
+				}
+				if value, notNull := v.value(c, n); notNull {
+					row[idx] = &value
+				}
+			}
+			ok = claimUnique(row, uniques, seen)
+			if ok {
+				for idx, fk := range t.foreignKeys {
+					if fk.distinct && picked[idx] != nil {
+						used[idx]++
+					}
+				}
+			}
+		}
+		if !ok {
+			result.Skipped++
+			continue
+		}
+
+		for idx, value := range row {
+			if idx > 0 {
+				batch.WriteByte('\t')
+			}
+			if value == nil {
+				batch.WriteString(`\N`)
+			} else {
+				batch.WriteString(escapeCopyText(*value))
+			}
+		}
+		batch.WriteByte('\n')
+		rows++
+		if rows == batchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// claimUnique reports whether row repeats none of the unique keys seen so
+// far, and if so records its keys. Keys with a NULL never repeat.
+func claimUnique(row []*string, uniques [][]int, seen []map[string]bool) bool {
+	keys := make([]string, 0, len(uniques))
+	for _, positions := range uniques {
+		var key strings.Builder
+		null := false
+		for _, pos := range positions {
+			if row[pos] == nil {
+				null = true
+				break
+			}
+			key.WriteString(*row[pos])
+			key.WriteByte(0)
+		}
+		if null {
+			keys = append(keys, "")
+			continue
+		}
+		keys = append(keys, key.String())
+	}
+	for idx, key := range keys {
+		if key != "" && seen[idx][key] {
+			return false
+		}
+	}
+	for idx, key := range keys {
+		if key != "" {
+			seen[idx][key] = true
+		}
+	}
+	return true
+}
+
+// referencedKeys returns the keys of up to MaxRows rows of the referenced
+// table, as text, in key order.
+func referencedKeys(ctx context.Context, tx pgx.Tx, fk foreignKeyPlan) ([][]string, error) {
+	selected := make([]string, len(fk.columns))
+	quoted := make([]string, len(fk.columns))
+	for idx, col := range fk.columns {
+		quoted[idx] = quoteIdent(col)
+		selected[idx] = quoted[idx] + "::text"
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE (%s) IS NOT NULL ORDER BY %s LIMIT %d",
+		strings.Join(selected, ", "), quoteIdent(fk.table), strings.Join(quoted, ", "), strings.Join(quoted, ", "), MaxRows)
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys of %s: %w", fk.table, err)
+	}
+	keys, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) ([]string, error) {
+		key := make([]string, len(fk.columns))
+		ptrs := make([]any, len(key))
+		for idx := range key {
+			ptrs[idx] = &key[idx]
+		}
+		return key, row.Scan(ptrs...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys of %s: %w", fk.table, err)
+	}
+	return keys, nil
+}
+
+// escapeCopyText escapes a value for COPY text format.
+func escapeCopyText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+func quoteIdent(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
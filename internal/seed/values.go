@@ -0,0 +1,339 @@
+package seed
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Word lists the text generators draw from.
+var (
+	firstNames = []string{"Ada", "Alan", "Amara", "Ben", "Chloe", "Diego", "Elena", "Farah", "Grace", "Hiro",
+		"Ines", "Jonas", "Kai", "Lena", "Mateo", "Nora", "Omar", "Priya", "Quinn", "Rosa", "Sven", "Tara",
+		"Uma", "Victor", "Wen", "Yusuf", "Zoe"}
+	lastNames = []string{"Adler", "Brooks", "Castro", "Dubois", "Eriksen", "Fischer", "Garcia", "Hansen",
+		"Ibrahim", "Jensen", "Kowalski", "Larsen", "Moreau", "Nakamura", "Okafor", "Patel", "Quist", "Rossi",
+		"Silva", "Tanaka", "Novak", "Virtanen", "Weber", "Yilmaz", "Zhang"}
+	cities     = []string{"Aarhus", "Berlin", "Chicago", "Denver", "Edinburgh", "Florence", "Lisbon", "Madrid", "Nairobi", "Osaka", "Porto", "Seoul", "Toronto", "Utrecht", "Vienna"}
+	countries  = []string{"Canada", "Denmark", "France", "Germany", "Italy", "Japan", "Kenya", "Mexico", "Norway", "Portugal", "Spain", "Sweden", "United Kingdom", "United States"}
+	streets    = []string{"Oak Street", "Maple Avenue", "Harbor Road", "Mill Lane", "Park Place", "River Drive", "Station Road", "Elm Court"}
+	adjectives = []string{"amber", "bold", "bright", "calm", "clever", "crisp", "gentle", "golden", "quiet", "rapid",
+		"silver", "steady", "swift", "vivid", "warm", "wild"}
+	nouns = []string{"anchor", "badger", "canyon", "comet", "falcon", "forest", "harbor", "lantern", "meadow", "orchid",
+		"pebble", "river", "summit", "thunder", "willow", "zephyr"}
+	companySuffixes = []string{"Labs", "Systems", "Group", "Works", "Partners", "Studio", "Co"}
+	statuses        = []string{"active", "inactive", "pending", "archived"}
+	colors          = []string{"red", "orange", "yellow", "green", "blue", "indigo", "violet", "black", "white"}
+	loremWords      = strings.Fields("lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor incididunt ut labore et dolore magna aliqua enim minim veniam quis nostrud exercitation ullamco laboris nisi aliquip commodo consequat")
+)
+
+// Tables whose name column holds a person's name rather than a thing's.
+var peopleTables = []string{"user", "customer", "employee", "person", "people", "member", "author", "contact",
+	"student", "teacher", "staff", "patient", "client", "account", "owner", "profile"}
+
+// generatorFor picks a generator for a column from its type and, for text
+// and numbers, its name. Returns "" for types there is no generator for.
+func generatorFor(table, column, dataType string, unique bool) string {
+	name := strings.ToLower(column)
+	has := func(parts ...string) bool {
+		for _, p := range parts {
+			if strings.Contains(name, p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch dataType {
+	case "text", "character varying", "character", "citext":
+		switch {
+		case has("email"):
+			return "email"
+		case has("username", "login", "handle", "nickname"):
+			return "username"
+		case has("first_name", "firstname", "given_name"):
+			return "first-name"
+		case has("last_name", "lastname", "surname", "family_name"):
+			return "last-name"
+		case has("company", "organization", "organisation", "employer"):
+			return "company"
+		case has("full_name", "display_name", "contact_name", "author"):
+			return "full-name"
+		case name == "name":
+			t := strings.ToLower(table)
+			for _, p := range peopleTables {
+				if strings.HasPrefix(t, p) {
+					return "full-name"
+				}
+			}
+			return "label"
+		case has("phone", "mobile", "fax"):
+			return "phone"
+		case has("url", "website", "homepage", "link"):
+			return "url"
+		case has("city", "town"):
+			return "city"
+		case has("country"):
+			return "country"
+		case has("address", "street"):
+			return "street"
+		case has("zip", "postal", "postcode"):
+			return "postal-code"
+		case has("title", "subject", "headline"):
+			return "title"
+		case has("description", "bio", "body", "content", "summary", "note", "comment", "message"):
+			return "paragraph"
+		case has("status") && !unique:
+			return "status"
+		case has("slug"):
+			return "slug"
+		case has("color", "colour"):
+			return "color"
+		case has("password", "hash", "token", "secret"):
+			return "token"
+		case has("code", "sku", "reference"):
+			return "code"
+		}
+		return "words"
+	case "smallint", "integer", "bigint":
+		switch {
+		case unique:
+			return GenSequence
+		case name == "age" || strings.HasSuffix(name, "_age"):
+			return "age"
+		case has("quantity", "qty", "count", "stock"):
+			return "quantity"
+		case has("year"):
+			return "year"
+		}
+		return "integer"
+	case "numeric", "real", "double precision":
+		if has("price", "amount", "total", "cost", "balance", "salary", "fee") {
+			return "price"
+		}
+		return "decimal"
+	case "boolean":
+		return "boolean"
+	case "date":
+		if has("birth", "dob") {
+			return "birth-date"
+		}
+		return "date"
+	case "timestamp with time zone", "timestamp without time zone":
+		return "timestamp"
+	case "time with time zone", "time without time zone":
+		return "time"
+	case "interval":
+		return "interval"
+	case "uuid":
+		return "uuid"
+	case "json", "jsonb":
+		return "json"
+	case "bytea":
+		return "bytes"
+	case "inet", "cidr":
+		return "ip"
+	}
+	return ""
+}
+
+// values generates column values for one table's rows.
+type values struct {
+	rng   *rand.Rand
+	now   time.Time
+	token string // Set apart unique text from earlier seeds of the same table
+	// next holds the last value given out, by column, for sequence columns
+	next map[string]int64
+}
+
+// value returns the text form of column c for row n, counting from 1, or
+// false for NULL. Foreign key columns are filled by the caller.
+func (v *values) value(c ColumnPlan, n int) (string, bool) {
+	if c.nullable && !c.Unique && v.rng.IntN(10) == 0 {
+		return "", false
+	}
+	if !c.array {
+		return v.scalar(c, n), true
+	}
+
+	// Arrays of 1 to 3 elements, each quoted
+	elements := make([]string, 1+v.rng.IntN(3))
+	for idx := range elements {
+		e := v.scalar(c, n)
+		elements[idx] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(e) + `"`
+	}
+	return "{" + strings.Join(elements, ",") + "}", true
+}
+
+func (v *values) scalar(c ColumnPlan, n int) string {
+	pick := func(list []string) string { return list[v.rng.IntN(len(list))] }
+	first, last := pick(firstNames), pick(lastNames)
+	text := func(s string) string {
+		if c.Unique {
+			return uniqueText(s, fmt.Sprintf("-%s%d", v.token, n), c.maxLength)
+		}
+		return truncate(s, c.maxLength)
+	}
+
+	switch c.Generator {
+	case "email":
+		local := strings.ToLower(first + "." + last)
+		if c.Unique {
+			local += fmt.Sprintf(".%s%d", v.token, n)
+		}
+		return truncate(local+"@example.com", c.maxLength)
+	case "username":
+		return text(strings.ToLower(first + "_" + last))
+	case "first-name":
+		return text(first)
+	case "last-name":
+		return text(last)
+	case "full-name":
+		return text(first + " " + last)
+	case "company":
+		return text(last + " " + pick(companySuffixes))
+	case "label":
+		return text(capitalize(pick(adjectives)) + " " + pick(nouns))
+	case "phone":
+		return text(fmt.Sprintf("+1-555-%03d-%04d", v.rng.IntN(1000), v.rng.IntN(10000)))
+	case "url":
+		return text(fmt.Sprintf("https://example.com/%s/%d", pick(nouns), n))
+	case "city":
+		return text(pick(cities))
+	case "country":
+		return text(pick(countries))
+	case "street":
+		return text(fmt.Sprintf("%d %s", 1+v.rng.IntN(999), pick(streets)))
+	case "postal-code":
+		return text(fmt.Sprintf("%05d", v.rng.IntN(100000)))
+	case "title":
+		return text(capitalize(v.words(3 + v.rng.IntN(4))))
+	case "paragraph":
+		return text(capitalize(v.words(8+v.rng.IntN(16))) + ".")
+	case "status":
+		return text(pick(statuses))
+	case "slug":
+		return text(pick(adjectives) + "-" + pick(nouns))
+	case "color":
+		return text(pick(colors))
+	case "token":
+		b := make([]byte, 16)
+		for idx := range b {
+			b[idx] = byte(v.rng.UintN(256))
+		}
+		return truncate(hex.EncodeToString(b), c.maxLength)
+	case "code":
+		return text(fmt.Sprintf("%s-%05d", strings.ToUpper(pick(nouns)[:3]), v.rng.IntN(100000)))
+	case "words":
+		return text(v.words(1 + v.rng.IntN(3)))
+	case GenEnum:
+		return pick(c.labels)
+
+	case GenSequence:
+		v.next[c.Column]++
+		return strconv.FormatInt(v.next[c.Column], 10)
+	case "age":
+		return strconv.Itoa(18 + v.rng.IntN(72))
+	case "quantity":
+		return strconv.Itoa(1 + v.rng.IntN(100))
+	case "year":
+		return strconv.Itoa(v.now.Year() - v.rng.IntN(30))
+	case "integer":
+		return strconv.Itoa(1 + v.rng.IntN(1000))
+	case "price", "decimal":
+		scale := c.scale
+		if scale == 0 && c.precision == 0 {
+			scale = 2
+		}
+		limit := 1000.0
+		if c.precision > 0 && c.precision < 4 {
+			limit = float64(pow10(c.precision)) - 1
+		}
+		f := v.rng.Float64() * limit
+		if c.Generator == "price" {
+			f = 1 + v.rng.Float64()*(limit-1)
+		}
+		return strconv.FormatFloat(f, 'f', scale, 64)
+	case "boolean":
+		return strconv.FormatBool(v.rng.IntN(2) == 0)
+	case "date":
+		return v.now.AddDate(0, 0, -v.rng.IntN(3*365)).Format(time.DateOnly)
+	case "birth-date":
+		return v.now.AddDate(-18-v.rng.IntN(60), 0, -v.rng.IntN(365)).Format(time.DateOnly)
+	case "timestamp":
+		return v.now.Add(-time.Duration(v.rng.Int64N(int64(365 * 24 * time.Hour)))).Format("2006-01-02 15:04:05Z07:00")
+	case "time":
+		return fmt.Sprintf("%02d:%02d:%02d", v.rng.IntN(24), v.rng.IntN(60), v.rng.IntN(60))
+	case "interval":
+		return fmt.Sprintf("%d minutes", 1+v.rng.IntN(600))
+	case "uuid":
+		b := make([]byte, 16)
+		for idx := range b {
+			b[idx] = byte(v.rng.UintN(256))
+		}
+		b[6] = b[6]&0x0f | 0x40 // Version 4
+		b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+		h := hex.EncodeToString(b)
+		return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+	case "json":
+		return fmt.Sprintf(`{"seed": %d, "tag": %q}`, n, pick(nouns))
+	case "bytes":
+		b := make([]byte, 8)
+		for idx := range b {
+			b[idx] = byte(v.rng.UintN(256))
+		}
+		return `\x` + hex.EncodeToString(b)
+	case "ip":
+		return fmt.Sprintf("10.%d.%d.%d", v.rng.IntN(256), v.rng.IntN(256), 1+v.rng.IntN(254))
+	}
+	return ""
+}
+
+func (v *values) words(n int) string {
+	words := make([]string, n)
+	for idx := range words {
+		words[idx] = loremWords[v.rng.IntN(len(loremWords))]
+	}
+	return strings.Join(words, " ")
+}
+
+// uniqueText ends s with suffix, shortening s so the result fits in max
+// characters when max is set.
+func uniqueText(s, suffix string, max int) string {
+	if max > 0 && max <= len(suffix) {
+		return truncate(suffix, max)
+	}
+	if max > 0 {
+		return truncate(s, max-len(suffix)) + suffix
+	}
+	return s + suffix
+}
+
+// truncate shortens s to max characters when max is set.
+func truncate(s string, max int) string {
+	if max <= 0 {
+		return s
+	}
+	if runes := []rune(s); len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
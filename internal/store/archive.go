@@ -0,0 +1,70 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// ArchiveVersion is bumped when the archive layout changes incompatibly.
+const ArchiveVersion = 1
+
+// Archive bundles everything the tool stores outside the database so a
+// workspace can be moved between machines. The tool stores no credentials;
+// connection settings come from the environment and are never exported.
+type Archive struct {
+	Version     int                               `json:"version"`
+	ExportedAt  time.Time                         `json:"exportedAt"`
+	Notes       []Note                            `json:"notes"`
+	Preferences map[string]map[string]Preferences `json:"preferences"`
+	Snapshots   []Snapshot                        `json:"snapshots"`
+}
+
+// ImportResult counts what an import added or replaced.
+type ImportResult struct {
+	Notes       int `json:"notes"`
+	Preferences int `json:"preferences"`
+	Snapshots   int `json:"snapshots"`
+}
+
+// Workspace groups the stores that make up the tool's persisted state.
+type Workspace struct {
+	Notes       *Notes
+	Preferences *PreferenceStore
+	Snapshots   *SnapshotStore
+}
+
+// Export collects the whole workspace into an archive.
+func (ws Workspace) Export() (*Archive, error) {
+	snaps, err := ws.Snapshots.All()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export snapshots: %w", err)
+	}
+	return &Archive{
+		Version:     ArchiveVersion,
+		ExportedAt:  time.Now().UTC(),
+		Notes:       ws.Notes.All(),
+		Preferences: ws.Preferences.All(),
+		Snapshots:   snaps,
+	}, nil
+}
+
+// Import merges an archive into the workspace. Items already present
+// (by ID) are kept; preferences in the archive replace local ones.
+func (ws Workspace) Import(a *Archive) (ImportResult, error) {
+	var result ImportResult
+	if a.Version != ArchiveVersion {
+		return result, fmt.Errorf("unsupported archive version %d", a.Version)
+	}
+
+	var err error
+	if result.Notes, err = ws.Notes.Merge(a.Notes); err != nil {
+		return result, fmt.Errorf("failed to import notes: %w", err)
+	}
+	if result.Preferences, err = ws.Preferences.Merge(a.Preferences); err != nil {
+		return result, fmt.Errorf("failed to import preferences: %w", err)
+	}
+	if result.Snapshots, err = ws.Snapshots.Merge(a.Snapshots); err != nil {
+		return result, fmt.Errorf("failed to import snapshots: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/datacopy"
+)
+
+// DataCopy is a data copy between databases with its per-table progress,
+// kept so a copy stopped by a cancel, an error, or a restart can be resumed
+// where it left off.
+type DataCopy struct {
+	ID       string                      `json:"id"`
+	Source   string                      `json:"source"`
+	Target   string                      `json:"target"`
+	Tables   []string                    `json:"tables,omitempty"` // As requested; empty means every table
+	Mappings map[string]datacopy.Mapping `json:"mappings,omitempty"`
+	Options  datacopy.Options            `json:"options"`
+	Progress []datacopy.TableProgress    `json:"progress"`
+	JobID    string                      `json:"jobId"` // The latest run
+	// Verification is the latest comparison of source and target, if any
+	Verification *DataCopyVerification `json:"verification,omitempty"`
+	// CreatedAt is when the copy was first started, UpdatedAt when its progress last changed
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// DataCopyVerification is a comparison of the tables of a data copy in
+// source and target.
+type DataCopyVerification struct {
+	JobID     string                 `json:"jobId"`
+	Options   datacopy.VerifyOptions `json:"options"`
+	Tables    []datacopy.TableCheck  `json:"tables"`
+	StartedAt time.Time              `json:"startedAt"`
+}
+
+const dataCopiesDocument = "datacopies"
+
+// DataCopyStore holds all data copies in memory and persists every change.
+type DataCopyStore struct {
+	store  *Store
+	mu     sync.Mutex
+	copies []DataCopy
+}
+
+// NewDataCopyStore loads existing data copies from the store.
+func NewDataCopyStore(s *Store) (*DataCopyStore, error) {
+	ds := &DataCopyStore{store: s, copies: []DataCopy{}}
+	if err := s.Load(dataCopiesDocument, &ds.copies); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// Add assigns an ID and timestamps to c and stores it.
+func (ds *DataCopyStore) Add(c DataCopy) (DataCopy, error) {
+	id, err := newID()
+	if err != nil {
+		return DataCopy{}, fmt.Errorf("failed to generate data copy ID: %w", err)
+	}
+	c.ID = id
+	c.CreatedAt = time.Now().UTC()
+	c.UpdatedAt = c.CreatedAt
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	copies := append(append([]DataCopy{}, ds.copies...), c)
+	if err := ds.store.Save(dataCopiesDocument, copies); err != nil {
+		return DataCopy{}, err
+	}
+	ds.copies = copies
+	return c, nil
+}
+
+// Get returns the data copy with the given ID.
+func (ds *DataCopyStore) Get(id string) (DataCopy, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if idx := ds.find(id); idx >= 0 {
+		return ds.copies[idx], nil
+	}
+	return DataCopy{}, ErrNotFound
+}
+
+// List returns every data copy, newest first.
+func (ds *DataCopyStore) List() []DataCopy {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	list := append([]DataCopy{}, ds.copies...)
+	sort.Slice(list, func(a, b int) bool { return list[a].CreatedAt.After(list[b].CreatedAt) })
+	return list
+}
+
+// Update applies fn to the data copy with the given ID and stores the result.
+func (ds *DataCopyStore) Update(id string, fn func(c *DataCopy)) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	idx := ds.find(id)
+	if idx < 0 {
+		return ErrNotFound
+	}
+	copies := append([]DataCopy{}, ds.copies...)
+	fn(&copies[idx])
+	copies[idx].UpdatedAt = time.Now().UTC()
+	if err := ds.store.Save(dataCopiesDocument, copies); err != nil {
+		return err
+	}
+	ds.copies = copies
+	return nil
+}
+
+func (ds *DataCopyStore) find(id string) int {
+	for idx, c := range ds.copies {
+		if c.ID == id {
+			return idx
+		}
+	}
+	return -1
+}
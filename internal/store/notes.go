@@ -0,0 +1,146 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Note target kinds.
+const (
+	NoteTable        = "table"
+	NoteColumn       = "column"
+	NoteRelationship = "relationship"
+)
+
+// Note is a free-form annotation attached to a schema object.
+// Relationships are identified by their referencing table and column.
+type Note struct {
+	ID        string    `json:"id"`
+	Database  string    `json:"database"`
+	Kind      string    `json:"kind"`
+	Table     string    `json:"table"`
+	Column    string    `json:"column,omitempty"`
+	Body      string    `json:"body"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ValidNoteKind reports whether kind is a supported note target.
+func ValidNoteKind(kind string) bool {
+	return kind == NoteTable || kind == NoteColumn || kind == NoteRelationship
+}
+
+const notesDocument = "notes"
+
+// Notes holds all notes in memory and persists every change.
+type Notes struct {
+	store *Store
+	mu    sync.RWMutex
+	notes []Note
+}
+
+// NewNotes loads existing notes from the store.
+func NewNotes(s *Store) (*Notes, error) {
+	n := &Notes{store: s, notes: []Note{}}
+	if err := s.Load(notesDocument, &n.notes); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// List returns notes for a database, optionally filtered to one table.
+func (n *Notes) List(database, table string) []Note {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	result := make([]Note, 0)
+	for _, note := range n.notes {
+		if note.Database != database {
+			continue
+		}
+		if table != "" && note.Table != table {
+			continue
+		}
+		result = append(result, note)
+	}
+	return result
+}
+
+// Add assigns an ID and timestamp to note and stores it.
+func (n *Notes) Add(note Note) (Note, error) {
+	id, err := newID()
+	if err != nil {
+		return Note{}, fmt.Errorf("failed to generate note ID: %w", err)
+	}
+	note.ID = id
+	note.CreatedAt = time.Now().UTC()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.notes = append(n.notes, note)
+	if err := n.store.Save(notesDocument, n.notes); err != nil {
+		n.notes = n.notes[:len(n.notes)-1]
+		return Note{}, err
+	}
+	return note, nil
+}
+
+// Delete removes the note with the given ID.
+func (n *Notes) Delete(id string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for idx, note := range n.notes {
+		if note.ID != id {
+			continue
+		}
+		remaining := make([]Note, 0, len(n.notes)-1)
+		remaining = append(remaining, n.notes[:idx]...)
+		remaining = append(remaining, n.notes[idx+1:]...)
+		if err := n.store.Save(notesDocument, remaining); err != nil {
+			return err
+		}
+		n.notes = remaining
+		return nil
+	}
+	return ErrNotFound
+}
+
+// All returns every note across databases.
+func (n *Notes) All() []Note {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return append([]Note{}, n.notes...)
+}
+
+// Merge adds notes whose IDs are not already present.
+// Returns the number of notes added.
+func (n *Notes) Merge(notes []Note) (int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	existing := make(map[string]bool, len(n.notes))
+	for _, note := range n.notes {
+		existing[note.ID] = true
+	}
+	merged := append([]Note{}, n.notes...)
+	for _, note := range notes {
+		if note.ID == "" || existing[note.ID] {
+			continue
+		}
+		existing[note.ID] = true
+		merged = append(merged, note)
+	}
+
+	added := len(merged) - len(n.notes)
+	if added == 0 {
+		return 0, nil
+	}
+	if err := n.store.Save(notesDocument, merged); err != nil {
+		return 0, err
+	}
+	n.notes = merged
+	return added, nil
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"slices"
+	"sync"
+)
+
+// maxRecentTables bounds the recently-viewed history per user and database.
+const maxRecentTables = 20
+
+// Preferences holds one user's view preferences for one database.
+type Preferences struct {
+	Pinned []string `json:"pinned"`
+	Recent []string `json:"recent"`
+}
+
+const preferencesDocument = "preferences"
+
+// PreferenceStore keeps per-user, per-database preferences and persists every change.
+type PreferenceStore struct {
+	store *Store
+	mu    sync.Mutex
+	prefs map[string]map[string]*Preferences // user -> database -> preferences
+}
+
+// NewPreferenceStore loads existing preferences from the store.
+func NewPreferenceStore(s *Store) (*PreferenceStore, error) {
+	p := &PreferenceStore{store: s, prefs: make(map[string]map[string]*Preferences)}
+	if err := s.Load(preferencesDocument, &p.prefs); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Get returns a copy of the preferences for user and database.
+func (p *PreferenceStore) Get(user, database string) Preferences {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.copyOf(user, database)
+}
+
+// Pin adds table to the user's pinned tables.
+func (p *PreferenceStore) Pin(user, database, table string) (Preferences, error) {
+	return p.update(user, database, func(prefs *Preferences) {
+		if !slices.Contains(prefs.Pinned, table) {
+			prefs.Pinned = append(prefs.Pinned, table)
+		}
+	})
+}
+
+// Unpin removes table from the user's pinned tables.
+func (p *PreferenceStore) Unpin(user, database, table string) (Preferences, error) {
+	return p.update(user, database, func(prefs *Preferences) {
+		prefs.Pinned = slices.DeleteFunc(prefs.Pinned, func(t string) bool { return t == table })
+	})
+}
+
+// Visit moves table to the front of the user's recently-viewed history.
+func (p *PreferenceStore) Visit(user, database, table string) (Preferences, error) {
+	return p.update(user, database, func(prefs *Preferences) {
+		recent := slices.DeleteFunc(prefs.Recent, func(t string) bool { return t == table })
+		recent = append([]string{table}, recent...)
+		if len(recent) > maxRecentTables {
+			recent = recent[:maxRecentTables]
+		}
+		prefs.Recent = recent
+	})
+}
+
+// update applies fn to the stored preferences and persists the result.
+// The in-memory copy is only replaced once the save succeeds.
+func (p *PreferenceStore) update(user, database string, fn func(*Preferences)) (Preferences, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	updated := p.copyOf(user, database)
+	fn(&updated)
+
+	byDB := p.prefs[user]
+	if byDB == nil {
+		byDB = make(map[string]*Preferences)
+		p.prefs[user] = byDB
+	}
+	previous := byDB[database]
+	byDB[database] = &updated
+
+	if err := p.store.Save(preferencesDocument, p.prefs); err != nil {
+		if previous == nil {
+			delete(byDB, database)
+		} else {
+			byDB[database] = previous
+		}
+		return Preferences{}, err
+	}
+	return p.copyOf(user, database), nil
+}
+
+// copyOf returns a defensive copy. Caller must hold p.mu.
+func (p *PreferenceStore) copyOf(user, database string) Preferences {
+	prefs := Preferences{Pinned: []string{}, Recent: []string{}}
+	if stored := p.prefs[user][database]; stored != nil {
+		prefs.Pinned = append(prefs.Pinned, stored.Pinned...)
+		prefs.Recent = append(prefs.Recent, stored.Recent...)
+	}
+	return prefs
+}
+
+// All returns a copy of every user's preferences, keyed by user then database.
+func (p *PreferenceStore) All() map[string]map[string]Preferences {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	all := make(map[string]map[string]Preferences, len(p.prefs))
+	for user, byDB := range p.prefs {
+		all[user] = make(map[string]Preferences, len(byDB))
+		for database := range byDB {
+			all[user][database] = p.copyOf(user, database)
+		}
+	}
+	return all
+}
+
+// Merge replaces preferences for every user and database present in prefs,
+// leaving others untouched. Returns the number of entries written.
+func (p *PreferenceStore) Merge(prefs map[string]map[string]Preferences) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	merged := make(map[string]map[string]*Preferences, len(p.prefs))
+	for user, byDB := range p.prefs {
+		merged[user] = make(map[string]*Preferences, len(byDB))
+		for database, stored := range byDB {
+			merged[user][database] = stored
+		}
+	}
+
+	count := 0
+	for user, byDB := range prefs {
+		if merged[user] == nil {
+			merged[user] = make(map[string]*Preferences, len(byDB))
+		}
+		for database, incoming := range byDB {
+			merged[user][database] = &incoming
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	if err := p.store.Save(preferencesDocument, merged); err != nil {
+		return 0, err
+	}
+	p.prefs = merged
+	return count, nil
+}
@@ -0,0 +1,245 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Snapshot sources.
+const (
+	SnapshotManual    = "manual"
+	SnapshotScheduled = "scheduled"
+	SnapshotBaseline  = "baseline" // Reference point for drift detection
+	SnapshotImport    = "import"   // Loaded from a SQL script
+)
+
+// SnapshotInfo describes a stored snapshot without its schema body.
+type SnapshotInfo struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name,omitempty"`
+	Database   string    `json:"database"`
+	Source     string    `json:"source"`
+	CreatedAt  time.Time `json:"createdAt"`
+	TableCount int       `json:"tableCount"`
+}
+
+// Snapshot is a point-in-time copy of a database schema.
+type Snapshot struct {
+	SnapshotInfo
+	Schema *schema.Schema `json:"schema"`
+}
+
+const snapshotIndexDocument = "snapshots"
+
+// snapshotDocument returns the document name holding one snapshot's schema.
+func snapshotDocument(id string) string {
+	return "snapshots/" + id
+}
+
+// SnapshotStore keeps an index of snapshots in memory; schema bodies are
+// stored one document per snapshot and loaded on demand.
+type SnapshotStore struct {
+	store *Store
+	mu    sync.Mutex
+	index []SnapshotInfo
+}
+
+// NewSnapshotStore loads the snapshot index from the store.
+func NewSnapshotStore(s *Store) (*SnapshotStore, error) {
+	ss := &SnapshotStore{store: s, index: []SnapshotInfo{}}
+	if err := s.Load(snapshotIndexDocument, &ss.index); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// Save stores a new snapshot of sch and returns its metadata.
+func (ss *SnapshotStore) Save(name, database, source string, sch *schema.Schema) (SnapshotInfo, error) {
+	id, err := newID()
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("failed to generate snapshot ID: %w", err)
+	}
+	info := SnapshotInfo{
+		ID:         id,
+		Name:       name,
+		Database:   database,
+		Source:     source,
+		CreatedAt:  time.Now().UTC(),
+		TableCount: len(sch.Tables),
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if err := ss.store.Save(snapshotDocument(id), Snapshot{SnapshotInfo: info, Schema: sch}); err != nil {
+		return SnapshotInfo{}, err
+	}
+	index := append(append([]SnapshotInfo{}, ss.index...), info)
+	if err := ss.store.Save(snapshotIndexDocument, index); err != nil {
+		_ = ss.store.Delete(snapshotDocument(id))
+		return SnapshotInfo{}, err
+	}
+	ss.index = index
+	return info, nil
+}
+
+// List returns snapshot metadata newest first, optionally filtered to one database.
+func (ss *SnapshotStore) List(database string) []SnapshotInfo {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	list := make([]SnapshotInfo, 0, len(ss.index))
+	for _, info := range ss.index {
+		if database == "" || info.Database == database {
+			list = append(list, info)
+		}
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].CreatedAt.After(list[b].CreatedAt) })
+	return list
+}
+
+// FindBaseline returns the newest baseline snapshot of database with name.
+func (ss *SnapshotStore) FindBaseline(database, name string) (SnapshotInfo, bool) {
+	for _, info := range ss.List(database) {
+		if info.Source == SnapshotBaseline && info.Name == name {
+			return info, true
+		}
+	}
+	return SnapshotInfo{}, false
+}
+
+// Get loads a snapshot including its schema.
+func (ss *SnapshotStore) Get(id string) (*Snapshot, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.find(id) < 0 {
+		return nil, ErrNotFound
+	}
+	var snap Snapshot
+	if err := ss.store.Load(snapshotDocument(id), &snap); err != nil {
+		return nil, err
+	}
+	if snap.Schema == nil {
+		return nil, fmt.Errorf("snapshot %s has no schema document", id)
+	}
+	return &snap, nil
+}
+
+// Delete removes a snapshot and its schema document.
+func (ss *SnapshotStore) Delete(id string) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.remove(map[string]bool{id: true})
+}
+
+// Prune removes snapshots of one database and source beyond the newest keep,
+// and any older than maxAge. A zero keep or maxAge disables that limit.
+// Returns the number of snapshots removed.
+func (ss *SnapshotStore) Prune(database, source string, keep int, maxAge time.Duration) (int, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var candidates []SnapshotInfo
+	for _, info := range ss.index {
+		if info.Database == database && info.Source == source {
+			candidates = append(candidates, info)
+		}
+	}
+	sort.Slice(candidates, func(a, b int) bool { return candidates[a].CreatedAt.After(candidates[b].CreatedAt) })
+
+	cutoff := time.Now().Add(-maxAge)
+	doomed := make(map[string]bool)
+	for idx, info := range candidates {
+		if (keep > 0 && idx >= keep) || (maxAge > 0 && info.CreatedAt.Before(cutoff)) {
+			doomed[info.ID] = true
+		}
+	}
+	if len(doomed) == 0 {
+		return 0, nil
+	}
+	return len(doomed), ss.remove(doomed)
+}
+
+// remove drops the given IDs from the index and deletes their documents.
+// Caller must hold ss.mu.
+func (ss *SnapshotStore) remove(ids map[string]bool) error {
+	index := make([]SnapshotInfo, 0, len(ss.index))
+	for _, info := range ss.index {
+		if !ids[info.ID] {
+			index = append(index, info)
+		}
+	}
+	if len(index) == len(ss.index) {
+		return ErrNotFound
+	}
+
+	if err := ss.store.Save(snapshotIndexDocument, index); err != nil {
+		return err
+	}
+	ss.index = index
+
+	for id := range ids {
+		if err := ss.store.Delete(snapshotDocument(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss *SnapshotStore) find(id string) int {
+	for idx, info := range ss.index {
+		if info.ID == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+// All loads every snapshot including its schema.
+func (ss *SnapshotStore) All() ([]Snapshot, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	all := make([]Snapshot, 0, len(ss.index))
+	for _, info := range ss.index {
+		var snap Snapshot
+		if err := ss.store.Load(snapshotDocument(info.ID), &snap); err != nil {
+			return nil, err
+		}
+		if snap.Schema == nil {
+			continue // Body lost; nothing useful to export
+		}
+		all = append(all, snap)
+	}
+	return all, nil
+}
+
+// Merge adds snapshots whose IDs are not already present, keeping their
+// original metadata. Returns the number of snapshots added.
+func (ss *SnapshotStore) Merge(snaps []Snapshot) (int, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	added := 0
+	for _, snap := range snaps {
+		if snap.Schema == nil || !validDocumentID(snap.ID) || ss.find(snap.ID) >= 0 {
+			continue
+		}
+		if err := ss.store.Save(snapshotDocument(snap.ID), snap); err != nil {
+			return added, err
+		}
+		index := append(append([]SnapshotInfo{}, ss.index...), snap.SnapshotInfo)
+		if err := ss.store.Save(snapshotIndexDocument, index); err != nil {
+			_ = ss.store.Delete(snapshotDocument(snap.ID))
+			return added, err
+		}
+		ss.index = index
+		added++
+	}
+	return added, nil
+}
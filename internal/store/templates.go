@@ -0,0 +1,114 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/templates"
+)
+
+const templatesDocument = "templates"
+
+// TemplateStore holds user-defined migration templates in memory and
+// persists every change. Built-in templates are not stored.
+type TemplateStore struct {
+	store     *Store
+	mu        sync.RWMutex
+	templates []templates.Template
+}
+
+// NewTemplateStore loads existing templates from the store.
+func NewTemplateStore(s *Store) (*TemplateStore, error) {
+	ts := &TemplateStore{store: s, templates: []templates.Template{}}
+	if err := s.Load(templatesDocument, &ts.templates); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// List returns the stored templates in creation order.
+func (ts *TemplateStore) List() []templates.Template {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	return append([]templates.Template{}, ts.templates...)
+}
+
+// Get returns the template with the given ID.
+func (ts *TemplateStore) Get(id string) (templates.Template, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	for _, t := range ts.templates {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return templates.Template{}, ErrNotFound
+}
+
+// Add assigns an ID and timestamps to t and stores it.
+func (ts *TemplateStore) Add(t templates.Template) (templates.Template, error) {
+	id, err := newID()
+	if err != nil {
+		return templates.Template{}, fmt.Errorf("failed to generate template ID: %w", err)
+	}
+	t.ID = id
+	t.Builtin = false
+	t.CreatedAt = time.Now().UTC()
+	t.UpdatedAt = t.CreatedAt
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.templates = append(ts.templates, t)
+	if err := ts.store.Save(templatesDocument, ts.templates); err != nil {
+		ts.templates = ts.templates[:len(ts.templates)-1]
+		return templates.Template{}, err
+	}
+	return t, nil
+}
+
+// Update replaces the template with t's ID, keeping its creation time.
+func (ts *TemplateStore) Update(t templates.Template) (templates.Template, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for idx, old := range ts.templates {
+		if old.ID != t.ID {
+			continue
+		}
+		t.Builtin = false
+		t.CreatedAt = old.CreatedAt
+		t.UpdatedAt = time.Now().UTC()
+
+		updated := append([]templates.Template{}, ts.templates...)
+		updated[idx] = t
+		if err := ts.store.Save(templatesDocument, updated); err != nil {
+			return templates.Template{}, err
+		}
+		ts.templates = updated
+		return t, nil
+	}
+	return templates.Template{}, ErrNotFound
+}
+
+// Delete removes the template with the given ID.
+func (ts *TemplateStore) Delete(id string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for idx, t := range ts.templates {
+		if t.ID != id {
+			continue
+		}
+		remaining := make([]templates.Template, 0, len(ts.templates)-1)
+		remaining = append(remaining, ts.templates[:idx]...)
+		remaining = append(remaining, ts.templates[idx+1:]...)
+		if err := ts.store.Save(templatesDocument, remaining); err != nil {
+			return err
+		}
+		ts.templates = remaining
+		return nil
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,286 @@
+// Package templates expands parameterized migration templates, such as adding
+// soft-delete or audit timestamp columns, into statements for given tables.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JonMunkholm/AltDbMigration/internal/schema"
+)
+
+// Parameter kinds.
+const (
+	ParamIdentifier = "identifier" // A table, column, or other name; quoted where used
+	ParamType       = "type"       // A column type from schema.AllowedTypes
+)
+
+// TableParam is the parameter every template has: the table it is applied to.
+const TableParam = "table"
+
+// Limits on user-defined templates.
+const (
+	maxStatements     = 50
+	maxStatementBytes = 8000
+	maxParams         = 10
+)
+
+// ErrInvalidTemplate is returned when a template or its arguments are invalid.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// Param is a value a template is expanded with besides the table.
+type Param struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"` // identifier (default) or type
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"` // Used when no value is given; required otherwise
+}
+
+// Template is a reusable change applied to one table at a time. Statements
+// refer to parameters as {{name}}, expanded to the quoted identifier or the
+// type, and to identifiers as {{name.raw}}, expanded unquoted for building
+// other names, e.g. "{{table.raw}}_deleted_at_idx". {{table}} is always
+// available.
+type Template struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Params      []Param   `json:"params"`
+	Up          []string  `json:"up"`
+	Down        []string  `json:"down"` // Reverses Up; empty when it cannot be undone
+	Builtin     bool      `json:"builtin"`
+	CreatedAt   time.Time `json:"createdAt"` // Zero for built-in templates
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Builtins are the templates every server has. They cannot be changed.
+var Builtins = []Template{
+	{
+		ID:          "soft-delete",
+		Name:        "Add soft-delete column",
+		Description: "A nullable deletion timestamp; rows with it set count as deleted",
+		Params: []Param{
+			{Name: "column", Kind: ParamIdentifier, Description: "Column name", Default: "deleted_at"},
+		},
+		Up: []string{
+			"ALTER TABLE {{table}} ADD COLUMN {{column}} timestamptz",
+		},
+		Down: []string{
+			"ALTER TABLE {{table}} DROP COLUMN {{column}}",
+		},
+	},
+	{
+		ID:          "timestamps",
+		Name:        "Add created_at/updated_at with trigger",
+		Description: "Creation and update timestamps defaulting to now(), with a trigger that sets updated_at on every update; the trigger function is shared by every table and kept on undo",
+		Params:      []Param{},
+		Up: []string{
+			"ALTER TABLE {{table}} ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(), ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now()",
+			"CREATE OR REPLACE FUNCTION altdb_set_updated_at() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN NEW.updated_at := now(); RETURN NEW; END $$",
+			`CREATE TRIGGER "{{table.raw}}_set_updated_at" BEFORE UPDATE ON {{table}} FOR EACH ROW EXECUTE FUNCTION altdb_set_updated_at()`,
+		},
+		Down: []string{
+			`DROP TRIGGER IF EXISTS "{{table.raw}}_set_updated_at" ON {{table}}`,
+			"ALTER TABLE {{table}} DROP COLUMN updated_at, DROP COLUMN created_at",
+		},
+	},
+	{
+		ID:          "tenant-id",
+		Name:        "Add tenant_id foreign key",
+		Description: "A nullable tenant reference with an index; fill it in before setting NOT NULL",
+		Params: []Param{
+			{Name: "tenants", Kind: ParamIdentifier, Description: "Tenant table", Default: "tenants"},
+			{Name: "key", Kind: ParamIdentifier, Description: "Referenced column", Default: "id"},
+			{Name: "column", Kind: ParamIdentifier, Description: "Column name", Default: "tenant_id"},
+			{Name: "type", Kind: ParamType, Description: "Column type, matching the referenced column", Default: "bigint"},
+		},
+		Up: []string{
+			"ALTER TABLE {{table}} ADD COLUMN {{column}} {{type}} REFERENCES {{tenants}} ({{key}})",
+			`CREATE INDEX "{{table.raw}}_{{column.raw}}_idx" ON {{table}} ({{column}})`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS "{{table.raw}}_{{column.raw}}_idx"`,
+			"ALTER TABLE {{table}} DROP COLUMN {{column}}",
+		},
+	},
+}
+
+// Builtin returns the built-in template with the given ID.
+func Builtin(id string) (Template, bool) {
+	for _, t := range Builtins {
+		if t.ID == id {
+			t.Builtin = true
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// placeholderPattern matches {{name}} and {{name.raw}}.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z_][a-z0-9_]*)(\.raw)?\s*\}\}`)
+
+// Validate checks a template's name, parameters, and statements, filling in
+// default parameter kinds. Every placeholder must name a parameter, and .raw
+// applies only to identifiers.
+func Validate(t *Template) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTemplate)
+	}
+	if len(t.Name) > 100 || len(t.Description) > 1000 {
+		return fmt.Errorf("%w: name or description is too long", ErrInvalidTemplate)
+	}
+	if t.Params == nil {
+		t.Params = []Param{}
+	}
+	if t.Down == nil {
+		t.Down = []string{}
+	}
+	if len(t.Params) > maxParams {
+		return fmt.Errorf("%w: at most %d parameters", ErrInvalidTemplate, maxParams)
+	}
+
+	kinds := map[string]string{TableParam: ParamIdentifier}
+	for idx := range t.Params {
+		p := &t.Params[idx]
+		if p.Kind == "" {
+			p.Kind = ParamIdentifier
+		}
+		if !schema.ValidIdentifier(p.Name) {
+			return fmt.Errorf("%w: parameter name %q must be a lowercase identifier", ErrInvalidTemplate, p.Name)
+		}
+		if _, dup := kinds[p.Name]; dup {
+			return fmt.Errorf("%w: parameter %q is declared twice or shadows table", ErrInvalidTemplate, p.Name)
+		}
+		if p.Kind != ParamIdentifier && p.Kind != ParamType {
+			return fmt.Errorf("%w: parameter %q kind must be identifier or type", ErrInvalidTemplate, p.Name)
+		}
+		if p.Default != "" {
+			if err := validateValue(*p, p.Default); err != nil {
+				return err
+			}
+		}
+		kinds[p.Name] = p.Kind
+	}
+
+	if len(t.Up) == 0 {
+		return fmt.Errorf("%w: at least one up statement is required", ErrInvalidTemplate)
+	}
+	for _, statements := range [][]string{t.Up, t.Down} {
+		if len(statements) > maxStatements {
+			return fmt.Errorf("%w: at most %d statements each way", ErrInvalidTemplate, maxStatements)
+		}
+		for _, s := range statements {
+			if strings.TrimSpace(s) == "" {
+				return fmt.Errorf("%w: statements cannot be empty", ErrInvalidTemplate)
+			}
+			if len(s) > maxStatementBytes {
+				return fmt.Errorf("%w: statement is too long", ErrInvalidTemplate)
+			}
+			for _, m := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+				kind, ok := kinds[m[1]]
+				if !ok {
+					return fmt.Errorf("%w: unknown parameter {{%s}}", ErrInvalidTemplate, m[1])
+				}
+				if m[2] != "" && kind != ParamIdentifier {
+					return fmt.Errorf("%w: {{%s.raw}} applies only to identifiers", ErrInvalidTemplate, m[1])
+				}
+			}
+			if strings.Contains(placeholderPattern.ReplaceAllString(s, ""), "{{") {
+				return fmt.Errorf("%w: malformed placeholder in %q", ErrInvalidTemplate, s)
+			}
+		}
+	}
+	return nil
+}
+
+// validateValue checks a value given for p.
+func validateValue(p Param, value string) error {
+	switch p.Kind {
+	case ParamType:
+		if !schema.IsValidType(value, nil) {
+			return fmt.Errorf("%w: %s: unsupported type %q", ErrInvalidTemplate, p.Name, value)
+		}
+	default:
+		if !schema.ValidIdentifier(value) {
+			return fmt.Errorf("%w: %s: %q is not a valid identifier", ErrInvalidTemplate, p.Name, value)
+		}
+	}
+	return nil
+}
+
+// Arguments resolves the values t is expanded with from args, using defaults
+// for those not given. Arguments t does not declare are rejected.
+func Arguments(t Template, args map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(t.Params)+1)
+	for _, p := range t.Params {
+		v, ok := args[p.Name]
+		if !ok || v == "" {
+			v = p.Default
+		}
+		if v == "" {
+			return nil, fmt.Errorf("%w: %s is required", ErrInvalidTemplate, p.Name)
+		}
+		if err := validateValue(p, v); err != nil {
+			return nil, err
+		}
+		values[p.Name] = v
+	}
+	for name := range args {
+		if !slices.ContainsFunc(t.Params, func(p Param) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("%w: unknown parameter %s", ErrInvalidTemplate, name)
+		}
+	}
+	return values, nil
+}
+
+// Changeset is a template expanded for a list of tables.
+type Changeset struct {
+	Up   []string `json:"up"`   // Each table's statements, in table order
+	Down []string `json:"down"` // Reverses Up, last table first; empty if the template has no down
+}
+
+// Expand substitutes values, as returned by Arguments, and each table into
+// t's statements. Tables must be valid identifiers.
+func Expand(t Template, tables []string, values map[string]string) (Changeset, error) {
+	kinds := map[string]string{TableParam: ParamIdentifier}
+	for _, p := range t.Params {
+		kinds[p.Name] = p.Kind
+	}
+
+	c := Changeset{Up: []string{}, Down: []string{}}
+	for _, table := range tables {
+		if !schema.ValidIdentifier(table) {
+			return Changeset{}, fmt.Errorf("%w: invalid table name %q", ErrInvalidTemplate, table)
+		}
+		vars := make(map[string]string, len(values)+1)
+		for k, v := range values {
+			vars[k] = v
+		}
+		vars[TableParam] = table
+
+		expand := func(s string) string {
+			return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+				m := placeholderPattern.FindStringSubmatch(match)
+				v := vars[m[1]]
+				if m[2] != "" || kinds[m[1]] == ParamType {
+					return v
+				}
+				return `"` + v + `"` // Valid identifiers hold no quotes
+			})
+		}
+		for _, s := range t.Up {
+			c.Up = append(c.Up, expand(s))
+		}
+		var down []string
+		for _, s := range t.Down {
+			down = append(down, expand(s))
+		}
+		c.Down = append(down, c.Down...)
+	}
+	return c, nil
+}
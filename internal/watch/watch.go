@@ -0,0 +1,106 @@
+// Package watch polls a file for changes without platform notification APIs.
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Watcher calls a function with a file's content whenever it changes.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	onChange func(ctx context.Context, content []byte, err error)
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	// Last state seen; the modification time and size are checked every
+	// interval, and the content hashed only when they differ
+	modTime time.Time
+	size    int64
+	sum     []byte
+	failed  bool // The last read failed; a later success is a change
+}
+
+// Start begins polling path every interval in a background goroutine. onChange
+// runs once with the initial content, then after each change to it. A file
+// that cannot be read is reported as err, once until it can be read again;
+// editors that save by replacing the file briefly cause this.
+// Calls never overlap: a slow onChange delays the next poll.
+func Start(path string, interval time.Duration, onChange func(ctx context.Context, content []byte, err error)) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher{
+		path:     path,
+		interval: interval,
+		onChange: onChange,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go w.loop()
+	return w
+}
+
+func (w *Watcher) loop() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.poll(true)
+	for {
+		select {
+		case <-ticker.C:
+			w.poll(false)
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// poll reads the file if it looks changed and calls onChange if its content
+// did. first forces a call so there is always an initial result.
+func (w *Watcher) poll(first bool) {
+	info, err := os.Stat(w.path)
+	if err == nil && !info.Mode().IsRegular() {
+		err = &fs.PathError{Op: "watch", Path: w.path, Err: errors.New("not a regular file")}
+	}
+	if err == nil && !first && !w.failed && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return
+	}
+
+	var content []byte
+	if err == nil {
+		content, err = os.ReadFile(w.path)
+	}
+	if err != nil {
+		if !w.failed {
+			w.failed = true
+			w.sum = nil
+			w.onChange(w.ctx, nil, err)
+		}
+		return
+	}
+
+	// Touched files keep their content; only report a real change
+	w.modTime, w.size = info.ModTime(), info.Size()
+	sum := sha256.Sum256(content)
+	if !first && !w.failed && bytes.Equal(sum[:], w.sum) {
+		return
+	}
+	w.failed = false
+	w.sum = sum[:]
+	w.onChange(w.ctx, content, nil)
+}
+
+// Stop cancels any running onChange and waits for the loop to exit.
+// Should be called on graceful shutdown.
+func (w *Watcher) Stop() {
+	w.cancel()
+	<-w.done
+}
@@ -54,6 +54,8 @@ func main() {
 		WriteTimeout:   cfg.WriteTimeout,
 		MaxHeaderBytes: 1 << 20, // 1 MB
 	}
+	// Event streams stay open until closed; Shutdown would wait them out
+	server.RegisterOnShutdown(handler.CloseStreams)
 
 	go func() {
 		sigCh := make(chan os.Signal, 1)
@@ -6,9 +6,13 @@ import type {
   DatabasesData,
   SwitchDatabaseData,
   CreateTableData,
+  CreateTableRequest,
   AddColumnData,
+  AddColumnsData,
   AddColumnRequest,
   TypesData,
+  CommentData,
+  UndoData,
 } from './types';
 
 // Custom error class with code property
@@ -144,11 +148,12 @@ export const Api = {
     return this.handleResponse<TypesData>(response);
   },
 
-  async createTable(name: string): Promise<CreateTableData> {
+  async createTable(name: string, options: Omit<CreateTableRequest, 'name'> = {}): Promise<CreateTableData> {
+    const request: CreateTableRequest = { name, ...options };
     const response = await fetchWithCSRFRetry('/api/tables', {
       method: 'POST',
       headers: getHeaders(),
-      body: JSON.stringify({ name }),
+      body: JSON.stringify(request),
     });
     return this.handleResponse<CreateTableData>(response);
   },
@@ -164,4 +169,48 @@ export const Api = {
     );
     return this.handleResponse<AddColumnData>(response);
   },
+
+  async addColumns(tableName: string, columns: AddColumnRequest[]): Promise<AddColumnsData> {
+    const response = await fetchWithCSRFRetry(
+      `/api/tables/${encodeURIComponent(tableName)}/columns/batch`,
+      {
+        method: 'POST',
+        headers: getHeaders(),
+        body: JSON.stringify(columns),
+      }
+    );
+    return this.handleResponse<AddColumnsData>(response);
+  },
+
+  async setTableComment(tableName: string, comment: string): Promise<CommentData> {
+    const response = await fetchWithCSRFRetry(
+      `/api/tables/${encodeURIComponent(tableName)}/comment`,
+      {
+        method: 'PUT',
+        headers: getHeaders(),
+        body: JSON.stringify({ comment }),
+      }
+    );
+    return this.handleResponse<CommentData>(response);
+  },
+
+  async setColumnComment(tableName: string, columnName: string, comment: string): Promise<CommentData> {
+    const response = await fetchWithCSRFRetry(
+      `/api/tables/${encodeURIComponent(tableName)}/columns/${encodeURIComponent(columnName)}/comment`,
+      {
+        method: 'PUT',
+        headers: getHeaders(),
+        body: JSON.stringify({ comment }),
+      }
+    );
+    return this.handleResponse<CommentData>(response);
+  },
+
+  async undo(): Promise<UndoData> {
+    const response = await fetchWithCSRFRetry('/api/undo', {
+      method: 'POST',
+      headers: getHeaders(),
+    });
+    return this.handleResponse<UndoData>(response);
+  },
 };
@@ -133,6 +133,7 @@ export const App = {
       await Api.switchDatabase(dbName);
       // Clear expanded tables when switching databases
       State.clearExpandedTables();
+      Modals.clearTypesCache();
       await this.loadSchema();
     } catch (error) {
       Utils.toast.error('Failed to switch database: ' + getErrorMessage(error));
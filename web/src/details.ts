@@ -36,10 +36,12 @@ export const Details = {
     const details = document.getElementById('details');
     if (!details) return;
 
-    const fkColumns = new Set((table.foreignKeys || []).map(fk => fk.columnName));
+    const fkColumns = new Set((table.foreignKeys || []).flatMap(fk => fk.columns));
     const fkDetails: Record<string, ForeignKey> = {};
     (table.foreignKeys || []).forEach(fk => {
-      fkDetails[fk.columnName] = fk;
+      fk.columns.forEach(col => {
+        fkDetails[col] = fk;
+      });
     });
 
     let html = `
@@ -59,17 +61,20 @@ export const Details = {
 
       html += `<li class="column-item ${classes}">`;
       html += `<div class="column-name">${Utils.escapeHtml(col.name)}</div>`;
-      html += `<div class="column-type">${Utils.escapeHtml(col.dataType)}</div>`;
+      const typeLabel = col.domain ? `${col.domain} (${col.dataType})` : col.dataType;
+      html += `<div class="column-type">${Utils.escapeHtml(typeLabel)}</div>`;
       html += '<div class="column-badges">';
       if (isPrimary) html += '<span class="badge pk">PK</span>';
       if (isFK) html += '<span class="badge fk">FK</span>';
       if (col.isNullable) html += '<span class="badge nullable">null</span>';
-      if (col.default) html += `<span class="badge default" title="Default: ${Utils.escapeHtml(col.default)}">def</span>`;
+      if (col.isGenerated) html += `<span class="badge default" title="Generated: ${Utils.escapeHtml(col.generationExpression || '')}">gen</span>`;
+      else if (col.identity) html += `<span class="badge default" title="Generated ${Utils.escapeHtml(col.identity)} as identity">id</span>`;
+      else if (col.default) html += `<span class="badge default" title="Default: ${Utils.escapeHtml(col.default)}">def</span>`;
       html += '</div>';
 
       if (isFK) {
         const fk = fkDetails[col.name];
-        html += `<div class="fk-ref" data-navigate="${Utils.escapeHtml(fk.referencesTable)}">→ ${Utils.escapeHtml(fk.referencesTable)}.${Utils.escapeHtml(fk.referencesColumn)}</div>`;
+        html += `<div class="fk-ref" data-navigate="${Utils.escapeHtml(fk.referencesTable)}">→ ${Utils.escapeHtml(fk.referencesTable)}.${Utils.escapeHtml(Utils.referencedColumn(fk, col.name))}</div>`;
       }
       html += '</li>';
     });
@@ -54,12 +54,12 @@ interface EdgeData {
   id: string;
   source: string;
   target: string;
-  sourceColumn: string;
-  targetColumn: string;
+  sourceColumn?: string; // Unset for inheritance edges
+  targetColumn?: string;
   label: string;
 }
 
-type ElementDefinition = { data: NodeData } | { data: EdgeData };
+type ElementDefinition = { data: NodeData } | { data: EdgeData; classes?: string };
 
 export const Graph = {
   init(): void {
@@ -86,7 +86,7 @@ export const Graph = {
 
       const columns = (table.columns || []).slice(0, 8).map(col => {
         const pk = col.isPrimary ? '🔑 ' : '   ';
-        const fk = (table.foreignKeys || []).some(f => f.columnName === col.name) ? '🔗 ' : '   ';
+        const fk = (table.foreignKeys || []).some(f => f.columns.includes(col.name)) ? '🔗 ' : '   ';
         const prefix = col.isPrimary ? pk : fk;
         return `${prefix}${col.name}`;
       });
@@ -123,17 +123,35 @@ export const Graph = {
         relationshipCount++;
         elements.push({
           data: {
-            id: `${table.name}-${fk.columnName}-${fk.referencesTable}`,
+            id: `${table.name}-${fk.columns.join(',')}-${fk.referencesTable}`,
             source: table.name,
             target: fk.referencesTable,
-            sourceColumn: fk.columnName,
-            targetColumn: fk.referencesColumn,
-            label: `${table.name}.${fk.columnName} → ${fk.referencesTable}.${fk.referencesColumn}`,
+            sourceColumn: fk.columns.join(', '),
+            targetColumn: fk.referencesColumns.join(', '),
+            label: `${table.name}(${fk.columns.join(', ')}) → ${fk.referencesTable}(${fk.referencesColumns.join(', ')})`,
           },
         });
       });
     });
 
+    // Create edges for table inheritance (child → parent)
+    const tableNames = new Set(tables.map(t => t.name));
+    tables.forEach(table => {
+      (table.parentTables || []).forEach(parent => {
+        if (!tableNames.has(parent)) return;
+        relationshipCount++;
+        elements.push({
+          data: {
+            id: `${table.name}-inherits-${parent}`,
+            source: table.name,
+            target: parent,
+            label: `${table.name} inherits ${parent}`,
+          },
+          classes: 'inherits',
+        });
+      });
+    });
+
     // Show/hide no relationships notice
     const noRelEl = document.getElementById('no-relationships');
     if (noRelEl) {
@@ -212,6 +230,15 @@ export const Graph = {
           opacity: 0.7,
         } as cytoscape.Css.Edge,
       },
+      {
+        selector: 'edge.inherits',
+        style: {
+          'line-style': 'dashed',
+          'line-color': '#9b59b6',
+          'target-arrow-color': '#9b59b6',
+          'target-arrow-shape': 'triangle-backcurve',
+        } as cytoscape.Css.Edge,
+      },
       {
         selector: 'edge:selected',
         style: {
@@ -283,6 +310,10 @@ export const Graph = {
 
     cy.on('tap', 'edge', evt => {
       const edge = evt.target;
+      if (edge.hasClass('inherits')) {
+        this.highlightRelationship(edge);
+        return;
+      }
       Details.showRelationship(
         edge.data('source'),
         edge.data('sourceColumn'),
@@ -104,7 +104,7 @@ export const ListView = {
               ${fk ? '<span class="column-badge fk">FK</span>' : ''}
               ${col.isNullable ? '<span class="column-badge null">NULL</span>' : ''}
             </span>
-            ${fk ? `<span class="fk-reference">&#8594; ${Utils.escapeHtml(fk.referencesTable)}.${Utils.escapeHtml(fk.referencesColumn)}</span>` : ''}
+            ${fk ? `<span class="fk-reference">&#8594; ${Utils.escapeHtml(fk.referencesTable)}.${Utils.escapeHtml(Utils.referencedColumn(fk, col.name))}</span>` : ''}
           </div>
         `;
       });
@@ -6,6 +6,7 @@ export { AddColumnModal } from './addColumn';
 // Import for backward-compatible Modals object
 import { CreateTableModal } from './createTable';
 import { AddColumnModal } from './addColumn';
+import { clearTypesCache } from './shared';
 
 // Backward-compatible Modals object (matches original API)
 export const Modals = {
@@ -17,4 +18,5 @@ export const Modals = {
   toggleForeignKeySection: () => AddColumnModal.toggleForeignKeySection(),
   loadForeignKeyColumns: () => AddColumnModal.loadForeignKeyColumns(),
   addColumn: () => AddColumnModal.submit(),
+  clearTypesCache: () => clearTypesCache(),
 };
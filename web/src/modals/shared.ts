@@ -19,6 +19,11 @@ export async function ensureTypesLoaded(): Promise<TypeInfo[]> {
   }
 }
 
+// Drop cached types; enum types differ between databases
+export function clearTypesCache(): void {
+  cachedTypes = null;
+}
+
 // Populate type dropdown from cached types
 export function populateTypeDropdown(select: HTMLSelectElement, types: TypeInfo[]): void {
   select.innerHTML = '';
@@ -1,34 +1,206 @@
 // Schema Types - matches Go backend types
 
 export interface ForeignKey {
-  columnName: string;
+  constraintName: string;
+  columns: string[];
   referencesTable: string;
-  referencesColumn: string;
+  referencesColumns: string[];
+  onDelete: string;
+  onUpdate: string;
+  deferrable: boolean;
+  initiallyDeferred: boolean;
+  comment?: string;
+}
+
+// Foreign Key Actions Request; an omitted action keeps the current one
+export interface ForeignKeyActionsRequest {
+  onDelete?: string; // NO ACTION, RESTRICT, CASCADE, SET NULL, or SET DEFAULT
+  onUpdate?: string;
+}
+
+export interface ForeignKeyActionsData {
+  table: string;
+  foreignKey: ForeignKey;
+}
+
+export interface ColumnDefault {
+  kind: string; // sequence, now, literal, null, function, expression
+  value: string;
+  cast?: string;
 }
 
 export interface Column {
   name: string;
+  position: number; // 1-based; dropped columns leave no gaps
   dataType: string;
   isNullable: boolean;
   default: string | null;
+  defaultValue?: ColumnDefault;
+  typeCategory: string; // base, array, range, multirange, enum, composite, domain
+  elementType?: string;
+  characterMaximumLength?: number;
+  numericPrecision?: number;
+  numericScale?: number;
+  collation?: string;
   isPrimary: boolean;
   isUnique: boolean;
+  isGenerated: boolean;
+  generationExpression?: string;
+  identity?: string; // ALWAYS or BY DEFAULT
+  sequence?: string;
+  comment?: string;
+  storage?: string; // Only when it differs from the type's default
+  statisticsTarget?: number;
+  domain?: string; // Domain the column is declared with
+  extension?: string; // Extension providing the column's type
+}
+
+export interface Index {
+  name: string;
+  columns: string[];
+  isUnique: boolean;
+  isPrimary: boolean;
+  method: string;
+  predicate?: string;
+  scans: number;
+  lastUsed?: string;
+  tablespace?: string;
+  comment?: string;
+}
+
+export interface PrimaryKey {
+  name: string;
+  columns: string[];
+  comment?: string;
+}
+
+export interface UniqueConstraint {
+  name: string;
+  columns: string[];
+  comment?: string;
+}
+
+export interface CheckConstraint {
+  name: string;
+  expression: string;
+  comment?: string;
+}
+
+export interface ExclusionElement {
+  column: string;
+  operator: string;
+}
+
+export interface ExclusionConstraint {
+  name: string;
+  method: string;
+  elements: ExclusionElement[];
+  predicate?: string;
+  definition: string;
+  comment?: string;
+}
+
+export interface Trigger {
+  name: string;
+  timing: string;
+  events: string[];
+  level: string;
+  function: string;
+  enabled: boolean;
+}
+
+export interface Partition {
+  name: string;
+  bound: string;
+  estimatedRows: number;
+  sizeBytes: number;
+}
+
+export interface Partitioning {
+  strategy: string;
+  key: string;
+  columns: string[];
+  partitions: Partition[];
+}
+
+export interface Grant {
+  role: string;
+  privileges: string[];
+}
+
+export interface ForeignTable {
+  server: string;
+  wrapper: string;
+  options: string[]; // key=value
+}
+
+export interface ForeignServer {
+  name: string;
+  wrapper: string;
+  type?: string;
+  version?: string;
+  options: string[]; // key=value
 }
 
 export interface Table {
   name: string;
+  comment?: string;
+  estimatedRows: number;
+  sizeBytes: number;
+  tablespace?: string;
+  storageParameters?: string[]; // key=value
   columns: Column[];
+  primaryKey?: PrimaryKey;
   foreignKeys: ForeignKey[];
+  indexes: Index[];
+  uniqueConstraints: UniqueConstraint[];
+  checkConstraints: CheckConstraint[];
+  exclusions: ExclusionConstraint[];
+  triggers: Trigger[];
+  partitioning?: Partitioning;
+  parentTables?: string[];
+  grants?: Grant[]; // Only with ?includeGrants=true
+  isForeign: boolean;
+  foreign?: ForeignTable;
+  isSystem?: boolean; // pg_catalog or information_schema
+}
+
+export interface Sequence {
+  name: string;
+  dataType: string;
+  startValue: number;
+  increment: number;
+  currentValue?: number;
+  ownedByTable?: string;
+  ownedByColumn?: string;
+}
+
+export interface SchemaWarning {
+  kind: string; // missing-foreign-key or fk-type-mismatch
+  table: string;
+  column: string;
+  message: string;
+}
+
+export interface ForeignKeyCycle {
+  tables: string[];
+  foreignKeys: string[]; // table.constraint
+  deferrable: boolean;
 }
 
 export interface Schema {
   tables: Table[];
+  sequences: Sequence[];
+  foreignServers: ForeignServer[];
+  warnings: SchemaWarning[];
+  foreignKeyCycles: ForeignKeyCycle[];
 }
 
 // API Response Types
 export interface ApiError {
   code: string;
   message: string;
+  details?: unknown;
 }
 
 export interface ApiResponse<T> {
@@ -42,6 +214,24 @@ export interface DatabasesData {
   current: string;
 }
 
+export interface CreateDatabaseRequest {
+  name: string;
+  template?: string;
+  encoding?: string;
+  locale?: string; // LC_COLLATE and LC_CTYPE
+}
+
+export interface SchemaInfo {
+  name: string;
+  tables: number;
+  views: number;
+}
+
+export interface SchemasData {
+  schemas: SchemaInfo[];
+  current: string;
+}
+
 export interface SwitchDatabaseData {
   database: string;
 }
@@ -54,15 +244,81 @@ export interface AddColumnData {
   column: string;
 }
 
+export interface AddColumnsData {
+  table: string;
+  columns: string[];
+}
+
+// Tags of a table, or of one of its columns when column is set
+export interface ObjectTags {
+  table: string;
+  column?: string;
+  tags: string[];
+}
+
+export interface TagsData {
+  enabled: boolean; // False until POST /api/tags/enable creates the metadata table
+  tags: ObjectTags[];
+}
+
+export interface CommentData {
+  table: string;
+  column?: string;
+  constraint?: string;
+  index?: string;
+  comment: string;
+}
+
+// Returned instead of the usual payload when a mutation is sent with ?dryRun=true
+export interface DDLPreviewData {
+  dryRun: true;
+  statements: string[];
+}
+
+// A schema change applied through the API; inverse is empty when it cannot be undone
+export interface Change {
+  id: number;
+  database: string;
+  description: string;
+  statements: string[];
+  inverse?: string[];
+  irreversible?: string; // Why there is no inverse
+  appliedAt: string;
+}
+
+export interface UndoData {
+  undone: Change;
+}
+
+// POST /api/tables/{t}/truncate: without a token the server returns one bound
+// to the table and rowCount; echo it back to truncate
+export interface TruncateData {
+  table: string;
+  rowCount: number;
+  truncated: boolean;
+  token?: string;
+  expiresAt?: string;
+}
+
 // Type information from backend
 export interface TypeInfo {
   name: string;
   description: string;
   category: string;
+  labels?: string[]; // Set for user-defined enums
+  baseType?: string; // Set for domains
+  attributes?: { name: string; dataType: string }[]; // Set for composite types
+}
+
+export interface ConversionTemplate {
+  name: string;
+  description: string;
 }
 
 export interface TypesData {
   types: TypeInfo[];
+  conversions: ConversionTemplate[]; // USING choices when changing a column's type
+  storageParameters: string[]; // Table storage parameters that may be set
 }
 
 // Add Column Request
@@ -75,7 +331,91 @@ export interface AddColumnRequest {
   foreignKey?: {
     referencesTable: string;
     referencesColumn: string;
+    deferrable?: boolean;
+    initiallyDeferred?: boolean; // Requires deferrable
   };
+  generated?: GeneratedExpr; // GENERATED ALWAYS AS (...) STORED
+  identity?: IdentityDef; // Instead of a serial type; smallint, integer, or bigint
+}
+
+// Identity column options; start and increment default to 1
+export interface IdentityDef {
+  generation: 'always' | 'by-default';
+  start?: number;
+  increment?: number;
+}
+
+// Generated column expression node; kind is column, text, number, concat,
+// arithmetic, lower, or upper
+export interface GeneratedExpr {
+  kind: string;
+  column?: string;
+  value?: string;
+  operator?: string; // +, -, *, or / for arithmetic
+  args?: GeneratedExpr[];
+}
+
+// Create Table Request; primaryKey lists key columns for a composite key,
+// otherwise the table gets an id SERIAL PRIMARY KEY column, or a bigint
+// identity column when idIdentity is set
+export interface CreateTableRequest {
+  name: string;
+  columns?: AddColumnRequest[];
+  primaryKey?: string[];
+  idIdentity?: IdentityDef;
+}
+
+// An object that would be dropped along with a table
+export interface DependentObject {
+  kind: string; // foreign-key, view, or materialized view
+  name: string;
+  table: string; // Table holding the foreign key; the view itself for views
+}
+
+// Bulk drop plan; tables are in drop order, dependents first
+export interface BulkDropData {
+  dryRun?: boolean;
+  tables: string[];
+  cyclic: string[]; // Dropped together in the first statement
+  external: DependentObject[]; // Require cascade and are dropped with it
+  cascade: boolean;
+  statements: string[];
+}
+
+// Clone Table Request; the zero value copies indexes, defaults, and constraints
+export interface CloneTableRequest {
+  name: string;
+  excludeIndexes?: boolean;
+  excludeDefaults?: boolean;
+  excludeConstraints?: boolean;
+}
+
+// Generated (never executed) migration recreating a table with reordered columns
+export interface ReorderScript {
+  table: string;
+  columns: string[];
+  statements: string[];
+  warnings: string[];
+}
+
+// Create or attach a partition; set one of from/to (RANGE), in (LIST),
+// modulus/remainder (HASH), or default
+export interface PartitionRequest {
+  name: string;
+  from?: string[];
+  to?: string[];
+  in?: string[];
+  modulus?: number;
+  remainder?: number;
+  default?: boolean;
+}
+
+// What DELETE /api/tables/{t}/columns/{c}/unique dropped
+export interface UniqueEnforcer {
+  kind: 'constraint' | 'index';
+  name: string;
+  definition: string;
+  partial?: boolean;
 }
 
 // Toast Types
@@ -87,3 +427,456 @@ export type ViewMode = 'graph' | 'list';
 // Cytoscape Types (augment as needed)
 import type { Core as CytoscapeCore } from 'cytoscape';
 export type { CytoscapeCore };
+
+// Schema diff (GET /api/diff): what must change to turn source into target
+export type DiffStatus = 'added' | 'removed' | 'modified' | 'unchanged';
+
+export interface ColumnChange {
+  name: string;
+  status: DiffStatus;
+  changed?: string[]; // Names of modified fields
+  before?: Column;
+  after?: Column;
+}
+
+// A constraint or index; before/after are normalized definitions
+export interface ObjectChange {
+  name: string;
+  kind: string; // primary-key, unique, check, foreign-key, exclusion, or index
+  status: DiffStatus;
+  before?: string;
+  after?: string;
+}
+
+export interface TableDiff {
+  name: string;
+  status: DiffStatus;
+  columns: ColumnChange[];
+  constraints: ObjectChange[];
+  indexes: ObjectChange[];
+}
+
+export interface DiffCounts {
+  added: number;
+  removed: number;
+  modified: number;
+}
+
+export interface SchemaDiffData {
+  source: string;
+  target: string; // Database name, or snapshot:<id>
+  tables: TableDiff[];
+  summary: {
+    tables: DiffCounts;
+    columns: DiffCounts;
+    constraints: DiffCounts;
+    indexes: DiffCounts;
+  };
+  identical: boolean;
+}
+
+export interface GenerateMigrationRequest {
+  target?: string; // Database to migrate to
+  snapshot?: string; // Or snapshot ID to migrate to
+  description?: string; // Names exported files, NNN_description.up.sql
+  version?: number; // Export only; written files take the next free number
+}
+
+// ?format= on migration generation; both return plain text instead of JSON
+export type MigrationFormat = 'goose' | 'atlas';
+
+export interface MigrationFiles {
+  version: number;
+  up: string; // File name
+  down: string;
+}
+
+export type MigrationStatusKind = 'applied' | 'pending' | 'modified' | 'missing';
+
+export interface MigrationStatus {
+  version: number;
+  name: string;
+  file?: string; // Absent for applied migrations whose file is gone
+  status: MigrationStatusKind;
+  checksum: string; // SHA-256 of the up file
+  appliedAt?: string;
+  executionMs?: number;
+}
+
+export interface MigrationsData {
+  directory: string; // Empty when none is configured
+  migrations: MigrationStatus[];
+}
+
+export interface RollbackMigrationsRequest {
+  to?: number; // Roll back every migration above this version; default is the last one only
+}
+
+export interface AppliedMigration {
+  version: number;
+  name: string;
+  checksum: string;
+  downChecksum?: string;
+  appliedAt: string;
+  executionMs: number;
+}
+
+// POST /api/migrations/squash; ?dryRun=true changes nothing
+export interface SquashMigrationsData {
+  dryRun: boolean;
+  baseline: MigrationFiles;
+  squashed: MigrationStatus[]; // The migrations the baseline replaces
+  archive?: string; // Where their files went, relative to the migrations directory
+  up: string; // The baseline has no down script, so it cannot be rolled back
+  warnings: string[];
+  record?: AppliedMigration; // The baseline's history entry
+}
+
+export interface GenerateMigrationData {
+  source: string;
+  target: string; // Database name, or snapshot:<id>
+  up: string; // SQL text, warnings first as comments
+  down: string;
+  upWarnings: string[];
+  downWarnings: string[];
+}
+
+export type DriftSeverity = 'error' | 'warning' | 'info';
+
+export interface Baseline {
+  name: string;
+  storage: 'file' | 'database';
+  database: string;
+  snapshotId?: string; // Set for file storage
+  createdAt: string;
+}
+
+export interface DriftItem {
+  severity: DriftSeverity;
+  kind: string; // table, column, or a constraint or index kind
+  table: string;
+  name?: string;
+  status: 'added' | 'removed' | 'modified';
+  message: string;
+}
+
+export interface DriftData {
+  baseline: Baseline;
+  drifted: boolean;
+  counts: Record<DriftSeverity, number>;
+  items: DriftItem[]; // Most severe first
+}
+
+export interface ImportSchemaRequest {
+  name?: string; // Snapshot name; defaults to schema.sql
+  sql: string;
+}
+
+export interface ImportSchemaData {
+  snapshot: {
+    id: string; // Use as ?snapshot= for diffs and generated migrations
+    name?: string;
+    database: string;
+    source: string;
+    createdAt: string;
+    tableCount: number;
+  };
+  diff: Omit<SchemaDiffData, 'source' | 'target'>; // Current database (before) vs. the import (after)
+}
+
+export interface ImportDBMLRequest {
+  name?: string; // Snapshot name; defaults to schema.dbml
+  dbml: string;
+  mode?: 'diff' | 'create'; // create requires the admin token
+}
+
+export interface ImportDBMLData extends ImportSchemaData {
+  warnings: string[]; // Parts of the document that were left out
+}
+
+export interface CreateDBMLData {
+  tables: string[]; // Created
+  enums: string[]; // Created
+  skipped: string[]; // Already present, e.g. "table users"
+  statements: string[];
+  warnings: string[];
+}
+
+export interface StartDataCopyRequest {
+  source?: string; // Defaults to the current database
+  target: string;
+  tables?: string[]; // Empty copies every table both databases have
+  mappings?: Record<string, Record<string, DataCopyColumnRule>>; // By table, then target column
+  truncate?: boolean; // Empty the target tables first
+  batchSize?: number; // Rows per COPY into the target; default 10000
+  upsert?: boolean; // Update rows whose primary key is already in the target
+}
+
+// Without from, constant, or template a column comes from the source column
+// of the same name
+export interface DataCopyColumnRule {
+  from?: string; // Source column, when it was renamed
+  columns?: string[]; // More source columns, for templates that take several
+  drop?: boolean; // Leave the column out, so it gets its default
+  constant?: string; // Same value in every row
+  template?: string; // A DataCopyTemplate name
+  arg?: string; // The template's argument
+  cast?: string; // Built-in type to cast the value to
+}
+
+export interface DataCopyTemplate {
+  name: string;
+  description: string;
+  multiple: boolean; // Takes more than one column
+  argument: boolean; // Takes an argument
+}
+
+export interface DataCopyTemplatesData {
+  templates: DataCopyTemplate[];
+}
+
+export interface DataCopyTable {
+  table: string;
+  status: 'pending' | 'copying' | 'done' | 'failed' | 'cancelled';
+  rowsCopied: number;
+  estimatedRows: number; // From source statistics
+  error?: string;
+  checkpoint?: string[]; // Source key of the last committed row, while partly copied
+}
+
+export interface DataCopyData {
+  id: string;
+  job?: {
+    // The latest run; absent after a server restart
+    id: string;
+    kind: string;
+    status: 'running' | 'succeeded' | 'failed' | 'cancelled';
+    totalSteps: number;
+    doneSteps: number;
+    current?: string;
+    error?: string;
+    startedAt: string;
+    finishedAt?: string;
+  };
+  source: string;
+  target: string;
+  options: { truncate: boolean; batchSize?: number; upsert?: boolean };
+  tables: DataCopyTable[]; // In copy order: referenced tables first
+  resumable: boolean; // Stopped before every table was done
+  warnings?: string[];
+}
+
+export interface DataCopiesData {
+  copies: DataCopyData[]; // Newest first
+}
+
+export interface DataCopyTableCheck {
+  table: string;
+  status: 'pending' | 'checking' | 'match' | 'mismatch' | 'failed';
+  sourceRows: number;
+  targetRows: number;
+  checksum?: 'keys' | 'rows'; // Compared besides counts
+  mismatches?: string[];
+  note?: string; // Why no checksum was compared
+  error?: string;
+}
+
+// Started with POST /api/datacopy/{id}/verify?checksum=keys|rows&sample=100
+export interface DataCopyVerifyData {
+  id: string; // The data copy
+  job?: DataCopyData['job']; // Absent after a server restart
+  options: { checksum?: 'keys' | 'rows'; sampleSize?: number };
+  tables: DataCopyTableCheck[];
+  mismatched: string[]; // Tables that differ
+  startedAt: string;
+}
+
+export type MigrationRisk = 'low' | 'medium' | 'high' | 'unknown';
+
+export interface MigrationPlanStep {
+  statement: string;
+  table?: string; // The table locked, when there is one
+  lock?: string; // Strongest lock taken, e.g. ACCESS EXCLUSIVE
+  blocks: string; // What waits while the lock is held
+  rewrite: boolean; // Rewrites the table and its indexes
+  scan: boolean; // Reads the whole table while holding the lock
+  estimatedRows: number; // Before the migration; 0 for new tables
+  sizeBytes: number;
+  risk: MigrationRisk;
+  impact: string;
+  notes?: string[]; // Ways to take a lighter lock
+}
+
+// POST /api/migrations/plan takes a GenerateMigrationRequest
+export interface MigrationPlanData {
+  source: string;
+  target: string; // Database name, or snapshot:<id>
+  steps: MigrationPlanStep[];
+  risk: MigrationRisk; // The highest risk of any step
+  warnings: string[];
+}
+
+export type LintSeverity = 'error' | 'warning' | 'info';
+
+export interface LintFinding {
+  rule: string;
+  severity: LintSeverity;
+  table: string;
+  column?: string;
+  message: string;
+}
+
+export interface LintRule {
+  id: string;
+  description: string;
+  severity: LintSeverity;
+  enabled: boolean; // LINT_DISABLED_RULES turns rules off
+}
+
+// POST /api/lint/migration; give sql, or target or snapshot to generate from
+export interface LintMigrationRequest {
+  sql?: string;
+  target?: string;
+  snapshot?: string;
+}
+
+export interface LintMigrationFinding extends LintFinding {
+  statement: number; // Index into statements
+  sql: string;
+}
+
+export interface LintMigrationData {
+  statements: string[];
+  findings: LintMigrationFinding[];
+  rules: LintRule[];
+}
+
+// POST /api/migrations/validate; give target or snapshot as the desired schema
+export interface ValidateMigrationRequest {
+  target?: string;
+  snapshot?: string;
+  sql?: string; // The migration to validate; default is the generated one
+  baseline?: 'schema' | 'files'; // How the shadow gets the current schema; default schema
+  template?: string; // Or a database to copy as the shadow; nobody may be connected to it
+}
+
+export interface MigrationValidation {
+  jobId: string;
+  target: string; // Database name, or snapshot:<id>
+  baseline: 'schema' | 'files' | 'template';
+  template?: string;
+  statements: string[]; // The migration validated
+  baselineDiff?: Omit<SchemaDiffData, 'source' | 'target'>; // What the shadow lacked of the current schema before migrating
+  diff?: Omit<SchemaDiffData, 'source' | 'target'>; // What the shadow lacks of the desired schema after migrating
+  matches: boolean;
+  warnings: string[];
+}
+
+// Also returned by GET /api/migrations/validate/{jobId}
+export interface MigrationValidationData {
+  job: NonNullable<DataCopyData['job']>;
+  validation: MigrationValidation;
+}
+
+// GET /api/watch, and each `diff` event of GET /api/watch/events
+export interface SchemaWatchData {
+  file: string;
+  format: 'sql' | 'dbml';
+  database: string;
+  checkedAt: string;
+  diff?: Omit<SchemaDiffData, 'source' | 'target'>; // Live database (before) vs. the file (after); absent on error
+  warnings: string[];
+  error?: string; // The file could not be read or parsed
+}
+
+export interface MigrationTemplateParam {
+  name: string;
+  kind: 'identifier' | 'type';
+  description?: string;
+  default?: string; // Used when no value is given; required otherwise
+}
+
+// Statements use {{table}}, {{param}} (quoted identifier, or type), and {{param.raw}} (bare identifier)
+export interface MigrationTemplate {
+  id: string;
+  name: string;
+  description?: string;
+  params: MigrationTemplateParam[];
+  up: string[];
+  down: string[]; // Empty when the template cannot be undone
+  builtin: boolean;
+  createdAt: string;
+  updatedAt: string;
+}
+
+// GET /api/templates
+export interface MigrationTemplatesData {
+  templates: MigrationTemplate[]; // Built-in first, then user-defined
+}
+
+// POST /api/templates and PUT /api/templates/{id}
+export type SaveMigrationTemplateRequest = Pick<MigrationTemplate, 'name' | 'description' | 'params' | 'up' | 'down'>;
+
+// POST /api/templates/{id}/apply; give tables or allTables
+export interface ApplyMigrationTemplateRequest {
+  tables?: string[];
+  allTables?: boolean; // Every table but foreign tables, partitions, and tables a parameter names
+  params?: Record<string, string>;
+}
+
+export interface ApplyMigrationTemplateData {
+  template: string;
+  tables: string[];
+  statements: string[];
+  inverse: string[]; // Run by undo; empty when the change cannot be undone
+}
+
+// POST /api/seed
+export interface SeedRequest {
+  rows?: number; // Per table, when tables is not given; default 100
+  tables?: Record<string, number>; // Row counts by table; only these are seeded
+  seed?: number; // Repeats the values of an earlier seed
+}
+
+export interface SeedColumnPlan {
+  column: string;
+  generator: string; // e.g. email, full-name, price, enum, sequence, foreign-key
+  unique?: boolean;
+  references?: string; // table.column, for foreign key columns
+}
+
+export interface SeedTablePlan {
+  table: string;
+  rows: number;
+  columns: SeedColumnPlan[]; // Columns not listed get their defaults
+}
+
+export interface SeedData {
+  dryRun?: boolean;
+  job?: NonNullable<DataCopyData['job']>; // Absent for ?dryRun=true
+  seed: number;
+  tables: SeedTablePlan[]; // In seed order, parents first
+  warnings: string[];
+}
+
+export interface StatementNotice {
+  severity: string; // e.g. NOTICE, WARNING
+  code: string;
+  message: string;
+  detail?: string;
+  hint?: string;
+}
+
+export interface StatementLog {
+  statement?: string; // Absent when the script could not be split to match the server's results
+  command: string; // Command tag, e.g. "ALTER TABLE" or "UPDATE 42"
+  durationMs: number;
+  rowsAffected: number;
+  notices?: StatementNotice[];
+  batched?: boolean; // Ran as one query with the rest of the script; durationMs is not per statement
+}
+
+// GET /api/migrations/{version}/log
+export interface MigrationLogData extends AppliedMigration {
+  statements: StatementLog[] | null; // null for migrations recorded before logs were kept, and squash baselines
+}
@@ -90,12 +90,19 @@ export const Utils = {
     const lookup: FkLookup = {};
     tables.forEach(table => {
       (table.foreignKeys || []).forEach(fk => {
-        lookup[`${table.name}.${fk.columnName}`] = fk;
+        fk.columns.forEach(col => {
+          lookup[`${table.name}.${col}`] = fk;
+        });
       });
     });
     return lookup;
   },
 
+  // Column referenced by one column of a (possibly composite) foreign key
+  referencedColumn(fk: ForeignKey, column: string): string {
+    return fk.referencesColumns[fk.columns.indexOf(column)] ?? '';
+  },
+
   // Escape HTML to prevent XSS
   escapeHtml(text: string): string {
     const div = document.createElement('div');